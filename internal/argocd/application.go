@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package argocd reads the state of Argo CD applications and drives their sync operations through kubectl, so that
+// no Argo CD CLI login is needed.
+package argocd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SyncWaveAnnotation orders the applications of the root-app: lower waves are synced first.
+	SyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+	// RefreshAnnotation requests Argo CD to compare an application with its sources again.
+	RefreshAnnotation = "argocd.argoproj.io/refresh"
+
+	// RootApp is the application all Orchestrator applications are generated from.
+	RootApp = "root-app"
+)
+
+// Health and sync statuses and operation phases as reported by Argo CD.
+const (
+	Healthy     = "Healthy"
+	Degraded    = "Degraded"
+	Progressing = "Progressing"
+	Missing     = "Missing"
+
+	Synced    = "Synced"
+	OutOfSync = "OutOfSync"
+
+	PhaseRunning = "Running"
+	PhaseFailed  = "Failed"
+	PhaseError   = "Error"
+)
+
+// Application is the part of an Argo CD Application the installers act on.
+type Application struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+
+	Status struct {
+		Health struct {
+			Status string `json:"status"`
+		} `json:"health"`
+
+		Sync struct {
+			Status string `json:"status"`
+		} `json:"sync"`
+
+		OperationState struct {
+			Phase     string    `json:"phase"`
+			Message   string    `json:"message"`
+			StartedAt time.Time `json:"startedAt"`
+		} `json:"operationState"`
+
+		Conditions []Condition `json:"conditions"`
+		Resources  []Resource  `json:"resources"`
+	} `json:"status"`
+}
+
+// Condition is an error or warning Argo CD reports for an application.
+type Condition struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Resource is a resource managed by an application.
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is the sync status of the resource.
+	Status string `json:"status"`
+	Health *struct {
+		Status string `json:"status"`
+	} `json:"health"`
+}
+
+// HealthStatus returns the health of the resource, or an empty string if Argo CD doesn't assess it.
+func (r Resource) HealthStatus() string {
+	if r.Health == nil {
+		return ""
+	}
+	return r.Health.Status
+}
+
+func (r Resource) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// Name returns the name of the application.
+func (a Application) Name() string {
+	return a.Metadata.Name
+}
+
+// Wave returns the sync wave of the application. Applications without a valid wave are in wave 0, like Argo CD does.
+func (a Application) Wave() int {
+	wave, err := strconv.Atoi(strings.TrimSpace(a.Metadata.Annotations[SyncWaveAnnotation]))
+	if err != nil {
+		return 0
+	}
+	return wave
+}
+
+// Health returns the health status of the application.
+func (a Application) Health() string {
+	return a.Status.Health.Status
+}
+
+// Sync returns the sync status of the application.
+func (a Application) Sync() string {
+	return a.Status.Sync.Status
+}
+
+// Green reports whether the application is synced and healthy.
+func (a Application) Green() bool {
+	return a.Health() == Healthy && a.Sync() == Synced
+}
+
+// OperationPhase returns the phase of the last sync operation, or an empty string if it was never synced.
+func (a Application) OperationPhase() string {
+	return a.Status.OperationState.Phase
+}
+
+// OperationFailed reports whether the last sync operation failed.
+func (a Application) OperationFailed() bool {
+	phase := a.OperationPhase()
+	return phase == PhaseFailed || phase == PhaseError
+}
+
+// ResourcesOfKind returns the resources of the given kinds for which match returns true.
+func (a Application) ResourcesOfKind(match func(Resource) bool, kinds ...string) []Resource {
+	var resources []Resource
+	for _, resource := range a.Status.Resources {
+		if slices.Contains(kinds, resource.Kind) && match(resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+func (a Application) String() string {
+	return fmt.Sprintf("%s (wave %d, health %s, sync %s)", a.Name(), a.Wave(), orUnknown(a.Health()),
+		orUnknown(a.Sync()))
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "Unknown"
+	}
+	return s
+}
+
+// ParseApplication parses the JSON output of kubectl get application.
+func ParseApplication(data []byte) (Application, error) {
+	var app Application
+	if err := json.Unmarshal(data, &app); err != nil {
+		return Application{}, fmt.Errorf("failed to parse application: %w", err)
+	}
+	return app, nil
+}
+
+// ParseApplications parses the JSON output of kubectl get applications.
+func ParseApplications(data []byte) ([]Application, error) {
+	var list struct {
+		Items []Application `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse applications: %w", err)
+	}
+	return list.Items, nil
+}
+
+// SortByWave sorts applications in the order Argo CD syncs them: by wave, then by name.
+func SortByWave(apps []Application) {
+	slices.SortStableFunc(apps, func(a, b Application) int {
+		return cmp.Or(cmp.Compare(a.Wave(), b.Wave()), strings.Compare(a.Name(), b.Name()))
+	})
+}
+
+// NotGreen returns the applications that are not synced and healthy.
+func NotGreen(apps []Application) []Application {
+	var notGreen []Application
+	for _, app := range apps {
+		if !app.Green() {
+			notGreen = append(notGreen, app)
+		}
+	}
+	return notGreen
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argocd_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+const applications = `{"items": [
+  {"metadata": {"name": "web-ui", "annotations": {"argocd.argoproj.io/sync-wave": "2000"}},
+   "status": {"health": {"status": "Healthy"}, "sync": {"status": "Synced"}}},
+  {"metadata": {"name": "root-app"},
+   "status": {"health": {"status": "Progressing"}, "sync": {"status": "OutOfSync"}}},
+  {"metadata": {"name": "namespace-label", "annotations": {"argocd.argoproj.io/sync-wave": "-10"}},
+   "status": {"health": {"status": "Degraded"}, "sync": {"status": "Synced"},
+     "operationState": {"phase": "Failed"},
+     "resources": [
+       {"kind": "Job", "namespace": "ns-label", "name": "label-job", "health": {"status": "Degraded"}},
+       {"kind": "ConfigMap", "namespace": "ns-label", "name": "config", "health": {"status": "Degraded"}},
+       {"kind": "Job", "namespace": "ns-label", "name": "done-job", "health": {"status": "Healthy"}}
+     ]}},
+  {"metadata": {"name": "cert-manager", "annotations": {"argocd.argoproj.io/sync-wave": "-10"}},
+   "status": {"health": {"status": "Healthy"}, "sync": {"status": "Synced"}}},
+  {"metadata": {"name": "broken-wave", "annotations": {"argocd.argoproj.io/sync-wave": "soon"}},
+   "status": {}}
+]}`
+
+// recorder records the commands that change the cluster and answers queries from responses.
+type recorder struct {
+	executor.Local
+	responses map[string]string
+	commands  []string
+}
+
+func (r *recorder) Run(name string, args ...string) error {
+	r.commands = append(r.commands, strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+func (r *recorder) Query(name string, args ...string) (string, error) {
+	return r.responses[strings.Join(append([]string{name}, args...), " ")], nil
+}
+
+var _ = Describe("Application", func() {
+	var apps []argocd.Application
+
+	BeforeEach(func() {
+		var err error
+		apps, err = argocd.ParseApplications([]byte(applications))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	names := func(apps []argocd.Application) []string {
+		var names []string
+		for _, app := range apps {
+			names = append(names, app.Name())
+		}
+		return names
+	}
+
+	It("sorts applications by wave, then by name", func() {
+		argocd.SortByWave(apps)
+		Expect(names(apps)).To(Equal([]string{"cert-manager", "namespace-label", "broken-wave", "root-app", "web-ui"}))
+	})
+
+	It("finds the applications that are not synced and healthy", func() {
+		Expect(names(argocd.NotGreen(apps))).To(Equal([]string{"root-app", "namespace-label", "broken-wave"}))
+	})
+
+	It("reports the state of the last sync operation", func() {
+		Expect(apps[2].OperationFailed()).To(BeTrue())
+		Expect(apps[1].OperationFailed()).To(BeFalse())
+		Expect(apps[4].String()).To(Equal("broken-wave (wave 0, health Unknown, sync Unknown)"))
+	})
+
+	It("filters resources by kind", func() {
+		unhealthy := apps[2].ResourcesOfKind(func(r argocd.Resource) bool {
+			return r.HealthStatus() != argocd.Healthy
+		}, "Job", "CustomResourceDefinition")
+		Expect(unhealthy).To(HaveLen(1))
+		Expect(unhealthy[0].String()).To(Equal("Job/ns-label/label-job"))
+	})
+})
+
+var _ = Describe("Client", func() {
+	var (
+		x      *recorder
+		client argocd.Client
+	)
+
+	BeforeEach(func() {
+		x = &recorder{responses: map[string]string{}}
+		client = argocd.Client{X: x, Namespace: "onprem"}
+	})
+
+	It("lists the applications of all namespaces in wave order", func() {
+		x.responses["kubectl get applications.argoproj.io --all-namespaces -o json"] = applications
+		apps, err := argocd.ListAll(x)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(HaveLen(5))
+		Expect(apps[0].Name()).To(Equal("cert-manager"))
+	})
+
+	It("starts sync operations with the requested options", func() {
+		Expect(client.Sync("external-secrets", argocd.SyncOptions{Force: true, Replace: true, ServerSideApply: true})).
+			To(Succeed())
+		Expect(x.commands).To(ConsistOf(`kubectl patch applications.argoproj.io external-secrets -n onprem --type merge ` +
+			`-p {"operation":{"initiatedBy":{"username":"admin"},"sync":{"prune":false,` +
+			`"syncOptions":["Replace=true","ServerSideApply=true"],"syncStrategy":{"hook":{"force":true}}}}}`))
+	})
+
+	It("terminates running operations and drops pending ones", func() {
+		running := argocd.Application{}
+		running.Metadata.Name = "a"
+		running.Status.OperationState.Phase = argocd.PhaseRunning
+		pending := argocd.Application{}
+		pending.Metadata.Name = "b"
+
+		Expect(client.Terminate(running)).To(Succeed())
+		Expect(client.Terminate(pending)).To(Succeed())
+		Expect(x.commands).To(Equal([]string{
+			`kubectl patch applications.argoproj.io a -n onprem --type merge -p ` +
+				`{"operation":null,"status":{"operationState":{"phase":"Terminating"}}}`,
+			`kubectl patch applications.argoproj.io b -n onprem --type merge -p {"operation":null}`,
+		}))
+	})
+
+	It("only removes the state of the last operation if there is one", func() {
+		x.responses["kubectl get applications.argoproj.io root-app -n onprem -o json"] =
+			`{"metadata": {"name": "root-app"}, "status": {"operationState": {"phase": "Succeeded"}}}`
+		Expect(client.ResetOperation("root-app")).To(Succeed())
+		Expect(x.commands).To(HaveExactElements(
+			`kubectl patch applications.argoproj.io root-app -n onprem --type merge -p {"operation":null}`,
+			`kubectl patch applications.argoproj.io root-app -n onprem --type json -p `+
+				`[{"op":"remove","path":"/status/operationState"}]`,
+		))
+
+		x.commands = nil
+		x.responses["kubectl get applications.argoproj.io root-app -n onprem -o json"] = `{"metadata": {"name": "root-app"}}`
+		Expect(client.ResetOperation("root-app")).To(Succeed())
+		Expect(x.commands).To(HaveLen(1))
+	})
+
+	It("skips deleting applications that don't exist", func() {
+		Expect(client.Delete("tenancy-datamodel")).To(Succeed())
+		Expect(x.commands).To(BeEmpty())
+
+		x.responses["kubectl get applications.argoproj.io tenancy-datamodel -n onprem --ignore-not-found -o name"] =
+			"application.argoproj.io/tenancy-datamodel"
+		Expect(client.Delete("tenancy-datamodel")).To(Succeed())
+		Expect(x.commands).To(HaveExactElements(
+			`kubectl patch applications.argoproj.io tenancy-datamodel -n onprem --type merge -p {"metadata":{"finalizers":null}}`,
+			"kubectl delete applications.argoproj.io tenancy-datamodel -n onprem --ignore-not-found --wait=false",
+		))
+	})
+})
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argocd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// Client acts on the applications in Namespace with kubectl.
+type Client struct {
+	X         executor.Executor
+	Namespace string
+}
+
+// SyncOptions configure a sync operation.
+type SyncOptions struct {
+	// Prune deletes the resources that are no longer part of the application.
+	Prune bool
+	// Force deletes and recreates resources that can't be patched.
+	Force bool
+	// Replace replaces resources instead of applying them.
+	Replace bool
+	// ServerSideApply applies resources server-side, which avoids the annotation size limit of large CRDs.
+	ServerSideApply bool
+}
+
+// Get returns the application called name.
+func (c Client) Get(name string) (Application, error) {
+	out, err := c.X.Query("kubectl", "get", "applications.argoproj.io", name, "-n", c.Namespace, "-o", "json")
+	if err != nil {
+		return Application{}, fmt.Errorf("failed to get application %s: %w", name, err)
+	}
+	return ParseApplication([]byte(out))
+}
+
+// List returns all applications in the order Argo CD syncs them.
+func (c Client) List() ([]Application, error) {
+	out, err := c.X.Query("kubectl", "get", "applications.argoproj.io", "-n", c.Namespace, "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	apps, err := ParseApplications([]byte(out))
+	if err != nil {
+		return nil, err
+	}
+	SortByWave(apps)
+	return apps, nil
+}
+
+// ListAll returns the applications in all namespaces in the order Argo CD syncs them.
+func ListAll(x executor.Executor) ([]Application, error) {
+	out, err := x.Query("kubectl", "get", "applications.argoproj.io", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	apps, err := ParseApplications([]byte(out))
+	if err != nil {
+		return nil, err
+	}
+	SortByWave(apps)
+	return apps, nil
+}
+
+// Sync starts a sync operation of the application, replacing any operation that has not started yet.
+func (c Client) Sync(name string, opts SyncOptions) error {
+	var syncOptions []string
+	if opts.Replace {
+		syncOptions = append(syncOptions, "Replace=true")
+	}
+	if opts.ServerSideApply {
+		syncOptions = append(syncOptions, "ServerSideApply=true")
+	}
+	sync := map[string]any{
+		"syncStrategy": map[string]any{"hook": map[string]any{"force": opts.Force}},
+		"prune":        opts.Prune,
+	}
+	if len(syncOptions) > 0 {
+		sync["syncOptions"] = syncOptions
+	}
+	return c.patch(name, "merge", map[string]any{
+		"operation": map[string]any{
+			"initiatedBy": map[string]any{"username": "admin"},
+			"sync":        sync,
+		},
+	})
+}
+
+// Terminate stops the sync operation of the application. A running operation is terminated by Argo CD, a pending one
+// is dropped.
+func (c Client) Terminate(app Application) error {
+	patch := map[string]any{"operation": nil}
+	if app.OperationPhase() == PhaseRunning {
+		patch["status"] = map[string]any{"operationState": map[string]any{"phase": "Terminating"}}
+	}
+	return c.patch(app.Name(), "merge", patch)
+}
+
+// ResetOperation drops the operation of the application together with the state of the last one, so that Argo CD
+// doesn't retry it.
+func (c Client) ResetOperation(name string) error {
+	if err := c.patch(name, "merge", map[string]any{"operation": nil}); err != nil {
+		return err
+	}
+	app, err := c.Get(name)
+	if err != nil {
+		return err
+	}
+	if app.OperationPhase() == "" {
+		return nil
+	}
+	return c.patch(name, "json", []map[string]any{{"op": "remove", "path": "/status/operationState"}})
+}
+
+// Refresh requests Argo CD to compare the application with its sources again. A hard refresh also discards the
+// cached manifests.
+func (c Client) Refresh(name string, hard bool) error {
+	refresh := "normal"
+	if hard {
+		refresh = "hard"
+	}
+	if err := c.X.Run("kubectl", "annotate", "applications.argoproj.io", name, "-n", c.Namespace, "--overwrite",
+		RefreshAnnotation+"="+refresh); err != nil {
+		return fmt.Errorf("failed to refresh application %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete deletes the application without waiting for Argo CD to delete its resources. Deleting an application that
+// doesn't exist is not an error.
+func (c Client) Delete(name string) error {
+	found, err := c.X.Query("kubectl", "get", "applications.argoproj.io", name, "-n", c.Namespace, "--ignore-not-found",
+		"-o", "name")
+	if err != nil {
+		return fmt.Errorf("failed to get application %s: %w", name, err)
+	}
+	if found == "" {
+		return nil
+	}
+	if err := c.patch(name, "merge", map[string]any{"metadata": map[string]any{"finalizers": nil}}); err != nil {
+		return err
+	}
+	if err := c.X.Run("kubectl", "delete", "applications.argoproj.io", name, "-n", c.Namespace, "--ignore-not-found",
+		"--wait=false"); err != nil {
+		return fmt.Errorf("failed to delete application %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c Client) patch(name, patchType string, patch any) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if err := c.X.Run("kubectl", "patch", "applications.argoproj.io", name, "-n", c.Namespace, "--type", patchType,
+		"-p", string(data)); err != nil {
+		return fmt.Errorf("failed to patch application %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argoheal_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestArgoHeal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Argo CD Heal Suite")
+}
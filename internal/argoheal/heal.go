@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package argoheal detects known failures of Argo CD applications and remediates them. Applications are remediated
+// wave by wave, since an application can't get healthy before the waves it depends on, within rate limits, and every
+// remediation is recorded in an audit log.
+package argoheal
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// Limits bound how often applications are remediated. Zero values other than Grace are replaced by the defaults.
+type Limits struct {
+	// Grace is how long an application must have been seen not synced and healthy before it is remediated, so that
+	// Argo CD gets the chance to recover by itself. Zero remediates at once.
+	Grace time.Duration
+	// Cooldown is the minimum time between two remediations of the same failure of an application, 3m by default.
+	Cooldown time.Duration
+	// MaxPerApp bounds the remediations of an application within Window, 3 by default.
+	MaxPerApp int
+	// Window is the period MaxPerApp applies to, 30m by default.
+	Window time.Duration
+	// MaxPerPass bounds the remediations of a single pass over the applications, 5 by default.
+	MaxPerPass int
+}
+
+// Action is a remediation as recorded in the audit log.
+type Action struct {
+	Time      time.Time `json:"time"`
+	App       string    `json:"app"`
+	Namespace string    `json:"namespace"`
+	Wave      int       `json:"wave"`
+	Signature string    `json:"signature"`
+	Health    string    `json:"health"`
+	Sync      string    `json:"sync"`
+	Phase     string    `json:"phase,omitempty"`
+	// Error is why the remediation failed, empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Healer remediates the applications of a cluster. A Healer keeps track of the applications between passes, so the
+// same Healer should be used for all passes of a deployment.
+type Healer struct {
+	X executor.Executor
+	// Signatures are tried in order, the first one matching an application is remediated. DefaultSignatures are used
+	// if empty.
+	Signatures []Signature
+	Limits     Limits
+	// AuditLog is the file the remediations are appended to as JSON lines. The remediations already in it count
+	// towards the rate limits, so that they also apply across runs. Nothing is recorded if empty.
+	AuditLog string
+	Log      *slog.Logger
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+
+	loaded bool
+	// history are the remediations within the rate limit window.
+	history []Action
+	// notGreenSince is when each application was first seen not synced and healthy.
+	notGreenSince map[string]time.Time
+}
+
+// Heal makes one pass over the applications and remediates the failures of the applications that block the
+// deployment: those of the lowest wave that is not synced and healthy, or the root-app once all others are. It
+// returns the remediations made, including those that failed.
+func (h *Healer) Heal() ([]Action, error) {
+	if err := h.loadAudit(); err != nil {
+		return nil, err
+	}
+	apps, err := argocd.ListAll(h.X)
+	if err != nil {
+		return nil, err
+	}
+	now := h.now()
+	h.track(apps, now)
+
+	signatures := h.Signatures
+	if len(signatures) == 0 {
+		signatures = DefaultSignatures()
+	}
+
+	var actions []Action
+	for _, app := range blocking(apps) {
+		log := h.Log.With("app", app.Name(), "namespace", app.Metadata.Namespace, "wave", app.Wave())
+		signature, ok := match(signatures, app, now)
+		if !ok {
+			continue
+		}
+		if since := h.notGreenSince[appKey(app)]; now.Sub(since) < h.Limits.Grace {
+			log.Debug("failure within the grace period", "signature", signature.Name, "since", since)
+			continue
+		}
+		if reason := h.limited(app, signature, now); reason != "" {
+			log.Info("remediation rate limited", "signature", signature.Name, "reason", reason)
+			continue
+		}
+		if len(actions) >= h.maxPerPass() {
+			log.Info("remediation deferred to the next pass", "signature", signature.Name,
+				"maxPerPass", h.maxPerPass())
+			continue
+		}
+
+		action := Action{
+			Time:      now,
+			App:       app.Name(),
+			Namespace: app.Metadata.Namespace,
+			Wave:      app.Wave(),
+			Signature: signature.Name,
+			Health:    app.Health(),
+			Sync:      app.Sync(),
+			Phase:     app.OperationPhase(),
+		}
+		log.Info("remediating application", "signature", signature.Name, "health", app.Health(), "sync", app.Sync())
+		target := Target{App: app, Apps: argocd.Client{X: h.X, Namespace: app.Metadata.Namespace}, X: h.X}
+		if err := signature.Remediate(target); err != nil {
+			action.Error = err.Error()
+			log.Warn("remediation failed", "signature", signature.Name, "error", err)
+		}
+		h.history = append(h.history, action)
+		actions = append(actions, action)
+		if err := h.audit(action); err != nil {
+			return actions, err
+		}
+	}
+	return actions, nil
+}
+
+// blocking returns the applications that are not synced and healthy in the lowest such wave, or the root-apps if
+// all other applications are synced and healthy.
+func blocking(apps []argocd.Application) []argocd.Application {
+	var children, roots []argocd.Application
+	for _, app := range argocd.NotGreen(apps) {
+		if app.Name() == argocd.RootApp {
+			roots = append(roots, app)
+		} else {
+			children = append(children, app)
+		}
+	}
+	if len(children) == 0 {
+		return roots
+	}
+	// The applications are sorted by wave.
+	wave := children[0].Wave()
+	var blocked []argocd.Application
+	for _, app := range children {
+		if app.Wave() == wave {
+			blocked = append(blocked, app)
+		}
+	}
+	return blocked
+}
+
+func match(signatures []Signature, app argocd.Application, now time.Time) (Signature, bool) {
+	for _, signature := range signatures {
+		if signature.Match(app, now) {
+			return signature, true
+		}
+	}
+	return Signature{}, false
+}
+
+// track records when applications got not synced and healthy and forgets those that recovered.
+func (h *Healer) track(apps []argocd.Application, now time.Time) {
+	if h.notGreenSince == nil {
+		h.notGreenSince = map[string]time.Time{}
+	}
+	seen := map[string]bool{}
+	for _, app := range argocd.NotGreen(apps) {
+		key := appKey(app)
+		seen[key] = true
+		if _, ok := h.notGreenSince[key]; !ok {
+			h.notGreenSince[key] = now
+		}
+	}
+	for key := range h.notGreenSince {
+		if !seen[key] {
+			delete(h.notGreenSince, key)
+		}
+	}
+}
+
+// limited returns why the application can't be remediated for the failure now, or an empty string if it can.
+func (h *Healer) limited(app argocd.Application, signature Signature, now time.Time) string {
+	window := cmp.Or(h.Limits.Window, 30*time.Minute)
+	cooldown := cmp.Or(h.Limits.Cooldown, 3*time.Minute)
+	maxPerApp := cmp.Or(h.Limits.MaxPerApp, 3)
+
+	var recent int
+	var last time.Time
+	for _, action := range h.history {
+		if action.App != app.Name() || action.Namespace != app.Metadata.Namespace || now.Sub(action.Time) >= window {
+			continue
+		}
+		recent++
+		if action.Signature == signature.Name && action.Time.After(last) {
+			last = action.Time
+		}
+	}
+	switch {
+	case recent >= maxPerApp:
+		return fmt.Sprintf("%d remediations within %s", recent, window)
+	case !last.IsZero() && now.Sub(last) < cooldown:
+		return fmt.Sprintf("remediated %s ago, cooldown is %s", now.Sub(last).Round(time.Second), cooldown)
+	}
+	return ""
+}
+
+func (h *Healer) maxPerPass() int {
+	return cmp.Or(h.Limits.MaxPerPass, 5)
+}
+
+func (h *Healer) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+// loadAudit reads the remediations of earlier runs from the audit log, once.
+func (h *Healer) loadAudit() error {
+	if h.loaded || h.AuditLog == "" {
+		return nil
+	}
+	data, err := os.ReadFile(h.AuditLog)
+	if errors.Is(err, fs.ErrNotExist) {
+		h.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	h.history, err = parseAudit(data)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log %s: %w", h.AuditLog, err)
+	}
+	h.loaded = true
+	return nil
+}
+
+// parseAudit parses the JSON lines of an audit log.
+func parseAudit(data []byte) ([]Action, error) {
+	var actions []Action
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var action Action
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, scanner.Err()
+}
+
+func (h *Healer) audit(action Action) error {
+	if h.AuditLog == "" {
+		return nil
+	}
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	if err := h.X.AppendFile(h.AuditLog, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func appKey(app argocd.Application) string {
+	return app.Metadata.Namespace + "/" + app.Name()
+}
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argoheal_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// cluster serves apps to kubectl and records the commands that change the cluster.
+type cluster struct {
+	executor.Local
+	apps      []argocd.Application
+	responses map[string]string
+	commands  []string
+}
+
+func (c *cluster) Run(name string, args ...string) error {
+	c.commands = append(c.commands, strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+func (c *cluster) Query(name string, args ...string) (string, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	if command == "kubectl get applications.argoproj.io --all-namespaces -o json" {
+		data, err := json.Marshal(map[string]any{"items": c.apps})
+		return string(data), err
+	}
+	if len(args) == 7 && strings.HasPrefix(command, "kubectl get applications.argoproj.io ") {
+		for _, app := range c.apps {
+			if app.Name() == args[2] {
+				data, err := json.Marshal(app)
+				return string(data), err
+			}
+		}
+	}
+	return c.responses[command], nil
+}
+
+// synced reports how often the application was synced.
+func (c *cluster) synced(name string) int {
+	prefix := "kubectl patch applications.argoproj.io " + name + " -n "
+	return len(slices.DeleteFunc(slices.Clone(c.commands), func(command string) bool {
+		return !strings.HasPrefix(command, prefix) || !strings.Contains(command, `"sync":`)
+	}))
+}
+
+// application returns an application in the onprem namespace.
+func application(name string, wave int, health, sync, phase string) argocd.Application {
+	var app argocd.Application
+	app.Metadata.Name = name
+	app.Metadata.Namespace = "onprem"
+	app.Metadata.Annotations = map[string]string{argocd.SyncWaveAnnotation: fmt.Sprint(wave)}
+	app.Status.Health.Status = health
+	app.Status.Sync.Status = sync
+	app.Status.OperationState.Phase = phase
+	return app
+}
+
+func green(name string, wave int) argocd.Application {
+	return application(name, wave, argocd.Healthy, argocd.Synced, "Succeeded")
+}
+
+func failed(name string, wave int) argocd.Application {
+	return application(name, wave, argocd.Degraded, argocd.OutOfSync, argocd.PhaseFailed)
+}
+
+var _ = Describe("Healer", func() {
+	var (
+		c      *cluster
+		now    time.Time
+		healer *argoheal.Healer
+	)
+
+	BeforeEach(func() {
+		c = &cluster{responses: map[string]string{}}
+		now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		healer = &argoheal.Healer{
+			X:   c,
+			Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Now: func() time.Time { return now },
+		}
+	})
+
+	It("only remediates the lowest wave that is not synced and healthy", func() {
+		c.apps = []argocd.Application{
+			application(argocd.RootApp, 0, argocd.Progressing, argocd.OutOfSync, argocd.PhaseRunning),
+			green("cert-manager", -10),
+			failed("vault", 100),
+			failed("keycloak", 100),
+			failed("web-ui", 2000),
+		}
+
+		actions, err := healer.Heal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actions).To(HaveLen(2))
+		Expect(actions[0]).To(haveAction("keycloak", argoheal.SignatureFailedSync))
+		Expect(actions[1]).To(haveAction("vault", argoheal.SignatureFailedSync))
+		Expect(c.synced("vault")).To(Equal(1))
+		Expect(c.synced("web-ui")).To(BeZero())
+		Expect(c.synced(argocd.RootApp)).To(BeZero())
+	})
+
+	It("syncs the root-app again once all other applications are synced and healthy", func() {
+		c.apps = []argocd.Application{
+			application(argocd.RootApp, 0, argocd.Healthy, argocd.OutOfSync, "Succeeded"),
+			green("vault", 100),
+		}
+
+		actions, err := healer.Heal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].Signature).To(Equal(argoheal.SignatureRootAppStalled))
+		Expect(c.synced(argocd.RootApp)).To(Equal(1))
+	})
+
+	It("leaves failures alone during the grace period", func() {
+		healer.Limits.Grace = 5 * time.Minute
+		c.apps = []argocd.Application{failed("vault", 100)}
+
+		Expect(healer.Heal()).To(BeEmpty())
+		now = now.Add(5 * time.Minute)
+		Expect(healer.Heal()).To(HaveLen(1))
+	})
+
+	It("waits for the cooldown and stops after the maximum remediations of an application", func() {
+		healer.Limits = argoheal.Limits{Cooldown: time.Minute, MaxPerApp: 2, Window: time.Hour}
+		c.apps = []argocd.Application{failed("vault", 100)}
+
+		Expect(healer.Heal()).To(HaveLen(1))
+		now = now.Add(30 * time.Second)
+		Expect(healer.Heal()).To(BeEmpty())
+		now = now.Add(time.Minute)
+		Expect(healer.Heal()).To(HaveLen(1))
+		now = now.Add(10 * time.Minute)
+		Expect(healer.Heal()).To(BeEmpty())
+		now = now.Add(time.Hour)
+		Expect(healer.Heal()).To(HaveLen(1))
+	})
+
+	It("defers remediations beyond the maximum of a pass", func() {
+		healer.Limits.MaxPerPass = 2
+		c.apps = []argocd.Application{failed("a", 1), failed("b", 1), failed("c", 1)}
+
+		Expect(healer.Heal()).To(HaveLen(2))
+		Expect(healer.Heal()).To(HaveLen(1))
+		Expect(c.synced("c")).To(Equal(1))
+	})
+
+	It("records remediations in the audit log and applies the rate limits across runs", func() {
+		auditLog := filepath.Join(GinkgoT().TempDir(), "audit.log")
+		healer.AuditLog = auditLog
+		c.apps = []argocd.Application{failed("vault", 100)}
+
+		Expect(healer.Heal()).To(HaveLen(1))
+
+		data, err := os.ReadFile(auditLog)
+		Expect(err).NotTo(HaveOccurred())
+		var action argoheal.Action
+		Expect(json.Unmarshal(data, &action)).To(Succeed())
+		Expect(action.App).To(Equal("vault"))
+		Expect(action.Namespace).To(Equal("onprem"))
+		Expect(action.Wave).To(Equal(100))
+		Expect(action.Signature).To(Equal(argoheal.SignatureFailedSync))
+		Expect(action.Phase).To(Equal(argocd.PhaseFailed))
+		Expect(action.Time).To(BeTemporally("==", now))
+
+		next := &argoheal.Healer{X: c, Log: healer.Log, Now: healer.Now, AuditLog: auditLog}
+		Expect(next.Heal()).To(BeEmpty())
+		now = now.Add(5 * time.Minute)
+		Expect(next.Heal()).To(HaveLen(1))
+	})
+
+	It("records failed remediations", func() {
+		healer.Signatures = []argoheal.Signature{{
+			Name:      "broken",
+			Match:     func(argocd.Application, time.Time) bool { return true },
+			Remediate: func(argoheal.Target) error { return fmt.Errorf("no luck") },
+		}}
+		c.apps = []argocd.Application{failed("vault", 100)}
+
+		actions, err := healer.Heal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].Error).To(Equal("no luck"))
+	})
+})
+
+// haveAction matches an action by its application and signature.
+func haveAction(app, signature string) OmegaMatcher {
+	return And(
+		WithTransform(func(a argoheal.Action) string { return a.App }, Equal(app)),
+		WithTransform(func(a argoheal.Action) string { return a.Signature }, Equal(signature)),
+	)
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argoheal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// stuckOperationAfter is how long a sync operation may run before it is considered stuck.
+const stuckOperationAfter = 20 * time.Minute
+
+// Names of the default signatures.
+const (
+	SignatureRootAppStalled   = "root-app-stalled"
+	SignatureServerSideApply  = "server-side-apply-required"
+	SignatureDegradedJob      = "degraded-job"
+	SignatureStuckOperation   = "stuck-operation"
+	SignatureFailedSync       = "failed-sync"
+	SignatureComparisonError  = "comparison-error"
+	comparisonErrorCondition  = "ComparisonError"
+	annotationTooLongFragment = "Too long: must have at most"
+)
+
+// Signature is a known failure of an application and its remediation.
+type Signature struct {
+	// Name identifies the failure in the logs and the audit log.
+	Name string
+	// Match reports whether the application shows the failure at now.
+	Match func(app argocd.Application, now time.Time) bool
+	// Remediate fixes the failure of the target application.
+	Remediate func(t Target) error
+}
+
+// Target is an application being remediated and the means to act on it.
+type Target struct {
+	App argocd.Application
+	// Apps acts on the applications in the namespace of App.
+	Apps argocd.Client
+	X    executor.Executor
+}
+
+// DefaultSignatures returns the failures known to block Orchestrator deployments and upgrades, most specific first.
+func DefaultSignatures() []Signature {
+	return []Signature{
+		{
+			// The root-app only needs a new sync once the applications it deploys are synced and healthy.
+			Name: SignatureRootAppStalled,
+			Match: func(app argocd.Application, _ time.Time) bool {
+				return app.Name() == argocd.RootApp && app.OperationPhase() != argocd.PhaseRunning
+			},
+			Remediate: func(t Target) error {
+				return ResyncRootApp(t.Apps)
+			},
+		},
+		{
+			// Large CRDs exceed the size limit of the last-applied annotation of a client-side apply, and CRDs whose
+			// schema changed can't be patched.
+			Name: SignatureServerSideApply,
+			Match: func(app argocd.Application, _ time.Time) bool {
+				return app.OperationFailed() &&
+					(strings.Contains(app.Status.OperationState.Message, annotationTooLongFragment) ||
+						len(outOfSyncCRDs(app)) > 0)
+			},
+			Remediate: func(t Target) error {
+				return t.Resync(argocd.SyncOptions{Replace: true, ServerSideApply: true})
+			},
+		},
+		{
+			// A job that failed keeps failing the sync until it is deleted and created again.
+			Name: SignatureDegradedJob,
+			Match: func(app argocd.Application, _ time.Time) bool {
+				return len(DegradedJobs(app)) > 0
+			},
+			Remediate: func(t Target) error {
+				var errs []error
+				for _, job := range DegradedJobs(t.App) {
+					errs = append(errs, t.DeleteJob(job.Namespace, job.Name))
+				}
+				if err := errors.Join(errs...); err != nil {
+					return err
+				}
+				return t.Resync(argocd.SyncOptions{})
+			},
+		},
+		{
+			// An operation waiting for a hook or resource that never gets healthy doesn't time out.
+			Name: SignatureStuckOperation,
+			Match: func(app argocd.Application, now time.Time) bool {
+				startedAt := app.Status.OperationState.StartedAt
+				return app.OperationPhase() == argocd.PhaseRunning && !startedAt.IsZero() &&
+					now.Sub(startedAt) > stuckOperationAfter
+			},
+			Remediate: func(t Target) error {
+				return t.Resync(argocd.SyncOptions{})
+			},
+		},
+		{
+			Name: SignatureFailedSync,
+			Match: func(app argocd.Application, _ time.Time) bool {
+				return app.OperationFailed()
+			},
+			Remediate: func(t Target) error {
+				return t.Resync(argocd.SyncOptions{})
+			},
+		},
+		{
+			// Manifests that failed to render, e.g. while a repository was unavailable, stay cached until a hard
+			// refresh.
+			Name: SignatureComparisonError,
+			Match: func(app argocd.Application, _ time.Time) bool {
+				return slices.ContainsFunc(app.Status.Conditions, func(c argocd.Condition) bool {
+					return c.Type == comparisonErrorCondition
+				})
+			},
+			Remediate: func(t Target) error {
+				return t.Apps.Refresh(t.App.Name(), true)
+			},
+		},
+	}
+}
+
+// Resync stops the operation of the application, discards its cached manifests and syncs it again.
+func (t Target) Resync(opts argocd.SyncOptions) error {
+	if err := t.Apps.Terminate(t.App); err != nil {
+		return err
+	}
+	if err := t.Apps.Refresh(t.App.Name(), true); err != nil {
+		return err
+	}
+	return t.Apps.Sync(t.App.Name(), opts)
+}
+
+// DeleteJob deletes a job of the application and its pods, see DeleteJob.
+func (t Target) DeleteJob(namespace, name string) error {
+	return DeleteJob(t.X, namespace, name)
+}
+
+// ResyncRootApp drops the operation of the root-app, which may wait for applications that changed since it started,
+// and syncs it again.
+func ResyncRootApp(apps argocd.Client) error {
+	if err := apps.ResetOperation(argocd.RootApp); err != nil {
+		return err
+	}
+	return apps.Sync(argocd.RootApp, argocd.SyncOptions{})
+}
+
+// DeleteJob deletes a job and its pods after removing their finalizers, which would block the deletion, so that the
+// next sync creates the job again.
+func DeleteJob(x executor.Executor, namespace, name string) error {
+	out, err := x.Query("kubectl", "get", "pods", "-n", namespace, "-l", "job-name="+name, "-o", "name")
+	if err != nil {
+		return fmt.Errorf("failed to list pods of job %s/%s: %w", namespace, name, err)
+	}
+	var errs []error
+	for _, pod := range strings.Fields(out) {
+		errs = append(errs, removeFinalizers(x, namespace, pod))
+	}
+	errs = append(errs, removeFinalizers(x, namespace, "job/"+name))
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if err := x.Run("kubectl", "delete", "job", name, "-n", namespace, "--ignore-not-found",
+		"--cascade=background", "--wait=false"); err != nil {
+		return fmt.Errorf("failed to delete job %s/%s: %w", namespace, name, err)
+	}
+	if err := x.Run("kubectl", "delete", "pods", "-n", namespace, "-l", "job-name="+name, "--ignore-not-found",
+		"--wait=false"); err != nil {
+		return fmt.Errorf("failed to delete pods of job %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+func removeFinalizers(x executor.Executor, namespace, resource string) error {
+	if err := x.Run("kubectl", "patch", resource, "-n", namespace, "--type", "merge",
+		"-p", `{"metadata":{"finalizers":null}}`); err != nil {
+		return fmt.Errorf("failed to remove finalizers of %s/%s: %w", namespace, resource, err)
+	}
+	return nil
+}
+
+// DegradedJobs returns the jobs of an application that failed.
+func DegradedJobs(app argocd.Application) []argocd.Resource {
+	return app.ResourcesOfKind(func(r argocd.Resource) bool {
+		return r.HealthStatus() == argocd.Degraded
+	}, "Job")
+}
+
+func outOfSyncCRDs(app argocd.Application) []argocd.Resource {
+	return app.ResourcesOfKind(func(r argocd.Resource) bool {
+		return r.Status == argocd.OutOfSync
+	}, "CustomResourceDefinition")
+}
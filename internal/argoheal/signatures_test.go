@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package argoheal_test
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// signatureOf returns the name of the first default signature matching app, or an empty string.
+func signatureOf(app argocd.Application) string {
+	for _, signature := range argoheal.DefaultSignatures() {
+		if signature.Match(app, now) {
+			return signature.Name
+		}
+	}
+	return ""
+}
+
+func withResources(app argocd.Application, resources ...argocd.Resource) argocd.Application {
+	app.Status.Resources = resources
+	return app
+}
+
+func resource(kind, name, sync, health string) argocd.Resource {
+	r := argocd.Resource{Kind: kind, Namespace: "orch-platform", Name: name, Status: sync}
+	if health != "" {
+		r.Health = &struct {
+			Status string `json:"status"`
+		}{Status: health}
+	}
+	return r
+}
+
+var _ = Describe("DefaultSignatures", func() {
+	DescribeTable("match the failures of applications",
+		func(app argocd.Application, signature string) {
+			Expect(signatureOf(app)).To(Equal(signature))
+		},
+		Entry("a progressing application", application("vault", 100, argocd.Progressing, argocd.Synced,
+			argocd.PhaseRunning), ""),
+		Entry("a root-app that is not running", application(argocd.RootApp, 0, argocd.Healthy, argocd.OutOfSync,
+			"Succeeded"), argoheal.SignatureRootAppStalled),
+		Entry("a root-app that is running", application(argocd.RootApp, 0, argocd.Progressing, argocd.OutOfSync,
+			argocd.PhaseRunning), ""),
+		Entry("a CRD too large for a client-side apply", func() argocd.Application {
+			app := failed("external-secrets", 10)
+			app.Status.OperationState.Message = "CustomResourceDefinition.apiextensions.k8s.io " +
+				`"clustersecretstores.external-secrets.io" is invalid: metadata.annotations: ` +
+				"Too long: must have at most 262144 bytes"
+			return app
+		}(), argoheal.SignatureServerSideApply),
+		Entry("a failed sync of a changed CRD", withResources(failed("external-secrets", 10),
+			resource("CustomResourceDefinition", "secretstores.external-secrets.io", argocd.OutOfSync, "")),
+			argoheal.SignatureServerSideApply),
+		Entry("a degraded job", withResources(
+			application("namespace-label", -10, argocd.Degraded, argocd.Synced, argocd.PhaseRunning),
+			resource("Job", "label-job", argocd.Synced, argocd.Degraded)), argoheal.SignatureDegradedJob),
+		Entry("an operation running for too long", func() argocd.Application {
+			app := application("vault", 100, argocd.Progressing, argocd.OutOfSync, argocd.PhaseRunning)
+			app.Status.OperationState.StartedAt = now.Add(-time.Hour)
+			return app
+		}(), argoheal.SignatureStuckOperation),
+		Entry("an operation running for a short while", func() argocd.Application {
+			app := application("vault", 100, argocd.Progressing, argocd.OutOfSync, argocd.PhaseRunning)
+			app.Status.OperationState.StartedAt = now.Add(-time.Minute)
+			return app
+		}(), ""),
+		Entry("a failed sync", failed("vault", 100), argoheal.SignatureFailedSync),
+		Entry("a comparison error", func() argocd.Application {
+			app := application("vault", 100, argocd.Healthy, "Unknown", "")
+			app.Status.Conditions = []argocd.Condition{{Type: "ComparisonError", Message: "repository not found"}}
+			return app
+		}(), argoheal.SignatureComparisonError),
+	)
+
+	It("deletes degraded jobs and their pods without finalizers before syncing again", func() {
+		c := &cluster{responses: map[string]string{
+			"kubectl get pods -n orch-platform -l job-name=label-job -o name": "pod/label-job-abc\npod/label-job-def\n",
+		}}
+		c.apps = []argocd.Application{withResources(
+			application("namespace-label", -10, argocd.Degraded, argocd.Synced, "Succeeded"),
+			resource("Job", "label-job", argocd.Synced, argocd.Degraded),
+			resource("Job", "done-job", argocd.Synced, argocd.Healthy))}
+		healer := &argoheal.Healer{X: c, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+		Expect(healer.Heal()).To(HaveLen(1))
+		Expect(c.commands).To(HaveExactElements(
+			`kubectl patch pod/label-job-abc -n orch-platform --type merge -p {"metadata":{"finalizers":null}}`,
+			`kubectl patch pod/label-job-def -n orch-platform --type merge -p {"metadata":{"finalizers":null}}`,
+			`kubectl patch job/label-job -n orch-platform --type merge -p {"metadata":{"finalizers":null}}`,
+			"kubectl delete job label-job -n orch-platform --ignore-not-found --cascade=background --wait=false",
+			"kubectl delete pods -n orch-platform -l job-name=label-job --ignore-not-found --wait=false",
+			`kubectl patch applications.argoproj.io namespace-label -n onprem --type merge -p {"operation":null}`,
+			"kubectl annotate applications.argoproj.io namespace-label -n onprem --overwrite "+
+				"argocd.argoproj.io/refresh=hard",
+			`kubectl patch applications.argoproj.io namespace-label -n onprem --type merge -p `+
+				`{"operation":{"initiatedBy":{"username":"admin"},"sync":{"prune":false,`+
+				`"syncStrategy":{"hook":{"force":false}}}}}`,
+		))
+	})
+
+	It("syncs CRDs server-side", func() {
+		c := &cluster{responses: map[string]string{}}
+		c.apps = []argocd.Application{withResources(failed("external-secrets", 10),
+			resource("CustomResourceDefinition", "secretstores.external-secrets.io", argocd.OutOfSync, ""))}
+		healer := &argoheal.Healer{X: c, Log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+		Expect(healer.Heal()).To(HaveLen(1))
+		Expect(c.commands).To(ContainElement(ContainSubstring(`"syncOptions":["Replace=true","ServerSideApply=true"]`)))
+	})
+})
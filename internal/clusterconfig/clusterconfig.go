@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clusterconfig loads the cluster configurations in orch-configs/clusters, merges them with the profiles they
+// reference and validates the result.
+package clusterconfig
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Paths relative to the repository root.
+const (
+	// ClustersDir holds the cluster configurations.
+	ClustersDir = "orch-configs/clusters"
+	// RootAppValues are the defaults of the root-app chart the cluster configuration is installed with.
+	RootAppValues = "argocd/root-app/values.yaml"
+)
+
+// boilerplate is the comment every cluster configuration starts with, which says nothing about the cluster.
+const boilerplate = "Cluster specific values applied to root-app only"
+
+// Profile is a cluster configuration that can be installed.
+type Profile struct {
+	Name        string
+	Description string
+}
+
+// DeepMerge performs a deep merge of newValuesMap into baseMap.
+func DeepMerge(baseMap, newValuesMap map[string]interface{}) {
+	for key, newValue := range newValuesMap {
+		if baseValue, exists := baseMap[key]; exists {
+			// If both values are maps, perform a recursive merge.
+			baseMapAsMap, baseIsMap := baseValue.(map[string]interface{})
+			newValueAsMap, newIsMap := newValue.(map[string]interface{})
+			if baseIsMap && newIsMap {
+				DeepMerge(baseMapAsMap, newValueAsMap)
+			} else {
+				// Overwrite the value in baseMap if it's not a map or types differ.
+				baseMap[key] = newValue
+			}
+		} else {
+			// Add the new value to baseMap if it doesn't exist.
+			baseMap[key] = newValue
+		}
+	}
+}
+
+// Merge loads and merges values from a cluster configuration file and its referenced files, in the order Argo CD
+// applies them. Paths are relative to the repository root fsys.
+func Merge(fsys fs.FS, clusterConfigPath string) (map[string]interface{}, error) {
+	clusterConfigPath = path.Clean(clusterConfigPath)
+
+	rootConfig, err := readValues(fsys, clusterConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster configuration: %w", err)
+	}
+
+	root, ok := rootConfig["root"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid cluster definition: 'root' key is missing in the configuration")
+	}
+	clusterValuesPaths, ok := root["clusterValues"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid cluster definition: 'clusterValues' list is missing in the configuration")
+	}
+
+	clusterValues := make(map[string]interface{})
+	for _, entry := range clusterValuesPaths {
+		filePath, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid clusterValues entry, expected string but got %T", entry)
+		}
+		fileValues, err := readValues(fsys, path.Clean(filePath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster values file '%s': %w", filePath, err)
+		}
+		if path.Clean(filePath) == clusterConfigPath {
+			if root, ok := fileValues["root"].(map[string]interface{}); ok {
+				delete(root, "clusterValues")
+			}
+		}
+		DeepMerge(clusterValues, fileValues)
+	}
+
+	// merge the cluster template into itself
+	fileValues, err := readValues(fsys, clusterConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if root, ok := fileValues["root"].(map[string]interface{}); ok {
+		delete(root, "clusterValues")
+	}
+	DeepMerge(clusterValues, fileValues)
+
+	return clusterValues, nil
+}
+
+// Profiles lists the cluster configurations in fsys.
+func Profiles(fsys fs.FS) ([]Profile, error) {
+	files, err := fs.Glob(fsys, path.Join(ClustersDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]Profile, 0, len(files))
+	for _, file := range files {
+		profile := Profile{Name: strings.TrimSuffix(path.Base(file), ".yaml")}
+		if profile.Description, err = describe(fsys, file); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// Check validates that profile is one of the cluster configurations in fsys, merges its values over the root-app
+// chart defaults and validates the result. The error lists the valid profiles if profile is unknown.
+func Check(fsys fs.FS, profile string) (map[string]interface{}, error) {
+	profiles, err := Profiles(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster configurations: %w", err)
+	}
+
+	found := false
+	for _, p := range profiles {
+		found = found || p.Name == profile
+	}
+	if !found {
+		var choices strings.Builder
+		for _, p := range profiles {
+			fmt.Fprintf(&choices, "\n  %-24s %s", p.Name, p.Description)
+		}
+		return nil, fmt.Errorf("unknown profile %q, valid profiles are:%s", profile, choices.String())
+	}
+
+	clusterValues, err := Merge(fsys, path.Join(ClustersDir, profile+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge cluster values of profile %s: %w", profile, err)
+	}
+
+	values, err := readValues(fsys, RootAppValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root-app defaults: %w", err)
+	}
+	DeepMerge(values, clusterValues)
+	if err := Validate(values); err != nil {
+		return nil, fmt.Errorf("invalid cluster values for profile %s: %w", profile, err)
+	}
+	return values, nil
+}
+
+func readValues(fsys fs.FS, name string) (map[string]interface{}, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+	return values, nil
+}
+
+// describe returns the comment a cluster configuration starts with or, if that is only the boilerplate, a summary of
+// its target cluster, domain and the enable-* profiles it includes.
+func describe(fsys fs.FS, file string) (string, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return "", err
+	}
+
+	var comment []string
+lines:
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "# SPDX-"), line == "#", line == "" && len(comment) == 0:
+		case strings.HasPrefix(line, "#") && strings.TrimSpace(line[1:]) != boilerplate:
+			comment = append(comment, strings.TrimSpace(line[1:]))
+		default:
+			break lines
+		}
+	}
+	if len(comment) > 0 {
+		return strings.Join(comment, " "), nil
+	}
+
+	values, err := Merge(fsys, file)
+	if err != nil {
+		// Listing the profiles must not fail on a broken one, Check reports the error when it is chosen.
+		return "invalid: " + err.Error(), nil //nolint: nilerr
+	}
+	target, _ := lookup(values, "orchestratorDeployment.targetCluster")
+	domain, _ := lookup(values, "argo.clusterDomain")
+	description := fmt.Sprintf("%v cluster on %v", target, domain)
+
+	config, err := readValues(fsys, file)
+	if err != nil {
+		return "", err
+	}
+	clusterValues, _ := lookup(config, "root.clusterValues")
+	entries, _ := clusterValues.([]interface{})
+	var enabled []string
+	for _, entry := range entries {
+		name, _ := entry.(string)
+		if name = path.Base(name); strings.HasPrefix(name, "enable-") {
+			enabled = append(enabled, strings.TrimSuffix(strings.TrimPrefix(name, "enable-"), ".yaml"))
+		}
+	}
+	if len(enabled) > 0 {
+		description += " with " + strings.Join(enabled, ", ")
+	}
+	return description, nil
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clusterconfig_test
+
+import (
+	"os"
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/clusterconfig"
+)
+
+const validCluster = `# SPDX-FileCopyrightText: 2026 Intel Corporation
+#
+# SPDX-License-Identifier: Apache-2.0
+
+# Cluster specific values applied to root-app only
+root:
+  clusterValues:
+    - orch-configs/profiles/enable-platform.yaml
+    - orch-configs/clusters/onprem.yaml
+
+argo:
+  project: onprem
+  namespace: onprem
+  clusterName: onprem
+  clusterDomain: cluster.onprem
+  deployRepoURL: https://gitea.example.com/argocd/edge-manageability-framework
+  deployRepoRevision: main
+
+orchestratorDeployment:
+  targetCluster: onprem
+`
+
+var _ = Describe("Check", func() {
+	var fsys fstest.MapFS
+
+	BeforeEach(func() {
+		fsys = fstest.MapFS{
+			"orch-configs/clusters/onprem.yaml": {Data: []byte(validCluster)},
+			"orch-configs/clusters/dev.yaml": {Data: []byte("# Kind cluster for development\nroot:\n" +
+				"  clusterValues: []\n")},
+			"orch-configs/profiles/enable-platform.yaml": {Data: []byte("argo:\n  enabled:\n    vault: true\n" +
+				"  clusterDomain: platform.example.com\n")},
+			"argocd/root-app/values.yaml": {Data: []byte("argo:\n  autosync: true\n  clusterDomain: ''\n")},
+		}
+	})
+
+	It("merges the profile's cluster values", func() {
+		values, err := clusterconfig.Check(fsys, "onprem")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveKeyWithValue("argo", SatisfyAll(
+			HaveKeyWithValue("enabled", HaveKeyWithValue("vault", true)),
+			// The cluster configuration overrides the profiles it includes.
+			HaveKeyWithValue("clusterDomain", "cluster.onprem"),
+			HaveKeyWithValue("autosync", true),
+		)))
+	})
+
+	It("lists the valid profiles with their descriptions", func() {
+		_, err := clusterconfig.Check(fsys, "onprem-typo")
+		Expect(err).To(MatchError(SatisfyAll(
+			ContainSubstring(`unknown profile "onprem-typo"`),
+			MatchRegexp(`dev +Kind cluster for development`),
+			MatchRegexp(`onprem +onprem cluster on cluster.onprem with platform`),
+		)))
+	})
+
+	It("fails when a referenced values file is missing", func() {
+		delete(fsys, "orch-configs/profiles/enable-platform.yaml")
+		_, err := clusterconfig.Check(fsys, "onprem")
+		Expect(err).To(MatchError(ContainSubstring("orch-configs/profiles/enable-platform.yaml")))
+	})
+
+	It("reports every schema violation", func() {
+		fsys["orch-configs/profiles/enable-platform.yaml"] = &fstest.MapFile{Data: []byte(
+			"argo:\n  enabled:\n    vault: yes-please\n  clusterName: ''\n  clusterDomain: Not_A_Domain\n")}
+		fsys["orch-configs/clusters/onprem.yaml"] = &fstest.MapFile{Data: []byte(
+			"root:\n  clusterValues:\n    - orch-configs/profiles/enable-platform.yaml\n")}
+
+		_, err := clusterconfig.Check(fsys, "onprem")
+		Expect(err).To(MatchError(SatisfyAll(
+			ContainSubstring("argo.project is required"),
+			ContainSubstring("argo.clusterName must not be empty"),
+			ContainSubstring(`argo.clusterDomain must be a valid domain name, got "Not_A_Domain"`),
+			ContainSubstring("argo.enabled entry vault must be a boolean"),
+			ContainSubstring("orchestratorDeployment.targetCluster is required"),
+		)))
+	})
+})
+
+var _ = Describe("shipped cluster configurations", func() {
+	It("are all valid", func() {
+		repo := os.DirFS("../..")
+
+		profiles, err := clusterconfig.Profiles(repo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profiles).NotTo(BeEmpty())
+		for _, profile := range profiles {
+			// bkc only feeds the deployment manifest generation and is never installed.
+			if profile.Name == "bkc" {
+				continue
+			}
+			_, err := clusterconfig.Check(repo, profile.Name)
+			Expect(err).NotTo(HaveOccurred(), profile.Name)
+		}
+	})
+})
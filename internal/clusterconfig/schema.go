@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clusterconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var domainRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// field is a rule of the cluster values schema.
+type field struct {
+	path     string
+	required bool
+	check    func(value interface{}) error
+}
+
+// schema lists the values the root-app chart and the applications it deploys rely on. root.clusterValues is checked
+// by Merge already.
+var schema = []field{
+	{path: "root.useLocalValues", check: isBool},
+	{path: "argo.project", required: true, check: isNonEmptyString},
+	{path: "argo.namespace", required: true, check: isNonEmptyString},
+	{path: "argo.clusterName", required: true, check: isNonEmptyString},
+	{path: "argo.clusterDomain", required: true, check: isDomain},
+	{path: "argo.deployRepoURL", required: true, check: isNonEmptyString},
+	{path: "argo.deployRepoRevision", required: true, check: isNonEmptyString},
+	{path: "argo.autosync", check: isBool},
+	{path: "argo.enabled", required: true, check: isBoolMap},
+	{path: "orchestratorDeployment.targetCluster", required: true, check: isNonEmptyString},
+}
+
+// Validate checks merged cluster values against the schema and reports every violation.
+func Validate(values map[string]interface{}) error {
+	var errs []error
+	for _, f := range schema {
+		value, ok := lookup(values, f.path)
+		if !ok || value == nil {
+			if f.required {
+				errs = append(errs, fmt.Errorf("%s is required", f.path))
+			}
+			continue
+		}
+		if err := f.check(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", f.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookup returns the value at a dot separated path.
+func lookup(values map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func isBool(value interface{}) error {
+	if _, ok := value.(bool); !ok {
+		return fmt.Errorf("must be a boolean, got %T", value)
+	}
+	return nil
+}
+
+func isNonEmptyString(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("must be a string, got %T", value)
+	}
+	if s == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+func isDomain(value interface{}) error {
+	if err := isNonEmptyString(value); err != nil {
+		return err
+	}
+	if !domainRegex.MatchString(value.(string)) {
+		return fmt.Errorf("must be a valid domain name, got %q", value)
+	}
+	return nil
+}
+
+func isBoolMap(value interface{}) error {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("must be a map, got %T", value)
+	}
+	for key, entry := range m {
+		if _, ok := entry.(bool); !ok {
+			return fmt.Errorf("entry %s must be a boolean, got %T", key, entry)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cnpg_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCNPG(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CNPG Suite")
+}
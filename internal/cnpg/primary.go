@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cnpg finds the pods of the CloudNativePG clusters of the Orchestrator.
+package cnpg
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// clusterLabel is the label of the pods of a cluster with its name.
+const clusterLabel = "cnpg.io/cluster"
+
+// Primary returns the primary pod of cluster in namespace.
+func Primary(x executor.Executor, namespace, cluster string) (string, error) {
+	pod, err := firstPod(x, namespace, clusterLabel+"="+cluster+",cnpg.io/instanceRole=primary")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the primary of cluster %s: %w", cluster, err)
+	}
+	return pod, nil
+}
+
+// ServicePrimary returns the pod service in namespace selects. The read-write service of a cluster selects its
+// primary, also after a major-version upgrade pointed the service to the pods of a new cluster.
+func ServicePrimary(x executor.Executor, namespace, service string) (string, error) {
+	selector, err := serviceSelector(x, namespace, service)
+	if err != nil {
+		return "", err
+	}
+	labels := make([]string, 0, len(selector))
+	for key, value := range selector {
+		labels = append(labels, key+"="+value)
+	}
+	slices.Sort(labels)
+	pod, err := firstPod(x, namespace, strings.Join(labels, ","))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the pod of service %s: %w", service, err)
+	}
+	return pod, nil
+}
+
+// ServiceCluster returns the cluster whose pods service in namespace selects, which is the cluster serving the
+// clients of the service after a major-version upgrade.
+func ServiceCluster(x executor.Executor, namespace, service string) (string, error) {
+	selector, err := serviceSelector(x, namespace, service)
+	if err != nil {
+		return "", err
+	}
+	cluster := selector[clusterLabel]
+	if cluster == "" {
+		return "", fmt.Errorf("service %s doesn't select the pods of a cluster", service)
+	}
+	return cluster, nil
+}
+
+func serviceSelector(x executor.Executor, namespace, service string) (map[string]string, error) {
+	out, err := x.Query("kubectl", "get", "service", service, "-n", namespace, "-o", "jsonpath={.spec.selector}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service %s: %w", service, err)
+	}
+	var selector map[string]string
+	if err := json.Unmarshal([]byte(out), &selector); err != nil {
+		return nil, fmt.Errorf("failed to parse the selector of service %s: %w", service, err)
+	}
+	return selector, nil
+}
+
+func firstPod(x executor.Executor, namespace, selector string) (string, error) {
+	pod, err := x.Query("kubectl", "get", "pods", "-n", namespace, "-l", selector,
+		"-o", "jsonpath={.items[0].metadata.name}")
+	if err != nil {
+		return "", err
+	}
+	if pod = strings.TrimSpace(pod); pod == "" {
+		return "", fmt.Errorf("no pod matches %s", selector)
+	}
+	return pod, nil
+}
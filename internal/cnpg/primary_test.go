@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cnpg_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/cnpg"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor/executortest"
+)
+
+var _ = Describe("Primary", func() {
+	It("finds the primary by the labels of the cluster", func() {
+		x := executortest.New()
+		x.Responses["kubectl get pods -n orch-database -l cnpg.io/cluster=postgresql-cluster,cnpg.io/instanceRole=primary"] =
+			"postgresql-cluster-2\n"
+
+		Expect(cnpg.Primary(x, "orch-database", "postgresql-cluster")).To(Equal("postgresql-cluster-2"))
+	})
+
+	It("fails when no pod is the primary", func() {
+		x := executortest.New()
+		x.Responses["kubectl get pods"] = ""
+
+		_, err := cnpg.Primary(x, "orch-database", "postgresql-cluster")
+		Expect(err).To(MatchError("failed to find the primary of cluster postgresql-cluster: " +
+			"no pod matches cnpg.io/cluster=postgresql-cluster,cnpg.io/instanceRole=primary"))
+	})
+})
+
+var _ = Describe("ServicePrimary", func() {
+	It("finds the pod through the selector of the service", func() {
+		x := executortest.New()
+		x.Responses["kubectl get service postgresql-cluster-rw -n orch-database -o jsonpath={.spec.selector}"] =
+			`{"cnpg.io/instanceRole":"primary","cnpg.io/cluster":"postgresql-cluster-18"}`
+		x.Responses["kubectl get pods -n orch-database -l cnpg.io/cluster=postgresql-cluster-18"] =
+			"postgresql-cluster-18-1"
+
+		Expect(cnpg.ServicePrimary(x, "orch-database", "postgresql-cluster-rw")).To(Equal("postgresql-cluster-18-1"))
+	})
+})
+
+var _ = Describe("ServiceCluster", func() {
+	It("returns the cluster the service selects", func() {
+		x := executortest.New()
+		x.Responses["kubectl get service postgresql-cluster-rw -n orch-database -o jsonpath={.spec.selector}"] =
+			`{"cnpg.io/instanceRole":"primary","cnpg.io/cluster":"postgresql-cluster-18"}`
+
+		Expect(cnpg.ServiceCluster(x, "orch-database", "postgresql-cluster-rw")).To(Equal("postgresql-cluster-18"))
+	})
+
+	It("fails when the service doesn't select a cluster", func() {
+		x := executortest.New()
+		x.Responses["kubectl get service postgresql-cluster-rw"] = `{"app":"fenced"}`
+
+		_, err := cnpg.ServiceCluster(x, "orch-database", "postgresql-cluster-rw")
+		Expect(err).To(MatchError("service postgresql-cluster-rw doesn't select the pods of a cluster"))
+	})
+})
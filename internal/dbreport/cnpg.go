@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// backupCompleted is the phase of a successful CloudNativePG backup.
+const backupCompleted = "completed"
+
+// ParseCluster returns the state of a CloudNativePG cluster from its resource and the list of its backups as JSON, as
+// printed by kubectl get clusters.postgresql.cnpg.io and kubectl get backups.postgresql.cnpg.io -o json.
+func ParseCluster(clusterJSON, backupsJSON []byte) (Cluster, error) {
+	var resource struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Instances int `json:"instances"`
+		} `json:"spec"`
+		Status struct {
+			Phase                string     `json:"phase"`
+			ReadyInstances       int        `json:"readyInstances"`
+			CurrentPrimary       string     `json:"currentPrimary"`
+			LastSuccessfulBackup *time.Time `json:"lastSuccessfulBackup"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(clusterJSON, &resource); err != nil {
+		return Cluster{}, fmt.Errorf("parsing cluster: %w", err)
+	}
+	cluster := Cluster{
+		Phase:          resource.Status.Phase,
+		Instances:      resource.Spec.Instances,
+		ReadyInstances: resource.Status.ReadyInstances,
+		Primary:        resource.Status.CurrentPrimary,
+		LastBackup:     resource.Status.LastSuccessfulBackup,
+	}
+
+	var backups struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Cluster struct {
+					Name string `json:"name"`
+				} `json:"cluster"`
+			} `json:"spec"`
+			Status struct {
+				Phase     string     `json:"phase"`
+				StoppedAt *time.Time `json:"stoppedAt"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(backupsJSON, &backups); err != nil {
+		return Cluster{}, fmt.Errorf("parsing backups: %w", err)
+	}
+	// The status of the cluster only has the time of the last backup, and recent versions of CloudNativePG no longer
+	// set it, so the backups themselves are the reference.
+	for _, backup := range backups.Items {
+		stopped := backup.Status.StoppedAt
+		if backup.Spec.Cluster.Name != resource.Metadata.Name || backup.Status.Phase != backupCompleted ||
+			stopped == nil {
+			continue
+		}
+		if cluster.LastBackup == nil || !stopped.Before(*cluster.LastBackup) {
+			cluster.LastBackup = stopped
+			cluster.LastBackupName = backup.Metadata.Name
+		}
+	}
+	return cluster, nil
+}
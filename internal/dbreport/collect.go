@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Connector opens a connection to a database of the cluster. The statistics of the tables are only visible from
+// their own database, so the collector connects to each of them.
+type Connector func(ctx context.Context, database string) (*pgx.Conn, error)
+
+// Collector gathers the report from the primary of the cluster, connected as a superuser so that the queries of all
+// roles are visible.
+type Collector struct {
+	Connect Connector
+	// Tables is the number of tables with the most dead tuples reported per database.
+	Tables int
+	// Queries is the number of longest-running queries reported.
+	Queries int
+}
+
+const (
+	databasesQuery = `SELECT datname, pg_database_size(datname) FROM pg_database
+		WHERE NOT datistemplate AND datallowconn ORDER BY 2 DESC`
+	connectionsQuery = `SELECT COALESCE(usename, ''), COALESCE(state, ''), count(*) FROM pg_stat_activity
+		WHERE backend_type = 'client backend' GROUP BY 1, 2 ORDER BY 1`
+	maxConnectionsQuery = `SELECT current_setting('max_connections')::int`
+	// queriesQuery reports the running queries by the start of their transaction, so that transactions left idle
+	// hold their place too.
+	queriesQuery = `SELECT pid, COALESCE(usename, ''), COALESCE(datname, ''), state,
+		EXTRACT(EPOCH FROM now() - COALESCE(xact_start, query_start))::float8, left(query, 200)
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend' AND state <> 'idle' AND pid <> pg_backend_pid()
+		ORDER BY COALESCE(xact_start, query_start) LIMIT $1`
+	replicasQuery = `SELECT application_name, COALESCE(state, ''),
+		COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn), 0)::bigint,
+		COALESCE(EXTRACT(EPOCH FROM replay_lag), 0)::float8
+		FROM pg_stat_replication ORDER BY 1`
+	tablesQuery = `SELECT schemaname, relname, n_live_tup, n_dead_tup, pg_total_relation_size(relid),
+		GREATEST(last_vacuum, last_autovacuum)
+		FROM pg_stat_user_tables WHERE n_dead_tup > 0 ORDER BY n_dead_tup DESC LIMIT $1`
+)
+
+// Collect returns the report of the cluster, without its CloudNativePG state and violations.
+func (c *Collector) Collect(ctx context.Context) (Report, error) {
+	var report Report
+	conn, err := c.Connect(ctx, "postgres")
+	if err != nil {
+		return report, fmt.Errorf("connecting to postgres: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	rows, _ := conn.Query(ctx, databasesQuery)
+	if report.Databases, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (DatabaseSize, error) {
+		var db DatabaseSize
+		err := row.Scan(&db.Name, &db.Bytes)
+		return db, err
+	}); err != nil {
+		return report, fmt.Errorf("querying database sizes: %w", err)
+	}
+	if report.Connections, err = connections(ctx, conn); err != nil {
+		return report, err
+	}
+	if err := conn.QueryRow(ctx, maxConnectionsQuery).Scan(&report.MaxConnections); err != nil {
+		return report, fmt.Errorf("querying max_connections: %w", err)
+	}
+	rows, _ = conn.Query(ctx, queriesQuery, c.Queries)
+	if report.Queries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (Query, error) {
+		var q Query
+		err := row.Scan(&q.PID, &q.Role, &q.Database, &q.State, &q.Seconds, &q.Query)
+		return q, err
+	}); err != nil {
+		return report, fmt.Errorf("querying running queries: %w", err)
+	}
+	rows, _ = conn.Query(ctx, replicasQuery)
+	if report.Replicas, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (Replica, error) {
+		var r Replica
+		err := row.Scan(&r.Name, &r.State, &r.LagBytes, &r.LagSecs)
+		return r, err
+	}); err != nil {
+		return report, fmt.Errorf("querying replication: %w", err)
+	}
+
+	for _, db := range report.Databases {
+		tables, err := c.tables(ctx, db.Name)
+		if err != nil {
+			return report, err
+		}
+		report.Tables = append(report.Tables, tables...)
+	}
+	return report, nil
+}
+
+func connections(ctx context.Context, conn *pgx.Conn) ([]RoleConnections, error) {
+	rows, _ := conn.Query(ctx, connectionsQuery)
+	var roles []RoleConnections
+	var role, state string
+	var count int
+	_, err := pgx.ForEachRow(rows, []any{&role, &state, &count}, func() error {
+		if len(roles) == 0 || roles[len(roles)-1].Role != role {
+			roles = append(roles, RoleConnections{Role: role})
+		}
+		r := &roles[len(roles)-1]
+		switch state {
+		case "active":
+			r.Active += count
+		case "idle":
+			r.Idle += count
+		case "idle in transaction", "idle in transaction (aborted)":
+			r.IdleInTransaction += count
+		default:
+			r.Other += count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying connections: %w", err)
+	}
+	return roles, nil
+}
+
+func (c *Collector) tables(ctx context.Context, database string) ([]TableBloat, error) {
+	conn, err := c.Connect(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", database, err)
+	}
+	defer conn.Close(ctx)
+
+	rows, _ := conn.Query(ctx, tablesQuery, c.Tables)
+	tables, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (TableBloat, error) {
+		t := TableBloat{Database: database}
+		err := row.Scan(&t.Schema, &t.Table, &t.LiveTuples, &t.DeadTuples, &t.Bytes, &t.LastVacuum)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying tables of %s: %w", database, err)
+	}
+	return tables, nil
+}
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDBReport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Database Report Suite")
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the report as tables, one per section.
+func WriteTable(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	section := func(title, header string) {
+		fmt.Fprintf(tw, "\n%s\n%s\n", title, header)
+	}
+
+	lastBackup := "none"
+	if r.Cluster.LastBackup != nil {
+		lastBackup = fmt.Sprintf("%s (%s ago)", r.Cluster.LastBackup.UTC().Format(time.RFC3339),
+			r.Time.Sub(*r.Cluster.LastBackup).Round(time.Minute))
+		if r.Cluster.LastBackupName != "" {
+			lastBackup += " " + r.Cluster.LastBackupName
+		}
+	}
+	fmt.Fprintf(tw, "Cluster:\t%s, %d/%d instances ready, primary %s\n", r.Cluster.Phase, r.Cluster.ReadyInstances,
+		r.Cluster.Instances, r.Cluster.Primary)
+	fmt.Fprintf(tw, "Last backup:\t%s\n", lastBackup)
+
+	section("Databases", "DATABASE\tSIZE")
+	for _, db := range r.Databases {
+		fmt.Fprintf(tw, "%s\t%s\n", db.Name, FormatBytes(db.Bytes))
+	}
+
+	section("Tables with the most dead tuples", "TABLE\tLIVE\tDEAD\tDEAD %\tSIZE\tLAST VACUUM")
+	for _, t := range r.Tables {
+		vacuum := "never"
+		if t.LastVacuum != nil {
+			vacuum = t.LastVacuum.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s.%s.%s\t%d\t%d\t%.1f\t%s\t%s\n", t.Database, t.Schema, t.Table, t.LiveTuples, t.DeadTuples,
+			100*t.DeadRatio(), FormatBytes(t.Bytes), vacuum)
+	}
+
+	total := 0
+	for _, c := range r.Connections {
+		total += c.Total()
+	}
+	section(fmt.Sprintf("Connections (%d of %d)", total, r.MaxConnections),
+		"ROLE\tACTIVE\tIDLE\tIDLE IN TRANSACTION\tOTHER\tTOTAL")
+	for _, c := range r.Connections {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", c.Role, c.Active, c.Idle, c.IdleInTransaction, c.Other, c.Total())
+	}
+
+	section("Longest-running queries", "PID\tROLE\tDATABASE\tSTATE\tDURATION\tQUERY")
+	for _, q := range r.Queries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", q.PID, q.Role, q.Database, q.State,
+			seconds(q.Seconds).Round(time.Second), strings.Join(strings.Fields(q.Query), " "))
+	}
+
+	section("Replication", "REPLICA\tSTATE\tLAG\tREPLAY LAG")
+	for _, rep := range r.Replicas {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rep.Name, rep.State, FormatBytes(rep.LagBytes),
+			seconds(rep.LagSecs).Round(time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Violations) == 0 {
+		_, err := fmt.Fprintf(w, "\nNo thresholds exceeded\n")
+		return err
+	}
+	fmt.Fprintf(w, "\n%d thresholds exceeded:\n", len(r.Violations))
+	for _, v := range r.Violations {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+	return nil
+}
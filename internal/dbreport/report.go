@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dbreport reports the health of the PostgreSQL cluster of the Orchestrator: the size of the databases, the
+// dead tuples of the tables, the connections per role, the long-running queries, the replication lag and the last
+// successful backup, and checks them against thresholds so that the report can gate CI.
+package dbreport
+
+import (
+	"fmt"
+	"time"
+)
+
+// DatabaseSize is the size on disk of a database.
+type DatabaseSize struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+// TableBloat estimates the bloat of a table from its dead tuples, which VACUUM hasn't reclaimed yet.
+type TableBloat struct {
+	Database   string     `json:"database"`
+	Schema     string     `json:"schema"`
+	Table      string     `json:"table"`
+	LiveTuples int64      `json:"liveTuples"`
+	DeadTuples int64      `json:"deadTuples"`
+	Bytes      int64      `json:"bytes"`
+	LastVacuum *time.Time `json:"lastVacuum,omitempty"`
+}
+
+// DeadRatio is the share of the tuples of the table that are dead.
+func (t TableBloat) DeadRatio() float64 {
+	if t.LiveTuples+t.DeadTuples == 0 {
+		return 0
+	}
+	return float64(t.DeadTuples) / float64(t.LiveTuples+t.DeadTuples)
+}
+
+// RoleConnections counts the client connections of a role by state.
+type RoleConnections struct {
+	Role              string `json:"role"`
+	Active            int    `json:"active"`
+	Idle              int    `json:"idle"`
+	IdleInTransaction int    `json:"idleInTransaction"`
+	Other             int    `json:"other"`
+}
+
+// Total is the number of connections of the role.
+func (c RoleConnections) Total() int {
+	return c.Active + c.Idle + c.IdleInTransaction + c.Other
+}
+
+// Query is a query that has been running, or a transaction that has been open, for a while.
+type Query struct {
+	PID      int32   `json:"pid"`
+	Role     string  `json:"role"`
+	Database string  `json:"database"`
+	State    string  `json:"state"`
+	Seconds  float64 `json:"seconds"`
+	Query    string  `json:"query"`
+}
+
+// Replica is a standby streaming from the primary.
+type Replica struct {
+	Name     string  `json:"name"`
+	State    string  `json:"state"`
+	LagBytes int64   `json:"lagBytes"`
+	LagSecs  float64 `json:"lagSeconds"`
+}
+
+// Cluster is the state of the CloudNativePG cluster.
+type Cluster struct {
+	Phase          string `json:"phase"`
+	Instances      int    `json:"instances"`
+	ReadyInstances int    `json:"readyInstances"`
+	Primary        string `json:"primary"`
+	// LastBackup is when the last successful backup completed, nil if there is none.
+	LastBackup     *time.Time `json:"lastBackup,omitempty"`
+	LastBackupName string     `json:"lastBackupName,omitempty"`
+}
+
+// Report is the health of the PostgreSQL cluster at a point in time.
+type Report struct {
+	Time           time.Time         `json:"time"`
+	Cluster        Cluster           `json:"cluster"`
+	Databases      []DatabaseSize    `json:"databases"`
+	Tables         []TableBloat      `json:"tables"`
+	Connections    []RoleConnections `json:"connections"`
+	MaxConnections int               `json:"maxConnections"`
+	Queries        []Query           `json:"queries"`
+	Replicas       []Replica         `json:"replicas"`
+	Violations     []Violation       `json:"violations"`
+}
+
+// Violation is a threshold exceeded by the report.
+type Violation struct {
+	Check   string `json:"check"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s %s: %s", v.Check, v.Subject, v.Message)
+}
+
+// The checks of the violations.
+const (
+	CheckDatabaseSize   = "database-size"
+	CheckDeadTuples     = "dead-tuples"
+	CheckConnections    = "connections"
+	CheckQueryDuration  = "query-duration"
+	CheckReplicationLag = "replication-lag"
+	CheckReplicas       = "replicas"
+	CheckBackupAge      = "backup-age"
+)
+
+// Evaluate returns the thresholds the report exceeds at now.
+func Evaluate(r Report, t Thresholds, now time.Time) []Violation {
+	var violations []Violation
+	add := func(check, subject, format string, args ...any) {
+		violations = append(violations, Violation{Check: check, Subject: subject, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t.MaxDatabaseBytes > 0 {
+		for _, db := range r.Databases {
+			if db.Bytes > t.MaxDatabaseBytes {
+				add(CheckDatabaseSize, db.Name, "%s exceeds %s", FormatBytes(db.Bytes), FormatBytes(t.MaxDatabaseBytes))
+			}
+		}
+	}
+	if t.MaxDeadRatio > 0 {
+		for _, table := range r.Tables {
+			if table.DeadTuples >= t.MinDeadTuples && table.DeadRatio() > t.MaxDeadRatio {
+				add(CheckDeadTuples, table.Database+"."+table.Schema+"."+table.Table,
+					"%d dead tuples, %.0f%% of the table, exceed %.0f%%",
+					table.DeadTuples, 100*table.DeadRatio(), 100*t.MaxDeadRatio)
+			}
+		}
+	}
+	if t.MaxConnectionsRatio > 0 && r.MaxConnections > 0 {
+		total := 0
+		for _, role := range r.Connections {
+			total += role.Total()
+		}
+		if ratio := float64(total) / float64(r.MaxConnections); ratio > t.MaxConnectionsRatio {
+			add(CheckConnections, "cluster", "%d of %d connections in use exceed %.0f%%",
+				total, r.MaxConnections, 100*t.MaxConnectionsRatio)
+		}
+	}
+	if t.MaxQueryDuration > 0 {
+		for _, query := range r.Queries {
+			if d := seconds(query.Seconds); d > t.MaxQueryDuration {
+				add(CheckQueryDuration, fmt.Sprintf("pid %d", query.PID), "%s by %s on %s for %s exceeds %s",
+					query.State, query.Role, query.Database, d.Round(time.Second), t.MaxQueryDuration)
+			}
+		}
+	}
+	if t.MaxReplicationLag > 0 {
+		for _, replica := range r.Replicas {
+			if d := seconds(replica.LagSecs); d > t.MaxReplicationLag {
+				add(CheckReplicationLag, replica.Name, "replay lag of %s exceeds %s",
+					d.Round(time.Millisecond), t.MaxReplicationLag)
+			}
+		}
+	}
+	if r.Cluster.Instances > 0 && r.Cluster.ReadyInstances < r.Cluster.Instances {
+		add(CheckReplicas, "cluster", "%d of %d instances are ready", r.Cluster.ReadyInstances, r.Cluster.Instances)
+	}
+	if t.MaxBackupAge > 0 {
+		switch {
+		case r.Cluster.LastBackup == nil:
+			add(CheckBackupAge, "cluster", "no successful backup")
+		case now.Sub(*r.Cluster.LastBackup) > t.MaxBackupAge:
+			add(CheckBackupAge, "cluster", "last successful backup %s ago exceeds %s",
+				now.Sub(*r.Cluster.LastBackup).Round(time.Minute), t.MaxBackupAge)
+		}
+	}
+	return violations
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// FormatBytes formats a size in binary units, e.g. 1.5 GiB.
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/dbreport"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// healthyReport is within the default thresholds.
+func healthyReport() dbreport.Report {
+	lastBackup := now.Add(-2 * time.Hour)
+	return dbreport.Report{
+		Time: now,
+		Cluster: dbreport.Cluster{
+			Phase: "Cluster in healthy state", Instances: 1, ReadyInstances: 1, Primary: "postgresql-cluster-1",
+			LastBackup: &lastBackup,
+		},
+		Databases: []dbreport.DatabaseSize{{Name: "inventory", Bytes: 3 << 30}, {Name: "postgres", Bytes: 7 << 20}},
+		Tables: []dbreport.TableBloat{
+			{Database: "inventory", Schema: "public", Table: "hosts", LiveTuples: 90000, DeadTuples: 10000},
+			{Database: "inventory", Schema: "public", Table: "locks", LiveTuples: 10, DeadTuples: 900},
+		},
+		Connections: []dbreport.RoleConnections{
+			{Role: "inventory", Active: 2, Idle: 20},
+			{Role: "keycloak", Active: 1, Idle: 9, IdleInTransaction: 1},
+		},
+		MaxConnections: 100,
+		Queries: []dbreport.Query{
+			{PID: 42, Role: "inventory", Database: "inventory", State: "active", Seconds: 12, Query: "SELECT\n  1"},
+		},
+		Replicas: []dbreport.Replica{{Name: "postgresql-cluster-2", State: "streaming", LagBytes: 4096, LagSecs: 0.2}},
+	}
+}
+
+func checks(violations []dbreport.Violation) []string {
+	var names []string
+	for _, v := range violations {
+		names = append(names, v.Check+" "+v.Subject)
+	}
+	return names
+}
+
+var _ = Describe("Evaluate", func() {
+	var (
+		report     dbreport.Report
+		thresholds dbreport.Thresholds
+	)
+
+	BeforeEach(func() {
+		report = healthyReport()
+		thresholds = dbreport.DefaultThresholds()
+	})
+
+	It("passes a healthy cluster", func() {
+		Expect(dbreport.Evaluate(report, thresholds, now)).To(BeEmpty())
+	})
+
+	It("checks the size of each database when a limit is set", func() {
+		thresholds.MaxDatabaseBytes = 2 << 30
+		violations := dbreport.Evaluate(report, thresholds, now)
+		Expect(checks(violations)).To(Equal([]string{"database-size inventory"}))
+		Expect(violations[0].Message).To(Equal("3.0 GiB exceeds 2.0 GiB"))
+	})
+
+	It("ignores the dead tuples of small tables", func() {
+		thresholds.MaxDeadRatio = 0.05
+		Expect(checks(dbreport.Evaluate(report, thresholds, now))).To(Equal([]string{
+			"dead-tuples inventory.public.hosts",
+		}))
+		thresholds.MinDeadTuples = 100
+		Expect(checks(dbreport.Evaluate(report, thresholds, now))).To(Equal([]string{
+			"dead-tuples inventory.public.hosts", "dead-tuples inventory.public.locks",
+		}))
+	})
+
+	It("checks the connections in use against max_connections", func() {
+		report.Connections[0].Idle = 70
+		violations := dbreport.Evaluate(report, thresholds, now)
+		Expect(checks(violations)).To(Equal([]string{"connections cluster"}))
+		Expect(violations[0].Message).To(Equal("83 of 100 connections in use exceed 80%"))
+	})
+
+	It("checks long-running queries and replication lag", func() {
+		report.Queries[0].Seconds = 3600
+		report.Replicas[0].LagSecs = 45
+		Expect(checks(dbreport.Evaluate(report, thresholds, now))).To(Equal([]string{
+			"query-duration pid 42", "replication-lag postgresql-cluster-2",
+		}))
+	})
+
+	It("reports instances that aren't ready", func() {
+		report.Cluster.Instances = 3
+		Expect(checks(dbreport.Evaluate(report, thresholds, now))).To(Equal([]string{"replicas cluster"}))
+	})
+
+	It("checks the age of the last backup when a limit is set", func() {
+		thresholds.MaxBackupAge = time.Hour
+		Expect(checks(dbreport.Evaluate(report, thresholds, now))).To(Equal([]string{"backup-age cluster"}))
+		report.Cluster.LastBackup = nil
+		violations := dbreport.Evaluate(report, thresholds, now)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Message).To(Equal("no successful backup"))
+	})
+
+	It("disables the checks with a zero limit", func() {
+		report.Queries[0].Seconds = 3600
+		report.Replicas[0].LagSecs = 45
+		report.Connections[0].Idle = 90
+		report.Cluster.LastBackup = nil
+		Expect(dbreport.Evaluate(report, dbreport.Thresholds{}, now)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ThresholdsFromEnv", func() {
+	It("overrides the defaults", func() {
+		env := map[string]string{
+			"DB_REPORT_MAX_DATABASE_SIZE":   "20Gi",
+			"DB_REPORT_MAX_DEAD_RATIO":      "0.3",
+			"DB_REPORT_MAX_QUERY_DURATION":  "0",
+			"DB_REPORT_MAX_BACKUP_AGE":      "26h",
+			"DB_REPORT_MIN_DEAD_TUPLES":     "500",
+			"DB_REPORT_MAX_REPLICATION_LAG": "1m",
+		}
+		thresholds, err := dbreport.ThresholdsFromEnv(func(name string) string { return env[name] })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(thresholds).To(Equal(dbreport.Thresholds{
+			MaxDatabaseBytes:    20 << 30,
+			MaxDeadRatio:        0.3,
+			MinDeadTuples:       500,
+			MaxConnectionsRatio: 0.8,
+			MaxReplicationLag:   time.Minute,
+			MaxBackupAge:        26 * time.Hour,
+		}))
+	})
+
+	It("rejects invalid values", func() {
+		_, err := dbreport.ThresholdsFromEnv(func(name string) string {
+			if name == "DB_REPORT_MAX_CONNECTIONS_RATIO" {
+				return "80"
+			}
+			return ""
+		})
+		Expect(err).To(MatchError(ContainSubstring("invalid DB_REPORT_MAX_CONNECTIONS_RATIO")))
+	})
+})
+
+var _ = Describe("ParseCluster", func() {
+	const cluster = `{
+		"metadata": {"name": "postgresql-cluster"},
+		"spec": {"instances": 1},
+		"status": {"phase": "Cluster in healthy state", "readyInstances": 1, "currentPrimary": "postgresql-cluster-1"}
+	}`
+
+	It("takes the last completed backup of the cluster", func() {
+		backups := `{"items": [
+			{"metadata": {"name": "nightly-1"}, "spec": {"cluster": {"name": "postgresql-cluster"}},
+			 "status": {"phase": "completed", "stoppedAt": "2026-10-18T01:00:00Z"}},
+			{"metadata": {"name": "nightly-2"}, "spec": {"cluster": {"name": "postgresql-cluster"}},
+			 "status": {"phase": "completed", "stoppedAt": "2026-10-19T01:00:00Z"}},
+			{"metadata": {"name": "nightly-3"}, "spec": {"cluster": {"name": "postgresql-cluster"}},
+			 "status": {"phase": "failed", "stoppedAt": "2026-10-19T02:00:00Z"}},
+			{"metadata": {"name": "other"}, "spec": {"cluster": {"name": "other-cluster"}},
+			 "status": {"phase": "completed", "stoppedAt": "2026-10-19T03:00:00Z"}}
+		]}`
+		parsed, err := dbreport.ParseCluster([]byte(cluster), []byte(backups))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Phase).To(Equal("Cluster in healthy state"))
+		Expect(parsed.Instances).To(Equal(1))
+		Expect(parsed.ReadyInstances).To(Equal(1))
+		Expect(parsed.Primary).To(Equal("postgresql-cluster-1"))
+		Expect(parsed.LastBackupName).To(Equal("nightly-2"))
+		Expect(*parsed.LastBackup).To(BeTemporally("==", time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)))
+	})
+
+	It("has no last backup without completed backups", func() {
+		parsed, err := dbreport.ParseCluster([]byte(cluster), []byte(`{"items": []}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.LastBackup).To(BeNil())
+	})
+})
+
+var _ = Describe("Writing the report", func() {
+	It("writes the sections as tables followed by the violations", func() {
+		report := healthyReport()
+		report.Violations = []dbreport.Violation{{Check: "backup-age", Subject: "cluster", Message: "no successful backup"}}
+		var out bytes.Buffer
+		Expect(dbreport.WriteTable(&out, report)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Last backup:  2026-10-19T10:00:00Z (2h0m0s ago)"))
+		Expect(out.String()).To(MatchRegexp(`inventory\.public\.hosts\s+90000\s+10000\s+10\.0\s+0 B\s+never`))
+		Expect(out.String()).To(ContainSubstring("Connections (33 of 100)"))
+		Expect(out.String()).To(MatchRegexp(`42\s+inventory\s+inventory\s+active\s+12s\s+SELECT 1`))
+		Expect(out.String()).To(ContainSubstring("1 thresholds exceeded:\n  backup-age cluster: no successful backup"))
+	})
+
+	It("writes JSON that reads back to the same report", func() {
+		report := healthyReport()
+		var out bytes.Buffer
+		Expect(dbreport.WriteJSON(&out, report)).To(Succeed())
+		var decoded dbreport.Report
+		Expect(json.Unmarshal(out.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded.Databases).To(Equal(report.Databases))
+		Expect(decoded.Connections).To(Equal(report.Connections))
+		Expect(*decoded.Cluster.LastBackup).To(BeTemporally("==", *report.Cluster.LastBackup))
+	})
+})
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dbreport
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Thresholds are the limits the report is checked against. A zero limit disables its check.
+type Thresholds struct {
+	// MaxDatabaseBytes is the largest size of a database.
+	MaxDatabaseBytes int64
+	// MaxDeadRatio is the largest share of dead tuples of a table with at least MinDeadTuples of them, so that small
+	// tables that are vacuumed often don't fail the check.
+	MaxDeadRatio  float64
+	MinDeadTuples int64
+	// MaxConnectionsRatio is the largest share of max_connections in use.
+	MaxConnectionsRatio float64
+	// MaxQueryDuration is the longest a query may run or a transaction may stay open.
+	MaxQueryDuration time.Duration
+	// MaxReplicationLag is the largest replay lag of a replica.
+	MaxReplicationLag time.Duration
+	// MaxBackupAge is the longest since the last successful backup. It is disabled by default since the cluster of
+	// the Orchestrator isn't backed up by CloudNativePG unless object storage is configured.
+	MaxBackupAge time.Duration
+}
+
+// DefaultThresholds returns the thresholds used unless they are overridden.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		MaxDeadRatio:        0.2,
+		MinDeadTuples:       10000,
+		MaxConnectionsRatio: 0.8,
+		MaxQueryDuration:    5 * time.Minute,
+		MaxReplicationLag:   30 * time.Second,
+	}
+}
+
+// ThresholdsFromEnv returns the default thresholds overridden by the DB_REPORT_* variables looked up with getenv:
+//
+//	DB_REPORT_MAX_DATABASE_SIZE      e.g. 20Gi
+//	DB_REPORT_MAX_DEAD_RATIO         e.g. 0.2
+//	DB_REPORT_MIN_DEAD_TUPLES        e.g. 10000
+//	DB_REPORT_MAX_CONNECTIONS_RATIO  e.g. 0.8
+//	DB_REPORT_MAX_QUERY_DURATION     e.g. 5m
+//	DB_REPORT_MAX_REPLICATION_LAG    e.g. 30s
+//	DB_REPORT_MAX_BACKUP_AGE         e.g. 26h
+//
+// A value of 0 disables the check.
+func ThresholdsFromEnv(getenv func(string) string) (Thresholds, error) {
+	t := DefaultThresholds()
+	var err error
+	parse := func(name string, set func(string) error) {
+		value := getenv(name)
+		if value == "" || err != nil {
+			return
+		}
+		if e := set(value); e != nil {
+			err = fmt.Errorf("invalid %s %q: %w", name, value, e)
+		}
+	}
+	parse("DB_REPORT_MAX_DATABASE_SIZE", func(v string) error {
+		q, e := resource.ParseQuantity(v)
+		t.MaxDatabaseBytes = q.Value()
+		return e
+	})
+	parse("DB_REPORT_MAX_DEAD_RATIO", ratio(&t.MaxDeadRatio))
+	parse("DB_REPORT_MIN_DEAD_TUPLES", func(v string) (e error) {
+		t.MinDeadTuples, e = strconv.ParseInt(v, 10, 64)
+		return e
+	})
+	parse("DB_REPORT_MAX_CONNECTIONS_RATIO", ratio(&t.MaxConnectionsRatio))
+	parse("DB_REPORT_MAX_QUERY_DURATION", duration(&t.MaxQueryDuration))
+	parse("DB_REPORT_MAX_REPLICATION_LAG", duration(&t.MaxReplicationLag))
+	parse("DB_REPORT_MAX_BACKUP_AGE", duration(&t.MaxBackupAge))
+	return t, err
+}
+
+func ratio(out *float64) func(string) error {
+	return func(v string) error {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		if r < 0 || r > 1 {
+			return fmt.Errorf("not between 0 and 1")
+		}
+		*out = r
+		return nil
+	}
+}
+
+func duration(out *time.Duration) func(string) error {
+	return func(v string) error {
+		d, err := time.ParseDuration(v)
+		*out = d
+		return err
+	}
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package executor abstracts the side effects of the on-prem installers so that they can either be applied to the
+// host or recorded as a plan of what would change.
+package executor
+
+import (
+	"io/fs"
+)
+
+// Executor performs every change an installer makes to the host or the cluster.
+type Executor interface {
+	// Run runs a command that changes the system, streaming its output.
+	Run(name string, args ...string) error
+	// RunInput is like Run with input written to the command's stdin, e.g. kubectl apply -f -.
+	RunInput(input string, name string, args ...string) error
+	// Query runs a read-only command and returns its output. Queries are run when planning too, so they must never
+	// change anything.
+	Query(name string, args ...string) (string, error)
+	// WriteFile creates or replaces the file at path.
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+	// AppendFile appends data to the file at path, creating it if needed.
+	AppendFile(path string, data []byte, perm fs.FileMode) error
+	// MkdirAll creates a directory and its parents.
+	MkdirAll(path string, perm fs.FileMode) error
+	// RemoveAll removes path and everything it contains.
+	RemoveAll(path string) error
+	// Wait calls wait, which blocks until the system reaches the state described by description.
+	Wait(description string, wait func() error) error
+	// Apply calls apply, which makes the change described by description in-process, e.g. a git push.
+	Apply(description string, apply func() error) error
+	// Redact registers secrets that must never be shown, e.g. credentials passed as command arguments.
+	Redact(secrets ...string)
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package executortest provides a fake executor for the tests of the packages that change a cluster or host.
+package executortest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// Fake answers queries from Responses by the longest key the command starts with and records the commands that
+// change the system instead of running them. Files are read and written locally, so tests point it to temporary
+// directories. Tests fake the behavior of specific commands by embedding it and overriding its methods.
+type Fake struct {
+	executor.Local
+	// Responses are the outputs of the queries by a prefix of their command.
+	Responses map[string]string
+	// Commands are the commands run, in order.
+	Commands []string
+	// Inputs are the inputs of the commands run with RunInput by command, in order.
+	Inputs map[string][]string
+}
+
+var _ executor.Executor = (*Fake)(nil)
+
+// New returns a fake without responses.
+func New() *Fake {
+	return &Fake{Responses: map[string]string{}, Inputs: map[string][]string{}}
+}
+
+// Command returns the command line of a command, as recorded and matched against the responses.
+func Command(name string, args ...string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+func (f *Fake) Run(name string, args ...string) error {
+	f.Commands = append(f.Commands, Command(name, args...))
+	return nil
+}
+
+func (f *Fake) RunInput(input string, name string, args ...string) error {
+	command := Command(name, args...)
+	f.Commands = append(f.Commands, command)
+	if f.Inputs == nil {
+		f.Inputs = map[string][]string{}
+	}
+	f.Inputs[command] = append(f.Inputs[command], input)
+	return nil
+}
+
+func (f *Fake) Query(name string, args ...string) (string, error) {
+	command := Command(name, args...)
+	keys := slices.Collect(maps.Keys(f.Responses))
+	slices.SortFunc(keys, func(a, b string) int { return len(b) - len(a) })
+	for _, key := range keys {
+		if strings.HasPrefix(command, key) {
+			return f.Responses[key], nil
+		}
+	}
+	return "", fmt.Errorf("%s: not found", command)
+}
+
+// Ran returns the commands run that start with prefix.
+func (f *Fake) Ran(prefix string) []string {
+	return slices.DeleteFunc(slices.Clone(f.Commands), func(command string) bool {
+		return !strings.HasPrefix(command, prefix)
+	})
+}
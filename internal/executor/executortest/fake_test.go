@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executortest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor/executortest"
+)
+
+var _ = Describe("Fake", func() {
+	It("answers queries by the longest matching prefix", func() {
+		f := executortest.New()
+		f.Responses["kubectl get pods"] = "all"
+		f.Responses["kubectl get pods -n orch-database"] = "database"
+
+		Expect(f.Query("kubectl", "get", "pods", "-n", "orch-database", "-o", "name")).To(Equal("database"))
+		Expect(f.Query("kubectl", "get", "pods", "-A")).To(Equal("all"))
+		_, err := f.Query("kubectl", "get", "nodes")
+		Expect(err).To(MatchError("kubectl get nodes: not found"))
+	})
+
+	It("records the commands and their inputs", func() {
+		f := executortest.New()
+		Expect(f.Run("kubectl", "delete", "pod", "x")).To(Succeed())
+		Expect(f.RunInput("kind: Secret", "kubectl", "apply", "-f", "-")).To(Succeed())
+
+		Expect(f.Commands).To(Equal([]string{"kubectl delete pod x", "kubectl apply -f -"}))
+		Expect(f.Inputs).To(Equal(map[string][]string{"kubectl apply -f -": {"kind: Secret"}}))
+		Expect(f.Ran("kubectl delete")).To(Equal([]string{"kubectl delete pod x"}))
+	})
+})
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+)
+
+// Local applies changes to the local host.
+type Local struct{}
+
+var _ Executor = Local{}
+
+func (Local) Run(name string, args ...string) error {
+	return sh.RunV(name, args...)
+}
+
+func (Local) RunInput(input string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", name, err)
+	}
+	return nil
+}
+
+func (Local) Query(name string, args ...string) (string, error) {
+	return sh.Output(name, args...)
+}
+
+func (Local) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+
+func (Local) AppendFile(path string, data []byte, perm fs.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (Local) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (Local) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (Local) Wait(_ string, wait func() error) error {
+	return wait()
+}
+
+func (Local) Apply(_ string, apply func() error) error {
+	return apply()
+}
+
+func (Local) Redact(...string) {}
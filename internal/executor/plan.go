@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+)
+
+const redacted = "<redacted>"
+
+// Plan records changes instead of applying them and prints each one to Out as it is recorded. Queries are still run
+// so that the plan reflects the current state of the system.
+type Plan struct {
+	Out io.Writer
+	// Changes counts the changes recorded so far.
+	Changes int
+
+	secrets []string
+}
+
+var _ Executor = (*Plan)(nil)
+
+// NewPlan returns a Plan printing to out.
+func NewPlan(out io.Writer) *Plan {
+	return &Plan{Out: out}
+}
+
+func (p *Plan) Run(name string, args ...string) error {
+	p.record("run", commandLine(name, args), "")
+	return nil
+}
+
+func (p *Plan) RunInput(input string, name string, args ...string) error {
+	p.record("run", commandLine(name, args), input)
+	return nil
+}
+
+func (p *Plan) Query(name string, args ...string) (string, error) {
+	return sh.Output(name, args...)
+}
+
+func (p *Plan) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	p.record("write", fmt.Sprintf("%s (%04o)", path, perm), string(data))
+	return nil
+}
+
+func (p *Plan) AppendFile(path string, data []byte, _ fs.FileMode) error {
+	p.record("append", path, string(data))
+	return nil
+}
+
+func (p *Plan) MkdirAll(path string, perm fs.FileMode) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil
+	}
+	p.record("mkdir", fmt.Sprintf("%s (%04o)", path, perm), "")
+	return nil
+}
+
+func (p *Plan) RemoveAll(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	p.record("remove", path, "")
+	return nil
+}
+
+func (p *Plan) Wait(description string, _ func() error) error {
+	p.record("wait", description, "")
+	return nil
+}
+
+func (p *Plan) Apply(description string, _ func() error) error {
+	p.record("apply", description, "")
+	return nil
+}
+
+func (p *Plan) Redact(secrets ...string) {
+	for _, secret := range secrets {
+		if secret != "" {
+			p.secrets = append(p.secrets, secret)
+		}
+	}
+}
+
+func (p *Plan) record(action string, subject string, detail string) {
+	p.Changes++
+
+	fmt.Fprintf(p.Out, "  %-7s %s\n", action, p.redact(subject))
+	if detail == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(p.redact(detail), "\n"), "\n") {
+		fmt.Fprintf(p.Out, "          | %s\n", line)
+	}
+}
+
+func (p *Plan) redact(s string) string {
+	for _, secret := range p.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+func commandLine(name string, args []string) string {
+	quoted := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{name}, args...) {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
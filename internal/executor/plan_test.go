@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executor_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		out  *bytes.Buffer
+		plan *executor.Plan
+		dir  string
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		plan = executor.NewPlan(out)
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should record changes without applying them", func() {
+		path := filepath.Join(dir, "modules.conf")
+
+		Expect(plan.WriteFile(path, []byte("dm-snapshot\ndm-mirror\n"), 0o644)).To(Succeed())
+		Expect(plan.MkdirAll(filepath.Join(dir, "new"), 0o755)).To(Succeed())
+		Expect(plan.Run("helm", "upgrade", "--install", "root-app", "--set", "a=b c")).To(Succeed())
+		Expect(plan.Wait("pods are ready", func() error {
+			Fail("wait must not be called when planning")
+			return nil
+		})).To(Succeed())
+		Expect(plan.Apply("push repo to gitea", func() error {
+			Fail("apply must not be called when planning")
+			return nil
+		})).To(Succeed())
+
+		Expect(path).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dir, "new")).NotTo(BeADirectory())
+		Expect(plan.Changes).To(Equal(5))
+		Expect(out.String()).To(Equal(
+			"  write   " + path + " (0644)\n" +
+				"          | dm-snapshot\n" +
+				"          | dm-mirror\n" +
+				"  mkdir   " + filepath.Join(dir, "new") + " (0755)\n" +
+				"  run     helm upgrade --install root-app --set 'a=b c'\n" +
+				"  wait    pods are ready\n" +
+				"  apply   push repo to gitea\n",
+		))
+	})
+
+	It("should not record changes that are already in place", func() {
+		Expect(plan.MkdirAll(dir, 0o755)).To(Succeed())
+		Expect(plan.RemoveAll(filepath.Join(dir, "missing"))).To(Succeed())
+
+		Expect(plan.Changes).To(BeZero())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("should redact secrets", func() {
+		plan.Redact("hunter2", "")
+
+		Expect(plan.RunInput("password: hunter2\n", "customize.sh", "-p", "hunter2")).To(Succeed())
+		Expect(out.String()).NotTo(ContainSubstring("hunter2"))
+		Expect(out.String()).To(ContainSubstring("customize.sh -p <redacted>"))
+		Expect(out.String()).To(ContainSubstring("| password: <redacted>"))
+	})
+
+	It("should run queries", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "f"), []byte("content"), 0o600)).To(Succeed())
+
+		Expect(plan.Query("cat", filepath.Join(dir, "f"))).To(Equal("content"))
+		Expect(plan.Changes).To(BeZero())
+	})
+})
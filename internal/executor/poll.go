@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executor
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Poll configures how WaitFor checks a condition.
+type Poll struct {
+	// Interval is how often the condition is checked, Timeout how long it is checked for.
+	Interval time.Duration
+	Timeout  time.Duration
+	// Sleep pauses between checks, it defaults to time.Sleep.
+	Sleep func(time.Duration)
+	// Log receives a debug message for every failed check, if set.
+	Log *slog.Logger
+}
+
+// WaitFor polls condition through x.Wait until it returns true or the timeout passes. Errors returned by condition
+// are retried, since the system is expected to be in flux while it is waited for, and the last one is returned on
+// timeout.
+func WaitFor(x Executor, description string, p Poll, condition func() (bool, error)) error {
+	return x.Wait(description, func() error {
+		sleep := p.Sleep
+		if sleep == nil {
+			sleep = time.Sleep
+		}
+		attempts := max(int(p.Timeout/max(p.Interval, 1)), 1)
+		var lastErr error
+		for attempt := 1; ; attempt++ {
+			done, err := condition()
+			if err == nil && done {
+				return nil
+			}
+			lastErr = err
+			if attempt >= attempts {
+				break
+			}
+			if p.Log != nil {
+				p.Log.Debug("waiting", "for", description, "elapsed", time.Duration(attempt)*p.Interval, "error", err)
+			}
+			sleep(p.Interval)
+		}
+		if lastErr != nil {
+			return fmt.Errorf("timed out after %s waiting until %s: %w", p.Timeout, description, lastErr)
+		}
+		return fmt.Errorf("timed out after %s waiting until %s", p.Timeout, description)
+	})
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package executor_test
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+var _ = Describe("WaitFor", func() {
+	var (
+		slept []time.Duration
+		poll  executor.Poll
+	)
+
+	BeforeEach(func() {
+		slept = nil
+		poll = executor.Poll{
+			Interval: time.Second,
+			Timeout:  3 * time.Second,
+			Sleep:    func(d time.Duration) { slept = append(slept, d) },
+		}
+	})
+
+	It("retries failed checks until the condition holds", func() {
+		checks := 0
+		Expect(executor.WaitFor(executor.Local{}, "pods are ready", poll, func() (bool, error) {
+			checks++
+			if checks == 1 {
+				return false, errors.New("not found")
+			}
+			return checks == 3, nil
+		})).To(Succeed())
+		Expect(slept).To(Equal([]time.Duration{time.Second, time.Second}))
+	})
+
+	It("returns the last error on timeout without sleeping after the last check", func() {
+		Expect(executor.WaitFor(executor.Local{}, "pods are ready", poll, func() (bool, error) {
+			return false, errors.New("not found")
+		})).To(MatchError("timed out after 3s waiting until pods are ready: not found"))
+		Expect(slept).To(HaveLen(2))
+	})
+
+	It("does not check when planning", func() {
+		Expect(executor.WaitFor(executor.NewPlan(io.Discard), "pods are ready", poll, func() (bool, error) {
+			Fail("the condition must not be checked when planning")
+			return false, nil
+		})).To(Succeed())
+	})
+})
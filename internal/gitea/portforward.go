@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitea
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+var forwardingRegex = regexp.MustCompile(`^Forwarding from (127\.0\.0\.1:\d+) ->`)
+
+// PortForward is a running kubectl port-forward to a service.
+type PortForward struct {
+	// Addr is the local end of the port-forward, e.g. 127.0.0.1:41235.
+	Addr string
+	cmd  *exec.Cmd
+}
+
+// StartPortForward forwards a free local port to port of service in namespace and returns once the port-forward
+// accepts connections.
+func StartPortForward(namespace, service, port string, timeout time.Duration) (*PortForward, error) {
+	cmd := exec.Command("kubectl", "port-forward", "-n", namespace, "svc/"+service, ":"+port) //nolint: gosec
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start port-forward to %s/%s: %w", namespace, service, err)
+	}
+
+	addr := make(chan string, 1)
+	go func() {
+		defer close(addr)
+		found := false
+		// Keep reading until kubectl exits so that it never blocks writing to a full pipe.
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if match := forwardingRegex.FindStringSubmatch(scanner.Text()); match != nil && !found {
+				found = true
+				addr <- match[1]
+			}
+		}
+	}()
+
+	select {
+	case local, ok := <-addr:
+		if !ok {
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("port-forward to %s/%s exited", namespace, service)
+		}
+		return &PortForward{Addr: local, cmd: cmd}, nil
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("timed out waiting for port-forward to %s/%s", namespace, service)
+	}
+}
+
+// Close stops the port-forward.
+func (p *PortForward) Close() error {
+	if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	_ = p.cmd.Wait()
+	return nil
+}
+
+// HTTPClient returns a client that connects to addr whatever the host of the request URL, and verifies the server
+// against caPEM under that host name. This lets a port-forwarded service be reached by its cluster DNS name.
+func HTTPClient(addr string, caPEM []byte) (*http.Client, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in CA bundle")
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitea pushes the repositories extracted from the installer artifacts to the Gitea instance Argo CD deploys
+// from.
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// protocolMu serializes the pushes that replace the HTTPS transport of go-git, which is global to the process.
+var protocolMu sync.Mutex
+
+const (
+	remoteName = "gitea"
+	branch     = plumbing.ReferenceName("refs/heads/main")
+)
+
+// PushOptions configure Push.
+type PushOptions struct {
+	// URL is the repository URL, e.g. https://gitea-http.gitea.svc.cluster.local/argocd/edge-manageability-framework.
+	URL      string
+	Username string
+	Password string
+	// HTTPClient, when set, is used for the HTTPS remote of the push, e.g. to trust the Gitea CA or to dial a
+	// port-forward.
+	HTTPClient *http.Client
+	// Force replaces the history of the remote branch with a single commit instead of adding a commit on top of it.
+	Force bool
+	// Message is the commit message.
+	Message string
+}
+
+// PushResult describes the outcome of Push.
+type PushResult struct {
+	URL string
+	// Previous is the commit the remote branch pointed to, zero if the branch did not exist.
+	Previous plumbing.Hash
+	// Current is the commit the remote branch points to now.
+	Current plumbing.Hash
+	// Changes counts the files added, modified or deleted compared to Previous.
+	Changes int
+	// UpToDate is set when the directory matched Previous and nothing was pushed.
+	UpToDate bool
+}
+
+func (r PushResult) String() string {
+	switch {
+	case r.UpToDate:
+		return fmt.Sprintf("%s: up to date at %s", r.URL, r.Current.String()[:7])
+	case r.Previous.IsZero():
+		return fmt.Sprintf("%s: created main at %s", r.URL, r.Current.String()[:7])
+	default:
+		return fmt.Sprintf("%s: updated main %s..%s, %d files changed", r.URL, r.Previous.String()[:7],
+			r.Current.String()[:7], r.Changes)
+	}
+}
+
+// useHTTPClient makes go-git use httpClient for HTTPS remotes until the returned function restores the previous
+// transport, so that the client of a push doesn't leak to the other remotes of the process.
+func useHTTPClient(httpClient *http.Client) func() {
+	protocolMu.Lock()
+	previous, ok := client.Protocols["https"]
+	client.InstallProtocol("https", githttp.NewClient(httpClient))
+	return func() {
+		if ok {
+			client.InstallProtocol("https", previous)
+		} else {
+			client.InstallProtocol("https", nil)
+		}
+		protocolMu.Unlock()
+	}
+}
+
+// Push commits the contents of dir to the main branch of the repository at opts.URL. Unless opts.Force is set the
+// commit is added on top of the remote branch, so that the history of earlier installations and upgrades is kept.
+// The git metadata is held in memory only and dir is left untouched.
+func Push(dir string, opts PushOptions) (*PushResult, error) {
+	if opts.HTTPClient != nil {
+		defer useHTTPClient(opts.HTTPClient)()
+	}
+
+	auth := &githttp.BasicAuth{Username: opts.Username, Password: opts.Password}
+
+	repo, err := git.Init(memory.NewStorage(), osfs.New(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init repository: %w", err)
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: remoteName, URLs: []string{opts.URL}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add remote: %w", err)
+	}
+
+	result := &PushResult{URL: opts.URL}
+
+	var parent *object.Commit
+	if !opts.Force {
+		if parent, err = fetchBranch(repo, remote, auth); err != nil {
+			return nil, err
+		}
+		if parent != nil {
+			result.Previous = parent.Hash
+			if err := repo.Storer.SetReference(plumbing.NewHashReference(branch, parent.Hash)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	// The index starts out empty, so it ends up holding exactly the files in dir and files removed from dir since
+	// the previous push are deleted by the commit.
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return nil, fmt.Errorf("failed to add files: %w", err)
+	}
+
+	now := time.Now()
+	hash, err := worktree.Commit(opts.Message, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: opts.Username, Email: opts.Username + "@orch-installer.com", When: now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		changes, err := changedFiles(parent, commit)
+		if err != nil {
+			return nil, err
+		}
+		if changes == 0 {
+			result.Current = parent.Hash
+			result.UpToDate = true
+			return result, nil
+		}
+		result.Changes = changes
+	}
+
+	err = remote.Push(&git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(branch + ":" + branch)},
+		Auth:       auth,
+		Force:      opts.Force,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to push to %s: %w", opts.URL, err)
+	}
+
+	result.Current = hash
+	return result, nil
+}
+
+// fetchBranch returns the head of the remote main branch, or nil if the remote repository has no main branch yet.
+func fetchBranch(repo *git.Repository, remote *git.Remote, auth transport.AuthMethod) (*object.Commit, error) {
+	err := remote.Fetch(&git.FetchOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec("+" + branch + ":refs/remotes/" + remoteName + "/main")},
+		Auth:       auth,
+	})
+	switch {
+	case errors.Is(err, transport.ErrEmptyRemoteRepository), errors.Is(err, git.NoMatchingRefSpecError{}):
+		return nil, nil
+	case err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate):
+		return nil, fmt.Errorf("failed to fetch main: %w", err)
+	}
+
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, "main"), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve fetched main: %w", err)
+	}
+	return repo.CommitObject(ref.Hash())
+}
+
+func changedFiles(from, to *object.Commit) (int, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return 0, err
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return 0, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return 0, fmt.Errorf("failed to diff commits: %w", err)
+	}
+	return len(changes), nil
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitea_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/gitea"
+)
+
+var _ = Describe("Push", func() {
+	var (
+		remoteDir string
+		dir       string
+		opts      gitea.PushOptions
+	)
+
+	write := func(rel, content string) {
+		path := filepath.Join(dir, rel)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+
+	// history returns the messages of the commits on the remote main branch, newest first, and the files of its head.
+	history := func() ([]string, []string) {
+		remote, err := git.PlainOpen(remoteDir)
+		Expect(err).NotTo(HaveOccurred())
+		ref, err := remote.Reference(plumbing.NewBranchReferenceName("main"), true)
+		Expect(err).NotTo(HaveOccurred())
+
+		var messages []string
+		commits, err := remote.Log(&git.LogOptions{From: ref.Hash()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commits.ForEach(func(c *object.Commit) error {
+			messages = append(messages, c.Message)
+			return nil
+		})).To(Succeed())
+
+		head, err := remote.CommitObject(ref.Hash())
+		Expect(err).NotTo(HaveOccurred())
+		tree, err := head.Tree()
+		Expect(err).NotTo(HaveOccurred())
+		var files []string
+		Expect(tree.Files().ForEach(func(f *object.File) error {
+			files = append(files, f.Name)
+			return nil
+		})).To(Succeed())
+
+		return messages, files
+	}
+
+	BeforeEach(func() {
+		remoteDir = filepath.Join(GinkgoT().TempDir(), "remote.git")
+		_, err := git.PlainInit(remoteDir, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir = GinkgoT().TempDir()
+		write("argocd/root-app/Chart.yaml", "name: root-app\nversion: 1.0.0\n")
+		write("orch-configs/clusters/onprem.yaml", "root:\n")
+
+		opts = gitea.PushOptions{URL: "file://" + remoteDir, Username: "argocd", Message: "Install 1.0.0"}
+	})
+
+	It("creates the branch on the first push", func() {
+		result, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Previous.IsZero()).To(BeTrue())
+		Expect(result.String()).To(ContainSubstring("created main"))
+
+		messages, files := history()
+		Expect(messages).To(Equal([]string{"Install 1.0.0"}))
+		Expect(files).To(ConsistOf("argocd/root-app/Chart.yaml", "orch-configs/clusters/onprem.yaml"))
+	})
+
+	It("adds a commit on top of the previous one", func() {
+		first, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		write("argocd/root-app/Chart.yaml", "name: root-app\nversion: 1.1.0\n")
+		Expect(os.Remove(filepath.Join(dir, "orch-configs/clusters/onprem.yaml"))).To(Succeed())
+		write("orch-configs/clusters/onprem-oxm.yaml", "root:\n")
+		opts.Message = "Upgrade to 1.1.0"
+
+		result, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Previous).To(Equal(first.Current))
+		Expect(result.Changes).To(Equal(3))
+
+		messages, files := history()
+		Expect(messages).To(Equal([]string{"Upgrade to 1.1.0", "Install 1.0.0"}))
+		Expect(files).To(ConsistOf("argocd/root-app/Chart.yaml", "orch-configs/clusters/onprem-oxm.yaml"))
+	})
+
+	It("does not push when nothing changed", func() {
+		first, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.UpToDate).To(BeTrue())
+		Expect(result.Current).To(Equal(first.Current))
+
+		messages, _ := history()
+		Expect(messages).To(HaveLen(1))
+	})
+
+	It("replaces the history when forced", func() {
+		_, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		write("argocd/root-app/Chart.yaml", "name: root-app\nversion: 1.1.0\n")
+		opts.Force = true
+		opts.Message = "Recreate repo from artifact"
+		_, err = gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		messages, _ := history()
+		Expect(messages).To(Equal([]string{"Recreate repo from artifact"}))
+	})
+
+	It("leaves the directory untouched", func() {
+		_, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(dir, ".git")).NotTo(BeADirectory())
+	})
+	It("restores the HTTPS transport of go-git after the push", func() {
+		previous := client.Protocols["https"]
+		opts.HTTPClient = &http.Client{}
+		_, err := gitea.Push(dir, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Protocols["https"]).To(BeIdenticalTo(previous))
+	})
+})
@@ -0,0 +1,288 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hostconfig manages the kernel parameters and modules Orchestrator needs through dedicated drop-in files, and
+// reverts them to the values the host had before.
+package hostconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// Paths relative to the root filesystem.
+const (
+	SysctlDropIn  = "etc/sysctl.d/99-orchestrator.conf"
+	ModulesDropIn = "etc/modules-load.d/99-orchestrator.conf"
+	// StatePath holds the live values the host had before the drop-ins were applied.
+	StatePath = "var/lib/orch-installer/host-config.json"
+
+	legacySysctlConf  = "etc/sysctl.conf"
+	legacyModulesConf = "etc/modules-load.d/lv-snapshots.conf"
+)
+
+// Sysctl is a kernel parameter.
+type Sysctl struct {
+	Key   string
+	Value string
+}
+
+// Sysctls are the kernel parameters Orchestrator needs.
+var Sysctls = []Sysctl{
+	// Kubernetes and its workloads watch far more files than the distribution defaults allow.
+	{Key: "fs.inotify.max_queued_events", Value: "1048576"},
+	{Key: "fs.inotify.max_user_instances", Value: "1048576"},
+	{Key: "fs.inotify.max_user_watches", Value: "1048576"},
+}
+
+// Modules are the kernel modules Orchestrator needs, loaded at boot.
+var Modules = []string{
+	// LV snapshots of the OpenEBS LVM local volumes.
+	"dm-snapshot",
+	"dm-mirror",
+}
+
+// State is the host configuration found before Apply first changed it.
+type State struct {
+	// Sysctls maps each kernel parameter to its previous live value.
+	Sysctls map[string]string `json:"sysctls"`
+	// Modules maps each kernel module to whether it was loaded.
+	Modules map[string]bool `json:"modules"`
+}
+
+// Manager applies and reverts the host configuration of Sysctls and Modules.
+type Manager struct {
+	// Root is the root filesystem, / unless testing.
+	Root string
+	Exec executor.Executor
+	Out  io.Writer
+}
+
+// Apply writes the drop-ins and sets the live values. The previous live values are recorded on the first run only, so
+// that repeated runs keep the values the host had before Orchestrator was installed.
+func (m Manager) Apply() error {
+	state, err := m.loadState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		if state, err = m.currentState(); err != nil {
+			return err
+		}
+		if err := m.saveState(state); err != nil {
+			return err
+		}
+	}
+
+	if err := m.removeLegacy(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(m.Out, "Writing kernel parameters to /%s\n", SysctlDropIn)
+	if err := m.Exec.MkdirAll(m.path(filepath.Dir(SysctlDropIn)), 0o755); err != nil {
+		return err
+	}
+	if err := m.Exec.WriteFile(m.path(SysctlDropIn), []byte(sysctlDropIn()), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", SysctlDropIn, err)
+	}
+	for _, sysctl := range Sysctls {
+		if err := m.setSysctl(sysctl.Key, sysctl.Value); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(m.Out, "Writing kernel modules to /%s\n", ModulesDropIn)
+	if err := m.Exec.MkdirAll(m.path(filepath.Dir(ModulesDropIn)), 0o755); err != nil {
+		return err
+	}
+	if err := m.Exec.WriteFile(m.path(ModulesDropIn), []byte(modulesDropIn()), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", ModulesDropIn, err)
+	}
+	for _, module := range Modules {
+		if err := m.Exec.Run("modprobe", module); err != nil {
+			return fmt.Errorf("loading kernel module %s: %w", module, err)
+		}
+	}
+
+	return nil
+}
+
+// Revert removes the drop-ins and restores the live values recorded by Apply. Modules that were not loaded before
+// are unloaded on a best effort basis since they may be in use.
+func (m Manager) Revert() error {
+	state, err := m.loadState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		fmt.Fprintln(m.Out, "No previous host configuration recorded, nothing to revert")
+		return nil
+	}
+
+	fmt.Fprintf(m.Out, "Removing /%s and /%s\n", SysctlDropIn, ModulesDropIn)
+	for _, path := range []string{SysctlDropIn, ModulesDropIn} {
+		if err := m.Exec.RemoveAll(m.path(path)); err != nil {
+			return fmt.Errorf("removing %s: %w", path, err)
+		}
+	}
+
+	for _, key := range sortedKeys(state.Sysctls) {
+		if err := m.setSysctl(key, state.Sysctls[key]); err != nil {
+			return err
+		}
+	}
+
+	for _, module := range sortedKeys(state.Modules) {
+		if state.Modules[module] {
+			continue
+		}
+		if err := m.Exec.Run("modprobe", "--remove", module); err != nil {
+			fmt.Fprintf(m.Out, "Warning: failed to unload kernel module %s: %v\n", module, err)
+		}
+	}
+
+	return m.Exec.RemoveAll(m.path(StatePath))
+}
+
+func (m Manager) path(rel string) string {
+	return filepath.Join(m.Root, rel)
+}
+
+func (m Manager) sysctlPath(key string) string {
+	return m.path(filepath.Join("proc/sys", strings.ReplaceAll(key, ".", "/")))
+}
+
+// setSysctl sets a live value the way sysctl --write does.
+func (m Manager) setSysctl(key, value string) error {
+	if err := m.Exec.WriteFile(m.sysctlPath(key), []byte(value+"\n"), 0o644); err != nil {
+		return fmt.Errorf("setting %s: %w", key, err)
+	}
+	return nil
+}
+
+func (m Manager) currentState() (*State, error) {
+	state := &State{Sysctls: map[string]string{}, Modules: map[string]bool{}}
+	for _, sysctl := range Sysctls {
+		value, err := os.ReadFile(m.sysctlPath(sysctl.Key))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", sysctl.Key, err)
+		}
+		state.Sysctls[sysctl.Key] = strings.TrimSpace(string(value))
+	}
+	for _, module := range Modules {
+		_, err := os.Stat(m.path(filepath.Join("sys/module", strings.ReplaceAll(module, "-", "_"))))
+		state.Modules[module] = err == nil
+	}
+	return state, nil
+}
+
+func (m Manager) loadState() (*State, error) {
+	data, err := os.ReadFile(m.path(StatePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading host configuration state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing host configuration state %s: %w", StatePath, err)
+	}
+	return &state, nil
+}
+
+func (m Manager) saveState(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := m.Exec.MkdirAll(m.path(filepath.Dir(StatePath)), 0o700); err != nil {
+		return err
+	}
+	if err := m.Exec.WriteFile(m.path(StatePath), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing host configuration state: %w", err)
+	}
+	return nil
+}
+
+// removeLegacy drops the settings earlier installers appended to /etc/sysctl.conf and the modules-load.d file they
+// wrote, both of which are superseded by the drop-ins.
+func (m Manager) removeLegacy() error {
+	if err := m.Exec.RemoveAll(m.path(legacyModulesConf)); err != nil {
+		return err
+	}
+
+	file, err := os.Open(m.path(legacySysctlConf))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", legacySysctlConf, err)
+	}
+	defer file.Close()
+
+	managed := map[string]bool{}
+	for _, sysctl := range Sysctls {
+		managed[fmt.Sprintf("%s = %s", sysctl.Key, sysctl.Value)] = true
+	}
+
+	var kept strings.Builder
+	removed := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if managed[strings.TrimSpace(line)] {
+			removed = true
+			continue
+		}
+		kept.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scanning %s: %w", legacySysctlConf, err)
+	}
+	if !removed {
+		return nil
+	}
+
+	fmt.Fprintf(m.Out, "Removing Orchestrator kernel parameters from /%s\n", legacySysctlConf)
+	return m.Exec.WriteFile(m.path(legacySysctlConf), []byte(kept.String()), 0o644)
+}
+
+func sysctlDropIn() string {
+	var b strings.Builder
+	b.WriteString("# Managed by onprem-config-installer, changes will be overwritten.\n")
+	for _, sysctl := range Sysctls {
+		fmt.Fprintf(&b, "%s = %s\n", sysctl.Key, sysctl.Value)
+	}
+	return b.String()
+}
+
+func modulesDropIn() string {
+	var b strings.Builder
+	b.WriteString("# Managed by onprem-config-installer, changes will be overwritten.\n")
+	for _, module := range Modules {
+		b.WriteString(module + "\n")
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hostconfig_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/hostconfig"
+)
+
+// recorder applies file changes to the temporary root and records commands instead of running them.
+type recorder struct {
+	executor.Local
+	commands []string
+}
+
+func (r *recorder) Run(name string, args ...string) error {
+	r.commands = append(r.commands, strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+var _ = Describe("Manager", func() {
+	var (
+		root    string
+		exec    *recorder
+		manager hostconfig.Manager
+	)
+
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(root, rel))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		exec = &recorder{}
+		manager = hostconfig.Manager{Root: root, Exec: exec, Out: io.Discard}
+
+		write("proc/sys/fs/inotify/max_queued_events", "16384\n")
+		write("proc/sys/fs/inotify/max_user_instances", "128\n")
+		write("proc/sys/fs/inotify/max_user_watches", "65536\n")
+		Expect(os.MkdirAll(filepath.Join(root, "sys/module/dm_mirror"), 0o755)).To(Succeed())
+	})
+
+	It("writes the drop-ins and sets the live values", func() {
+		Expect(manager.Apply()).To(Succeed())
+
+		Expect(read(hostconfig.SysctlDropIn)).To(ContainSubstring("fs.inotify.max_user_watches = 1048576\n"))
+		Expect(read(hostconfig.ModulesDropIn)).To(HaveSuffix("dm-snapshot\ndm-mirror\n"))
+		Expect(read("proc/sys/fs/inotify/max_queued_events")).To(Equal("1048576\n"))
+		Expect(read("proc/sys/fs/inotify/max_user_watches")).To(Equal("1048576\n"))
+		Expect(exec.commands).To(Equal([]string{"modprobe dm-snapshot", "modprobe dm-mirror"}))
+	})
+
+	It("is idempotent", func() {
+		Expect(manager.Apply()).To(Succeed())
+		dropIn := read(hostconfig.SysctlDropIn)
+		state := read(hostconfig.StatePath)
+
+		Expect(manager.Apply()).To(Succeed())
+		Expect(read(hostconfig.SysctlDropIn)).To(Equal(dropIn))
+		Expect(read(hostconfig.StatePath)).To(Equal(state))
+	})
+
+	It("reverts to the values found before the first run", func() {
+		Expect(manager.Apply()).To(Succeed())
+		Expect(manager.Apply()).To(Succeed())
+		exec.commands = nil
+
+		Expect(manager.Revert()).To(Succeed())
+
+		Expect(read("proc/sys/fs/inotify/max_queued_events")).To(Equal("16384\n"))
+		Expect(read("proc/sys/fs/inotify/max_user_instances")).To(Equal("128\n"))
+		Expect(read("proc/sys/fs/inotify/max_user_watches")).To(Equal("65536\n"))
+		for _, rel := range []string{hostconfig.SysctlDropIn, hostconfig.ModulesDropIn, hostconfig.StatePath} {
+			Expect(filepath.Join(root, rel)).NotTo(BeAnExistingFile())
+		}
+		// dm-mirror was loaded before and stays loaded.
+		Expect(exec.commands).To(Equal([]string{"modprobe --remove dm-snapshot"}))
+	})
+
+	It("does nothing on revert when nothing was applied", func() {
+		Expect(manager.Revert()).To(Succeed())
+		Expect(read("proc/sys/fs/inotify/max_user_watches")).To(Equal("65536\n"))
+		Expect(exec.commands).To(BeEmpty())
+	})
+
+	It("migrates settings written by earlier installers", func() {
+		write("etc/sysctl.conf", "# local settings\nvm.swappiness = 10\nfs.inotify.max_queued_events = 1048576\n"+
+			"fs.inotify.max_user_instances = 1048576\nfs.inotify.max_user_watches = 1048576\n")
+		write("etc/modules-load.d/lv-snapshots.conf", "dm-snapshot\ndm-mirror\n")
+
+		Expect(manager.Apply()).To(Succeed())
+
+		Expect(read("etc/sysctl.conf")).To(Equal("# local settings\nvm.swappiness = 10\n"))
+		Expect(filepath.Join(root, "etc/modules-load.d/lv-snapshots.conf")).NotTo(BeAnExistingFile())
+	})
+
+	It("only records changes when planning", func() {
+		manager.Exec = executor.NewPlan(io.Discard)
+
+		Expect(manager.Apply()).To(Succeed())
+
+		Expect(read("proc/sys/fs/inotify/max_user_watches")).To(Equal("65536\n"))
+		Expect(filepath.Join(root, hostconfig.SysctlDropIn)).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(root, hostconfig.StatePath)).NotTo(BeAnExistingFile())
+	})
+})
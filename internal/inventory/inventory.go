@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package inventory reads the node inventory of a multi-node RKE2 cluster and builds the SSH commands the installers
+// reach the nodes with.
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the inventory file format this package reads.
+const SchemaVersion = 1
+
+// Role is the RKE2 role of a node.
+type Role string
+
+const (
+	// Server nodes run the control plane and etcd.
+	Server Role = "server"
+	// Agent nodes run workloads only.
+	Agent Role = "agent"
+)
+
+// nodeNameRegex matches the names Kubernetes accepts for nodes.
+var nodeNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// SSH configures how a node is reached. Empty fields fall back to the inventory defaults and then to the SSH client
+// configuration.
+type SSH struct {
+	User         string `yaml:"user"`
+	Port         int    `yaml:"port"`
+	IdentityFile string `yaml:"identityFile"`
+}
+
+// Node is a cluster node.
+type Node struct {
+	// Name is the Kubernetes node name, which RKE2 is configured with.
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Role    Role   `yaml:"role"`
+	SSH     SSH    `yaml:"ssh"`
+}
+
+// Inventory lists the nodes of a cluster. The first server bootstraps the cluster, the installer runs on it.
+type Inventory struct {
+	Nodes []Node
+}
+
+type inventoryFile struct {
+	SchemaVersion int    `yaml:"schemaVersion"`
+	SSH           SSH    `yaml:"ssh"`
+	Nodes         []Node `yaml:"nodes"`
+}
+
+// Load reads and validates the inventory file at path.
+func Load(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node inventory: %w", err)
+	}
+	inventory, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node inventory %s: %w", path, err)
+	}
+	return inventory, nil
+}
+
+// Parse parses and validates an inventory file.
+func Parse(data []byte) (*Inventory, error) {
+	var file inventoryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schemaVersion %d, expected %d", file.SchemaVersion, SchemaVersion)
+	}
+	if len(file.Nodes) == 0 {
+		return nil, errors.New("no nodes listed")
+	}
+
+	var errs []error
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	servers := 0
+	for i := range file.Nodes {
+		node := &file.Nodes[i]
+		if !nodeNameRegex.MatchString(node.Name) {
+			errs = append(errs, fmt.Errorf("node %d: invalid name %q, must be a lowercase DNS name", i+1, node.Name))
+		}
+		if names[node.Name] {
+			errs = append(errs, fmt.Errorf("node %s is listed twice", node.Name))
+		}
+		names[node.Name] = true
+		if node.Address == "" {
+			errs = append(errs, fmt.Errorf("node %s: address is required", node.Name))
+		} else if addresses[node.Address] {
+			errs = append(errs, fmt.Errorf("node %s: address %s is listed twice", node.Name, node.Address))
+		}
+		addresses[node.Address] = true
+
+		switch node.Role {
+		case Server:
+			servers++
+		case Agent:
+		default:
+			errs = append(errs, fmt.Errorf("node %s: invalid role %q, must be %s or %s", node.Name, node.Role, Server,
+				Agent))
+		}
+
+		if node.SSH.User == "" {
+			node.SSH.User = file.SSH.User
+		}
+		if node.SSH.Port == 0 {
+			node.SSH.Port = file.SSH.Port
+		}
+		if node.SSH.IdentityFile == "" {
+			node.SSH.IdentityFile = file.SSH.IdentityFile
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case servers == 0:
+		return nil, errors.New("at least one server is required")
+	case servers%2 == 0:
+		// etcd needs a majority of the servers, so an even number tolerates no more failures than one server less.
+		return nil, fmt.Errorf("%d servers listed, an odd number is required for etcd quorum", servers)
+	}
+
+	return &Inventory{Nodes: file.Nodes}, nil
+}
+
+// Servers returns the server nodes in inventory order.
+func (inv *Inventory) Servers() []Node {
+	return inv.withRole(Server)
+}
+
+// Agents returns the agent nodes in inventory order.
+func (inv *Inventory) Agents() []Node {
+	return inv.withRole(Agent)
+}
+
+// Bootstrap returns the server that initializes the cluster and that the other nodes join.
+func (inv *Inventory) Bootstrap() Node {
+	return inv.Servers()[0]
+}
+
+// Node returns the node called name.
+func (inv *Inventory) Node(name string) (Node, bool) {
+	for _, node := range inv.Nodes {
+		if node.Name == name {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+func (inv *Inventory) withRole(role Role) []Node {
+	var nodes []Node
+	for _, node := range inv.Nodes {
+		if node.Role == role {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// sshOptions never prompt, so that an unreachable or misconfigured node fails the installer instead of hanging it.
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new"}
+
+// Command returns the SSH command line that runs command on the node. The arguments are quoted for the remote shell.
+func (n Node) Command(command ...string) []string {
+	args := append([]string{"ssh"}, sshOptions...)
+	if n.SSH.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(n.SSH.Port))
+	}
+	if n.SSH.IdentityFile != "" {
+		args = append(args, "-i", n.SSH.IdentityFile)
+	}
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = shellQuote(arg)
+	}
+	return append(args, n.target(), "--", strings.Join(quoted, " "))
+}
+
+// Copy returns the command line that copies the local path src, recursively, to dst on the node.
+func (n Node) Copy(src, dst string) []string {
+	args := append([]string{"scp", "-r", "-q"}, sshOptions...)
+	if n.SSH.Port != 0 {
+		args = append(args, "-P", strconv.Itoa(n.SSH.Port))
+	}
+	if n.SSH.IdentityFile != "" {
+		args = append(args, "-i", n.SSH.IdentityFile)
+	}
+	return append(args, src, n.target()+":"+dst)
+}
+
+func (n Node) target() string {
+	host := n.Address
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if n.SSH.User != "" {
+		return n.SSH.User + "@" + host
+	}
+	return host
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:+,@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// UpgradeBatches returns the order in which nodes are upgraded: servers one at a time, so that etcd keeps its quorum,
+// followed by agents in batches of up to concurrency nodes.
+func UpgradeBatches(servers, agents []string, concurrency int) [][]string {
+	concurrency = max(concurrency, 1)
+
+	var batches [][]string
+	for _, server := range servers {
+		batches = append(batches, []string{server})
+	}
+	for start := 0; start < len(agents); start += concurrency {
+		batches = append(batches, agents[start:min(start+concurrency, len(agents))])
+	}
+	return batches
+}
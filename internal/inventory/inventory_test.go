@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/inventory"
+)
+
+const inventoryFile = `
+schemaVersion: 1
+ssh:
+  user: ubuntu
+  identityFile: /root/.ssh/id_ed25519
+nodes:
+  - name: orch-cp-1
+    address: 10.0.0.11
+    role: server
+  - name: orch-cp-2
+    address: 10.0.0.12
+    role: server
+  - name: orch-cp-3
+    address: 10.0.0.13
+    role: server
+    ssh:
+      user: admin
+      port: 2222
+  - name: orch-worker-1
+    address: 10.0.0.21
+    role: agent
+`
+
+var _ = Describe("Inventory", func() {
+	var inv *inventory.Inventory
+
+	BeforeEach(func() {
+		var err error
+		inv, err = inventory.Parse([]byte(inventoryFile))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	names := func(nodes []inventory.Node) []string {
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		return names
+	}
+
+	It("splits nodes by role in inventory order", func() {
+		Expect(names(inv.Servers())).To(Equal([]string{"orch-cp-1", "orch-cp-2", "orch-cp-3"}))
+		Expect(names(inv.Agents())).To(Equal([]string{"orch-worker-1"}))
+		Expect(inv.Bootstrap().Name).To(Equal("orch-cp-1"))
+	})
+
+	It("applies the SSH defaults to nodes that don't override them", func() {
+		node, ok := inv.Node("orch-cp-1")
+		Expect(ok).To(BeTrue())
+		Expect(node.SSH).To(Equal(inventory.SSH{User: "ubuntu", IdentityFile: "/root/.ssh/id_ed25519"}))
+
+		node, _ = inv.Node("orch-cp-3")
+		Expect(node.SSH).To(Equal(inventory.SSH{User: "admin", Port: 2222, IdentityFile: "/root/.ssh/id_ed25519"}))
+	})
+
+	It("builds SSH command lines quoted for the remote shell", func() {
+		node, _ := inv.Node("orch-cp-3")
+		Expect(node.Command("sh", "-c", "cd /tmp && ls")).To(Equal([]string{
+			"ssh", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new", "-p", "2222",
+			"-i", "/root/.ssh/id_ed25519", "admin@10.0.0.13", "--", "sh -c 'cd /tmp && ls'",
+		}))
+		Expect(node.Copy("rke2", "/tmp/installer")).To(Equal([]string{
+			"scp", "-r", "-q", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new", "-P", "2222",
+			"-i", "/root/.ssh/id_ed25519", "rke2", "admin@10.0.0.13:/tmp/installer",
+		}))
+	})
+
+	It("brackets IPv6 addresses", func() {
+		node := inventory.Node{Name: "n", Address: "fd00::1"}
+		Expect(node.Command("true")).To(HaveExactElements(
+			"ssh", "-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new", "[fd00::1]", "--", "true"))
+	})
+
+	DescribeTable("rejects invalid inventories",
+		func(data, message string) {
+			_, err := inventory.Parse([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unknown schema", "schemaVersion: 2\n", "unsupported schemaVersion 2"),
+		Entry("no nodes", "schemaVersion: 1\n", "no nodes listed"),
+		Entry("invalid name", `
+schemaVersion: 1
+nodes: [{name: Orch_1, address: 10.0.0.1, role: server}]
+`, `invalid name "Orch_1"`),
+		Entry("duplicate name", `
+schemaVersion: 1
+nodes:
+  - {name: orch-1, address: 10.0.0.1, role: server}
+  - {name: orch-1, address: 10.0.0.2, role: agent}
+`, "node orch-1 is listed twice"),
+		Entry("duplicate address", `
+schemaVersion: 1
+nodes:
+  - {name: orch-1, address: 10.0.0.1, role: server}
+  - {name: orch-2, address: 10.0.0.1, role: agent}
+`, "address 10.0.0.1 is listed twice"),
+		Entry("missing address", `
+schemaVersion: 1
+nodes: [{name: orch-1, role: server}]
+`, "address is required"),
+		Entry("invalid role", `
+schemaVersion: 1
+nodes: [{name: orch-1, address: 10.0.0.1, role: worker}]
+`, `invalid role "worker"`),
+		Entry("no server", `
+schemaVersion: 1
+nodes: [{name: orch-1, address: 10.0.0.1, role: agent}]
+`, "at least one server is required"),
+		Entry("even number of servers", `
+schemaVersion: 1
+nodes:
+  - {name: orch-1, address: 10.0.0.1, role: server}
+  - {name: orch-2, address: 10.0.0.2, role: server}
+`, "an odd number is required"),
+	)
+})
+
+var _ = Describe("UpgradeBatches", func() {
+	It("upgrades servers one at a time, then agents in batches", func() {
+		Expect(inventory.UpgradeBatches([]string{"s1", "s2", "s3"}, []string{"a1", "a2", "a3", "a4", "a5"}, 2)).
+			To(Equal([][]string{{"s1"}, {"s2"}, {"s3"}, {"a1", "a2"}, {"a3", "a4"}, {"a5"}}))
+	})
+
+	It("upgrades agents one at a time without a concurrency", func() {
+		Expect(inventory.UpgradeBatches([]string{"s1"}, []string{"a1", "a2"}, 0)).
+			To(Equal([][]string{{"s1"}, {"a1"}, {"a2"}}))
+	})
+})
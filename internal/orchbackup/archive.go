@@ -0,0 +1,402 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package orchbackup backs up the data of an Orchestrator to a single versioned archive and restores it: logical
+// dumps of the PostgreSQL databases, a Vault snapshot, the Secrets and ConfigMaps of the Orchestrator namespaces and
+// the OpenEBS hostpath volumes, described by a manifest that is checked before anything is restored.
+package orchbackup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+const (
+	// FormatVersion is the version of the archive layout. Archives of other format versions are not restored.
+	FormatVersion = 1
+
+	manifestFile = "manifest.json"
+	// UnknownVersion is recorded for the versions that can't be determined.
+	UnknownVersion = "unknown"
+)
+
+// Manifest describes the content of an archive and the deployment it was taken from.
+type Manifest struct {
+	FormatVersion       int       `json:"formatVersion"`
+	CreatedAt           time.Time `json:"createdAt"`
+	OrchestratorVersion string    `json:"orchestratorVersion"`
+	KubernetesVersion   string    `json:"kubernetesVersion"`
+	PostgresVersion     string    `json:"postgresVersion"`
+	VaultVersion        string    `json:"vaultVersion"`
+	// VaultStorage is the storage backend of Vault. Only the raft storage is snapshotted, the data of the other
+	// backends is part of the database dumps.
+	VaultStorage string   `json:"vaultStorage"`
+	Databases    []string `json:"databases"`
+	Namespaces   []string `json:"namespaces"`
+	Volumes      []Volume `json:"volumes"`
+	// Files maps the path of every file of the archive other than the manifest to its SHA-256 checksum.
+	Files map[string]string `json:"files"`
+}
+
+// Archiver creates and restores backup archives of the Orchestrator deployed in the current kubectl context.
+type Archiver struct {
+	// Dir is where the archives are created and where they are unpacked while being restored.
+	Dir string
+	X   executor.Executor
+	Log *slog.Logger
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+// RestoreOptions configure a restore.
+type RestoreOptions struct {
+	// Force restores an archive of another Orchestrator or PostgreSQL version. Archives that are corrupt or of
+	// another format version are never restored.
+	Force bool
+}
+
+// Create backs up the Orchestrator and returns the path of the archive.
+func (a *Archiver) Create() (string, error) {
+	m := Manifest{FormatVersion: FormatVersion, CreatedAt: a.now().UTC()}
+	m.OrchestratorVersion = a.orchestratorVersion()
+	m.KubernetesVersion = a.kubernetesVersion()
+
+	name := fmt.Sprintf("orch-backup-%s-%s", strings.TrimPrefix(m.OrchestratorVersion, "v"),
+		m.CreatedAt.Format("20060102-150405"))
+	staging := filepath.Join(a.Dir, name)
+	if err := a.X.MkdirAll(staging, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	a.Log.Info("creating backup", "name", name, "orchestratorVersion", m.OrchestratorVersion)
+
+	if err := a.dumpDatabases(staging, &m); err != nil {
+		return "", err
+	}
+	if err := a.snapshotVault(staging, &m); err != nil {
+		return "", err
+	}
+	if err := a.saveObjects(staging, &m); err != nil {
+		return "", err
+	}
+	if err := a.saveVolumes(staging, &m); err != nil {
+		return "", err
+	}
+
+	files, err := checksums(staging)
+	if err != nil {
+		return "", err
+	}
+	m.Files = files
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := a.X.WriteFile(filepath.Join(staging, manifestFile), append(data, '\n'), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	archive := staging + ".tar.gz"
+	if err := a.X.Run("tar", "-czf", archive, "-C", staging, "."); err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	// The archive has the secrets of the Orchestrator, while the directory may be readable by others.
+	if err := a.X.Run("chmod", "600", archive); err != nil {
+		return "", fmt.Errorf("failed to restrict access to the archive: %w", err)
+	}
+	// The volumes are archived by root.
+	if err := a.X.Run("sudo", "rm", "-rf", staging); err != nil {
+		a.Log.Warn("failed to remove backup directory", "path", staging, "error", err)
+	}
+	a.Log.Info("backup created", "archive", archive, "files", len(m.Files))
+	return archive, nil
+}
+
+// Restore restores the Orchestrator from an archive, after checking that the archive is intact and compatible with
+// the deployment: the Secrets and ConfigMaps first, so that the restored data is readable with them, then the
+// databases, Vault and the volumes.
+func (a *Archiver) Restore(archive string, opts RestoreOptions) error {
+	staging := filepath.Join(a.Dir, "restore-"+a.now().UTC().Format("20060102-150405"))
+	if err := a.X.MkdirAll(staging, 0o700); err != nil {
+		return fmt.Errorf("failed to create restore directory: %w", err)
+	}
+	defer func() {
+		if err := a.X.Run("sudo", "rm", "-rf", staging); err != nil {
+			a.Log.Warn("failed to remove restore directory", "path", staging, "error", err)
+		}
+	}()
+	if err := a.X.Run("tar", "-xzf", archive, "-C", staging); err != nil {
+		return fmt.Errorf("failed to unpack archive %s: %w", archive, err)
+	}
+
+	m, err := readManifest(staging)
+	if err != nil {
+		return err
+	}
+	if err := verify(staging, m); err != nil {
+		return err
+	}
+	if err := a.checkCompatibility(m, opts); err != nil {
+		return err
+	}
+	a.Log.Info("restoring backup", "archive", archive, "createdAt", m.CreatedAt,
+		"orchestratorVersion", m.OrchestratorVersion)
+
+	if err := a.restoreObjects(staging, m); err != nil {
+		return err
+	}
+	if err := a.restoreDatabases(staging, m); err != nil {
+		return err
+	}
+	if err := a.restoreVault(staging, m); err != nil {
+		return err
+	}
+	if err := a.restoreVolumes(staging, m); err != nil {
+		return err
+	}
+	a.Log.Info("backup restored", "archive", archive)
+	return nil
+}
+
+// ReadManifest returns the manifest of an archive without unpacking the rest of it.
+func (a *Archiver) ReadManifest(archive string) (Manifest, error) {
+	out, err := a.X.Query("tar", "-xzOf", archive, "./"+manifestFile)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read the manifest of %s: %w", archive, err)
+	}
+	return parseManifest([]byte(out))
+}
+
+func readManifest(dir string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return parseManifest(data)
+}
+
+func parseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return Manifest{}, fmt.Errorf("archive format version %d is not supported, expected %d", m.FormatVersion,
+			FormatVersion)
+	}
+	return m, nil
+}
+
+// checksums returns the SHA-256 checksums of the files in dir by their path relative to dir.
+func checksums(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == manifestFile {
+			return nil
+		}
+		sum, err := checksum(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum backup: %w", err)
+	}
+	return files, nil
+}
+
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verify checks that the unpacked archive in dir holds exactly the files of the manifest.
+func verify(dir string, m Manifest) error {
+	files, err := checksums(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, path := range slices.Sorted(maps.Keys(m.Files)) {
+		sum, ok := files[path]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s is missing", path))
+		case sum != m.Files[path]:
+			errs = append(errs, fmt.Errorf("%s is corrupt: checksum %s, expected %s", path, sum, m.Files[path]))
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		if _, ok := m.Files[path]; !ok {
+			errs = append(errs, fmt.Errorf("%s is not in the manifest", path))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("archive is not intact: %w", err)
+	}
+	return nil
+}
+
+// checkCompatibility checks that the archive can be restored to the deployment: the Orchestrator must have the same
+// major and minor version, PostgreSQL can't be older and a Vault snapshot needs Vault on raft storage.
+func (a *Archiver) checkCompatibility(m Manifest, opts RestoreOptions) error {
+	var versionErrs, errs []error
+
+	current := a.orchestratorVersion()
+	if err := sameMinor(m.OrchestratorVersion, current); err != nil {
+		versionErrs = append(versionErrs, fmt.Errorf("orchestrator: %w", err))
+	}
+	if len(m.Databases) > 0 {
+		if err := notOlderMajor(m.PostgresVersion, a.postgresVersion()); err != nil {
+			versionErrs = append(versionErrs, fmt.Errorf("postgresql: %w", err))
+		}
+	}
+	if m.VaultStorage == raftStorage {
+		if status, err := a.vaultStatus(); err != nil {
+			errs = append(errs, fmt.Errorf("vault: %w", err))
+		} else if status.StorageType != raftStorage {
+			errs = append(errs, fmt.Errorf("vault: the archive has a raft snapshot but Vault uses %s storage",
+				status.StorageType))
+		}
+	}
+
+	if opts.Force {
+		for _, err := range versionErrs {
+			a.Log.Warn("restoring an archive of another version", "error", err)
+		}
+	} else {
+		errs = append(errs, versionErrs...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("archive is not compatible with the deployment: %w", err)
+	}
+	return nil
+}
+
+// sameMinor checks that two versions have the same major and minor version.
+func sameMinor(archived, current string) error {
+	a, err := semver.Parse(archived)
+	if err != nil {
+		return fmt.Errorf("unknown version of the archive %q", archived)
+	}
+	c, err := semver.Parse(current)
+	if err != nil {
+		return fmt.Errorf("unknown version of the deployment %q", current)
+	}
+	if a.Major != c.Major || a.Minor != c.Minor {
+		return fmt.Errorf("archive of version %s can't be restored to version %s", archived, current)
+	}
+	return nil
+}
+
+// notOlderMajor checks that the current major version of a PostgreSQL version such as 16.4 is not older than the
+// archived one.
+func notOlderMajor(archived, current string) error {
+	a, aok := majorVersion(archived)
+	c, cok := majorVersion(current)
+	switch {
+	case !aok:
+		return fmt.Errorf("unknown version of the archive %q", archived)
+	case !cok:
+		return fmt.Errorf("unknown version of the deployment %q", current)
+	case c < a:
+		return fmt.Errorf("dumps of version %s can't be restored to the older version %s", archived, current)
+	}
+	return nil
+}
+
+func majorVersion(version string) (int, bool) {
+	var major int
+	if _, err := fmt.Sscanf(version, "%d", &major); err != nil {
+		return 0, false
+	}
+	return major, true
+}
+
+// orchestratorVersion returns the version the root-app was installed with.
+func (a *Archiver) orchestratorVersion() string {
+	apps, err := argocd.ListAll(a.X)
+	if err != nil {
+		a.Log.Warn("failed to list applications", "error", err)
+		return UnknownVersion
+	}
+	for _, app := range apps {
+		if app.Name() != argocd.RootApp {
+			continue
+		}
+		out, err := a.X.Query("helm", "get", "values", argocd.RootApp, "-n", app.Metadata.Namespace, "--all",
+			"-o", "json")
+		if err != nil {
+			a.Log.Warn("failed to read the root-app values", "error", err)
+			return UnknownVersion
+		}
+		var values struct {
+			Argo struct {
+				OrchestratorVersion string `json:"orchestratorVersion"`
+			} `json:"argo"`
+		}
+		if err := json.Unmarshal([]byte(out), &values); err != nil || values.Argo.OrchestratorVersion == "" {
+			return UnknownVersion
+		}
+		return values.Argo.OrchestratorVersion
+	}
+	a.Log.Warn("root-app not found")
+	return UnknownVersion
+}
+
+func (a *Archiver) kubernetesVersion() string {
+	out, err := a.X.Query("kubectl", "version", "-o", "json")
+	if err != nil {
+		a.Log.Warn("failed to read the Kubernetes version", "error", err)
+		return UnknownVersion
+	}
+	var version struct {
+		ServerVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+	if err := json.Unmarshal([]byte(out), &version); err != nil || version.ServerVersion.GitVersion == "" {
+		return UnknownVersion
+	}
+	return version.ServerVersion.GitVersion
+}
+
+func (a *Archiver) now() time.Time {
+	if a.Now == nil {
+		return time.Now()
+	}
+	return a.Now()
+}
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor/executortest"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchbackup"
+)
+
+const (
+	psql = "kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- env PGPASSWORD=s3cret psql " +
+		"-h postgresql-cluster-rw -U postgres -d postgres -At -c "
+
+	vaultStatus = "kubectl exec -n orch-platform vault-0 -c vault -- vault status -format=json"
+
+	objects = `{"items": [
+  {"apiVersion": "v1", "kind": "Secret", "type": "Opaque",
+   "metadata": {"name": "db-password", "namespace": "orch-database", "uid": "1234", "resourceVersion": "42",
+     "creationTimestamp": "2026-01-01T00:00:00Z", "managedFields": [{}], "ownerReferences": [{}],
+     "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}", "keep": "me"}},
+   "data": {"password": "cGFzc3dvcmQ="}},
+  {"apiVersion": "v1", "kind": "Secret", "type": "kubernetes.io/service-account-token",
+   "metadata": {"name": "default-token", "namespace": "orch-database"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "kube-root-ca.crt", "namespace": "orch-database"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings", "namespace": "orch-database"},
+   "data": {"mode": "onprem"}}
+]}`
+
+	persistentVolumes = `{"items": [
+  {"metadata": {"name": "pvc-1"}, "spec": {"storageClassName": "openebs-hostpath",
+    "claimRef": {"name": "data-vault-0", "namespace": "orch-platform"},
+    "local": {"path": "/var/openebs/local/pvc-1"}}},
+  {"metadata": {"name": "pvc-2"}, "spec": {"storageClassName": "openebs-lvmpv",
+    "claimRef": {"name": "data", "namespace": "orch-database"}, "csi": {}}},
+  {"metadata": {"name": "pvc-3"}, "spec": {"storageClassName": "openebs-hostpath",
+    "local": {"path": "/var/openebs/local/pvc-3"}}}
+]}`
+)
+
+// cluster fakes copying from pods and archiving volumes by writing the target files. Archives are created and
+// unpacked with tar.
+type cluster struct {
+	*executortest.Fake
+}
+
+func newCluster() *cluster {
+	c := &cluster{Fake: executortest.New()}
+	c.Responses["kubectl get applications.argoproj.io --all-namespaces -o json"] =
+		`{"items": [{"metadata": {"name": "root-app", "namespace": "onprem"}}]}`
+	c.Responses["helm get values root-app -n onprem --all -o json"] = `{"argo": {"orchestratorVersion": "v2026.1.0"}}`
+	c.Responses["kubectl version -o json"] = `{"serverVersion": {"gitVersion": "v1.32.4+rke2r1"}}`
+	c.Responses["kubectl get service postgresql-cluster-rw -n orch-database -o jsonpath={.spec.selector}"] =
+		`{"cnpg.io/cluster":"postgresql-cluster","cnpg.io/instanceRole":"primary"}`
+	c.Responses["kubectl get pods -n orch-database -l cnpg.io/cluster=postgresql-cluster,cnpg.io/instanceRole=primary"] =
+		"postgresql-cluster-1"
+	c.Responses["kubectl get secret postgresql-cluster-superuser -n orch-database -o jsonpath={.data.username}"] =
+		base64.StdEncoding.EncodeToString([]byte("postgres"))
+	c.Responses["kubectl get secret postgresql-cluster-superuser -n orch-database -o jsonpath={.data.password}"] =
+		base64.StdEncoding.EncodeToString([]byte("s3cret"))
+	c.Responses[psql+"SHOW server_version"] = "16.4 (Debian 16.4-1.pgdg110+1)"
+	c.Responses[psql+"SELECT datname FROM pg_database WHERE NOT datistemplate"] = "app-orch-catalog\ninventory"
+	c.Responses[psql+"SELECT datname FROM pg_database"] = "postgres\ninventory"
+	c.Responses[vaultStatus] = `{"version": "1.17.2", "storage_type": "raft", "sealed": false}`
+	c.Responses["kubectl get secret vault-keys -n orch-platform"] =
+		base64.StdEncoding.EncodeToString([]byte(`{"keys_base64": ["key"], "root_token": "hvs.root"}`))
+	c.Responses["kubectl get namespaces"] = "default kube-system orch-database orch-platform"
+	c.Responses["kubectl get secrets,configmaps -n orch-database"] = objects
+	c.Responses["kubectl get secrets,configmaps -n orch-platform"] = `{"items": []}`
+	c.Responses["kubectl get pv -o json"] = persistentVolumes
+	return c
+}
+
+func (c *cluster) Run(name string, args ...string) error {
+	if err := c.Fake.Run(name, args...); err != nil {
+		return err
+	}
+	switch {
+	case name == "tar":
+		return c.Local.Run(name, args...)
+	case name == "kubectl" && args[0] == "cp" && strings.Contains(args[3], ":"):
+		return os.WriteFile(args[4], []byte("copy of "+args[3]), 0o600)
+	case name == "sudo" && args[0] == "tar" && args[1] == "-czf":
+		return os.WriteFile(args[2], []byte("archive of "+args[4]), 0o600)
+	case name == "sudo" && args[0] == "rm":
+		return os.RemoveAll(args[2])
+	}
+	return nil
+}
+
+func (c *cluster) Query(name string, args ...string) (string, error) {
+	if name == "tar" {
+		return c.Local.Query(name, args...)
+	}
+	return c.Fake.Query(name, args...)
+}
+
+func newArchiver(x executor.Executor) *orchbackup.Archiver {
+	return &orchbackup.Archiver{
+		Dir: GinkgoT().TempDir(),
+		X:   x,
+		Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Now: func() time.Time { return time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC) },
+	}
+}
+
+var _ = Describe("Archiver", func() {
+	var (
+		c        *cluster
+		archiver *orchbackup.Archiver
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		archiver = newArchiver(c)
+	})
+
+	Describe("Create", func() {
+		It("writes a versioned archive with a manifest of its content", func() {
+			archive, err := archiver.Create()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(archive).To(Equal(filepath.Join(archiver.Dir, "orch-backup-2026.1.0-20261018-123000.tar.gz")))
+			Expect(filepath.Join(archiver.Dir, "orch-backup-2026.1.0-20261018-123000")).NotTo(BeADirectory())
+
+			m, err := archiver.ReadManifest(archive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.FormatVersion).To(Equal(orchbackup.FormatVersion))
+			Expect(m.CreatedAt).To(BeTemporally("==", time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)))
+			Expect(m.OrchestratorVersion).To(Equal("v2026.1.0"))
+			Expect(m.KubernetesVersion).To(Equal("v1.32.4+rke2r1"))
+			Expect(m.PostgresVersion).To(Equal("16.4"))
+			Expect(m.VaultVersion).To(Equal("1.17.2"))
+			Expect(m.VaultStorage).To(Equal("raft"))
+			Expect(m.Databases).To(Equal([]string{"app-orch-catalog", "inventory"}))
+			Expect(m.Namespaces).To(Equal([]string{"orch-database", "orch-platform"}))
+			Expect(m.Volumes).To(Equal([]orchbackup.Volume{{
+				Namespace:        "orch-platform",
+				Claim:            "data-vault-0",
+				PersistentVolume: "pvc-1",
+				Path:             "/var/openebs/local/pvc-1",
+				File:             "volumes/orch-platform_data-vault-0.tar.gz",
+			}}))
+			Expect(slices.Sorted(maps.Keys(m.Files))).To(Equal([]string{
+				"kubernetes/orch-database.json",
+				"kubernetes/orch-platform.json",
+				"postgres/app-orch-catalog.dump",
+				"postgres/inventory.dump",
+				"vault/raft.snap",
+				"volumes/orch-platform_data-vault-0.tar.gz",
+			}))
+		})
+
+		It("dumps the databases through the read-write service and removes the dumps from the pod", func() {
+			_, err := archiver.Create()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.Ran("kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- env PGPASSWORD=s3cret " +
+				"pg_dump")).To(Equal([]string{
+				"kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- env PGPASSWORD=s3cret pg_dump " +
+					"-h postgresql-cluster-rw -U postgres -d app-orch-catalog -Fc " +
+					"-f /var/lib/postgresql/data/orch-backup/app-orch-catalog.dump",
+				"kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- env PGPASSWORD=s3cret pg_dump " +
+					"-h postgresql-cluster-rw -U postgres -d inventory -Fc " +
+					"-f /var/lib/postgresql/data/orch-backup/inventory.dump",
+			}))
+			Expect(c.Ran("kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- rm -rf " +
+				"/var/lib/postgresql/data/orch-backup")).To(HaveLen(1))
+		})
+
+		It("saves the secrets and config maps without the metadata of the objects they were read from", func() {
+			archive, err := archiver.Create()
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := c.Local.Query("tar", "-xzOf", archive, "./kubernetes/orch-database.json")
+			Expect(err).NotTo(HaveOccurred())
+			var list struct {
+				Kind  string           `json:"kind"`
+				Items []map[string]any `json:"items"`
+			}
+			Expect(json.Unmarshal([]byte(out), &list)).To(Succeed())
+			Expect(list.Kind).To(Equal("List"))
+			Expect(list.Items).To(HaveLen(2))
+			Expect(list.Items[0]["metadata"]).To(Equal(map[string]any{
+				"name":        "db-password",
+				"namespace":   "orch-database",
+				"annotations": map[string]any{"keep": "me"},
+			}))
+			Expect(list.Items[1]["metadata"]).To(HaveKeyWithValue("name", "settings"))
+		})
+
+		It("only records the Vault storage if it isn't raft", func() {
+			c.Responses[vaultStatus] = `{"version": "1.17.2", "storage_type": "postgresql", "sealed": false}`
+
+			archive, err := archiver.Create()
+			Expect(err).NotTo(HaveOccurred())
+			m, err := archiver.ReadManifest(archive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.VaultStorage).To(Equal("postgresql"))
+			Expect(m.Files).NotTo(HaveKey("vault/raft.snap"))
+			Expect(c.Ran("kubectl exec -n orch-platform vault-0 -c vault -- env")).To(BeEmpty())
+		})
+
+		It("fails if Vault is sealed", func() {
+			c.Responses[vaultStatus] = `{"version": "1.17.2", "storage_type": "raft", "sealed": true}`
+			_, err := archiver.Create()
+			Expect(err).To(MatchError(ContainSubstring("vault is sealed")))
+		})
+	})
+
+	Describe("Restore", func() {
+		var archive string
+
+		BeforeEach(func() {
+			var err error
+			archive, err = archiver.Create()
+			Expect(err).NotTo(HaveOccurred())
+			c.Commands = nil
+		})
+
+		It("restores the objects, databases, Vault and volumes", func() {
+			c.Responses["kubectl get pvc data-vault-0 -n orch-platform"] = "pvc-9"
+			c.Responses["kubectl get pv pvc-9 -o json"] =
+				`{"metadata": {"name": "pvc-9"}, "spec": {"local": {"path": "/var/openebs/local/pvc-9"}}}`
+
+			Expect(archiver.Restore(archive, orchbackup.RestoreOptions{})).To(Succeed())
+
+			pgRestore := "kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- env PGPASSWORD=s3cret " +
+				"pg_restore -h postgresql-cluster-rw -U postgres "
+			Expect(c.Ran(pgRestore)).To(Equal([]string{
+				pgRestore + "--create -d postgres /var/lib/postgresql/data/orch-backup/app-orch-catalog.dump",
+				pgRestore + "--clean --if-exists -d inventory /var/lib/postgresql/data/orch-backup/inventory.dump",
+			}))
+			Expect(c.Ran("kubectl exec -n orch-platform vault-0 -c vault -- env VAULT_TOKEN=hvs.root vault operator " +
+				"raft snapshot restore -force /tmp/orch-backup-raft.snap")).To(HaveLen(1))
+			Expect(c.Ran("sudo tar -xzpf")).To(ConsistOf(
+				HaveSuffix("volumes/orch-platform_data-vault-0.tar.gz -C /var/openebs/local/pvc-9")))
+
+			apply := "kubectl apply --server-side --force-conflicts --field-manager=orch-backup -f -"
+			Expect(c.Inputs[apply]).To(ContainElement(ContainSubstring("db-password")))
+			Expect(indexOf(c.Commands, apply)).To(BeNumerically("<", indexOf(c.Commands, pgRestore)))
+			Expect(indexOf(c.Commands, pgRestore)).To(BeNumerically("<", indexOf(c.Commands, "sudo tar -xzpf")))
+		})
+
+		It("refuses an archive of another Orchestrator version", func() {
+			c.Responses["helm get values root-app -n onprem --all -o json"] = `{"argo": {"orchestratorVersion": "v2026.2.0"}}`
+
+			err := archiver.Restore(archive, orchbackup.RestoreOptions{})
+			Expect(err).To(MatchError(ContainSubstring("v2026.1.0 can't be restored to version v2026.2.0")))
+			Expect(c.Ran("kubectl apply")).To(BeEmpty())
+
+			Expect(archiver.Restore(archive, orchbackup.RestoreOptions{Force: true})).To(Succeed())
+		})
+
+		It("refuses dumps of a newer PostgreSQL", func() {
+			c.Responses[psql+"SHOW server_version"] = "14.19"
+
+			err := archiver.Restore(archive, orchbackup.RestoreOptions{})
+			Expect(err).To(MatchError(ContainSubstring("dumps of version 16.4 can't be restored to the older version 14.19")))
+		})
+
+		It("never restores a Vault snapshot to Vault on another storage", func() {
+			c.Responses[vaultStatus] = `{"version": "1.17.2", "storage_type": "postgresql", "sealed": false}`
+
+			err := archiver.Restore(archive, orchbackup.RestoreOptions{Force: true})
+			Expect(err).To(MatchError(ContainSubstring("raft snapshot but Vault uses postgresql storage")))
+		})
+
+		It("refuses a corrupt archive", func() {
+			dir := GinkgoT().TempDir()
+			Expect(c.Local.Run("tar", "-xzf", archive, "-C", dir)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "postgres", "inventory.dump"), []byte("tampered"), 0o600)).
+				To(Succeed())
+			Expect(c.Local.Run("tar", "-czf", archive, "-C", dir, ".")).To(Succeed())
+
+			err := archiver.Restore(archive, orchbackup.RestoreOptions{Force: true})
+			Expect(err).To(MatchError(ContainSubstring("postgres/inventory.dump is corrupt")))
+			Expect(c.Ran("kubectl apply")).To(BeEmpty())
+		})
+
+		It("refuses archives of another format version", func() {
+			dir := GinkgoT().TempDir()
+			Expect(c.Local.Run("tar", "-xzf", archive, "-C", dir)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"formatVersion": 2}`), 0o600)).
+				To(Succeed())
+			Expect(c.Local.Run("tar", "-czf", archive, "-C", dir, ".")).To(Succeed())
+
+			err := archiver.Restore(archive, orchbackup.RestoreOptions{Force: true})
+			Expect(err).To(MatchError(ContainSubstring("archive format version 2 is not supported")))
+		})
+	})
+})
+
+// indexOf returns the index of the first command starting with prefix.
+func indexOf(commands []string, prefix string) int {
+	return slices.IndexFunc(commands, func(command string) bool { return strings.HasPrefix(command, prefix) })
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// namespacePrefix selects the Orchestrator namespaces whose Secrets and ConfigMaps are backed up.
+	namespacePrefix = "orch-"
+	objectsDir      = "kubernetes"
+)
+
+// saveObjects saves the Secrets and ConfigMaps of every Orchestrator namespace, one file per namespace.
+func (a *Archiver) saveObjects(staging string, m *Manifest) error {
+	out, err := a.X.Query("kubectl", "get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	if err := a.X.MkdirAll(filepath.Join(staging, objectsDir), 0o700); err != nil {
+		return err
+	}
+	for _, namespace := range strings.Fields(out) {
+		if !strings.HasPrefix(namespace, namespacePrefix) {
+			continue
+		}
+		list, err := a.X.Query("kubectl", "get", "secrets,configmaps", "-n", namespace, "-o", "json")
+		if err != nil {
+			return fmt.Errorf("failed to read the secrets and config maps of %s: %w", namespace, err)
+		}
+		objects, err := cleanObjects([]byte(list))
+		if err != nil {
+			return fmt.Errorf("failed to read the secrets and config maps of %s: %w", namespace, err)
+		}
+		if err := a.X.WriteFile(filepath.Join(staging, objectsDir, namespace+".json"), objects, 0o600); err != nil {
+			return err
+		}
+		m.Namespaces = append(m.Namespaces, namespace)
+	}
+	slices.Sort(m.Namespaces)
+	a.Log.Info("secrets and config maps saved", "namespaces", len(m.Namespaces))
+	return nil
+}
+
+// cleanObjects returns the objects of the JSON output of kubectl get as a List without the metadata that only
+// applies to the objects they were read from. The objects Kubernetes manages are left out.
+func cleanObjects(data []byte) ([]byte, error) {
+	var list struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	items := []map[string]any{}
+	for _, item := range list.Items {
+		metadata, _ := item["metadata"].(map[string]any)
+		if metadata == nil {
+			continue
+		}
+		if item["type"] == "kubernetes.io/service-account-token" || metadata["name"] == "kube-root-ca.crt" {
+			continue
+		}
+		for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "managedFields",
+			"ownerReferences", "generation"} {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		}
+		items = append(items, item)
+	}
+	return json.MarshalIndent(map[string]any{"apiVersion": "v1", "kind": "List", "items": items}, "", "  ")
+}
+
+// restoreObjects applies the saved Secrets and ConfigMaps, creating the namespaces that don't exist.
+func (a *Archiver) restoreObjects(staging string, m Manifest) error {
+	for _, namespace := range m.Namespaces {
+		objects, err := os.ReadFile(filepath.Join(staging, objectsDir, namespace+".json"))
+		if err != nil {
+			return fmt.Errorf("failed to read the secrets and config maps of %s: %w", namespace, err)
+		}
+		manifest := fmt.Sprintf(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": %q}}`, namespace)
+		if err := a.X.RunInput(manifest, "kubectl", "apply", "-f", "-"); err != nil {
+			return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+		}
+		// Server-side apply takes over the objects from the controllers that created them and isn't limited by the
+		// size of the last-applied annotation.
+		if err := a.X.RunInput(string(objects), "kubectl", "apply", "--server-side", "--force-conflicts",
+			"--field-manager=orch-backup", "-f", "-"); err != nil {
+			return fmt.Errorf("failed to restore the secrets and config maps of %s: %w", namespace, err)
+		}
+	}
+	a.Log.Info("secrets and config maps restored", "namespaces", len(m.Namespaces))
+	return nil
+}
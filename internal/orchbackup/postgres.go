@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/cnpg"
+)
+
+const (
+	databaseNamespace = "orch-database"
+	// postgresService is the read-write service of the CloudNativePG cluster, which always points to the primary.
+	postgresService = "postgresql-cluster-rw"
+	superuserSecret = "postgresql-cluster-superuser"
+	// remoteDumpDir is where the dumps are written in the primary pod, on the data volume since they can be large.
+	remoteDumpDir = "/var/lib/postgresql/data/orch-backup"
+	databasesDir  = "postgres"
+)
+
+// postgres runs the PostgreSQL clients in the primary pod against the read-write service, as the superuser.
+type postgres struct {
+	pod      string
+	user     string
+	password string
+}
+
+func (a *Archiver) postgres() (postgres, error) {
+	// The pods are found through the service, which a major-version upgrade points to the pods of a new cluster.
+	pod, err := cnpg.ServicePrimary(a.X, databaseNamespace, postgresService)
+	if err != nil {
+		return postgres{}, fmt.Errorf("failed to find the PostgreSQL primary: %w", err)
+	}
+	credentials := map[string]string{}
+	for _, key := range []string{"username", "password"} {
+		encoded, err := a.X.Query("kubectl", "get", "secret", superuserSecret, "-n", databaseNamespace,
+			"-o", "jsonpath={.data."+key+"}")
+		if err != nil {
+			return postgres{}, fmt.Errorf("failed to read the PostgreSQL superuser: %w", err)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return postgres{}, fmt.Errorf("failed to decode the PostgreSQL superuser %s: %w", key, err)
+		}
+		credentials[key] = string(value)
+	}
+	a.X.Redact(credentials["password"])
+	return postgres{pod: pod, user: credentials["username"], password: credentials["password"]}, nil
+}
+
+// exec returns the arguments of kubectl to run a PostgreSQL client in the primary pod.
+func (p postgres) exec(client string, args ...string) []string {
+	return append([]string{"exec", "-n", databaseNamespace, p.pod, "-c", "postgres", "--",
+		"env", "PGPASSWORD=" + p.password, client, "-h", postgresService, "-U", p.user}, args...)
+}
+
+func (a *Archiver) psql(p postgres, query string) (string, error) {
+	out, err := a.X.Query("kubectl", p.exec("psql", "-d", "postgres", "-At", "-c", query)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to query PostgreSQL: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// postgresVersion returns the version of the PostgreSQL server, e.g. 16.4.
+func (a *Archiver) postgresVersion() string {
+	p, err := a.postgres()
+	if err != nil {
+		a.Log.Warn("failed to connect to PostgreSQL", "error", err)
+		return UnknownVersion
+	}
+	version, err := a.psql(p, "SHOW server_version")
+	if err != nil || version == "" {
+		return UnknownVersion
+	}
+	return strings.Fields(version)[0]
+}
+
+// dumpDatabases dumps every database other than the templates and the postgres database in the custom format of
+// pg_dump, which pg_restore restores selectively.
+func (a *Archiver) dumpDatabases(staging string, m *Manifest) error {
+	p, err := a.postgres()
+	if err != nil {
+		return err
+	}
+	version, err := a.psql(p, "SHOW server_version")
+	if err != nil {
+		return err
+	}
+	if fields := strings.Fields(version); len(fields) > 0 {
+		m.PostgresVersion = fields[0]
+	}
+	out, err := a.psql(p, "SELECT datname FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres' "+
+		"ORDER BY datname")
+	if err != nil {
+		return err
+	}
+	if err := a.X.MkdirAll(filepath.Join(staging, databasesDir), 0o700); err != nil {
+		return err
+	}
+	if err := a.X.Run("kubectl", "exec", "-n", databaseNamespace, p.pod, "-c", "postgres", "--",
+		"mkdir", "-p", remoteDumpDir); err != nil {
+		return fmt.Errorf("failed to create the dump directory: %w", err)
+	}
+	defer a.removeRemoteDumps(p)
+
+	for _, database := range strings.Fields(out) {
+		remote := remoteDumpDir + "/" + database + ".dump"
+		a.Log.Info("dumping database", "database", database)
+		if err := a.X.Run("kubectl", p.exec("pg_dump", "-d", database, "-Fc", "-f", remote)...); err != nil {
+			return fmt.Errorf("failed to dump database %s: %w", database, err)
+		}
+		if err := a.X.Run("kubectl", "cp", "-c", "postgres", databaseNamespace+"/"+p.pod+":"+remote,
+			filepath.Join(staging, databasesDir, database+".dump")); err != nil {
+			return fmt.Errorf("failed to copy the dump of database %s: %w", database, err)
+		}
+		m.Databases = append(m.Databases, database)
+	}
+	a.Log.Info("databases dumped", "databases", len(m.Databases), "postgresVersion", m.PostgresVersion)
+	return nil
+}
+
+// restoreDatabases restores the dumps, replacing the objects of existing databases and creating the missing ones.
+func (a *Archiver) restoreDatabases(staging string, m Manifest) error {
+	if len(m.Databases) == 0 {
+		return nil
+	}
+	p, err := a.postgres()
+	if err != nil {
+		return err
+	}
+	existing, err := a.psql(p, "SELECT datname FROM pg_database")
+	if err != nil {
+		return err
+	}
+	if err := a.X.Run("kubectl", "exec", "-n", databaseNamespace, p.pod, "-c", "postgres", "--",
+		"mkdir", "-p", remoteDumpDir); err != nil {
+		return fmt.Errorf("failed to create the dump directory: %w", err)
+	}
+	defer a.removeRemoteDumps(p)
+
+	for _, database := range m.Databases {
+		remote := remoteDumpDir + "/" + database + ".dump"
+		if err := a.X.Run("kubectl", "cp", "-c", "postgres", filepath.Join(staging, databasesDir, database+".dump"),
+			databaseNamespace+"/"+p.pod+":"+remote); err != nil {
+			return fmt.Errorf("failed to copy the dump of database %s: %w", database, err)
+		}
+		args := []string{"--clean", "--if-exists", "-d", database, remote}
+		if !containsLine(existing, database) {
+			// The database is created from the dump, by a connection to the postgres database.
+			args = []string{"--create", "-d", "postgres", remote}
+		}
+		a.Log.Info("restoring database", "database", database)
+		if err := a.X.Run("kubectl", p.exec("pg_restore", args...)...); err != nil {
+			return fmt.Errorf("failed to restore database %s: %w", database, err)
+		}
+	}
+	a.Log.Info("databases restored", "databases", len(m.Databases))
+	return nil
+}
+
+func (a *Archiver) removeRemoteDumps(p postgres) {
+	if err := a.X.Run("kubectl", "exec", "-n", databaseNamespace, p.pod, "-c", "postgres", "--",
+		"rm", "-rf", remoteDumpDir); err != nil {
+		a.Log.Warn("failed to remove the dumps from the PostgreSQL primary", "error", err)
+	}
+}
+
+func containsLine(out, line string) bool {
+	for _, l := range strings.Split(out, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
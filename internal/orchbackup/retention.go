@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	archivePrefix = "orch-backup-"
+	archiveSuffix = ".tar.gz"
+	// archiveTimeLayout is the layout of the creation time at the end of the name of an archive.
+	archiveTimeLayout = "20060102-150405"
+)
+
+// Archive is a backup archive in a backup directory.
+type Archive struct {
+	Path                string    `json:"path"`
+	OrchestratorVersion string    `json:"orchestratorVersion"`
+	CreatedAt           time.Time `json:"createdAt"`
+	Size                int64     `json:"size"`
+}
+
+// Name returns the file name of the archive.
+func (a Archive) Name() string {
+	return filepath.Base(a.Path)
+}
+
+// parseArchiveName returns the version and creation time in the name of an archive, e.g.
+// orch-backup-2026.1.0-20261018-123000.tar.gz.
+func parseArchiveName(name string) (string, time.Time, bool) {
+	if !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveSuffix) {
+		return "", time.Time{}, false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveSuffix)
+	if len(rest) < len(archiveTimeLayout)+2 || rest[len(rest)-len(archiveTimeLayout)-1] != '-' {
+		return "", time.Time{}, false
+	}
+	createdAt, err := time.Parse(archiveTimeLayout, rest[len(rest)-len(archiveTimeLayout):])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return rest[:len(rest)-len(archiveTimeLayout)-1], createdAt, true
+}
+
+// ListArchives returns the archives in dir, the newest first. Files that aren't named like archives are ignored.
+func ListArchives(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	var archives []Archive
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version, createdAt, ok := parseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list archives: %w", err)
+		}
+		archives = append(archives, Archive{
+			Path:                filepath.Join(dir, entry.Name()),
+			OrchestratorVersion: version,
+			CreatedAt:           createdAt,
+			Size:                info.Size(),
+		})
+	}
+	slices.SortFunc(archives, func(a, b Archive) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return archives, nil
+}
+
+// Retention selects the archives to keep: the newest archive of each of the last Daily days and of each of the last
+// Weekly ISO weeks that have archives. Everything is kept if both are zero.
+type Retention struct {
+	Daily  int
+	Weekly int
+}
+
+// Expired returns the archives the retention doesn't keep. The archives must be sorted the newest first, as
+// ListArchives returns them.
+func (r Retention) Expired(archives []Archive) []Archive {
+	if r.Daily <= 0 && r.Weekly <= 0 {
+		return nil
+	}
+	days, weeks := map[string]bool{}, map[string]bool{}
+	var expired []Archive
+	for _, archive := range archives {
+		day := archive.CreatedAt.Format(time.DateOnly)
+		year, week := archive.CreatedAt.ISOWeek()
+		isoWeek := fmt.Sprintf("%d-W%02d", year, week)
+
+		// The first archive of a day or week is the newest of it.
+		keep := false
+		if !days[day] {
+			days[day] = true
+			keep = len(days) <= r.Daily
+		}
+		if !weeks[isoWeek] {
+			weeks[isoWeek] = true
+			keep = keep || len(weeks) <= r.Weekly
+		}
+		if !keep {
+			expired = append(expired, archive)
+		}
+	}
+	return expired
+}
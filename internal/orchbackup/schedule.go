@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const gib = 1 << 30
+
+// Job is a scheduled backup: it checks the free space of the backup directory, creates an archive, removes the
+// archives the retention doesn't keep and records the run in the status record of the directory.
+type Job struct {
+	Archiver  *Archiver
+	Retention Retention
+	// MinFreeBytes is the least space that must be free in the backup directory before a backup. Twice the size of
+	// the latest archive is required if that is more, since the backup is staged next to its archive.
+	MinFreeBytes uint64
+	// FreeBytes returns the space available in a directory, from statfs if nil.
+	FreeBytes func(dir string) (uint64, error)
+}
+
+// Run runs the backup and returns the recorded run. The run is recorded whether or not the backup succeeded.
+func (j *Job) Run() (Run, error) {
+	run := Run{StartedAt: j.Archiver.now().UTC()}
+	err := j.run(&run)
+	run.FinishedAt = j.Archiver.now().UTC()
+	run.Result = ResultSucceeded
+	if err != nil {
+		run.Result, run.Error = ResultFailed, err.Error()
+	}
+	if recordErr := j.record(run); recordErr != nil {
+		err = errors.Join(err, recordErr)
+	}
+	return run, err
+}
+
+func (j *Job) run(run *Run) error {
+	a := j.Archiver
+	j.removeStaging()
+
+	archives, err := ListArchives(a.Dir)
+	if err != nil {
+		return err
+	}
+	required := j.MinFreeBytes
+	if len(archives) > 0 && 2*uint64(archives[0].Size) > required {
+		required = 2 * uint64(archives[0].Size)
+	}
+	free, err := j.freeBytes(a.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the free space of %s: %w", a.Dir, err)
+	}
+	run.FreeBytes = free
+	if free < required {
+		return fmt.Errorf("not enough space in %s: %.1f GiB free, %.1f GiB required", a.Dir,
+			float64(free)/gib, float64(required)/gib)
+	}
+
+	archive, err := a.Create()
+	if err != nil {
+		return err
+	}
+	run.Archive = archive
+	if info, err := os.Stat(archive); err == nil {
+		run.Size = info.Size()
+	}
+
+	// The new archive is listed again so that the retention counts it.
+	archives, err = ListArchives(a.Dir)
+	if err != nil {
+		return err
+	}
+	for _, expired := range j.Retention.Expired(archives) {
+		a.Log.Info("removing expired archive", "archive", expired.Path, "createdAt", expired.CreatedAt)
+		if err := a.X.RemoveAll(expired.Path); err != nil {
+			return fmt.Errorf("failed to remove expired archive %s: %w", expired.Path, err)
+		}
+		run.Pruned = append(run.Pruned, expired.Path)
+	}
+	return nil
+}
+
+// removeStaging removes the staging directories left behind by failed backups, which take the space of a backup.
+func (j *Job) removeStaging() {
+	a := j.Archiver
+	dirs, err := filepath.Glob(filepath.Join(a.Dir, archivePrefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() || strings.HasSuffix(dir, archiveSuffix) {
+			continue
+		}
+		a.Log.Info("removing the staging directory of a failed backup", "path", dir)
+		// The volumes are archived by root.
+		if err := a.X.Run("sudo", "rm", "-rf", dir); err != nil {
+			a.Log.Warn("failed to remove staging directory", "path", dir, "error", err)
+		}
+	}
+}
+
+func (j *Job) freeBytes(dir string) (uint64, error) {
+	if j.FreeBytes != nil {
+		return j.FreeBytes(dir)
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil //nolint: gosec
+}
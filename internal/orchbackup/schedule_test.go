@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchbackup"
+)
+
+// archivesAt returns archives created at the given times, the newest first as ListArchives returns them.
+func archivesAt(times ...string) []orchbackup.Archive {
+	var archives []orchbackup.Archive
+	for _, t := range times {
+		createdAt, err := time.Parse(time.DateTime, t)
+		Expect(err).NotTo(HaveOccurred())
+		archives = append(archives, orchbackup.Archive{
+			Path:      "orch-backup-2026.1.0-" + createdAt.Format("20060102-150405") + ".tar.gz",
+			CreatedAt: createdAt,
+		})
+	}
+	return archives
+}
+
+func names(archives []orchbackup.Archive) []string {
+	var n []string
+	for _, archive := range archives {
+		n = append(n, archive.Name())
+	}
+	return n
+}
+
+var _ = Describe("Retention", func() {
+	// 2026-10-18 is a Sunday, so the 12th to the 18th are one ISO week.
+	archives := archivesAt(
+		"2026-10-18 12:00:00",
+		"2026-10-18 02:00:00",
+		"2026-10-17 02:00:00",
+		"2026-10-16 02:00:00",
+		"2026-10-11 02:00:00",
+		"2026-10-10 02:00:00",
+		"2026-10-04 02:00:00",
+		"2026-09-27 02:00:00",
+	)
+
+	It("keeps the newest archive of the last days and weeks", func() {
+		expired := orchbackup.Retention{Daily: 2, Weekly: 3}.Expired(archives)
+		Expect(names(expired)).To(Equal([]string{
+			"orch-backup-2026.1.0-20261018-020000.tar.gz",
+			"orch-backup-2026.1.0-20261016-020000.tar.gz",
+			"orch-backup-2026.1.0-20261010-020000.tar.gz",
+			"orch-backup-2026.1.0-20260927-020000.tar.gz",
+		}))
+	})
+
+	It("keeps only the newest archive of a day", func() {
+		Expect(names(orchbackup.Retention{Daily: 1}.Expired(archives[:3]))).To(Equal([]string{
+			"orch-backup-2026.1.0-20261018-020000.tar.gz",
+			"orch-backup-2026.1.0-20261017-020000.tar.gz",
+		}))
+		Expect(names(orchbackup.Retention{Weekly: 1}.Expired(archives[:3]))).To(Equal([]string{
+			"orch-backup-2026.1.0-20261018-020000.tar.gz",
+			"orch-backup-2026.1.0-20261017-020000.tar.gz",
+		}))
+	})
+
+	It("keeps everything without rules", func() {
+		Expect(orchbackup.Retention{}.Expired(archives)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ListArchives", func() {
+	It("lists the archives the newest first with the version and time of their name", func() {
+		dir := GinkgoT().TempDir()
+		for _, name := range []string{
+			"orch-backup-2026.1.0-20261017-020000.tar.gz",
+			"orch-backup-2026.1.0-rc1-20261018-020000.tar.gz",
+			"orch-backup-2026.1.0-20261016-020000",
+			"orch-backup-latest.tar.gz",
+			"backup-status.json",
+		} {
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte("archive"), 0o600)).To(Succeed())
+		}
+
+		archives, err := orchbackup.ListArchives(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archives).To(Equal([]orchbackup.Archive{
+			{
+				Path:                filepath.Join(dir, "orch-backup-2026.1.0-rc1-20261018-020000.tar.gz"),
+				OrchestratorVersion: "2026.1.0-rc1",
+				CreatedAt:           time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC),
+				Size:                7,
+			},
+			{
+				Path:                filepath.Join(dir, "orch-backup-2026.1.0-20261017-020000.tar.gz"),
+				OrchestratorVersion: "2026.1.0",
+				CreatedAt:           time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+				Size:                7,
+			},
+		}))
+	})
+})
+
+var _ = Describe("Job", func() {
+	var (
+		c    *cluster
+		job  *orchbackup.Job
+		free uint64
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		free = 100 << 30
+		job = &orchbackup.Job{
+			Archiver:     newArchiver(c),
+			Retention:    orchbackup.Retention{Daily: 1},
+			MinFreeBytes: 10 << 30,
+			FreeBytes:    func(string) (uint64, error) { return free, nil },
+		}
+	})
+
+	It("creates an archive, removes the expired ones and records the run", func() {
+		dir := job.Archiver.Dir
+		old := filepath.Join(dir, "orch-backup-2026.1.0-20261017-020000.tar.gz")
+		Expect(os.WriteFile(old, []byte("archive"), 0o600)).To(Succeed())
+		staging := filepath.Join(dir, "orch-backup-2026.1.0-20261017-010000")
+		Expect(os.Mkdir(staging, 0o700)).To(Succeed())
+
+		run, err := job.Run()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(run.Result).To(Equal(orchbackup.ResultSucceeded))
+		Expect(run.Archive).To(Equal(filepath.Join(dir, "orch-backup-2026.1.0-20261018-123000.tar.gz")))
+		Expect(run.Size).To(BeNumerically(">", 0))
+		Expect(run.FreeBytes).To(Equal(free))
+		Expect(run.Pruned).To(Equal([]string{old}))
+		Expect(old).NotTo(BeAnExistingFile())
+		Expect(staging).NotTo(BeADirectory())
+
+		status, err := orchbackup.ReadStatus(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Runs).To(HaveLen(1))
+		last, ok := status.LastSuccess()
+		Expect(ok).To(BeTrue())
+		Expect(last.Archive).To(Equal(run.Archive))
+	})
+
+	It("fails without enough free space and keeps the archives", func() {
+		dir := job.Archiver.Dir
+		old := filepath.Join(dir, "orch-backup-2026.1.0-20261017-020000.tar.gz")
+		Expect(os.WriteFile(old, make([]byte, 1024), 0o600)).To(Succeed())
+		job.MinFreeBytes = 0
+		free = 1500
+
+		run, err := job.Run()
+		Expect(err).To(MatchError(ContainSubstring("not enough space in " + dir)))
+		Expect(run.Result).To(Equal(orchbackup.ResultFailed))
+		Expect(old).To(BeAnExistingFile())
+		Expect(c.Ran("kubectl exec")).To(BeEmpty())
+
+		status, err := orchbackup.ReadStatus(dir)
+		Expect(err).NotTo(HaveOccurred())
+		last, ok := status.Last()
+		Expect(ok).To(BeTrue())
+		Expect(last.Error).To(ContainSubstring("not enough space"))
+		_, ok = status.LastSuccess()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("records failed backups without removing archives", func() {
+		c.Responses[vaultStatus] = `{"version": "1.17.2", "storage_type": "raft", "sealed": true}`
+		old := filepath.Join(job.Archiver.Dir, "orch-backup-2026.1.0-20261017-020000.tar.gz")
+		Expect(os.WriteFile(old, []byte("archive"), 0o600)).To(Succeed())
+
+		run, err := job.Run()
+		Expect(err).To(MatchError(ContainSubstring("vault is sealed")))
+		Expect(run.Result).To(Equal(orchbackup.ResultFailed))
+		Expect(old).To(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("Timer", func() {
+	It("renders a oneshot service and a persistent timer", func() {
+		units, err := orchbackup.Timer{
+			Schedule:   "*-*-* 02:00:00",
+			Command:    []string{"/usr/bin/onprem-backup", "--dir", "/mnt/orch backups", "--keep-daily", "7"},
+			Env:        map[string]string{"KUBECONFIG": "/home/ubuntu/.kube/config", "PATH": "/usr/bin:/bin"},
+			MountPoint: "/mnt/orch backups",
+		}.Units()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(units).To(HaveKey("orch-backup.service"))
+		Expect(units).To(HaveKey("orch-backup.timer"))
+
+		Expect(units["orch-backup.service"]).To(ContainSubstring("RequiresMountsFor=/mnt/orch backups\n"))
+		Expect(units["orch-backup.service"]).To(ContainSubstring(
+			"Environment=KUBECONFIG=/home/ubuntu/.kube/config\nEnvironment=PATH=/usr/bin:/bin\n"))
+		Expect(units["orch-backup.service"]).To(ContainSubstring(
+			`ExecStart=/usr/bin/onprem-backup --dir "/mnt/orch backups" --keep-daily 7` + "\n"))
+		Expect(units["orch-backup.timer"]).To(ContainSubstring("OnCalendar=*-*-* 02:00:00\nPersistent=true\n"))
+	})
+
+	It("needs a schedule", func() {
+		_, err := orchbackup.Timer{Command: []string{"onprem-backup"}}.Units()
+		Expect(err).To(HaveOccurred())
+	})
+})
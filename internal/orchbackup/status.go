@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// StatusFile is the status record of the scheduled backups in the backup directory.
+	StatusFile = "backup-status.json"
+	// maxRuns is the number of runs kept in the status record.
+	maxRuns = 50
+
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// Run is a scheduled backup in the status record.
+type Run struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	// FreeBytes is the space that was available in the backup directory before the backup.
+	FreeBytes uint64 `json:"freeBytes"`
+	Archive   string `json:"archive,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	// Pruned are the archives removed by the retention after the backup.
+	Pruned []string `json:"pruned,omitempty"`
+}
+
+// Status is the status record of the scheduled backups, the latest run last.
+type Status struct {
+	Runs []Run `json:"runs"`
+}
+
+// Last returns the latest run, if there is one.
+func (s Status) Last() (Run, bool) {
+	if len(s.Runs) == 0 {
+		return Run{}, false
+	}
+	return s.Runs[len(s.Runs)-1], true
+}
+
+// LastSuccess returns the latest run that succeeded, if there is one.
+func (s Status) LastSuccess() (Run, bool) {
+	for i := len(s.Runs) - 1; i >= 0; i-- {
+		if s.Runs[i].Result == ResultSucceeded {
+			return s.Runs[i], true
+		}
+	}
+	return Run{}, false
+}
+
+// ReadStatus returns the status record in dir, which is empty if no backup was scheduled yet.
+func ReadStatus(dir string) (Status, error) {
+	data, err := os.ReadFile(filepath.Join(dir, StatusFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to read backup status: %w", err)
+	}
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return Status{}, fmt.Errorf("failed to parse backup status %s: %w", filepath.Join(dir, StatusFile), err)
+	}
+	return status, nil
+}
+
+// record adds a run to the status record in the backup directory.
+func (j *Job) record(run Run) error {
+	status, err := ReadStatus(j.Archiver.Dir)
+	if err != nil {
+		// A corrupt record is replaced rather than failing every later backup.
+		j.Archiver.Log.Warn("replacing backup status", "error", err)
+		status = Status{}
+	}
+	status.Runs = append(status.Runs, run)
+	if len(status.Runs) > maxRuns {
+		status.Runs = status.Runs[len(status.Runs)-maxRuns:]
+	}
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The record has no secrets, it is readable by the users that list the backups.
+	if err := j.Archiver.X.WriteFile(filepath.Join(j.Archiver.Dir, StatusFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write backup status: %w", err)
+	}
+	return nil
+}
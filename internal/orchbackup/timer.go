@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/systemd"
+)
+
+// TimerUnit is the name of the systemd timer of the scheduled backups, which starts the service of the same name.
+const TimerUnit = "orch-backup"
+
+// Timer describes the systemd units that run the scheduled backups.
+type Timer struct {
+	// Schedule is the systemd calendar event of the backups, e.g. daily or *-*-* 02:00:00.
+	Schedule string
+	// Command is the command of the service and its arguments.
+	Command []string
+	// Env is the environment of the command.
+	Env map[string]string
+	// MountPoint is the backup directory if it is a mount the service must wait for.
+	MountPoint string
+}
+
+var serviceTemplate = template.Must(template.New("service").Parse(`[Unit]
+Description=Edge Orchestrator backup
+Wants=network-online.target
+After=network-online.target
+{{- if .MountPoint }}
+RequiresMountsFor={{ .MountPoint }}
+{{- end }}
+
+[Service]
+Type=oneshot
+{{- range .Env }}
+Environment={{ . }}
+{{- end }}
+ExecStart={{ .Command }}
+`))
+
+var timerTemplate = template.Must(template.New("timer").Parse(`[Unit]
+Description=Edge Orchestrator backup schedule
+
+[Timer]
+OnCalendar={{ .Schedule }}
+Persistent=true
+RandomizedDelaySec=10m
+
+[Install]
+WantedBy=timers.target
+`))
+
+// Units returns the content of the service and timer units by their file name.
+func (t Timer) Units() (map[string]string, error) {
+	if t.Schedule == "" || len(t.Command) == 0 {
+		return nil, errors.New("a backup timer needs a schedule and a command")
+	}
+	data := map[string]any{
+		"Schedule":   t.Schedule,
+		"Command":    systemd.Command(t.Command),
+		"Env":        systemd.Environment(t.Env),
+		"MountPoint": t.MountPoint,
+	}
+
+	units := map[string]string{}
+	for name, tmpl := range map[string]*template.Template{
+		TimerUnit + ".service": serviceTemplate,
+		TimerUnit + ".timer":   timerTemplate,
+	} {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		units[name] = b.String()
+	}
+	return units, nil
+}
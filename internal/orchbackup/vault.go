@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	vaultNamespace = "orch-platform"
+	vaultPod       = "vault-0"
+	raftStorage    = "raft"
+	vaultSnapshot  = "vault/raft.snap"
+	remoteSnapshot = "/tmp/orch-backup-raft.snap"
+)
+
+// vaultStatus is the part of vault status the backup reads.
+type vaultStatus struct {
+	Version     string `json:"version"`
+	StorageType string `json:"storage_type"`
+	Sealed      bool   `json:"sealed"`
+}
+
+func (a *Archiver) vaultStatus() (vaultStatus, error) {
+	out, err := a.X.Query("kubectl", "exec", "-n", vaultNamespace, vaultPod, "-c", "vault", "--",
+		"vault", "status", "-format=json")
+	if err != nil {
+		return vaultStatus{}, fmt.Errorf("failed to read the Vault status, is it unsealed? %w", err)
+	}
+	var status vaultStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return vaultStatus{}, fmt.Errorf("failed to parse the Vault status: %w", err)
+	}
+	if status.Sealed {
+		return vaultStatus{}, errors.New("vault is sealed")
+	}
+	return status, nil
+}
+
+// rootToken returns the root token in the vault-keys secret written when Vault was initialized.
+func (a *Archiver) rootToken() (string, error) {
+	encoded, err := a.X.Query("kubectl", "get", "secret", "vault-keys", "-n", vaultNamespace,
+		"-o", "jsonpath={.data.vault-keys}")
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret vault-keys: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret vault-keys: %w", err)
+	}
+	var keys struct {
+		RootToken string `json:"root_token"`
+	}
+	if err := json.Unmarshal(data, &keys); err != nil || keys.RootToken == "" {
+		return "", errors.New("secret vault-keys has no root token")
+	}
+	a.X.Redact(keys.RootToken)
+	return keys.RootToken, nil
+}
+
+// snapshotVault takes a raft snapshot of Vault. Vault on another storage backend keeps its data in the database, so
+// only its version and storage are recorded.
+func (a *Archiver) snapshotVault(staging string, m *Manifest) error {
+	status, err := a.vaultStatus()
+	if err != nil {
+		return err
+	}
+	m.VaultVersion, m.VaultStorage = status.Version, status.StorageType
+	if status.StorageType != raftStorage {
+		a.Log.Info("vault data is backed up with the databases", "storage", status.StorageType)
+		return nil
+	}
+
+	token, err := a.rootToken()
+	if err != nil {
+		return err
+	}
+	if err := a.X.MkdirAll(filepath.Join(staging, filepath.Dir(vaultSnapshot)), 0o700); err != nil {
+		return err
+	}
+	if err := a.X.Run("kubectl", "exec", "-n", vaultNamespace, vaultPod, "-c", "vault", "--",
+		"env", "VAULT_TOKEN="+token, "vault", "operator", "raft", "snapshot", "save", remoteSnapshot); err != nil {
+		return fmt.Errorf("failed to take a Vault snapshot: %w", err)
+	}
+	defer a.removeRemoteSnapshot()
+	if err := a.X.Run("kubectl", "cp", "-c", "vault", vaultNamespace+"/"+vaultPod+":"+remoteSnapshot,
+		filepath.Join(staging, vaultSnapshot)); err != nil {
+		return fmt.Errorf("failed to copy the Vault snapshot: %w", err)
+	}
+	a.Log.Info("vault snapshot taken", "vaultVersion", status.Version)
+	return nil
+}
+
+// restoreVault restores the raft snapshot of Vault, if the archive has one.
+func (a *Archiver) restoreVault(staging string, m Manifest) error {
+	if _, ok := m.Files[vaultSnapshot]; !ok {
+		return nil
+	}
+	token, err := a.rootToken()
+	if err != nil {
+		return err
+	}
+	if err := a.X.Run("kubectl", "cp", "-c", "vault", filepath.Join(staging, vaultSnapshot),
+		vaultNamespace+"/"+vaultPod+":"+remoteSnapshot); err != nil {
+		return fmt.Errorf("failed to copy the Vault snapshot: %w", err)
+	}
+	defer a.removeRemoteSnapshot()
+	// The snapshot is of another Vault cluster if Vault was installed again, which only -force restores.
+	if err := a.X.Run("kubectl", "exec", "-n", vaultNamespace, vaultPod, "-c", "vault", "--",
+		"env", "VAULT_TOKEN="+token, "vault", "operator", "raft", "snapshot", "restore", "-force",
+		remoteSnapshot); err != nil {
+		return fmt.Errorf("failed to restore the Vault snapshot: %w", err)
+	}
+	a.Log.Info("vault snapshot restored")
+	return nil
+}
+
+func (a *Archiver) removeRemoteSnapshot() {
+	if err := a.X.Run("kubectl", "exec", "-n", vaultNamespace, vaultPod, "-c", "vault", "--",
+		"rm", "-f", remoteSnapshot); err != nil {
+		a.Log.Warn("failed to remove the snapshot from the Vault pod", "error", err)
+	}
+}
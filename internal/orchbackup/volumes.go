@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchbackup
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	hostpathStorageClass = "openebs-hostpath"
+	volumesDir           = "volumes"
+)
+
+// Volume is an OpenEBS hostpath volume in an archive.
+type Volume struct {
+	Namespace        string `json:"namespace"`
+	Claim            string `json:"claim"`
+	PersistentVolume string `json:"persistentVolume"`
+	// Path is the directory of the volume on the node.
+	Path string `json:"path"`
+	// File is the path of the archive of the volume in the backup archive.
+	File string `json:"file"`
+}
+
+// persistentVolume is the part of a persistent volume the backup reads.
+type persistentVolume struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		StorageClassName string `json:"storageClassName"`
+		ClaimRef         *struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"claimRef"`
+		Local *struct {
+			Path string `json:"path"`
+		} `json:"local"`
+		HostPath *struct {
+			Path string `json:"path"`
+		} `json:"hostPath"`
+	} `json:"spec"`
+}
+
+func (pv persistentVolume) path() string {
+	switch {
+	case pv.Spec.Local != nil:
+		return pv.Spec.Local.Path
+	case pv.Spec.HostPath != nil:
+		return pv.Spec.HostPath.Path
+	}
+	return ""
+}
+
+// hostpathVolumes returns the claimed OpenEBS hostpath volumes in the JSON output of kubectl get pv.
+func hostpathVolumes(data []byte) ([]Volume, error) {
+	var list struct {
+		Items []persistentVolume `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse persistent volumes: %w", err)
+	}
+	var volumes []Volume
+	for _, pv := range list.Items {
+		if pv.Spec.StorageClassName != hostpathStorageClass || pv.Spec.ClaimRef == nil || pv.path() == "" {
+			continue
+		}
+		claim := pv.Spec.ClaimRef
+		volumes = append(volumes, Volume{
+			Namespace:        claim.Namespace,
+			Claim:            claim.Name,
+			PersistentVolume: pv.Metadata.Name,
+			Path:             pv.path(),
+			File:             volumesDir + "/" + claim.Namespace + "_" + claim.Name + ".tar.gz",
+		})
+	}
+	return volumes, nil
+}
+
+// saveVolumes archives the directories of the OpenEBS hostpath volumes, which are on this node.
+func (a *Archiver) saveVolumes(staging string, m *Manifest) error {
+	out, err := a.X.Query("kubectl", "get", "pv", "-o", "json")
+	if err != nil {
+		return fmt.Errorf("failed to list persistent volumes: %w", err)
+	}
+	volumes, err := hostpathVolumes([]byte(out))
+	if err != nil {
+		return err
+	}
+	if err := a.X.MkdirAll(filepath.Join(staging, volumesDir), 0o700); err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		a.Log.Info("archiving volume", "claim", volume.Namespace+"/"+volume.Claim, "path", volume.Path)
+		// The volumes are owned by the users of the pods, only root reads all of them.
+		if err := a.X.Run("sudo", "tar", "-czf", filepath.Join(staging, volume.File), "-C", volume.Path,
+			"."); err != nil {
+			return fmt.Errorf("failed to archive volume %s/%s: %w", volume.Namespace, volume.Claim, err)
+		}
+		m.Volumes = append(m.Volumes, volume)
+	}
+	a.Log.Info("volumes archived", "volumes", len(m.Volumes))
+	return nil
+}
+
+// restoreVolumes unpacks the archived volumes into the volumes currently bound to their claims, or into their
+// original directories if the claims don't exist.
+func (a *Archiver) restoreVolumes(staging string, m Manifest) error {
+	for _, volume := range m.Volumes {
+		path := volume.Path
+		pv, err := a.X.Query("kubectl", "get", "pvc", volume.Claim, "-n", volume.Namespace, "--ignore-not-found",
+			"-o", "jsonpath={.spec.volumeName}")
+		if err == nil && strings.TrimSpace(pv) != "" {
+			out, err := a.X.Query("kubectl", "get", "pv", strings.TrimSpace(pv), "-o", "json")
+			if err != nil {
+				return fmt.Errorf("failed to read persistent volume %s: %w", pv, err)
+			}
+			var current persistentVolume
+			if err := json.Unmarshal([]byte(out), &current); err != nil {
+				return fmt.Errorf("failed to parse persistent volume %s: %w", pv, err)
+			}
+			if current.path() != "" {
+				path = current.path()
+			}
+		}
+
+		a.Log.Info("restoring volume", "claim", volume.Namespace+"/"+volume.Claim, "path", path)
+		if err := a.X.Run("sudo", "mkdir", "-p", path); err != nil {
+			return err
+		}
+		if err := a.X.Run("sudo", "tar", "-xzpf", filepath.Join(staging, volume.File), "-C", path); err != nil {
+			return fmt.Errorf("failed to restore volume %s/%s: %w", volume.Namespace, volume.Claim, err)
+		}
+	}
+	a.Log.Info("volumes restored", "volumes", len(m.Volumes))
+	return nil
+}
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	installersRSPath = "edge-orch/common/files"
+	archivesRSPath   = "edge-orch/common/files/orchestrator"
+)
+
+// fetchArtifacts pulls the installer packages and the repo archive of Version from the release service.
+func (u *Upgrader) fetchArtifacts(gitea bool) error {
+	pulls := map[string][]string{
+		u.PackagesDir: nil,
+		u.ReposDir:    {archivesRSPath + "/onpremfull:" + u.Version},
+	}
+	for _, pkg := range installerPackages(gitea) {
+		pulls[u.PackagesDir] = append(pulls[u.PackagesDir], installersRSPath+"/"+pkg+":"+u.Version)
+	}
+
+	for _, dir := range []string{u.PackagesDir, u.ReposDir} {
+		if err := u.X.Run("sudo", "rm", "-rf", dir); err != nil {
+			return err
+		}
+		if err := u.X.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		for _, artifact := range pulls[dir] {
+			ref := u.ReleaseServiceURL + "/" + artifact
+			u.Log.Info("pulling artifact", "ref", ref, "dir", dir)
+			if err := u.X.Run("sudo", "oras", "pull", "-o", dir, ref); err != nil {
+				return fmt.Errorf("failed to pull %s: %w", ref, err)
+			}
+		}
+	}
+	return nil
+}
+
+// clusterValues reads the settings of the installation that the cluster values are generated from.
+func (u *Upgrader) clusterValues(profile clusterProfile) (map[string]string, error) {
+	values := map[string]string{
+		"DISABLE_CO_PROFILE":     strconv.FormatBool(profile.DisableClusterOrch),
+		"DISABLE_AO_PROFILE":     strconv.FormatBool(profile.DisableAppOrch),
+		"DISABLE_O11Y_PROFILE":   strconv.FormatBool(profile.DisableObservability),
+		"SINGLE_TENANCY_PROFILE": strconv.FormatBool(profile.SingleTenancy),
+		"INSTALL_GITEA":          strconv.FormatBool(profile.installGitea()),
+		"SRE_TLS_ENABLED":        "false",
+	}
+
+	loadBalancerIP := func(namespace, service string) (string, error) {
+		return u.X.Query("kubectl", "get", "svc", service, "-n", namespace,
+			"-o", "jsonpath={.status.loadBalancer.ingress[0].ip}")
+	}
+	var err error
+	if values["ARGO_IP"], err = loadBalancerIP(argoCDNamespace, "argocd-server"); err != nil {
+		return nil, fmt.Errorf("failed to read the Argo CD IP: %w", err)
+	}
+	if values["TRAEFIK_IP"], err = loadBalancerIP("orch-gateway", "traefik"); err != nil {
+		return nil, fmt.Errorf("failed to read the Traefik IP: %w", err)
+	}
+	for _, ingress := range []string{"ingress-haproxy-kubernetes-ingress", "ingress-nginx-controller"} {
+		exists, err := u.exists("svc", ingress, "-n", "orch-boots")
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			if values["HAPROXY_IP"], err = loadBalancerIP("orch-boots", ingress); err != nil {
+				return nil, fmt.Errorf("failed to read the boots ingress IP: %w", err)
+			}
+			break
+		}
+	}
+	if values["HAPROXY_IP"] == "" {
+		return nil, errors.New("no ingress service found in orch-boots namespace")
+	}
+
+	helmValue := func(app, path string) string {
+		value, err := u.X.Query("kubectl", "get", "applications.argoproj.io", app, "-n", appsNamespace,
+			"-o", "jsonpath={.spec.sources[*].helm.valuesObject."+path+"}")
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(value)
+	}
+	if helmValue("sre-exporter", "otelCollector.tls.enabled") == "true" {
+		values["SRE_TLS_ENABLED"] = "true"
+		if helmValue("sre-exporter", "otelCollector.tls.caSecret.enabled") == "true" {
+			values["SRE_DEST_CA_CERT"] = "true"
+		}
+	}
+	values["SMTP_SKIP_VERIFY"] = cmp.Or(helmValue("alerting-monitor", "alertingMonitor.smtp.insecureSkipVerify"),
+		"false")
+	return values, nil
+}
+
+// updateClusterValues writes the settings of the installation to onprem.env, generates the cluster values of Profile
+// from it and packages them into the repo archive the root-app is upgraded from.
+func (u *Upgrader) updateClusterValues(profile clusterProfile) error {
+	values, err := u.clusterValues(profile)
+	if err != nil {
+		return err
+	}
+	envFile := filepath.Join(u.Dir, "onprem.env")
+	if err := u.X.Apply("update "+envFile, func() error {
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			return err
+		}
+		env := string(data)
+		for name, value := range values {
+			env = setEnvVar(env, name, value)
+		}
+		return os.WriteFile(envFile, []byte(env), 0o644)
+	}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", envFile, err)
+	}
+	u.Log.Info("installation settings saved", "path", envFile)
+
+	archive, err := repoArchive(u.ReposDir)
+	if err != nil && !u.LocalPackages {
+		// The archive is pulled when the upgrade runs, so it doesn't exist yet when planning.
+		archive = filepath.Join(u.ReposDir, "onpremFull_"+emfRepo+".tgz")
+	} else if err != nil {
+		return err
+	}
+
+	valuesFile := filepath.Join(u.Dir, u.Profile+".yaml")
+	if err := u.X.RemoveAll(valuesFile); err != nil {
+		return err
+	}
+	if err := u.X.Run("bash", "-c", "cd "+shellQuote(u.Dir)+" && ./generate_cluster_yaml.sh onprem"); err != nil {
+		return fmt.Errorf("failed to generate the cluster values: %w", err)
+	}
+
+	tmpDir := filepath.Join(u.ReposDir, "tmp")
+	if err := u.X.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := u.X.MkdirAll(tmpDir, 0o755); err != nil {
+		return err
+	}
+	if err := u.X.Run("tar", "-xf", archive, "-C", tmpDir); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", archive, err)
+	}
+	clusterValues := filepath.Join(tmpDir, emfRepo, "orch-configs", "clusters", u.Profile+".yaml")
+	if err := u.X.Run("cp", valuesFile, clusterValues); err != nil {
+		return err
+	}
+	if u.Confirm != nil && !u.Confirm(fmt.Sprintf("Edit %s if required. Ready to proceed?", clusterValues)) {
+		return errors.New("upgrade aborted by the user")
+	}
+	if err := u.X.Run("tar", "-zcf", archive, "-C", tmpDir, emfRepo); err != nil {
+		return fmt.Errorf("failed to package %s: %w", archive, err)
+	}
+	u.Log.Info("cluster values packaged", "profile", u.Profile, "archive", archive)
+	return u.X.RemoveAll(tmpDir)
+}
+
+// setEnvVar sets the exported variable name in the env file content to value, appending it if it is not set yet.
+// Empty values leave the variable untouched.
+func setEnvVar(content, name, value string) string {
+	if value == "" {
+		return content
+	}
+	line := fmt.Sprintf("export %s='%s'", name, value)
+	re := regexp.MustCompile(`(?m)^export ` + regexp.QuoteMeta(name) + `=.*$`)
+	if re.MatchString(content) {
+		return re.ReplaceAllLiteralString(content, line)
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
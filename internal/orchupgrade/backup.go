@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	volumeGroup         = "lvmvg"
+	volumeSnapshotClass = "openebs-lvm-vsc"
+	etcdSnapshotDir     = "/var/orch-backups"
+	rke2SnapshotDir     = "/var/lib/rancher/rke2/server/db/snapshots"
+)
+
+// Backup saves what the upgrade replaces: the secret of the PostgreSQL superuser, the passwords of the database
+// users, the MPS and RPS secrets and a dump of all databases. With BackupVolumes, the persistent volumes are copied to
+// LVM volumes, which the removal of the Orchestrator keeps, and an etcd snapshot is taken. What an earlier attempt
+// saved is kept, so that a failed upgrade restores the state from before it started.
+func (u *Upgrader) Backup() error {
+	if err := u.X.MkdirAll(u.BackupDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	if err := u.saveSecret(databaseNamespace, "postgresql-cluster-superuser", superuserSecret); err != nil {
+		return err
+	}
+	if err := u.savePasswords(); err != nil {
+		return err
+	}
+	for _, name := range []string{"mps", "rps"} {
+		if err := u.saveSecret(infraNamespace, name, name+"_secret.yaml"); err != nil {
+			return err
+		}
+	}
+	if err := u.dumpDatabases(); err != nil {
+		return err
+	}
+
+	if !u.BackupVolumes {
+		return nil
+	}
+	if err := u.backupVolumes(); err != nil {
+		return err
+	}
+	return u.snapshotEtcd()
+}
+
+// boundClaim is a bound persistent volume claim.
+type boundClaim struct {
+	Name      string
+	Namespace string
+	Volume    string
+}
+
+// backupVolumes copies the data of every bound volume claim to a new logical volume in the same volume group.
+func (u *Upgrader) backupVolumes() error {
+	space, err := u.X.Query("sudo", "vgs", "--noheadings", "--units", "g", "--nosuffix", "-o", "vg_size,vg_free",
+		volumeGroup)
+	if err != nil {
+		return fmt.Errorf("failed to read the size of volume group %s: %w", volumeGroup, err)
+	}
+	if err := checkBackupSpace(space); err != nil {
+		return err
+	}
+
+	out, err := u.X.Query("kubectl", "get", "pvc", "--all-namespaces", "-o",
+		`jsonpath={range .items[?(@.status.phase=="Bound")]}{.metadata.name}{" "}{.metadata.namespace}{" "}{.spec.volumeName}{"\n"}{end}`)
+	if err != nil {
+		return fmt.Errorf("failed to list volume claims: %w", err)
+	}
+
+	date := time.Now().Format("2006-01-02-15_04")
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		claim := boundClaim{Name: fields[0], Namespace: fields[1], Volume: fields[2]}
+		// DKAM populates its volume again on start.
+		if claim.Name == "dkam-pvc" {
+			continue
+		}
+		if err := u.backupVolume(claim, date); err != nil {
+			return fmt.Errorf("failed to back up volume claim %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkBackupSpace checks the output of vgs for room to copy the used space, with a margin of 5%.
+func checkBackupSpace(vgs string) error {
+	fields := strings.Fields(vgs)
+	if len(fields) != 2 {
+		return fmt.Errorf("unexpected volume group size %q", vgs)
+	}
+	size, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid volume group size %q: %w", fields[0], err)
+	}
+	free, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid volume group free space %q: %w", fields[1], err)
+	}
+	used := size - free
+	if free <= used*1.05 {
+		return fmt.Errorf("not enough space in volume group %s to back up the volumes: %.1fG used, %.1fG free",
+			volumeGroup, used, free)
+	}
+	return nil
+}
+
+// backupVolume copies a volume through a snapshot, for a consistent copy of a volume that is in use.
+func (u *Upgrader) backupVolume(claim boundClaim, date string) error {
+	snapshot := claim.Name + "-snap"
+	manifest := fmt.Sprintf(`apiVersion: snapshot.storage.k8s.io/v1
+kind: VolumeSnapshot
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  volumeSnapshotClassName: %s
+  source:
+    persistentVolumeClaimName: %s
+`, snapshot, claim.Namespace, volumeSnapshotClass, claim.Name)
+	if err := u.X.RunInput(manifest, "kubectl", "apply", "-f", "-"); err != nil {
+		return err
+	}
+	if err := u.waitFor(fmt.Sprintf("volume snapshot %s/%s is ready", claim.Namespace, snapshot), 200*time.Second,
+		func() (bool, error) {
+			ready, err := u.X.Query("kubectl", "get", "volumesnapshot", snapshot, "-n", claim.Namespace,
+				"-o", "jsonpath={.status.readyToUse}")
+			return ready == "true", err
+		}); err != nil {
+		return err
+	}
+
+	device := "/dev/" + volumeGroup + "/" + claim.Volume
+	size, err := u.X.Query("sudo", "lvs", "--noheadings", "--units", "m", "--nosuffix", "-o", "lv_size", device)
+	if err != nil {
+		return fmt.Errorf("failed to read the size of %s: %w", device, err)
+	}
+	lvs, err := u.X.Query("sudo", "lvs", "--noheadings", "-o", "lv_name,origin", volumeGroup)
+	if err != nil {
+		return err
+	}
+	snapshotVolume := snapshotOf(lvs, claim.Volume)
+	if snapshotVolume == "" {
+		return fmt.Errorf("no snapshot of logical volume %s found", claim.Volume)
+	}
+
+	backup := fmt.Sprintf("%s-%s-bak-%s", claim.Name, claim.Namespace, date)
+	u.Log.Info("backing up volume", "claim", claim.Namespace+"/"+claim.Name, "volume", claim.Volume,
+		"backup", backup, "sizeMiB", strings.TrimSpace(size))
+	for _, command := range [][]string{
+		{"lvcreate", "-n", backup, "-L", strings.TrimSpace(size) + "m", volumeGroup, "-y"},
+		{"mkfs.ext4", "-q", "/dev/" + volumeGroup + "/" + backup},
+		{"mkdir", "-p", "/mnt/original-lv", "/mnt/backup-lv"},
+		{"mount", "/dev/" + volumeGroup + "/" + snapshotVolume, "/mnt/original-lv"},
+		{"mount", "/dev/" + volumeGroup + "/" + backup, "/mnt/backup-lv"},
+		{"cp", "-a", "/mnt/original-lv/.", "/mnt/backup-lv/"},
+		{"umount", "/mnt/original-lv"},
+		{"umount", "/mnt/backup-lv"},
+		{"rm", "-rf", "/mnt/original-lv", "/mnt/backup-lv"},
+	} {
+		if err := u.X.Run("sudo", command...); err != nil {
+			return err
+		}
+	}
+	return u.kubectl("delete", "volumesnapshot", snapshot, "-n", claim.Namespace)
+}
+
+// snapshotOf returns the snapshot of the logical volume origin from the output of lvs -o lv_name,origin.
+func snapshotOf(lvs, origin string) string {
+	for _, line := range strings.Split(lvs, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == origin && fields[0] != origin {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// snapshotEtcd takes an etcd snapshot named after the installed RKE2 installer and moves it to etcdSnapshotDir.
+func (u *Upgrader) snapshotEtcd() error {
+	version, err := u.X.Query("dpkg-query", "-W", "-f=${Version}", "onprem-ke-installer")
+	if err != nil {
+		return fmt.Errorf("failed to read the onprem-ke-installer version: %w", err)
+	}
+	name := "pre-upgrade-snapshot-" + version
+	if err := u.X.Run("sudo", "rke2", "etcd-snapshot", "save", "--name", name); err != nil {
+		return fmt.Errorf("failed to take etcd snapshot: %w", err)
+	}
+	if err := u.X.Run("sudo", "mkdir", "-p", etcdSnapshotDir); err != nil {
+		return err
+	}
+	if err := u.X.Run("sudo", "find", rke2SnapshotDir, "-name", name+"*", "-exec", "mv", "{}", etcdSnapshotDir,
+		";"); err != nil {
+		return fmt.Errorf("failed to move etcd snapshot: %w", err)
+	}
+	u.Log.Info("etcd snapshot saved", "name", name, "dir", etcdSnapshotDir)
+	return nil
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchupgrade"
+)
+
+const dump = `--
+-- Roles
+--
+
+CREATE ROLE "orch-infra-mps_user";
+ALTER ROLE "orch-infra-mps_user" WITH PASSWORD 'secret';
+
+--
+-- User Configurations
+--
+
+CREATE DATABASE "orch-infra-mps";
+`
+
+var _ = Describe("Backup", func() {
+	var (
+		c *cluster
+		u *orchupgrade.Upgrader
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		u = newUpgrader(c, io.Discard)
+		c.Responses["kubectl get secret"] = "secret/found"
+		c.Responses["kubectl get secret postgresql-cluster-superuser -n orch-database -o yaml"] = "kind: Secret"
+		c.Responses["kubectl get secret mps -n orch-infra -o yaml"] = "kind: Secret\nmetadata:\n  name: mps"
+		c.Responses["kubectl get secret rps"] = ""
+		c.Responses["kubectl get secret alerting-local-postgresql -n orch-infra -o jsonpath"] = "YWxlcnRpbmc="
+		c.Responses["kubectl get secret orch-database-postgresql -n orch-database -o jsonpath"] = "cG9zdGdyZXM="
+		c.onRun = func(command string) {
+			if strings.HasPrefix(command, "kubectl cp") {
+				fields := strings.Fields(command)
+				Expect(os.WriteFile(fields[len(fields)-1], []byte(dump), 0o600)).To(Succeed())
+			}
+		}
+	})
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(u.BackupDir, name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	It("saves the secrets, passwords and a dump without roles", func() {
+		Expect(u.Backup()).To(Succeed())
+
+		Expect(read("postgres_secret.yaml")).To(Equal("kind: Secret\n"))
+		Expect(read("mps_secret.yaml")).To(ContainSubstring("name: mps"))
+		Expect(filepath.Join(u.BackupDir, "rps_secret.yaml")).NotTo(BeAnExistingFile())
+
+		var passwords map[string]string
+		Expect(json.Unmarshal([]byte(read("postgres-passwords.json")), &passwords)).To(Succeed())
+		Expect(passwords).To(HaveKeyWithValue("Alerting", "YWxlcnRpbmc="))
+		Expect(passwords).To(HaveKeyWithValue("PostgreSQL", "cG9zdGdyZXM="))
+		info, err := os.Stat(filepath.Join(u.BackupDir, "postgres-passwords.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+		Expect(c.Ran("kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- pg_dumpall")).NotTo(BeEmpty())
+		Expect(read("orch-database_backup.sql")).NotTo(ContainSubstring("CREATE ROLE"))
+		Expect(read("orch-database_backup.sql")).To(ContainSubstring(`CREATE DATABASE "orch-infra-mps"`))
+		Expect(read("orch-database_backup.sql.bak")).To(Equal(dump))
+	})
+
+	It("keeps what an earlier attempt saved", func() {
+		Expect(u.Backup()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(u.BackupDir, "postgres-passwords.json"), []byte("{}"), 0o600)).To(Succeed())
+		c.Commands = nil
+
+		Expect(u.Backup()).To(Succeed())
+		Expect(read("postgres-passwords.json")).To(Equal("{}"))
+		Expect(c.Commands).To(BeEmpty())
+	})
+
+	It("backs up volumes through snapshots when there is room", func() {
+		u.BackupVolumes = true
+		c.Responses["sudo vgs"] = "  100.00  60.00"
+		c.Responses["kubectl get pvc"] = "data-vault-0 orch-platform pvc-1\ndkam-pvc orch-infra pvc-2\n"
+		c.Responses["kubectl get volumesnapshot"] = "true"
+		c.Responses["sudo lvs --noheadings --units m"] = " 1024.00"
+		c.Responses["sudo lvs --noheadings -o lv_name,origin"] = "  pvc-1\n  snapshot-1 pvc-1\n  pvc-2\n"
+		c.Responses["dpkg-query"] = "3.1.0"
+
+		Expect(u.Backup()).To(Succeed())
+		Expect(c.Inputs["kubectl apply -f -"]).To(ContainElement(ContainSubstring("persistentVolumeClaimName: data-vault-0")))
+		Expect(c.Ran("sudo lvcreate -n data-vault-0-orch-platform-bak-")).NotTo(BeEmpty())
+		Expect(c.Ran("sudo mount /dev/lvmvg/snapshot-1 /mnt/original-lv")).NotTo(BeEmpty())
+		Expect(c.Ran("sudo lvcreate -n dkam-pvc")).To(BeEmpty())
+		Expect(c.Ran("kubectl delete volumesnapshot data-vault-0-snap -n orch-platform")).NotTo(BeEmpty())
+		Expect(c.Ran("sudo rke2 etcd-snapshot save --name pre-upgrade-snapshot-")).NotTo(BeEmpty())
+	})
+
+	It("refuses to back up volumes without room for a copy", func() {
+		u.BackupVolumes = true
+		c.Responses["sudo vgs"] = "  100.00  40.00"
+
+		Expect(u.Backup()).To(MatchError(ContainSubstring("not enough space in volume group lvmvg")))
+		Expect(c.Ran("sudo lvcreate")).To(BeEmpty())
+	})
+})
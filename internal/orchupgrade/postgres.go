@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/cnpg"
+)
+
+const (
+	// databaseDump is the dump of all databases without the roles, which the new PostgreSQL creates itself.
+	databaseDump = "orch-database_backup.sql"
+	// superuserSecret is the backup of the secret of the PostgreSQL superuser.
+	superuserSecret = "postgres_secret.yaml"
+	// passwordsFile holds the base64 encoded passwords of the database users.
+	passwordsFile = "postgres-passwords.json"
+
+	remoteDump = "/var/lib/postgresql/data/" + databaseDump
+)
+
+// secretKey is a key of a secret.
+type secretKey struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// databaseUser is a database user whose password is kept across the upgrade, which regenerates the secrets of the
+// database users.
+type databaseUser struct {
+	Name string
+	// Source is the secret the password is saved from.
+	Source secretKey
+	// Targets are the secrets the password is restored to.
+	Targets []secretKey
+	// CNPGSecret is the secret CloudNativePG reads the password from, it is restored if it exists.
+	CNPGSecret string
+}
+
+func localPostgresUser(name, namespace, service, cnpgSecret string) databaseUser {
+	secret := service + "-local-postgresql"
+	reader := service + "-reader-local-postgresql"
+	return databaseUser{
+		Name:   name,
+		Source: secretKey{namespace, secret, "PGPASSWORD"},
+		Targets: []secretKey{
+			{namespace, secret, "PGPASSWORD"},
+			{namespace, reader, "PGPASSWORD"},
+		},
+		CNPGSecret: cnpgSecret,
+	}
+}
+
+var databaseUsers = []databaseUser{
+	localPostgresUser("Alerting", infraNamespace, "alerting", "orch-infra-alerting"),
+	localPostgresUser("CatalogService", "orch-app", "app-orch-catalog", "orch-app-app-orch-catalog"),
+	localPostgresUser("Inventory", infraNamespace, "inventory", "orch-infra-inventory"),
+	localPostgresUser("IAMTenancy", "orch-iam", "iam-tenancy", "orch-iam-iam-tenancy"),
+	localPostgresUser("PlatformKeycloak", platformNamespace, "platform-keycloak", "orch-platform-platform-keycloak"),
+	localPostgresUser("Vault", platformNamespace, "vault", "orch-platform-vault"),
+	localPostgresUser("Mps", infraNamespace, "mps", "orch-infra-mps"),
+	localPostgresUser("Rps", infraNamespace, "rps", "orch-infra-rps"),
+	{
+		Name:    "PostgreSQL",
+		Source:  secretKey{databaseNamespace, "orch-database-postgresql", "password"},
+		Targets: []secretKey{{databaseNamespace, "orch-database-postgresql", "password"}},
+	},
+}
+
+// savePasswords saves the passwords of the database users, unless an earlier attempt saved them already.
+func (u *Upgrader) savePasswords() error {
+	path := u.backupPath(passwordsFile)
+	if _, err := os.Stat(path); err == nil {
+		u.Log.Info("database passwords already saved", "path", path)
+		return nil
+	}
+
+	return u.X.Apply("save the database passwords to "+path, func() error {
+		passwords := map[string]string{}
+		for _, user := range databaseUsers {
+			password, err := u.X.Query("kubectl", "get", "secret", user.Source.Name, "-n", user.Source.Namespace,
+				"-o", "jsonpath={.data."+user.Source.Key+"}")
+			if err != nil {
+				return fmt.Errorf("failed to read the password of %s: %w", user.Name, err)
+			}
+			passwords[user.Name] = password
+		}
+		data, err := json.MarshalIndent(passwords, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return fmt.Errorf("failed to save the database passwords: %w", err)
+		}
+		u.Log.Info("database passwords saved", "path", path, "users", len(passwords))
+		return nil
+	})
+}
+
+// restorePasswords waits for the upgrade to recreate the secrets of the database users and restores the saved
+// passwords to them.
+func (u *Upgrader) restorePasswords() error {
+	path := u.backupPath(passwordsFile)
+	var passwords map[string]string
+	if err := u.X.Apply("read the database passwords from "+path, func() error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the saved database passwords: %w", err)
+		}
+		return json.Unmarshal(data, &passwords)
+	}); err != nil {
+		return err
+	}
+
+	for _, user := range databaseUsers {
+		password, ok := passwords[user.Name]
+		if passwords != nil && !ok {
+			return fmt.Errorf("no saved password for database user %s", user.Name)
+		}
+		u.X.Redact(password)
+
+		for _, target := range user.Targets {
+			if err := u.waitFor(fmt.Sprintf("secret %s/%s exists", target.Namespace, target.Name), u.timeout(),
+				func() (bool, error) { return u.exists("secret", target.Name, "-n", target.Namespace) }); err != nil {
+				return err
+			}
+			if err := u.patchSecret(target, password); err != nil {
+				return err
+			}
+		}
+
+		if user.CNPGSecret == "" {
+			continue
+		}
+		exists, err := u.exists("secret", user.CNPGSecret, "-n", databaseNamespace)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := u.patchSecret(secretKey{databaseNamespace, user.CNPGSecret, "password"}, password); err != nil {
+				return err
+			}
+		}
+	}
+	u.Log.Info("database passwords restored")
+	return nil
+}
+
+func (u *Upgrader) patchSecret(key secretKey, base64Value string) error {
+	patch, err := json.Marshal(map[string]any{"data": map[string]string{key.Key: base64Value}})
+	if err != nil {
+		return err
+	}
+	if err := u.kubectl("patch", "secret", key.Name, "-n", key.Namespace, "--type", "merge",
+		"-p", string(patch)); err != nil {
+		return fmt.Errorf("failed to patch secret %s/%s: %w", key.Namespace, key.Name, err)
+	}
+	return nil
+}
+
+// saveSecret saves the secret as YAML to file in the backup directory, unless an earlier attempt saved it already.
+// Secrets that don't exist are skipped.
+func (u *Upgrader) saveSecret(namespace, name, file string) error {
+	path := u.backupPath(file)
+	if _, err := os.Stat(path); err == nil {
+		u.Log.Info("secret already saved", "secret", namespace+"/"+name, "path", path)
+		return nil
+	}
+	exists, err := u.exists("secret", name, "-n", namespace)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		u.Log.Info("secret not found, skipping its backup", "secret", namespace+"/"+name)
+		return nil
+	}
+
+	return u.X.Apply(fmt.Sprintf("save secret %s/%s to %s", namespace, name, path), func() error {
+		secret, err := u.X.Query("kubectl", "get", "secret", name, "-n", namespace, "-o", "yaml")
+		if err != nil {
+			return fmt.Errorf("failed to read secret %s/%s: %w", namespace, name, err)
+		}
+		if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to save secret %s/%s: %w", namespace, name, err)
+		}
+		u.Log.Info("secret saved", "secret", namespace+"/"+name, "path", path)
+		return nil
+	})
+}
+
+// restoreSecret applies a secret saved by saveSecret, without the metadata that ties it to the deleted secret.
+// Secrets that were not saved are skipped.
+func (u *Upgrader) restoreSecret(file string) error {
+	path := u.backupPath(file)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read saved secret: %w", err)
+	}
+	manifest, err := cleanSecret(data)
+	if err != nil {
+		return fmt.Errorf("invalid saved secret %s: %w", path, err)
+	}
+	if err := u.X.RunInput(string(manifest), "kubectl", "apply", "-f", "-"); err != nil {
+		return fmt.Errorf("failed to restore secret from %s: %w", path, err)
+	}
+	u.Log.Info("secret restored", "path", path)
+	return nil
+}
+
+// cleanSecret removes the metadata of a saved secret that only applies to the object it was read from.
+func cleanSecret(data []byte) ([]byte, error) {
+	var secret map[string]any
+	if err := yaml.Unmarshal(data, &secret); err != nil {
+		return nil, err
+	}
+	metadata, ok := secret["metadata"].(map[string]any)
+	if !ok {
+		return nil, errors.New("no metadata")
+	}
+	for _, field := range []string{"labels", "annotations", "ownerReferences", "finalizers", "managedFields",
+		"resourceVersion", "uid", "creationTimestamp"} {
+		delete(metadata, field)
+	}
+	return yaml.Marshal(secret)
+}
+
+// dumpDatabases dumps all databases, unless an earlier attempt did already. The roles are stripped from the dump
+// since the upgraded PostgreSQL creates them with the restored passwords; the full dump is kept next to it.
+func (u *Upgrader) dumpDatabases() error {
+	path := u.backupPath(databaseDump)
+	if _, err := os.Stat(path); err == nil {
+		u.Log.Info("database dump already taken", "path", path)
+		return nil
+	}
+
+	u.Log.Info("dumping databases", "pod", postgresPod)
+	if err := u.kubectl("exec", "-n", databaseNamespace, postgresPod, "-c", "postgres", "--",
+		"pg_dumpall", "-U", "postgres", "-f", remoteDump); err != nil {
+		return fmt.Errorf("failed to dump databases: %w", err)
+	}
+	full := path + ".bak"
+	if err := u.kubectl("cp", "-c", "postgres", databaseNamespace+"/"+postgresPod+":"+remoteDump, full); err != nil {
+		return fmt.Errorf("failed to copy database dump: %w", err)
+	}
+	return u.X.Apply("strip the roles from the database dump to "+path, func() error {
+		dump, err := os.ReadFile(full)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, stripRoles(dump), 0o600); err != nil {
+			return fmt.Errorf("failed to write database dump: %w", err)
+		}
+		u.Log.Info("databases dumped", "path", path, "bytes", len(dump))
+		return nil
+	})
+}
+
+// stripRoles removes the roles section of a pg_dumpall dump.
+func stripRoles(dump []byte) []byte {
+	lines := bytes.SplitAfter(dump, []byte("\n"))
+	roles, userConfigs := -1, -1
+	for i, line := range lines {
+		switch {
+		case roles < 0 && bytes.Contains(line, []byte("-- Roles")):
+			roles = i
+		case roles >= 0 && bytes.Contains(line, []byte("-- User Configurations")):
+			userConfigs = i
+		}
+		if userConfigs >= 0 {
+			break
+		}
+	}
+	if roles < 0 || userConfigs < 0 {
+		return dump
+	}
+	return bytes.Join(append(lines[:roles+1:roles+1], lines[userConfigs:]...), nil)
+}
+
+// deletePostgres deletes the PostgreSQL applications together with their resources, so that the upgrade recreates
+// the database on CloudNativePG.
+func (u *Upgrader) deletePostgres() error {
+	finalizer := `{"metadata": {"finalizers": ["resources-finalizer.argocd.argoproj.io"]}}`
+	for _, app := range []string{"postgresql-secrets", "postgresql"} {
+		exists, err := u.exists("applications.argoproj.io", app, "-n", appsNamespace)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			u.Log.Info("application not found, skipping its deletion", "app", app)
+			continue
+		}
+		// The finalizer makes Argo CD delete the resources of the application with it.
+		if err := u.kubectl("patch", "applications.argoproj.io", app, "-n", appsNamespace, "--type", "merge",
+			"-p", finalizer); err != nil {
+			return err
+		}
+		if err := u.kubectl("delete", "applications.argoproj.io", app, "-n", appsNamespace,
+			"--cascade=background", "--wait=false"); err != nil {
+			return err
+		}
+		u.Log.Info("application deleted", "app", app)
+	}
+	u.kubectlBestEffort("delete", "pvc", "data-postgresql-0", "-n", databaseNamespace, "--ignore-not-found",
+		"--wait=false")
+	return u.kubectl("delete", "secret", "postgresql", "-n", databaseNamespace, "--ignore-not-found")
+}
+
+// restoreDatabases restores the dump to the primary of the PostgreSQL cluster.
+func (u *Upgrader) restoreDatabases() error {
+	pod, err := cnpg.Primary(u.X, databaseNamespace, "postgresql-cluster")
+	if err != nil {
+		return err
+	}
+
+	if err := u.kubectl("cp", "-c", "postgres", u.backupPath(databaseDump),
+		databaseNamespace+"/"+pod+":"+remoteDump); err != nil {
+		return fmt.Errorf("failed to copy database dump: %w", err)
+	}
+
+	encoded, err := u.X.Query("kubectl", "get", "secret", "orch-database-postgresql", "-n", databaseNamespace,
+		"-o", "jsonpath={.data.password}")
+	if err != nil {
+		return fmt.Errorf("failed to read the PostgreSQL password: %w", err)
+	}
+	password, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode the PostgreSQL password: %w", err)
+	}
+	u.X.Redact(string(password))
+
+	u.Log.Info("restoring databases", "pod", pod)
+	if err := u.kubectl("exec", "-n", databaseNamespace, pod, "-c", "postgres", "--",
+		"env", "PGPASSWORD="+string(password), "psql", "-U", "postgres", "-f", remoteDump); err != nil {
+		return fmt.Errorf("failed to restore databases: %w", err)
+	}
+	u.Log.Info("databases restored")
+	return nil
+}
+
+// postgresPrimaryRunning reports whether the primary of the PostgreSQL cluster runs.
+func (u *Upgrader) postgresPrimaryRunning() (bool, error) {
+	phase, err := u.X.Query("kubectl", "get", "pods", "-n", databaseNamespace,
+		"-l", "cnpg.io/cluster=postgresql-cluster,cnpg.io/instanceRole=primary", "-o", "jsonpath={.items[0].status.phase}")
+	return strings.TrimSpace(phase) == "Running", err
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	postgresPod = "postgresql-cluster-1"
+)
+
+// clusterProfile are the profiles the root-app was installed with.
+type clusterProfile struct {
+	DisableClusterOrch   bool
+	DisableAppOrch       bool
+	DisableObservability bool
+	SingleTenancy        bool
+}
+
+// installGitea reports whether the installation runs Gitea, which only cluster and app orchestration need.
+func (p clusterProfile) installGitea() bool {
+	return !p.DisableClusterOrch && !p.DisableAppOrch
+}
+
+// parseProfile derives the profiles from the value files of the root-app.
+func parseProfile(valueFiles string) clusterProfile {
+	return clusterProfile{
+		DisableClusterOrch: !strings.Contains(valueFiles, "enable-cluster-orch.yaml"),
+		DisableAppOrch:     !strings.Contains(valueFiles, "enable-app-orch.yaml"),
+		DisableObservability: !strings.Contains(valueFiles, "enable-o11y.yaml") &&
+			!strings.Contains(valueFiles, "o11y-onprem-1k.yaml"),
+		SingleTenancy: strings.Contains(valueFiles, "enable-singleTenancy.yaml"),
+	}
+}
+
+// clusterProfile returns the profiles of the installation, read from the root-app once.
+func (u *Upgrader) clusterProfile() (clusterProfile, error) {
+	if u.profile != nil {
+		return *u.profile, nil
+	}
+	valueFiles, err := u.X.Query("kubectl", "get", "applications.argoproj.io", "root-app", "-n", appsNamespace,
+		"-o", "jsonpath={.spec.sources[0].helm.valueFiles[*]}")
+	if err != nil {
+		return clusterProfile{}, fmt.Errorf("failed to read the value files of root-app: %w", err)
+	}
+	if strings.TrimSpace(valueFiles) == "" {
+		return clusterProfile{}, errors.New("root-app has no value files")
+	}
+	profile := parseProfile(valueFiles)
+	u.profile = &profile
+	return profile, nil
+}
+
+// installerPackages returns the installer packages of the installation in the order they are upgraded.
+func installerPackages(gitea bool) []string {
+	var packages []string
+	if gitea {
+		packages = append(packages, "onprem-gitea-installer")
+	}
+	return append(packages,
+		"onprem-config-installer",
+		"onprem-ke-installer",
+		"onprem-argocd-installer",
+		"onprem-orch-installer",
+	)
+}
+
+// PreChecks checks that the upgrade can start: the tools it runs are installed, PostgreSQL runs so that it can be
+// backed up, the Orchestrator packages are installed and, for an upgrade from local packages, all packages are there.
+// It changes nothing.
+func (u *Upgrader) PreChecks() error {
+	tools := []string{"kubectl", "helm"}
+	if !u.LocalPackages {
+		tools = append(tools, "oras")
+	}
+	var errs []error
+	for _, tool := range tools {
+		if _, err := u.X.Query("which", tool); err != nil {
+			errs = append(errs, fmt.Errorf("%s is not installed", tool))
+		}
+	}
+
+	if _, err := os.Stat(u.backupPath(databaseDump)); err == nil {
+		u.Log.Info("database dump of an earlier attempt found, it is restored instead of taking a new one",
+			"path", u.backupPath(databaseDump))
+	} else {
+		phase, err := u.X.Query("kubectl", "get", "pod", postgresPod, "-n", databaseNamespace,
+			"-o", "jsonpath={.status.phase}")
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("PostgreSQL pod %s not found: %w", postgresPod, err))
+		case phase != "Running":
+			errs = append(errs, fmt.Errorf("PostgreSQL pod %s is %s, it must be Running to be backed up", postgresPod,
+				phase))
+		default:
+			u.Log.Info("PostgreSQL is running", "pod", postgresPod)
+		}
+	}
+
+	profile, err := u.clusterProfile()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	u.Log.Info("cluster profile detected", "disableClusterOrch", profile.DisableClusterOrch,
+		"disableAppOrch", profile.DisableAppOrch, "disableO11y", profile.DisableObservability,
+		"singleTenancy", profile.SingleTenancy, "gitea", profile.installGitea())
+
+	for _, pkg := range installerPackages(profile.installGitea()) {
+		version, err := u.X.Query("dpkg-query", "-W", "-f=${Version}", pkg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("package %s is not installed, the Orchestrator is not installed or its "+
+				"installation is broken", pkg))
+			continue
+		}
+		u.Log.Info("installed package", "package", pkg, "version", version)
+
+		if u.LocalPackages {
+			if _, err := packageFile(u.PackagesDir, pkg); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if u.LocalPackages {
+		if _, err := repoArchive(u.ReposDir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// packageFile returns the DEB of pkg in dir.
+func packageFile(dir, pkg string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, pkg+"_*_amd64.deb"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s package not found in %s", pkg, dir)
+	}
+	return matches[len(matches)-1], nil
+}
+
+// repoArchive returns the edge-manageability-framework repo archive in dir.
+func repoArchive(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+emfRepo+"*.tgz"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s archive not found in %s", emfRepo, dir)
+	}
+	return matches[len(matches)-1], nil
+}
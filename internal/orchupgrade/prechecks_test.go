@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreChecks", func() {
+	var c *cluster
+
+	BeforeEach(func() {
+		c = newCluster()
+		c.installed()
+	})
+
+	It("passes for an installed Orchestrator and logs its profile", func() {
+		var log bytes.Buffer
+		u := newUpgrader(c, &log)
+
+		Expect(u.PreChecks()).To(Succeed())
+		Expect(log.String()).To(ContainSubstring(`"msg":"cluster profile detected"`))
+		Expect(log.String()).To(ContainSubstring(`"gitea":true`))
+		Expect(c.Commands).To(BeEmpty())
+	})
+
+	It("reports every failed check", func() {
+		delete(c.Responses, "which")
+		c.Responses["which kubectl"] = "/usr/bin/kubectl"
+		c.Responses["kubectl get pod postgresql-cluster-1 -n orch-database -o jsonpath={.status.phase}"] = "Pending"
+		delete(c.Responses, "dpkg-query -W -f=${Version}")
+		u := newUpgrader(c, io.Discard)
+
+		err := u.PreChecks()
+		Expect(err).To(MatchError(ContainSubstring("helm is not installed")))
+		Expect(err).To(MatchError(ContainSubstring("oras is not installed")))
+		Expect(err).To(MatchError(ContainSubstring("postgresql-cluster-1 is Pending")))
+		Expect(err).To(MatchError(ContainSubstring("package onprem-gitea-installer is not installed")))
+	})
+
+	It("skips the PostgreSQL check when a dump of an earlier attempt exists", func() {
+		delete(c.Responses, "kubectl get pod postgresql-cluster-1 -n orch-database -o jsonpath={.status.phase}")
+		u := newUpgrader(c, io.Discard)
+		Expect(os.MkdirAll(u.BackupDir, 0o700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(u.BackupDir, "orch-database_backup.sql"), nil, 0o600)).To(Succeed())
+
+		Expect(u.PreChecks()).To(Succeed())
+	})
+
+	It("requires the local packages and repo archive", func() {
+		u := newUpgrader(c, io.Discard)
+		u.LocalPackages = true
+		Expect(os.MkdirAll(u.PackagesDir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(u.PackagesDir, "onprem-ke-installer_3.2.0_amd64.deb"), nil,
+			0o644)).To(Succeed())
+
+		err := u.PreChecks()
+		Expect(err).To(MatchError(ContainSubstring("onprem-config-installer package not found")))
+		Expect(err).NotTo(MatchError(ContainSubstring("onprem-ke-installer package not found")))
+		Expect(err).To(MatchError(ContainSubstring("edge-manageability-framework archive not found")))
+	})
+
+	It("fails without the value files of the root-app", func() {
+		c.Responses["kubectl get applications.argoproj.io root-app -n onprem -o jsonpath={.spec.sources[0]"] = ""
+		u := newUpgrader(c, io.Discard)
+
+		Expect(u.PreChecks()).To(MatchError(ContainSubstring("root-app has no value files")))
+	})
+})
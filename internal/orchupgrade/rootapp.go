@@ -0,0 +1,477 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+)
+
+// orchInstallerPostrm replaces the postrm script of the installed orch-installer, whose removal during the package
+// upgrade would otherwise delete the secrets of the installation.
+const orchInstallerPostrm = `#!/usr/bin/env bash
+
+set -o errexit
+
+export KUBECONFIG=/home/$USER/.kube/config
+export PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin:/usr/games:/usr/local/games:/snap/bin
+
+kubectl delete job -n gitea -l managed-by=edge-manageability-framework || true
+kubectl delete sts -n orch-database postgresql || true
+kubectl delete job -n orch-infra credentials || true
+kubectl delete job -n orch-infra loca-credentials || true
+
+if [ "${1}" = "upgrade" ]; then
+    exit 0
+fi
+
+# Secrets for postgresql are generated on each installation, so we have to clean them up to avoid issues during reinstallation
+kubectl delete secret -l managed-by=edge-manageability-framework -A || true
+`
+
+// externalSecretsCRDs is the bundle of the external-secrets CRDs the upgraded Orchestrator uses.
+const externalSecretsCRDs = "https://raw.githubusercontent.com/external-secrets/external-secrets/refs/tags/v0.20.4/deploy/crds/bundle.yaml"
+
+// UpgradeRootApp upgrades the installer packages, which upgrades RKE2, Gitea, Argo CD and the root-app, migrates
+// PostgreSQL to CloudNativePG with the data and passwords saved by the backup and applies the fixes the upgraded
+// applications need to start.
+func (u *Upgrader) UpgradeRootApp() error {
+	profile, err := u.clusterProfile()
+	if err != nil {
+		return err
+	}
+	gitea := profile.installGitea()
+
+	if !u.LocalPackages {
+		if err := u.fetchArtifacts(gitea); err != nil {
+			return err
+		}
+	}
+	if err := u.X.Run("sudo", "chown", "-R", "_apt:root", u.PackagesDir); err != nil {
+		return err
+	}
+	if err := u.updateClusterValues(profile); err != nil {
+		return err
+	}
+
+	u.kubectlBestEffort("delete", "job", "kyverno-clean-reports", "-n", "kyverno", "--ignore-not-found")
+	if gitea {
+		for _, secret := range []string{"gitea-apporch-token", "gitea-argocd-token", "gitea-clusterorch-token"} {
+			u.kubectlBestEffort("delete", "secret", secret, "-n", giteaNamespace, "--ignore-not-found")
+		}
+	}
+	if err := u.upgradePackages(gitea); err != nil {
+		return err
+	}
+	if err := u.X.Run("helm", "delete", "-n", "kube-system", "rke2-metrics-server"); err != nil {
+		u.Log.Warn("ignoring failed deletion of rke2-metrics-server", "error", err)
+	}
+
+	if err := u.migratePostgres(); err != nil {
+		return err
+	}
+	return u.finishUpgrade(gitea)
+}
+
+// upgradePackages upgrades the installer packages in the order they depend on each other.
+func (u *Upgrader) upgradePackages(gitea bool) error {
+	if err := u.installPackage("onprem-config-installer", nil); err != nil {
+		return err
+	}
+	if err := u.installPackage("onprem-ke-installer", u.env("RKE2_INVENTORY", "RKE2_UPGRADE_CONCURRENCY")); err != nil {
+		return err
+	}
+	if gitea {
+		if err := u.installPackage("onprem-gitea-installer", map[string]string{
+			"IMAGE_REGISTRY": u.GiteaImageRegistry,
+			"INSTALL_GITEA":  "true",
+		}); err != nil {
+			return err
+		}
+		if err := u.waitForPods(giteaNamespace); err != nil {
+			return err
+		}
+	}
+	if err := u.installPackage("onprem-argocd-installer", nil); err != nil {
+		return err
+	}
+	if err := u.waitForPods(argoCDNamespace); err != nil {
+		return err
+	}
+
+	if err := u.X.RunInput(orchInstallerPostrm, "sudo", "tee",
+		"/var/lib/dpkg/info/onprem-orch-installer.postrm"); err != nil {
+		return fmt.Errorf("failed to patch the onprem-orch-installer postrm script: %w", err)
+	}
+	env := map[string]string{
+		"INSTALL_GITEA":          strconv.FormatBool(gitea),
+		"ORCH_INSTALLER_PROFILE": u.Profile,
+		"GIT_REPOS":              u.ReposDir,
+	}
+	if !gitea {
+		maps.Copy(env, u.env("GIT_TOKEN", "GIT_USER", "DEPLOY_REPO_URL"))
+		u.X.Redact(env["GIT_TOKEN"])
+	}
+	return u.installPackage("onprem-orch-installer", env)
+}
+
+// env returns the names set in Env.
+func (u *Upgrader) env(names ...string) map[string]string {
+	env := map[string]string{}
+	for _, name := range names {
+		if value := u.Env[name]; value != "" {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// installPackage upgrades pkg from PackagesDir, passing env to its maintainer scripts.
+func (u *Upgrader) installPackage(pkg string, env map[string]string) error {
+	deb := filepath.Join(u.PackagesDir, pkg+"_"+u.Version+"_amd64.deb")
+	if found, err := packageFile(u.PackagesDir, pkg); err == nil {
+		deb = found
+	}
+
+	args := []string{"env", "DEBIAN_FRONTEND=noninteractive", "NEEDRESTART_MODE=l"}
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		args = append(args, name+"="+env[name])
+	}
+	args = append(args, "apt-get", "install", "--only-upgrade", "--allow-downgrades", "-y", deb)
+
+	u.Log.Info("upgrading package", "package", pkg, "deb", deb)
+	if err := u.X.Run("sudo", args...); err != nil {
+		return fmt.Errorf("failed to upgrade %s: %w", pkg, err)
+	}
+	if version, err := u.X.Query("dpkg-query", "-W", "-f=${Version}", pkg); err == nil {
+		u.Log.Info("package upgraded", "package", pkg, "version", version)
+	}
+	return nil
+}
+
+// waitForPods waits for the pods in namespace, except those of jobs, to be ready.
+func (u *Upgrader) waitForPods(namespace string) error {
+	if err := u.kubectl("wait", "pod", "--selector=!job-name", "--all", "--for=condition=Ready",
+		"-n", namespace, "--timeout=600s"); err != nil {
+		return fmt.Errorf("pods in namespace %s are not ready: %w", namespace, err)
+	}
+	return nil
+}
+
+// migratePostgres lets the upgraded root-app recreate PostgreSQL on CloudNativePG and restores the passwords, the
+// superuser secret and the databases saved by the backup to it. The cluster belongs to the release, so it is deleted
+// and recreated at the version of the release rather than moved with pgupgrade, which needs the old cluster running
+// beside the new one until the switch-over.
+func (u *Upgrader) migratePostgres() error {
+	apps := u.apps()
+	if err := u.resyncRootApp(); err != nil {
+		return err
+	}
+	if err := u.waitFor("the upgrade recreates the database secrets", u.timeout(), func() (bool, error) {
+		return u.exists("secret", "app-orch-catalog-reader-local-postgresql", "-n", "orch-app")
+	}); err != nil {
+		return err
+	}
+
+	if err := u.resyncRootApp(); err != nil {
+		return err
+	}
+	if err := u.waitGreen("postgresql-secrets", time.Hour); err != nil {
+		return err
+	}
+	if err := u.deletePostgres(); err != nil {
+		return err
+	}
+	if err := u.resyncRootApp(); err != nil {
+		return err
+	}
+	if err := u.waitGreen("postgresql-secrets", u.timeout()); err != nil {
+		u.Log.Warn("postgresql-secrets is not green, syncing it again", "error", err)
+		if err := u.syncApp("postgresql-secrets", argocd.SyncOptions{}); err != nil {
+			return err
+		}
+		if err := apps.Sync(argocd.RootApp, argocd.SyncOptions{}); err != nil {
+			return err
+		}
+	}
+
+	if err := u.restorePasswords(); err != nil {
+		return err
+	}
+	if err := u.patchKeycloakAdmin(); err != nil {
+		return err
+	}
+	if err := u.restoreSecret(superuserSecret); err != nil {
+		return err
+	}
+	if err := u.waitFor("the PostgreSQL primary is Running", 5*time.Minute, u.postgresPrimaryRunning); err != nil {
+		return err
+	}
+	return u.restoreDatabases()
+}
+
+// patchKeycloakAdmin adds the username and password keys the upgraded Keycloak reads to its admin secret.
+func (u *Upgrader) patchKeycloakAdmin() error {
+	exists, err := u.exists("secret", "platform-keycloak", "-n", platformNamespace)
+	if err != nil || !exists {
+		return err
+	}
+	password, err := u.X.Query("kubectl", "get", "secret", "platform-keycloak", "-n", platformNamespace,
+		"-o", "jsonpath={.data.admin-password}")
+	if err != nil || password == "" {
+		return fmt.Errorf("failed to read the admin password of Keycloak: %w", err)
+	}
+	u.X.Redact(password)
+	if err := u.patchSecret(secretKey{platformNamespace, "platform-keycloak", "password"}, password); err != nil {
+		return err
+	}
+	return u.patchSecret(secretKey{platformNamespace, "platform-keycloak", "username"},
+		base64.StdEncoding.EncodeToString([]byte("admin")))
+}
+
+// finishUpgrade applies what the upgraded applications need after the PostgreSQL migration: Vault is unsealed, the
+// secrets the migration invalidated are restored or recreated, the services connected to PostgreSQL are restarted and
+// the applications the upgrade removed are pruned.
+func (u *Upgrader) finishUpgrade(gitea bool) error {
+	apps := u.apps()
+	if err := u.unsealVault(); err != nil {
+		return err
+	}
+	for _, name := range []string{"mps", "rps"} {
+		if err := u.restoreSecret(name + "_secret.yaml"); err != nil {
+			return err
+		}
+	}
+	if err := apps.Sync(argocd.RootApp, argocd.SyncOptions{}); err != nil {
+		return err
+	}
+	if gitea {
+		if err := u.restoreGiteaCredentials(); err != nil {
+			return err
+		}
+	}
+	if err := u.deleteFleetGitRepoCredentials(); err != nil {
+		return err
+	}
+	for _, service := range []string{"mps", "rps"} {
+		if err := u.updateConnectionString(service); err != nil {
+			return err
+		}
+	}
+
+	for _, deployment := range []string{"rps", "mps", "inventory", "onboarding-manager", "dkam"} {
+		if err := u.kubectl("rollout", "restart", "deployment", deployment, "-n", infraNamespace); err != nil {
+			return err
+		}
+	}
+	if err := u.restartStatefulSet("keycloak-tenant-controller-set", platformNamespace); err != nil {
+		return err
+	}
+	if err := u.resyncRootApp(); err != nil {
+		return err
+	}
+	if u.Profile != "onprem-vpro" {
+		if err := u.restartStatefulSet("harbor-oci-database", "orch-harbor"); err != nil {
+			u.Log.Warn("ignoring failed restart of harbor-oci-database", "error", err)
+		}
+		u.kubectlBestEffort("rollout", "restart", "deployment", "harbor-oci-core", "-n", "orch-harbor")
+	}
+
+	if err := u.replaceExternalSecretsCRDs(); err != nil {
+		return err
+	}
+	if err := u.unsealVault(); err != nil {
+		return err
+	}
+
+	if err := apps.ResetOperation(argocd.RootApp); err != nil {
+		return err
+	}
+	if err := apps.Delete("external-secrets"); err != nil {
+		return err
+	}
+	// Pruning removes the applications the new version no longer has.
+	if err := apps.Sync(argocd.RootApp, argocd.SyncOptions{Prune: true}); err != nil {
+		return err
+	}
+
+	for _, app := range []string{"ingress-nginx", "nginx-ingress-pxe-boots"} {
+		if err := apps.Delete(app); err != nil {
+			return err
+		}
+	}
+	if err := u.deletePods("orch-harbor", "harbor-oci-nginx"); err != nil {
+		return err
+	}
+
+	if !gitea {
+		releases, err := u.X.Query("helm", "list", "-n", giteaNamespace, "-q")
+		if err != nil {
+			return err
+		}
+		if slices.Contains(strings.Fields(releases), "gitea") {
+			if err := u.X.Run("helm", "uninstall", "gitea", "-n", giteaNamespace); err != nil {
+				return fmt.Errorf("failed to uninstall Gitea: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// restoreGiteaCredentials stores the Gitea credentials of app orchestration in Vault again.
+func (u *Upgrader) restoreGiteaCredentials() error {
+	credentials := map[string]string{}
+	for _, key := range []string{"username", "password"} {
+		encoded, err := u.X.Query("kubectl", "get", "secret", "app-gitea-credential", "-n", platformNamespace,
+			"-o", "jsonpath={.data."+key+"}")
+		if err != nil {
+			return fmt.Errorf("failed to read the Gitea credentials: %w", err)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode the Gitea %s: %w", key, err)
+		}
+		credentials[key] = string(value)
+	}
+	u.X.Redact(credentials["password"])
+	if err := u.kubectl("exec", "-n", platformNamespace, vaultPod, "-c", "vault", "--",
+		"vault", "kv", "put", "secret/ma_git_service",
+		"username="+credentials["username"], "password="+credentials["password"]); err != nil {
+		return fmt.Errorf("failed to store the Gitea credentials in Vault: %w", err)
+	}
+	return nil
+}
+
+// deleteFleetGitRepoCredentials deletes the Fleet Git credentials, which are recreated with the upgraded Gitea
+// tokens.
+func (u *Upgrader) deleteFleetGitRepoCredentials() error {
+	out, err := u.X.Query("kubectl", "get", "secret", "--all-namespaces", "--no-headers",
+		"-o", "custom-columns=NAMESPACE:.metadata.namespace,NAME:.metadata.name")
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.Contains(fields[1], "fleet-gitrepo-cred") {
+			continue
+		}
+		if err := u.kubectl("delete", "secret", fields[1], "-n", fields[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateConnectionString points the connection string of an AMT service at the CloudNativePG service.
+func (u *Upgrader) updateConnectionString(service string) error {
+	encoded, err := u.X.Query("kubectl", "get", "secret", service+"-local-postgresql", "-n", infraNamespace,
+		"-o", "jsonpath={.data.PGPASSWORD}")
+	if err != nil {
+		return fmt.Errorf("failed to read the database password of %s: %w", service, err)
+	}
+	password, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode the database password of %s: %w", service, err)
+	}
+	connection := base64.StdEncoding.EncodeToString([]byte(connectionString(service, string(password))))
+	// The patch carries the connection string encoded, so both forms of the password are secret.
+	u.X.Redact(string(password), connection)
+	return u.patchSecret(secretKey{infraNamespace, service, "connectionString"}, connection)
+}
+
+func connectionString(service, password string) string {
+	return fmt.Sprintf("postgresql://orch-infra-%s_user:%s@postgresql-cluster-rw.orch-database/orch-infra-%s"+
+		"?search_path=public&sslmode=disable", service, password, service)
+}
+
+// restartStatefulSet scales a stateful set to zero and back, for applications that must not run two pods at once.
+func (u *Upgrader) restartStatefulSet(name, namespace string) error {
+	replicas, err := u.X.Query("kubectl", "get", "statefulset", name, "-n", namespace,
+		"-o", "jsonpath={.spec.replicas}")
+	if err != nil {
+		return fmt.Errorf("failed to read the replicas of %s: %w", name, err)
+	}
+	if err := u.kubectl("scale", "statefulset", name, "-n", namespace, "--replicas=0"); err != nil {
+		return err
+	}
+	if err := u.kubectl("wait", "--for=delete", "pod", "-l", "app="+name, "-n", namespace,
+		"--timeout=300s"); err != nil {
+		return err
+	}
+	if err := u.kubectl("scale", "statefulset", name, "-n", namespace,
+		"--replicas="+strings.TrimSpace(replicas)); err != nil {
+		return err
+	}
+	u.Log.Info("stateful set restarted", "name", name, "namespace", namespace)
+	return nil
+}
+
+// replaceExternalSecretsCRDs replaces the external-secrets CRDs with those of the upgraded version, and deletes the
+// Kyverno policies that restart MPS and RPS when their secrets change.
+func (u *Upgrader) replaceExternalSecretsCRDs() error {
+	for _, crd := range []string{
+		"clustersecretstores.external-secrets.io",
+		"secretstores.external-secrets.io",
+		"externalsecrets.external-secrets.io",
+	} {
+		exists, err := u.exists("crd", crd)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if err := u.kubectl("delete", "crd", crd, "--wait=false"); err != nil {
+			return err
+		}
+		if err := u.kubectl("patch", "crd", crd, "--type", "merge",
+			"-p", `{"metadata":{"finalizers":[]}}`); err != nil {
+			return err
+		}
+	}
+	for _, policy := range []string{"restart-mps-deployment-on-secret-change", "restart-rps-deployment-on-secret-change"} {
+		if err := u.kubectl("delete", "clusterpolicy", policy, "--ignore-not-found"); err != nil {
+			return err
+		}
+	}
+	u.kubectlBestEffort("apply", "--server-side=true", "--force-conflicts", "-f", externalSecretsCRDs)
+	return nil
+}
+
+// deletePods deletes the pods in namespace whose name contains prefix.
+func (u *Upgrader) deletePods(namespace, prefix string) error {
+	pods, err := u.podsNamed(namespace, prefix)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+	return u.kubectl(append([]string{"delete", "pod", "-n", namespace, "--ignore-not-found"}, pods...)...)
+}
+
+// podsNamed returns the pods in namespace whose name contains prefix.
+func (u *Upgrader) podsNamed(namespace, prefix string) ([]string, error) {
+	out, err := u.X.Query("kubectl", "get", "pods", "-n", namespace, "--no-headers",
+		"-o", "custom-columns=NAME:.metadata.name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+	var pods []string
+	for _, pod := range strings.Fields(out) {
+		if strings.Contains(pod, prefix) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
@@ -0,0 +1,355 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
+)
+
+const (
+	// appMaxAttempts is how often the sync of an application is attempted before the next one is synced.
+	appMaxAttempts = 5
+	// appRecreateAttempt is the failed attempt after which the application is deleted for the root-app to recreate it.
+	appRecreateAttempt = 3
+	// jobRestarts bounds how often a sync is remediated while waiting for it because one of its jobs failed.
+	jobRestarts = 3
+	// healAuditLog is the audit log of the remediations of the upgrade in the backup directory.
+	healAuditLog = "argo-heal-audit.log"
+	// syncPasses is how often the applications that are not green are synced, in wave order.
+	syncPasses = 2
+)
+
+// serverSideApps are the applications whose resources changed in a way that a client-side apply can't update. They
+// are synced with server-side apply and replaced resources, after deleting their failed resources.
+var serverSideApps = map[string]bool{
+	"external-secrets":                 true,
+	"copy-app-gitea-cred-to-fleet":     true,
+	"copy-ca-cert-boots-to-gateway":    true,
+	"copy-ca-cert-boots-to-infra":      true,
+	"copy-ca-cert-gateway-to-cattle":   true,
+	"copy-ca-cert-gateway-to-infra":    true,
+	"copy-ca-cert-gitea-to-app":        true,
+	"copy-ca-cert-gitea-to-cluster":    true,
+	"copy-cluster-gitea-cred-to-fleet": true,
+	"copy-keycloak-admin-to-infra":     true,
+	"infra-external":                   true,
+	"platform-keycloak":                true,
+	"namespace-label":                  true,
+	"wait-istio-job":                   true,
+}
+
+// SyncByWave syncs the applications that are not synced and healthy one by one in wave order, then syncs the root-app
+// and removes what the new version replaced. The known failures are remediated by an argoheal.Healer, within its rate
+// limits and recorded in its audit log in the backup directory.
+func (u *Upgrader) SyncByWave() error {
+	if err := u.resyncRootApp(); err != nil {
+		return err
+	}
+
+	for pass := 1; pass <= syncPasses; pass++ {
+		notGreen, err := u.notGreenApps()
+		if err != nil {
+			return err
+		}
+		if len(notGreen) == 0 {
+			u.Log.Info("all applications are synced and healthy")
+			break
+		}
+		if pass > 1 {
+			u.heal()
+		}
+		u.Log.Info("syncing applications", "pass", pass, "notGreen", len(notGreen))
+		for _, app := range notGreen {
+			u.Log.Info("application not synced and healthy", "app", app.Name(), "wave", app.Wave(),
+				"health", app.Health(), "sync", app.Sync())
+		}
+		for _, app := range notGreen {
+			u.syncWaveApp(app.Name(), app.Wave())
+		}
+	}
+
+	u.syncRootApp()
+	if err := u.postUpgradeCleanup(); err != nil {
+		return err
+	}
+	u.syncRootApp()
+
+	apps, err := u.apps().List()
+	if err != nil {
+		return err
+	}
+	if notGreen := argocd.NotGreen(apps); len(notGreen) > 0 {
+		names := make([]string, 0, len(notGreen))
+		for _, app := range notGreen {
+			names = append(names, app.String())
+		}
+		return fmt.Errorf("applications not synced and healthy: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// notGreenApps returns the applications other than the root-app that are not synced and healthy, in wave order.
+func (u *Upgrader) notGreenApps() ([]argocd.Application, error) {
+	apps, err := u.apps().List()
+	if err != nil {
+		return nil, err
+	}
+	var notGreen []argocd.Application
+	for _, app := range argocd.NotGreen(apps) {
+		if app.Name() != argocd.RootApp {
+			notGreen = append(notGreen, app)
+		}
+	}
+	return notGreen, nil
+}
+
+// syncWaveApp syncs an application until it is synced and healthy, remediating failures between attempts, and
+// reports whether it got there.
+func (u *Upgrader) syncWaveApp(name string, wave int) bool {
+	apps := u.apps()
+	log := u.Log.With("app", name, "wave", wave)
+
+	if name == "haproxy-ingress-pxe-boots" {
+		// The ingress creates the certificate of the boots endpoint again, it fails to sync while the old one exists.
+		u.kubectlBestEffort("delete", "secret", "tls-boots", "-n", "orch-boots", "--ignore-not-found")
+	}
+
+	for attempt := 1; attempt <= appMaxAttempts; attempt++ {
+		app, err := apps.Get(name)
+		if err != nil {
+			log.Warn("application not found", "error", err)
+			return false
+		}
+		if app.Green() {
+			log.Info("application synced and healthy", "attempt", attempt)
+			return true
+		}
+		if app.OperationFailed() || len(argoheal.DegradedJobs(app)) > 0 {
+			log.Warn("application failed, remediating it", "phase", app.OperationPhase(), "health", app.Health(),
+				"message", app.Status.OperationState.Message)
+			u.heal()
+		} else if app.Health() != argocd.Healthy {
+			u.refresh(name, attempt > 1)
+		}
+
+		var opts argocd.SyncOptions
+		if serverSideApps[name] {
+			if err := u.resyncRootApp(); err != nil {
+				log.Warn("failed to sync the root-app", "error", err)
+			}
+			u.terminate(app)
+			u.deleteProblemResources(app)
+			opts = argocd.SyncOptions{Force: true, Replace: true, ServerSideApply: true}
+		}
+
+		start := time.Now()
+		log.Info("syncing application", "attempt", attempt, "serverSide", opts.ServerSideApply)
+		if err := apps.Sync(name, opts); err != nil {
+			log.Warn("failed to start sync", "attempt", attempt, "error", err)
+			continue
+		}
+		if u.awaitSync(name) {
+			log.Info("application synced and healthy", "attempt", attempt,
+				"duration", time.Since(start).Round(time.Second))
+			return true
+		}
+
+		if attempt == appRecreateAttempt {
+			log.Warn("application keeps failing, deleting it for the root-app to recreate it", "attempt", attempt)
+			if err := u.recreateApp(name); err != nil {
+				log.Warn("failed to recreate application", "error", err)
+			}
+			continue
+		}
+		if app, err := apps.Get(name); err == nil {
+			u.terminate(app)
+		}
+		u.refresh(name, true)
+	}
+	log.Error("application not synced and healthy after all attempts", "attempts", appMaxAttempts)
+	return false
+}
+
+// awaitSync waits up to AppTimeout for the sync of an application and reports whether the application got synced
+// and healthy. A sync that fails because of a failed job is restarted after deleting the job.
+func (u *Upgrader) awaitSync(name string) bool {
+	apps := u.apps()
+	// The sync is assumed to succeed when planning, since the wait doesn't run.
+	green := true
+	_ = u.X.Wait(fmt.Sprintf("application %s is synced and healthy", name), func() error {
+		green = false
+		interval := u.pollInterval()
+		restarts := 0
+		for elapsed := time.Duration(0); elapsed < u.appTimeout(); elapsed += interval {
+			app, err := apps.Get(name)
+			switch {
+			case err != nil:
+			case app.Green():
+				green = true
+				return nil
+			case restarts < jobRestarts && len(argoheal.DegradedJobs(app)) > 0:
+				restarts++
+				u.Log.Warn("sync has failed jobs, remediating it", "app", name,
+					"jobs", len(argoheal.DegradedJobs(app)))
+				u.heal()
+				elapsed = 0
+			case app.OperationFailed():
+				u.Log.Warn("sync failed", "app", name, "phase", app.OperationPhase(),
+					"message", app.Status.OperationState.Message)
+				return nil
+			}
+			u.sleep(interval)
+		}
+		u.Log.Warn("application not synced and healthy in time", "app", name, "timeout", u.appTimeout())
+		return nil
+	})
+	return green
+}
+
+// syncRootApp syncs the root-app after the other applications. A root-app that doesn't get green is reported by the
+// final check, since it is only as healthy as the applications it deploys.
+func (u *Upgrader) syncRootApp() {
+	apps := u.apps()
+	for attempt := 1; attempt <= appRecreateAttempt; attempt++ {
+		app, err := apps.Get(argocd.RootApp)
+		if err != nil {
+			u.Log.Warn("failed to get the root-app", "error", err)
+			return
+		}
+		if app.Green() {
+			u.Log.Info("root-app synced and healthy")
+			return
+		}
+		if app.OperationFailed() {
+			u.terminate(app)
+			u.refresh(argocd.RootApp, true)
+		}
+		u.Log.Info("syncing root-app", "attempt", attempt)
+		if err := apps.Sync(argocd.RootApp, argocd.SyncOptions{}); err != nil {
+			u.Log.Warn("failed to start sync", "app", argocd.RootApp, "error", err)
+			continue
+		}
+		if u.awaitSync(argocd.RootApp) {
+			return
+		}
+	}
+}
+
+// postUpgradeCleanup removes what the new version replaced and restarts DKAM to build its artifacts again.
+func (u *Upgrader) postUpgradeCleanup() error {
+	for _, app := range []string{"tenancy-api-mapping", "tenancy-datamodel"} {
+		if err := u.recreateApp(app); err != nil {
+			return err
+		}
+	}
+	if err := u.kubectl("delete", "deployment", "os-resource-manager", "-n", infraNamespace,
+		"--ignore-not-found"); err != nil {
+		return err
+	}
+	return u.kubectl("delete", "pod", "-n", infraNamespace, "-l", "app.kubernetes.io/name=dkam",
+		"--ignore-not-found")
+}
+
+// recreateApp deletes an application and syncs the root-app, which creates it again if the new version has it.
+func (u *Upgrader) recreateApp(name string) error {
+	apps := u.apps()
+	if err := apps.ResetOperation(argocd.RootApp); err != nil {
+		return err
+	}
+	if err := apps.Delete(name); err != nil {
+		return err
+	}
+	u.Log.Info("application deleted", "app", name)
+	return apps.Sync(argocd.RootApp, argocd.SyncOptions{})
+}
+
+// resyncRootApp drops the operation of the root-app and syncs it again.
+func (u *Upgrader) resyncRootApp() error {
+	return argoheal.ResyncRootApp(u.apps())
+}
+
+// heal makes a pass of the healer over the applications. Its remediations are logged by the healer.
+func (u *Upgrader) heal() {
+	if u.healer == nil {
+		if err := u.X.MkdirAll(u.BackupDir, 0o700); err != nil {
+			u.Log.Warn("failed to create the directory of the remediation audit log", "error", err)
+		}
+		u.healer = &argoheal.Healer{X: u.X, AuditLog: u.backupPath(healAuditLog), Log: u.Log}
+	}
+	if _, err := u.healer.Heal(); err != nil {
+		u.Log.Warn("failed to remediate applications", "error", err)
+	}
+}
+
+// syncApp syncs an application, after terminating its current operation, and waits for it to get green.
+func (u *Upgrader) syncApp(name string, opts argocd.SyncOptions) error {
+	apps := u.apps()
+	app, err := apps.Get(name)
+	if err != nil {
+		return err
+	}
+	if app.Green() {
+		return nil
+	}
+	u.terminate(app)
+	if err := apps.Sync(name, opts); err != nil {
+		return err
+	}
+	return u.waitGreen(name, u.appTimeout())
+}
+
+// waitGreen waits for an application to be synced and healthy.
+func (u *Upgrader) waitGreen(name string, timeout time.Duration) error {
+	apps := u.apps()
+	return u.waitFor(fmt.Sprintf("application %s is synced and healthy", name), timeout, func() (bool, error) {
+		app, err := apps.Get(name)
+		return app.Green(), err
+	})
+}
+
+func (u *Upgrader) terminate(app argocd.Application) {
+	if err := u.apps().Terminate(app); err != nil {
+		u.Log.Warn("failed to terminate sync", "app", app.Name(), "error", err)
+	}
+}
+
+func (u *Upgrader) refresh(name string, hard bool) {
+	if err := u.apps().Refresh(name, hard); err != nil {
+		u.Log.Warn("failed to refresh application", "app", name, "error", err)
+	}
+}
+
+// deleteProblemResources deletes the resources of an application that a server-side sync can't update in place.
+func (u *Upgrader) deleteProblemResources(app argocd.Application) {
+	for _, resource := range app.ResourcesOfKind(func(r argocd.Resource) bool {
+		health := r.HealthStatus()
+		return r.Status == argocd.OutOfSync || health == argocd.Degraded || health == argocd.Missing
+	}, "Job", "CustomResourceDefinition", "ExternalSecret", "SecretStore", "ClusterSecretStore") {
+		u.deleteResource(resource)
+	}
+}
+
+// deleteResource deletes a resource without waiting for its finalizers.
+func (u *Upgrader) deleteResource(resource argocd.Resource) {
+	u.Log.Info("deleting resource", "resource", resource.String())
+	if resource.Kind == "Job" {
+		if err := argoheal.DeleteJob(u.X, resource.Namespace, resource.Name); err != nil {
+			u.Log.Warn("failed to delete job", "job", resource.String(), "error", err)
+		}
+		return
+	}
+	args := []string{resource.Kind, resource.Name}
+	if resource.Namespace != "" {
+		args = append(args, "-n", resource.Namespace)
+	}
+	u.kubectlBestEffort(append([]string{"patch"}, append(args, "--type", "merge",
+		"-p", `{"metadata":{"finalizers":[]}}`)...)...)
+	u.kubectlBestEffort(append([]string{"delete"}, append(args, "--ignore-not-found", "--wait=false")...)...)
+}
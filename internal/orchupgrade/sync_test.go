@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade_test
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchupgrade"
+)
+
+var _ = Describe("SyncByWave", func() {
+	var (
+		c *cluster
+		u *orchupgrade.Upgrader
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		c.apps["root-app"] = &app{Health: "Progressing", Sync: "OutOfSync"}
+		c.apps["cert-manager"] = &app{Wave: -10, Health: "Healthy", Sync: "Synced"}
+		c.apps["web-ui"] = &app{Wave: 2000, Health: "Missing", Sync: "OutOfSync"}
+		c.apps["vault"] = &app{Wave: 100, Health: "Progressing", Sync: "OutOfSync"}
+		c.apps["tenancy-datamodel"] = &app{Wave: 150, Health: "Healthy", Sync: "Synced"}
+		u = newUpgrader(c, io.Discard)
+	})
+
+	// synced returns the applications a sync was started for, in order.
+	synced := func() []string {
+		var names []string
+		for _, command := range c.Commands {
+			if strings.HasPrefix(command, "kubectl patch applications.argoproj.io") &&
+				strings.Contains(command, `"sync":`) {
+				names = append(names, strings.Fields(command)[3])
+			}
+		}
+		return names
+	}
+
+	It("syncs the applications that are not green in wave order, then the root-app", func() {
+		Expect(u.SyncByWave()).To(Succeed())
+
+		// The root-app is green after the first sync, so it is only synced again after the cleanup deletes the
+		// tenancy applications.
+		Expect(synced()).To(Equal([]string{"root-app", "vault", "web-ui", "root-app", "root-app"}))
+		Expect(c.Commands).To(ContainElement("kubectl delete applications.argoproj.io tenancy-datamodel -n onprem " +
+			"--ignore-not-found --wait=false"))
+		Expect(c.Commands).To(ContainElement("kubectl delete deployment os-resource-manager -n orch-infra " +
+			"--ignore-not-found"))
+	})
+
+	It("remediates the failed jobs of an application and syncs it again", func() {
+		c.apps["vault"].FailSyncs = 1
+		c.apps["vault"].Resources = []map[string]any{
+			{"kind": "Job", "namespace": "orch-platform", "name": "vault-init", "health": map[string]string{
+				"status": "Degraded",
+			}},
+			{"kind": "Job", "namespace": "orch-platform", "name": "vault-done", "health": map[string]string{
+				"status": "Healthy",
+			}},
+		}
+
+		c.Responses["kubectl get pods -n orch-platform -l job-name=vault-init -o name"] = "pod/vault-init-x7k2p"
+
+		Expect(u.SyncByWave()).To(Succeed())
+
+		Expect(c.Commands).To(ContainElements(
+			`kubectl patch job/vault-init -n orch-platform --type merge -p {"metadata":{"finalizers":null}}`,
+			"kubectl delete job vault-init -n orch-platform --ignore-not-found --cascade=background --wait=false",
+		))
+		Expect(c.Ran("kubectl delete job vault-done")).To(BeEmpty())
+		// The remediation is audited like those of the healer in other deployments.
+		Expect(filepath.Join(u.BackupDir, "argo-heal-audit.log")).To(BeAnExistingFile())
+		Expect(c.Commands).To(ContainElement("kubectl annotate applications.argoproj.io vault -n onprem --overwrite " +
+			"argocd.argoproj.io/refresh=hard"))
+		Expect(synced()).To(Equal([]string{"root-app", "vault", "vault", "web-ui", "root-app", "root-app"}))
+	})
+
+	It("syncs the applications with changed CRDs server-side after deleting their failed resources", func() {
+		c.apps["external-secrets"] = &app{Wave: 50, Health: "Degraded", Sync: "OutOfSync", Resources: []map[string]any{
+			{"kind": "CustomResourceDefinition", "name": "secretstores.external-secrets.io", "status": "OutOfSync"},
+			{"kind": "ConfigMap", "namespace": "orch-secret", "name": "config", "status": "OutOfSync"},
+		}}
+
+		Expect(u.SyncByWave()).To(Succeed())
+
+		Expect(c.Commands).To(ContainElement("kubectl delete CustomResourceDefinition " +
+			"secretstores.external-secrets.io --ignore-not-found --wait=false"))
+		Expect(c.Ran("kubectl delete ConfigMap")).To(BeEmpty())
+		Expect(c.Commands).To(ContainElement(`kubectl patch applications.argoproj.io external-secrets -n onprem ` +
+			`--type merge -p {"operation":{"initiatedBy":{"username":"admin"},"sync":{"prune":false,` +
+			`"syncOptions":["Replace=true","ServerSideApply=true"],"syncStrategy":{"hook":{"force":true}}}}}`))
+	})
+
+	It("recreates an application that keeps failing", func() {
+		c.apps["vault"].FailSyncs = appFailures
+
+		Expect(u.SyncByWave()).To(Succeed())
+
+		Expect(c.Commands).To(ContainElement("kubectl delete applications.argoproj.io vault -n onprem " +
+			"--ignore-not-found --wait=false"))
+	})
+
+	It("deletes the boots certificate before syncing the boots ingress", func() {
+		c.apps["haproxy-ingress-pxe-boots"] = &app{Wave: 1000, Health: "Degraded", Sync: "Synced"}
+
+		Expect(u.SyncByWave()).To(Succeed())
+
+		Expect(c.Commands).To(ContainElement("kubectl delete secret tls-boots -n orch-boots --ignore-not-found"))
+	})
+
+	It("fails with the applications that are still not green", func() {
+		c.apps["root-app"].FailSyncs = appFailures
+
+		Expect(u.SyncByWave()).To(MatchError(ContainSubstring("root-app (wave 0, health Degraded, sync OutOfSync)")))
+	})
+})
+
+// appFailures makes an application fail all syncs.
+const appFailures = 100
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package orchupgrade upgrades an on-prem Orchestrator in phases: pre-checks, backup, the root-app upgrade, a
+// wave-ordered sync of the Argo CD applications with remediation of the failures known to happen during upgrades, and
+// a post-verification.
+package orchupgrade
+
+import (
+	"cmp"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+// Phase names, in the order they run.
+const (
+	PhasePreChecks        = "pre-checks"
+	PhaseBackup           = "backup"
+	PhaseRootAppUpgrade   = "root-app-upgrade"
+	PhaseWaveSync         = "wave-sync"
+	PhasePostVerification = "post-verification"
+)
+
+const (
+	appsNamespace     = "onprem"
+	argoCDNamespace   = "argocd"
+	giteaNamespace    = "gitea"
+	databaseNamespace = "orch-database"
+	platformNamespace = "orch-platform"
+	infraNamespace    = "orch-infra"
+
+	emfRepo = "edge-manageability-framework"
+)
+
+// Config holds the inputs of an upgrade.
+type Config struct {
+	// Dir is the directory of the on-prem scripts, onprem.env and generate_cluster_yaml.sh.
+	Dir string
+	// Version is the Orchestrator version the installers and the repo archive are pulled at.
+	Version string
+	// Profile is the ORCH_INSTALLER_PROFILE of the installation.
+	Profile string
+	// ReleaseServiceURL is the registry the artifacts are pulled from.
+	ReleaseServiceURL string
+	// LocalPackages uses the artifacts already in PackagesDir and ReposDir instead of pulling them.
+	LocalPackages bool
+	// PackagesDir holds the installer DEBs.
+	PackagesDir string
+	// ReposDir holds the edge-manageability-framework repo archive, it is passed to orch-installer as GIT_REPOS.
+	ReposDir string
+	// BackupDir holds the database dump, passwords and secrets saved before the upgrade.
+	BackupDir string
+	// BackupVolumes backs up the persistent volumes to LVM volumes and takes an etcd snapshot.
+	BackupVolumes bool
+	// ClusterDomain is the domain the Orchestrator is served on, used to check that DKAM serves its certificates.
+	ClusterDomain string
+	// GiteaImageRegistry is the registry the Gitea installer pulls from.
+	GiteaImageRegistry string
+	// Env is passed to the installer packages, e.g. RKE2_INVENTORY, GIT_TOKEN or DEPLOY_REPO_URL.
+	Env map[string]string
+}
+
+// Timing configures how long the upgrade waits. Zero values use the defaults.
+type Timing struct {
+	// PollInterval is how often state is checked while waiting.
+	PollInterval time.Duration
+	// AppTimeout is how long a sync of a single application is waited for before it is remediated.
+	AppTimeout time.Duration
+	// Timeout bounds the waits for the cluster, e.g. for pods or secrets to be recreated.
+	Timeout time.Duration
+}
+
+// Upgrader runs the upgrade phases.
+type Upgrader struct {
+	Config
+	Timing Timing
+
+	X   executor.Executor
+	Log *slog.Logger
+	// Confirm asks the user to confirm prompt and reports whether they did. If nil, the upgrade doesn't ask.
+	Confirm func(prompt string) bool
+	// Sleep pauses the upgrade, it defaults to time.Sleep.
+	Sleep func(time.Duration)
+
+	profile *clusterProfile
+	// healer remediates the applications during the wave sync, created on first use.
+	healer *argoheal.Healer
+}
+
+// Phases returns the upgrade phases, to be run by a steps.Runner.
+func (u *Upgrader) Phases() []steps.Step {
+	return []steps.Step{
+		steps.Logged(&u.Log, PhasePreChecks, "Check that the Orchestrator is installed and can be upgraded",
+			u.PreChecks),
+		steps.Logged(&u.Log, PhaseBackup, "Back up the databases, secrets and optionally volumes",
+			u.Backup),
+		steps.Logged(&u.Log, PhaseRootAppUpgrade, "Upgrade the installer packages and the root-app",
+			u.UpgradeRootApp),
+		steps.Logged(&u.Log, PhaseWaveSync, "Sync the applications wave by wave, remediating known failures",
+			u.SyncByWave),
+		steps.Logged(&u.Log, PhasePostVerification, "Verify that the Orchestrator is synced and healthy",
+			u.Verify),
+	}
+}
+
+func (u *Upgrader) apps() argocd.Client {
+	return argocd.Client{X: u.X, Namespace: appsNamespace}
+}
+
+func (u *Upgrader) sleep(d time.Duration) {
+	if u.Sleep != nil {
+		u.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func (u *Upgrader) pollInterval() time.Duration {
+	return cmp.Or(u.Timing.PollInterval, 5*time.Second)
+}
+
+func (u *Upgrader) appTimeout() time.Duration {
+	return cmp.Or(u.Timing.AppTimeout, 30*time.Second)
+}
+
+func (u *Upgrader) timeout() time.Duration {
+	return cmp.Or(u.Timing.Timeout, 10*time.Minute)
+}
+
+// waitFor polls condition until it returns true or timeout passes. Errors returned by condition are logged and
+// retried, since the cluster is expected to be in flux during the upgrade.
+func (u *Upgrader) waitFor(description string, timeout time.Duration, condition func() (bool, error)) error {
+	poll := executor.Poll{Interval: u.pollInterval(), Timeout: timeout, Sleep: u.Sleep, Log: u.Log}
+	return executor.WaitFor(u.X, description, poll, condition)
+}
+
+// kubectl runs kubectl with args.
+func (u *Upgrader) kubectl(args ...string) error {
+	return u.X.Run("kubectl", args...)
+}
+
+// kubectlBestEffort runs kubectl with args and only logs a failure, like the cleanups that may find nothing to do.
+func (u *Upgrader) kubectlBestEffort(args ...string) {
+	if err := u.X.Run("kubectl", args...); err != nil {
+		u.Log.Warn("ignoring failed command", "command", "kubectl "+strings.Join(args, " "), "error", err)
+	}
+}
+
+// exists reports whether the kubectl resource exists.
+func (u *Upgrader) exists(args ...string) (bool, error) {
+	out, err := u.X.Query("kubectl", append(append([]string{"get"}, args...), "--ignore-not-found", "-o", "name")...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+func (u *Upgrader) backupPath(name string) string {
+	return filepath.Join(u.BackupDir, name)
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor/executortest"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchupgrade"
+)
+
+// app is an Argo CD application of the fake cluster.
+type app struct {
+	Wave      int
+	Health    string
+	Sync      string
+	Phase     string
+	Resources []map[string]any
+	// FailSyncs is how many syncs of the application fail before one succeeds.
+	FailSyncs int
+}
+
+// cluster fakes the tools the upgrade runs. It serves apps to kubectl like Argo CD, where a sync makes an
+// application green and a sync of the root-app recreates the deleted applications.
+type cluster struct {
+	*executortest.Fake
+	apps    map[string]*app
+	deleted map[string]*app
+	// onRun is called with every command that is run.
+	onRun func(command string)
+}
+
+func newCluster() *cluster {
+	return &cluster{Fake: executortest.New(), apps: map[string]*app{}, deleted: map[string]*app{}}
+}
+
+func (c *cluster) Run(name string, args ...string) error {
+	if err := c.Fake.Run(name, args...); err != nil {
+		return err
+	}
+	if c.onRun != nil {
+		c.onRun(executortest.Command(name, args...))
+	}
+	if len(args) > 2 && name == "kubectl" && args[1] == "applications.argoproj.io" {
+		c.argoCD(args[0], args[2], strings.Join(args, " "))
+	}
+	return nil
+}
+
+// argoCD applies a patch or deletion of an application.
+func (c *cluster) argoCD(verb, name, command string) {
+	a, ok := c.apps[name]
+	switch {
+	case !ok:
+	case verb == "delete":
+		c.deleted[name] = a
+		delete(c.apps, name)
+	case verb == "patch" && strings.Contains(command, `"sync":`):
+		if name == "root-app" {
+			for name, a := range c.deleted {
+				a.Health, a.Sync, a.Phase = "Healthy", "Synced", "Succeeded"
+				c.apps[name] = a
+			}
+			clear(c.deleted)
+		}
+		if a.FailSyncs > 0 {
+			a.FailSyncs--
+			a.Health, a.Phase = "Degraded", "Failed"
+			return
+		}
+		a.Health, a.Sync, a.Phase = "Healthy", "Synced", "Succeeded"
+	}
+}
+
+func (c *cluster) Query(name string, args ...string) (string, error) {
+	command := executortest.Command(name, args...)
+	if strings.HasPrefix(command, "kubectl get applications.argoproj.io") {
+		switch {
+		case strings.HasSuffix(command, "-o json"):
+			return c.applications(args[2])
+		case strings.HasSuffix(command, "--ignore-not-found -o name"):
+			if _, ok := c.apps[args[2]]; ok {
+				return "application.argoproj.io/" + args[2], nil
+			}
+			return "", nil
+		}
+	}
+	return c.Fake.Query(name, args...)
+}
+
+// applications returns the JSON of the application called name or, if name is a flag, of all applications.
+func (c *cluster) applications(name string) (string, error) {
+	object := func(name string, a *app) map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{
+				"name":        name,
+				"namespace":   "onprem",
+				"annotations": map[string]string{"argocd.argoproj.io/sync-wave": fmt.Sprint(a.Wave)},
+			},
+			"status": map[string]any{
+				"health":         map[string]string{"status": a.Health},
+				"sync":           map[string]string{"status": a.Sync},
+				"operationState": map[string]string{"phase": a.Phase},
+				"resources":      a.Resources,
+			},
+		}
+	}
+	var data []byte
+	var err error
+	if !strings.HasPrefix(name, "-") {
+		a, ok := c.apps[name]
+		if !ok {
+			return "", fmt.Errorf("application %s not found", name)
+		}
+		data, err = json.Marshal(object(name, a))
+	} else {
+		var items []map[string]any
+		for _, name := range slices.Sorted(maps.Keys(c.apps)) {
+			items = append(items, object(name, c.apps[name]))
+		}
+		data, err = json.Marshal(map[string]any{"items": items})
+	}
+	return string(data), err
+}
+
+// installed answers the queries of an installation with all profiles enabled.
+func (c *cluster) installed() {
+	c.Responses["which"] = "/usr/bin/tool"
+	c.Responses["kubectl get pod postgresql-cluster-1 -n orch-database -o jsonpath={.status.phase}"] = "Running"
+	c.Responses["kubectl get applications.argoproj.io root-app -n onprem -o jsonpath={.spec.sources[0]"] =
+		"orch-configs/profiles/enable-cluster-orch.yaml orch-configs/profiles/enable-app-orch.yaml " +
+			"orch-configs/profiles/enable-o11y.yaml"
+	c.Responses["dpkg-query -W -f=${Version}"] = "3.1.0"
+}
+
+func newUpgrader(x executor.Executor, log io.Writer) *orchupgrade.Upgrader {
+	dir := GinkgoT().TempDir()
+	return &orchupgrade.Upgrader{
+		Config: orchupgrade.Config{
+			Dir:               dir,
+			Version:           "v2026.0.0",
+			Profile:           "onprem",
+			ReleaseServiceURL: "registry-rs.edgeorchestration.intel.com",
+			PackagesDir:       filepath.Join(dir, "installers"),
+			ReposDir:          filepath.Join(dir, "repo_archives"),
+			BackupDir:         filepath.Join(dir, "backup"),
+		},
+		Timing: orchupgrade.Timing{PollInterval: time.Second, AppTimeout: 3 * time.Second, Timeout: 3 * time.Second},
+		X:      x,
+		Log:    slog.New(slog.NewJSONHandler(log, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Sleep:  func(time.Duration) {},
+	}
+}
+
+var _ = Describe("Upgrader", func() {
+	It("runs the phases in order", func() {
+		u := newUpgrader(newCluster(), io.Discard)
+		var names []string
+		for _, step := range u.Phases() {
+			names = append(names, step.Name)
+		}
+		Expect(names).To(Equal([]string{
+			orchupgrade.PhasePreChecks,
+			orchupgrade.PhaseBackup,
+			orchupgrade.PhaseRootAppUpgrade,
+			orchupgrade.PhaseWaveSync,
+			orchupgrade.PhasePostVerification,
+		}))
+	})
+
+	It("logs the start and end of a phase with its name", func() {
+		c := newCluster()
+		c.installed()
+		var log bytes.Buffer
+		u := newUpgrader(c, &log)
+
+		Expect(u.Phases()[0].Run()).To(Succeed())
+
+		var messages []string
+		for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+			var record map[string]any
+			Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+			Expect(record).To(HaveKeyWithValue("phase", orchupgrade.PhasePreChecks))
+			messages = append(messages, record["msg"].(string))
+		}
+		Expect(messages[0]).To(Equal("phase started"))
+		Expect(messages[len(messages)-1]).To(Equal("phase completed"))
+	})
+
+	It("only records the changes when planning", func() {
+		c := newCluster()
+		c.installed()
+		c.apps["root-app"] = &app{Health: "Healthy", Sync: "Synced"}
+		c.apps["web-ui"] = &app{Wave: 10, Health: "Progressing", Sync: "OutOfSync"}
+		var out bytes.Buffer
+		plan := executor.NewPlan(&out)
+		// The plan answers queries from the fake cluster.
+		u := newUpgrader(&planned{Plan: plan, cluster: c}, io.Discard)
+
+		Expect(u.SyncByWave()).To(MatchError(ContainSubstring("web-ui")))
+		Expect(c.Commands).To(BeEmpty())
+		Expect(out.String()).To(ContainSubstring("kubectl patch applications.argoproj.io web-ui"))
+		Expect(out.String()).To(ContainSubstring("application web-ui is synced and healthy"))
+	})
+})
+
+// planned plans the changes and answers queries from the fake cluster.
+type planned struct {
+	*executor.Plan
+	cluster *cluster
+}
+
+func (p *planned) Query(name string, args ...string) (string, error) {
+	return p.cluster.Query(name, args...)
+}
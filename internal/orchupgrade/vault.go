@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const vaultPod = "vault-0"
+
+// vaultKeys is the content of the vault-keys secret written when Vault was initialized.
+type vaultKeys struct {
+	KeysBase64 []string `json:"keys_base64"`
+	RootToken  string   `json:"root_token"`
+}
+
+// parseVaultKeys decodes the base64 encoded vault-keys key of the vault-keys secret.
+func parseVaultKeys(encoded string) (vaultKeys, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return vaultKeys{}, fmt.Errorf("failed to decode secret vault-keys: %w", err)
+	}
+	var keys vaultKeys
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return vaultKeys{}, fmt.Errorf("secret vault-keys is malformed: %w", err)
+	}
+	if len(keys.KeysBase64) == 0 {
+		return vaultKeys{}, errors.New("secret vault-keys has no unseal keys")
+	}
+	if keys.RootToken == "" {
+		return vaultKeys{}, errors.New("secret vault-keys has no root token")
+	}
+	return keys, nil
+}
+
+// unsealVault restarts Vault, unseals it with the keys in the vault-keys secret and restarts Keycloak, which fails
+// while Vault is sealed.
+func (u *Upgrader) unsealVault() error {
+	if err := u.kubectl("delete", "pod", vaultPod, "-n", platformNamespace, "--ignore-not-found"); err != nil {
+		return err
+	}
+	if err := u.waitFor("pod "+vaultPod+" is Running", u.timeout(), func() (bool, error) {
+		phase, err := u.X.Query("kubectl", "get", "pod", vaultPod, "-n", platformNamespace,
+			"-o", "jsonpath={.status.phase}")
+		return phase == "Running", err
+	}); err != nil {
+		return err
+	}
+
+	encoded, err := u.X.Query("kubectl", "get", "secret", "vault-keys", "-n", platformNamespace,
+		"-o", "jsonpath={.data.vault-keys}")
+	if err != nil {
+		return fmt.Errorf("failed to read secret vault-keys: %w", err)
+	}
+	var keys vaultKeys
+	if err := u.X.Apply("read the Vault unseal keys", func() error {
+		keys, err = parseVaultKeys(encoded)
+		return err
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys.KeysBase64 {
+		u.X.Redact(key)
+	}
+	u.X.Redact(keys.RootToken)
+
+	for _, key := range keys.KeysBase64 {
+		if err := u.kubectl("exec", "-n", platformNamespace, vaultPod, "-c", "vault", "--",
+			"vault", "operator", "unseal", key); err != nil {
+			return fmt.Errorf("failed to unseal Vault: %w", err)
+		}
+	}
+	if err := u.kubectl("wait", "pod", "-n", platformNamespace, "-l", "app.kubernetes.io/name=vault",
+		"--for=condition=Ready", "--timeout=300s"); err != nil {
+		return fmt.Errorf("vault is not ready after unsealing: %w", err)
+	}
+	if err := u.kubectl("exec", "-n", platformNamespace, vaultPod, "-c", "vault", "--",
+		"vault", "login", "token="+keys.RootToken); err != nil {
+		return fmt.Errorf("failed to log in to Vault: %w", err)
+	}
+	u.Log.Info("vault unsealed")
+	return u.kubectl("delete", "pod", "platform-keycloak-0", "-n", platformNamespace, "--ignore-not-found")
+}
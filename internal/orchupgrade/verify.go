@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argocd"
+)
+
+// Verify checks that the upgraded Orchestrator is synced and healthy: all applications are green and, if
+// ClusterDomain is set, DKAM serves its certificate and the signed iPXE binary. Pods that aren't healthy are reported
+// but don't fail the verification, since some of them are expected to restart after an upgrade.
+func (u *Upgrader) Verify() error {
+	apps, err := u.apps().List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, app := range apps {
+		u.Log.Info("application status", "app", app.Name(), "wave", app.Wave(), "health", app.Health(),
+			"sync", app.Sync())
+	}
+	if notGreen := argocd.NotGreen(apps); len(notGreen) > 0 {
+		names := make([]string, 0, len(notGreen))
+		for _, app := range notGreen {
+			names = append(names, app.Name())
+		}
+		errs = append(errs, fmt.Errorf("%d applications are not synced and healthy: %s", len(notGreen),
+			strings.Join(names, ", ")))
+	}
+
+	if u.ClusterDomain == "" {
+		u.Log.Info("cluster domain not set, skipping the DKAM certificate check")
+	} else if err := u.checkDKAMCertificates(); err != nil {
+		errs = append(errs, err)
+	}
+
+	out, err := u.X.Query("kubectl", "get", "pods", "--all-namespaces", "-o", "json")
+	if err != nil {
+		u.Log.Warn("failed to list pods", "error", err)
+	} else if pods, err := unhealthyPods([]byte(out)); err != nil {
+		u.Log.Warn("failed to check pods", "error", err)
+	} else {
+		for _, pod := range pods {
+			u.Log.Warn("pod not healthy", "namespace", pod.Namespace, "pod", pod.Name, "phase", pod.Phase,
+				"reason", pod.Reason)
+		}
+		u.Log.Info("pods checked", "unhealthy", len(pods))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	u.Log.Info("orchestrator is synced and healthy", "apps", len(apps))
+	return nil
+}
+
+// checkDKAMCertificates waits for DKAM to serve its server certificate and the iPXE binary signed with it, which it
+// builds again after the upgrade.
+func (u *Upgrader) checkDKAMCertificates() error {
+	base := "https://tinkerbell-haproxy." + u.ClusterDomain + "/tink-stack"
+	return u.waitFor("DKAM serves its certificates", 10*time.Minute, func() (bool, error) {
+		// The certificate is what verifies the server, so it can only be downloaded without verification.
+		insecure := &http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		}}
+		cert, err := download(insecure, base+"/keys/Full_server.crt")
+		if err != nil {
+			return false, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(cert) {
+			return false, errors.New("Full_server.crt is not a PEM certificate")
+		}
+		verified := &http.Client{Timeout: 5 * time.Minute, Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}}
+		if _, err := download(verified, base+"/signed_ipxe.efi"); err != nil {
+			return false, err
+		}
+		u.Log.Info("DKAM certificates downloaded")
+		return true, nil
+	})
+}
+
+func download(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// unhealthyPod is a pod that isn't running or has a container that isn't ready.
+type unhealthyPod struct {
+	Namespace string
+	Name      string
+	Phase     string
+	Reason    string
+}
+
+// unhealthyPods returns the unhealthy pods in the JSON output of kubectl get pods.
+func unhealthyPods(data []byte) ([]unhealthyPod, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Status struct {
+				Phase             string `json:"phase"`
+				ContainerStatuses []struct {
+					Ready bool `json:"ready"`
+					State struct {
+						Waiting *struct {
+							Reason string `json:"reason"`
+						} `json:"waiting"`
+						Terminated *struct {
+							Reason string `json:"reason"`
+						} `json:"terminated"`
+					} `json:"state"`
+				} `json:"containerStatuses"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pods: %w", err)
+	}
+
+	var pods []unhealthyPod
+	for _, item := range list.Items {
+		phase := item.Status.Phase
+		var reasons []string
+		for _, container := range item.Status.ContainerStatuses {
+			switch {
+			case container.State.Waiting != nil:
+				reasons = append(reasons, container.State.Waiting.Reason)
+			case container.State.Terminated != nil && container.State.Terminated.Reason != "Completed":
+				reasons = append(reasons, container.State.Terminated.Reason)
+			case !container.Ready && phase == "Running":
+				reasons = append(reasons, "NotReady")
+			}
+		}
+		if phase == "Succeeded" || (phase == "Running" && len(reasons) == 0) {
+			continue
+		}
+		slices.Sort(reasons)
+		pods = append(pods, unhealthyPod{
+			Namespace: item.Metadata.Namespace,
+			Name:      item.Metadata.Name,
+			Phase:     phase,
+			Reason:    strings.Join(slices.Compact(reasons), ","),
+		})
+	}
+	return pods, nil
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package orchupgrade_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchupgrade"
+)
+
+const pods = `{"items": [
+  {"metadata": {"namespace": "orch-infra", "name": "dkam-0"},
+   "status": {"phase": "Running", "containerStatuses": [{"ready": true, "state": {"running": {}}}]}},
+  {"metadata": {"namespace": "orch-infra", "name": "credentials-x"},
+   "status": {"phase": "Succeeded"}},
+  {"metadata": {"namespace": "orch-app", "name": "catalog-0"},
+   "status": {"phase": "Running", "containerStatuses": [
+     {"ready": false, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}}
+]}`
+
+var _ = Describe("Verify", func() {
+	var (
+		c   *cluster
+		u   *orchupgrade.Upgrader
+		log bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		c.Responses["kubectl get pods --all-namespaces -o json"] = pods
+		c.apps["root-app"] = &app{Health: "Healthy", Sync: "Synced"}
+		c.apps["web-ui"] = &app{Wave: 2000, Health: "Healthy", Sync: "Synced"}
+		log.Reset()
+		u = newUpgrader(c, &log)
+	})
+
+	It("passes when all applications are green and reports unhealthy pods", func() {
+		Expect(u.Verify()).To(Succeed())
+
+		Expect(log.String()).To(ContainSubstring(`"msg":"pod not healthy","namespace":"orch-app","pod":"catalog-0",` +
+			`"phase":"Running","reason":"CrashLoopBackOff"`))
+		Expect(log.String()).NotTo(ContainSubstring(`"pod":"dkam-0"`))
+		Expect(log.String()).NotTo(ContainSubstring(`"pod":"credentials-x"`))
+		Expect(log.String()).To(ContainSubstring("skipping the DKAM certificate check"))
+		Expect(c.Commands).To(BeEmpty())
+	})
+
+	It("fails with the applications that are not green", func() {
+		c.apps["web-ui"].Health = "Degraded"
+
+		Expect(u.Verify()).To(MatchError("1 applications are not synced and healthy: web-ui"))
+	})
+})
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// healthyPhase is the phase of a CloudNativePG cluster whose instances all run.
+const healthyPhase = "Cluster in healthy state"
+
+// cluster is the part of a CloudNativePG cluster the upgrade reads.
+type cluster struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Instances int `json:"instances"`
+		Bootstrap struct {
+			InitDB struct {
+				Owner string `json:"owner"`
+			} `json:"initdb"`
+		} `json:"bootstrap"`
+		Managed struct {
+			Roles []struct {
+				Name string `json:"name"`
+			} `json:"roles"`
+		} `json:"managed"`
+	} `json:"spec"`
+	Status struct {
+		Phase          string `json:"phase"`
+		ReadyInstances int    `json:"readyInstances"`
+	} `json:"status"`
+}
+
+func parseCluster(data []byte) (cluster, error) {
+	var c cluster
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("failed to parse cluster: %w", err)
+	}
+	return c, nil
+}
+
+// healthy reports whether all instances of the cluster are ready.
+func (c cluster) healthy() bool {
+	return c.Status.Phase == healthyPhase && c.Spec.Instances > 0 && c.Status.ReadyInstances == c.Spec.Instances
+}
+
+// roles returns the roles the cluster creates: those of the operator, the owner of the initial database and the
+// managed roles.
+func (c cluster) roles() []string {
+	roles := []string{"postgres", "streaming_replica", cmp.Or(c.Spec.Bootstrap.InitDB.Owner, "app")}
+	for _, role := range c.Spec.Managed.Roles {
+		roles = append(roles, role.Name)
+	}
+	return roles
+}
+
+// targetCluster returns the manifest of the cluster of the new version, from the manifest of the source cluster: the
+// same instances, storage, resources, parameters, roles and superuser, with the image of the new version. The
+// databases are restored from the dump, so the initial database is created empty, and the backups, replication and
+// recovery of the source are left out since they refer to it.
+func targetCluster(source []byte, name, image string) ([]byte, error) {
+	var manifest map[string]any
+	if err := json.Unmarshal(source, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster: %w", err)
+	}
+	spec, ok := manifest["spec"].(map[string]any)
+	if !ok {
+		return nil, errors.New("cluster has no spec")
+	}
+	metadata, _ := manifest["metadata"].(map[string]any)
+	spec = maps.Clone(spec)
+	for _, field := range []string{"imageCatalogRef", "backup", "externalClusters", "replica", "plugins"} {
+		delete(spec, field)
+	}
+	spec["imageName"] = image
+	// The tools of the Orchestrator read the password of the superuser from the secret of the source cluster.
+	if _, ok := spec["superuserSecret"]; !ok {
+		spec["superuserSecret"] = map[string]any{"name": fmt.Sprint(metadata["name"]) + "-superuser"}
+	}
+
+	initdb := map[string]any{}
+	if bootstrap, ok := spec["bootstrap"].(map[string]any); ok {
+		if source, ok := bootstrap["initdb"].(map[string]any); ok {
+			initdb = maps.Clone(source)
+		}
+	}
+	for _, field := range []string{"postInitSQL", "postInitApplicationSQL", "postInitTemplateSQL",
+		"postInitSQLRefs", "postInitApplicationSQLRefs", "postInitTemplateSQLRefs", "import"} {
+		delete(initdb, field)
+	}
+	spec["bootstrap"] = map[string]any{"initdb": initdb}
+
+	target := map[string]any{
+		"apiVersion": manifest["apiVersion"],
+		"kind":       manifest["kind"],
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": metadata["namespace"],
+			"labels":    map[string]string{managedByLabel: managedBy},
+		},
+		"spec": spec,
+	}
+	return json.MarshalIndent(target, "", "  ")
+}
+
+// majorVersion returns the major version of a server_version_num, e.g. 17 for 170004.
+func majorVersion(versionNum string) (int, error) {
+	num, err := strconv.Atoi(strings.TrimSpace(versionNum))
+	if err != nil {
+		return 0, fmt.Errorf("invalid server version %q: %w", versionNum, err)
+	}
+	return num / 10000, nil
+}
+
+// missing returns the values of want that are not in have, sorted.
+func missing(want, have []string) []string {
+	var out []string
+	for _, value := range want {
+		if !slices.Contains(have, value) && !slices.Contains(out, value) {
+			out = append(out, value)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Dump stops the writes to the source cluster, counts the rows of every table and dumps every database in the
+// custom format of pg_dump to the backup directory. The writes are stopped by pointing the services of the cluster to
+// no pod and disconnecting the clients, so that the counts match the dumps; they stay stopped until the switch-over.
+func (u *Upgrader) Dump() error {
+	if err := u.reconcile(false); err != nil {
+		return err
+	}
+	if err := u.pointServices(u.Source, true, false); err != nil {
+		return err
+	}
+	if err := u.disconnectClients(u.Source); err != nil {
+		return err
+	}
+	u.Log.Info("writes stopped", "cluster", u.Source)
+
+	pod, err := u.primary(u.Source)
+	if err != nil {
+		return err
+	}
+	databases, err := u.databases(pod)
+	if err != nil {
+		return err
+	}
+	counts := rowCounts{}
+	for _, database := range databases {
+		if counts[database], err = u.countRows(pod, database); err != nil {
+			return err
+		}
+	}
+	if err := u.X.MkdirAll(u.BackupDir, 0o700); err != nil {
+		return err
+	}
+	if err := u.saveRowCounts(counts); err != nil {
+		return err
+	}
+
+	if err := u.X.Run("kubectl", "exec", "-n", u.Namespace, pod, "-c", "postgres", "--",
+		"mkdir", "-p", remoteDumpDir); err != nil {
+		return fmt.Errorf("failed to create the dump directory: %w", err)
+	}
+	defer u.removeRemoteDumps(pod)
+	for _, database := range databases {
+		remote := remoteDumpDir + "/" + database + ".dump"
+		u.Log.Info("dumping database", "database", database, "tables", len(counts[database]))
+		if err := u.X.Run("kubectl", u.exec(pod, "pg_dump", "-d", database, "-Fc", "-f", remote)...); err != nil {
+			return fmt.Errorf("failed to dump database %s: %w", database, err)
+		}
+		if err := u.X.Run("kubectl", "cp", "-c", "postgres", u.Namespace+"/"+pod+":"+remote,
+			u.backupPath(database+".dump")); err != nil {
+			return fmt.Errorf("failed to copy the dump of database %s: %w", database, err)
+		}
+	}
+	u.Log.Info("databases dumped", "databases", len(databases), "dir", u.BackupDir)
+	return nil
+}
+
+// Restore restores the dumps to the new cluster, replacing the databases a failed attempt may have left, and
+// analyzes them since a restore has no statistics.
+func (u *Upgrader) Restore() error {
+	counts, err := u.loadRowCounts()
+	if err != nil {
+		return err
+	}
+	pod, err := u.primary(u.Target)
+	if err != nil {
+		return err
+	}
+	if err := u.X.Run("kubectl", "exec", "-n", u.Namespace, pod, "-c", "postgres", "--",
+		"mkdir", "-p", remoteDumpDir); err != nil {
+		return fmt.Errorf("failed to create the dump directory: %w", err)
+	}
+	defer u.removeRemoteDumps(pod)
+
+	for _, database := range slices.Sorted(maps.Keys(counts)) {
+		remote := remoteDumpDir + "/" + database + ".dump"
+		if err := u.X.Run("kubectl", "cp", "-c", "postgres", u.backupPath(database+".dump"),
+			u.Namespace+"/"+pod+":"+remote); err != nil {
+			return fmt.Errorf("failed to copy the dump of database %s: %w", database, err)
+		}
+		if err := u.run(pod, "postgres", "DROP DATABASE IF EXISTS "+quoteIdent(database)+" WITH (FORCE)"); err != nil {
+			return err
+		}
+		u.Log.Info("restoring database", "database", database)
+		// The database is created from the dump, with its owner, privileges and settings.
+		if err := u.X.Run("kubectl", u.exec(pod, "pg_restore", "--create", "--exit-on-error", "-d", "postgres",
+			remote)...); err != nil {
+			return fmt.Errorf("failed to restore database %s: %w", database, err)
+		}
+	}
+	if err := u.X.Run("kubectl", u.exec(pod, "vacuumdb", "--all", "--analyze-only")...); err != nil {
+		return fmt.Errorf("failed to analyze the restored databases: %w", err)
+	}
+	u.Log.Info("databases restored", "cluster", u.Target, "databases", len(counts))
+	return nil
+}
+
+func (u *Upgrader) removeRemoteDumps(pod string) {
+	if err := u.X.Run("kubectl", "exec", "-n", u.Namespace, pod, "-c", "postgres", "--",
+		"rm", "-rf", remoteDumpDir); err != nil {
+		u.Log.Warn("failed to remove the dumps from the pod", "pod", pod, "error", err)
+	}
+}
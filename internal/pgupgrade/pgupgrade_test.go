@@ -0,0 +1,309 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor/executortest"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/pgupgrade"
+)
+
+const (
+	sourceCluster = `{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind": "Cluster",
+		"metadata": {
+			"name": "postgresql-cluster",
+			"namespace": "orch-database",
+			"labels": {"app.kubernetes.io/instance": "postgresql-cluster"},
+			"resourceVersion": "4242"
+		},
+		"spec": {
+			"instances": 1,
+			"imageName": "ghcr.io/cloudnative-pg/postgresql:17",
+			"storage": {"size": "8Gi"},
+			"backup": {"barmanObjectStore": {"destinationPath": "s3://backups"}},
+			"bootstrap": {"initdb": {
+				"database": "postgres",
+				"owner": "orch-database-postgresql_user",
+				"secret": {"name": "orch-database-postgresql"},
+				"postInitSQL": ["CREATE DATABASE \"orch-infra-inventory\";"]
+			}},
+			"managed": {"roles": [{"name": "orch-infra-inventory_user", "passwordSecret": {"name": "orch-infra-inventory"}}]}
+		},
+		"status": {"phase": "Cluster in healthy state", "readyInstances": 1}
+	}`
+	targetCluster = `{
+		"metadata": {"name": "postgresql-cluster-18", "labels": {"app.kubernetes.io/managed-by": "onprem-postgres-upgrade"}},
+		"spec": {"instances": 1},
+		"status": {"phase": "Cluster in healthy state", "readyInstances": 1}
+	}`
+
+	sourcePod = "postgresql-cluster-1"
+	targetPod = "postgresql-cluster-18-1"
+)
+
+// psql is the prefix of the queries run by psql in pod.
+func psql(pod, database string) string {
+	return "kubectl exec -n orch-database " + pod + " -c postgres -- psql -U postgres -X -d " + database + " -At -c "
+}
+
+// newCluster returns a fake of a healthy PostgreSQL 17 cluster with an inventory database, whose upgrade cluster
+// runs PostgreSQL 18.
+func newCluster() *executortest.Fake {
+	c := executortest.New()
+	for cluster, pod := range map[string]string{"postgresql-cluster": sourcePod, "postgresql-cluster-18": targetPod} {
+		c.Responses["kubectl get pods -n orch-database -l cnpg.io/cluster="+cluster+",cnpg.io/instanceRole=primary"] =
+			pod
+		c.Responses[psql(pod, "postgres")+"SELECT datname FROM pg_database"] = "orch-infra-inventory\n"
+		c.Responses[psql(pod, "orch-infra-inventory")+"SELECT format("] = "public.hosts|12\npublic.sites|3\n"
+		c.Responses[psql(pod, "orch-infra-inventory")+"SELECT extname"] = "uuid-ossp\n"
+	}
+	c.Responses["kubectl get clusters.postgresql.cnpg.io postgresql-cluster -n orch-database -o json"] = sourceCluster
+	c.Responses["kubectl get clusters.postgresql.cnpg.io postgresql-cluster-18 -n orch-database --ignore-not-found"] =
+		""
+	c.Responses["kubectl get clusters.postgresql.cnpg.io postgresql-cluster-18 -n orch-database -o json"] =
+		targetCluster
+	c.Responses[psql(sourcePod, "postgres")+"SHOW server_version_num"] = "170004"
+	c.Responses[psql(targetPod, "postgres")+"SHOW server_version_num"] = "180001"
+	c.Responses[psql(sourcePod, "postgres")+"SELECT rolname"] =
+		"orch-database-postgresql_user\norch-infra-inventory_user\npostgres\nstreaming_replica\n"
+	c.Responses[psql(targetPod, "postgres")+"SELECT name FROM pg_available_extensions"] = "plpgsql\nuuid-ossp\n"
+	for _, query := range []string{"SELECT gid", "SELECT datname FROM pg_database WHERE NOT datallowconn",
+		"SELECT spcname", "SELECT n.nspname"} {
+		c.Responses[psql(sourcePod, "postgres")+query] = ""
+	}
+	c.Responses["kubectl get service postgresql-cluster-rw -n orch-database --ignore-not-found"] =
+		`{"cnpg.io/cluster":"postgresql-cluster","cnpg.io/instanceRole":"primary"}`
+	c.Responses["kubectl get service postgresql-cluster-ro -n orch-database --ignore-not-found"] =
+		`{"cnpg.io/cluster":"postgresql-cluster","cnpg.io/instanceRole":"replica"}`
+	c.Responses["kubectl get service postgresql-cluster-r -n orch-database --ignore-not-found"] = ""
+	return c
+}
+
+func newUpgrader(x executor.Executor) *pgupgrade.Upgrader {
+	return &pgupgrade.Upgrader{
+		Config: pgupgrade.Config{
+			Namespace: "orch-database",
+			Source:    "postgresql-cluster",
+			Target:    "postgresql-cluster-18",
+			Major:     18,
+			Image:     pgupgrade.DefaultImage(18),
+			BackupDir: filepath.Join(GinkgoT().TempDir(), "postgres-upgrade"),
+		},
+		PollInterval: time.Second,
+		Timeout:      3 * time.Second,
+		X:            x,
+		Log:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Sleep:        func(time.Duration) {},
+	}
+}
+
+var _ = Describe("PreChecks", func() {
+	var c *executortest.Fake
+
+	BeforeEach(func() {
+		c = newCluster()
+	})
+
+	It("passes for a healthy cluster and changes nothing", func() {
+		Expect(newUpgrader(c).PreChecks()).To(Succeed())
+		Expect(c.Commands).To(BeEmpty())
+	})
+
+	It("reports every problem of the catalog", func() {
+		c.Responses[psql(sourcePod, "postgres")+"SELECT gid"] = "tx-1\n"
+		c.Responses[psql(sourcePod, "postgres")+"SELECT spcname"] = "fast\n"
+		c.Responses[psql(sourcePod, "postgres")+"SELECT rolname"] += "reporting\n"
+
+		err := newUpgrader(c).PreChecks()
+		Expect(err).To(MatchError(ContainSubstring("prepared transactions must be committed or rolled back: tx-1")))
+		Expect(err).To(MatchError(ContainSubstring("tablespaces don't exist in the new cluster: fast")))
+		Expect(err).To(MatchError(ContainSubstring("don't exist in the new cluster: reporting")))
+	})
+
+	It("refuses to downgrade", func() {
+		c.Responses[psql(sourcePod, "postgres")+"SHOW server_version_num"] = "180001"
+		Expect(newUpgrader(c).PreChecks()).To(MatchError(ContainSubstring("PostgreSQL 18, which is not older than 18")))
+	})
+
+	It("refuses to reuse a cluster it didn't create", func() {
+		c.Responses["kubectl get clusters.postgresql.cnpg.io postgresql-cluster-18 -n orch-database --ignore-not-found"] =
+			`{"metadata": {"name": "postgresql-cluster-18"}}`
+		Expect(newUpgrader(c).PreChecks()).To(MatchError(ContainSubstring("was not created by the upgrade")))
+	})
+
+	It("fails for an unhealthy cluster", func() {
+		c.Responses["kubectl get clusters.postgresql.cnpg.io postgresql-cluster -n orch-database -o json"] =
+			strings.Replace(sourceCluster, `"readyInstances": 1`, `"readyInstances": 0`, 1)
+		Expect(newUpgrader(c).PreChecks()).To(MatchError(ContainSubstring("0 of 1 instances ready")))
+	})
+})
+
+var _ = Describe("Provision", func() {
+	It("creates the cluster of the new version from the source cluster", func() {
+		c := newCluster()
+		Expect(newUpgrader(c).Provision()).To(Succeed())
+
+		var manifest map[string]any
+		Expect(json.Unmarshal([]byte(c.Inputs["kubectl apply -f -"][0]), &manifest)).To(Succeed())
+		Expect(manifest["metadata"]).To(Equal(map[string]any{
+			"name":      "postgresql-cluster-18",
+			"namespace": "orch-database",
+			"labels":    map[string]any{"app.kubernetes.io/managed-by": "onprem-postgres-upgrade"},
+		}))
+		spec := manifest["spec"].(map[string]any)
+		Expect(spec["imageName"]).To(Equal("ghcr.io/cloudnative-pg/postgresql:18"))
+		Expect(spec["storage"]).To(Equal(map[string]any{"size": "8Gi"}))
+		Expect(spec["managed"]).NotTo(BeNil())
+		Expect(spec).NotTo(HaveKey("backup"))
+		Expect(spec["superuserSecret"]).To(Equal(map[string]any{"name": "postgresql-cluster-superuser"}))
+		Expect(spec["bootstrap"]).To(Equal(map[string]any{"initdb": map[string]any{
+			"database": "postgres",
+			"owner":    "orch-database-postgresql_user",
+			"secret":   map[string]any{"name": "orch-database-postgresql"},
+		}}))
+	})
+
+	It("fails when the image misses an extension of the databases", func() {
+		c := newCluster()
+		c.Responses[psql(targetPod, "postgres")+"SELECT name FROM pg_available_extensions"] = "plpgsql\n"
+		Expect(newUpgrader(c).Provision()).To(MatchError(ContainSubstring("not available in the image " +
+			"ghcr.io/cloudnative-pg/postgresql:18: uuid-ossp")))
+	})
+
+	It("fails when the new cluster doesn't run the new version", func() {
+		c := newCluster()
+		c.Responses[psql(targetPod, "postgres")+"SHOW server_version_num"] = "170004"
+		Expect(newUpgrader(c).Provision()).To(MatchError(ContainSubstring("runs PostgreSQL 17 instead of 18")))
+	})
+})
+
+var _ = Describe("Moving the databases", func() {
+	var (
+		c *executortest.Fake
+		u *pgupgrade.Upgrader
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		u = newUpgrader(c)
+	})
+
+	It("stops the writes before counting the rows and dumping", func() {
+		Expect(u.Dump()).To(Succeed())
+		Expect(c.Commands[0]).To(Equal("kubectl annotate clusters.postgresql.cnpg.io postgresql-cluster " +
+			"-n orch-database --overwrite cnpg.io/reconciliationLoop=disabled"))
+		Expect(c.Commands[1]).To(Equal(`kubectl patch service postgresql-cluster-ro -n orch-database --type merge -p ` +
+			`{"spec":{"selector":{"cnpg.io/cluster":"postgresql-cluster","cnpg.io/instanceRole":"replica",` +
+			`"edge-orchestrator.intel.com/postgres-upgrade":"fenced"}}}`))
+		Expect(c.Commands[2]).To(HavePrefix("kubectl patch service postgresql-cluster-rw"))
+		Expect(c.Commands[3]).To(ContainSubstring("pg_terminate_backend"))
+		Expect(c.Commands).To(ContainElement("kubectl exec -n orch-database postgresql-cluster-1 -c postgres -- " +
+			"pg_dump -U postgres -d orch-infra-inventory -Fc -f " +
+			"/var/lib/postgresql/data/orch-postgres-upgrade/orch-infra-inventory.dump"))
+
+		counts, err := os.ReadFile(filepath.Join(u.BackupDir, "row-counts.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(MatchJSON(`{"orch-infra-inventory": {"public.hosts": 12, "public.sites": 3}}`))
+		selectors, err := os.ReadFile(filepath.Join(u.BackupDir, "services.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selectors).To(MatchJSON(`{
+			"postgresql-cluster-rw": {"cnpg.io/cluster": "postgresql-cluster", "cnpg.io/instanceRole": "primary"},
+			"postgresql-cluster-ro": {"cnpg.io/cluster": "postgresql-cluster", "cnpg.io/instanceRole": "replica"}
+		}`))
+	})
+
+	It("restores the dumps into fresh databases and verifies the row counts", func() {
+		Expect(u.Dump()).To(Succeed())
+		c.Commands = nil
+
+		Expect(u.Restore()).To(Succeed())
+		Expect(c.Commands).To(ContainElements(
+			`kubectl exec -n orch-database postgresql-cluster-18-1 -c postgres -- psql -U postgres -X -d postgres `+
+				`-v ON_ERROR_STOP=1 -c DROP DATABASE IF EXISTS "orch-infra-inventory" WITH (FORCE)`,
+			"kubectl exec -n orch-database postgresql-cluster-18-1 -c postgres -- pg_restore -U postgres --create "+
+				"--exit-on-error -d postgres /var/lib/postgresql/data/orch-postgres-upgrade/orch-infra-inventory.dump",
+			"kubectl exec -n orch-database postgresql-cluster-18-1 -c postgres -- vacuumdb -U postgres --all "+
+				"--analyze-only",
+		))
+		Expect(u.Verify()).To(Succeed())
+	})
+
+	It("reports the tables whose row counts differ", func() {
+		Expect(u.Dump()).To(Succeed())
+		c.Responses[psql(targetPod, "orch-infra-inventory")+"SELECT format("] = "public.hosts|11\npublic.extra|1\n"
+
+		err := u.Verify()
+		Expect(err).To(MatchError(ContainSubstring("orch-infra-inventory: table public.hosts has 11 rows instead of 12")))
+		Expect(err).To(MatchError(ContainSubstring("orch-infra-inventory: table public.sites is missing")))
+		Expect(err).To(MatchError(ContainSubstring("orch-infra-inventory: table public.extra is unexpected")))
+	})
+})
+
+var _ = Describe("Switching over", func() {
+	var (
+		c *executortest.Fake
+		u *pgupgrade.Upgrader
+	)
+
+	BeforeEach(func() {
+		c = newCluster()
+		u = newUpgrader(c)
+		Expect(u.Dump()).To(Succeed())
+		c.Commands = nil
+		// The services are fenced now, the saved selectors are used from here on.
+		delete(c.Responses, "kubectl get service postgresql-cluster-rw -n orch-database --ignore-not-found")
+	})
+
+	It("points the services to the new cluster and releases them from the old one", func() {
+		Expect(u.SwitchOver()).To(Succeed())
+		Expect(c.Commands).To(Equal([]string{
+			`kubectl patch service postgresql-cluster-ro -n orch-database --type merge -p ` +
+				`{"metadata":{"ownerReferences":null},"spec":{"selector":{"cnpg.io/cluster":"postgresql-cluster-18",` +
+				`"cnpg.io/instanceRole":"replica","edge-orchestrator.intel.com/postgres-upgrade":null}}}`,
+			`kubectl patch service postgresql-cluster-rw -n orch-database --type merge -p ` +
+				`{"metadata":{"ownerReferences":null},"spec":{"selector":{"cnpg.io/cluster":"postgresql-cluster-18",` +
+				`"cnpg.io/instanceRole":"primary","edge-orchestrator.intel.com/postgres-upgrade":null}}}`,
+		}))
+	})
+
+	It("rolls back to the old cluster", func() {
+		Expect(u.Rollback()).To(Succeed())
+		Expect(c.Commands).To(HaveLen(4))
+		Expect(c.Commands[1]).To(Equal(`kubectl patch service postgresql-cluster-rw -n orch-database --type merge -p ` +
+			`{"spec":{"selector":{"cnpg.io/cluster":"postgresql-cluster","cnpg.io/instanceRole":"primary",` +
+			`"edge-orchestrator.intel.com/postgres-upgrade":null}}}`))
+		Expect(c.Commands[2]).To(Equal("kubectl annotate clusters.postgresql.cnpg.io postgresql-cluster " +
+			"-n orch-database --overwrite cnpg.io/reconciliationLoop-"))
+		Expect(c.Commands[3]).To(HavePrefix("kubectl exec -n orch-database postgresql-cluster-18-1"))
+	})
+
+	It("retires the old cluster only after the switch-over", func() {
+		c.Responses["kubectl get service postgresql-cluster-rw -n orch-database -o jsonpath={.spec.selector}"] =
+			`{"cnpg.io/cluster":"postgresql-cluster","edge-orchestrator.intel.com/postgres-upgrade":"fenced"}`
+		Expect(u.Retire()).To(MatchError(ContainSubstring("switch over before retiring postgresql-cluster")))
+		Expect(c.Commands).To(BeEmpty())
+
+		c.Responses["kubectl get service postgresql-cluster-rw -n orch-database -o jsonpath={.spec.selector}"] =
+			`{"cnpg.io/cluster":"postgresql-cluster-18","cnpg.io/instanceRole":"primary"}`
+		Expect(u.Retire()).To(Succeed())
+		Expect(c.Commands).To(Equal([]string{
+			"kubectl delete pods -n orch-database -l cnpg.io/cluster=postgresql-cluster --ignore-not-found",
+			"kubectl delete pvc -n orch-database -l cnpg.io/cluster=postgresql-cluster --ignore-not-found",
+		}))
+	})
+})
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// catalogCheck is a query of the source cluster that returns the objects a dump and restore into the new cluster
+// would lose or fail on.
+type catalogCheck struct {
+	Problem string
+	Query   string
+}
+
+var catalogChecks = []catalogCheck{
+	{
+		// Prepared transactions aren't part of a dump and hold locks that block it.
+		Problem: "prepared transactions must be committed or rolled back",
+		Query:   "SELECT gid FROM pg_prepared_xacts ORDER BY gid",
+	},
+	{
+		Problem: "databases that don't allow connections can't be dumped",
+		Query:   "SELECT datname FROM pg_database WHERE NOT datallowconn AND datname <> 'template0' ORDER BY datname",
+	},
+	{
+		Problem: "tablespaces don't exist in the new cluster",
+		Query:   "SELECT spcname FROM pg_tablespace WHERE spcname NOT IN ('pg_default', 'pg_global') ORDER BY spcname",
+	},
+	{
+		Problem: "tables of the postgres database are not moved",
+		Query: "SELECT n.nspname || '.' || c.relname FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace " +
+			"WHERE c.relkind IN ('r', 'p') AND n.nspname NOT IN ('pg_catalog', 'information_schema') " +
+			"AND n.nspname NOT LIKE 'pg_toast%' ORDER BY 1",
+	},
+}
+
+// PreChecks checks that the cluster can be upgraded: it is healthy, the new version is newer, the new cluster
+// doesn't exist or was created by an earlier attempt, and its catalog has nothing the dump would lose or the restore
+// would fail on. It changes nothing.
+func (u *Upgrader) PreChecks() error {
+	data, err := u.X.Query("kubectl", "get", clusterResource, u.Source, "-n", u.Namespace, "-o", "json")
+	if err != nil {
+		return fmt.Errorf("cluster %s not found: %w", u.Source, err)
+	}
+	source, err := parseCluster([]byte(data))
+	if err != nil {
+		return err
+	}
+	// A cluster whose writes were stopped by an earlier attempt isn't reconciled, so its status is stale.
+	if !source.healthy() && source.Metadata.Annotations[reconciliationAnnotation] != "disabled" {
+		return fmt.Errorf("cluster %s is not healthy: %s, %d of %d instances ready", u.Source, source.Status.Phase,
+			source.Status.ReadyInstances, source.Spec.Instances)
+	}
+
+	var errs []error
+	target, err := u.X.Query("kubectl", "get", clusterResource, u.Target, "-n", u.Namespace, "--ignore-not-found",
+		"-o", "json")
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to look up cluster %s: %w", u.Target, err))
+	} else if strings.TrimSpace(target) != "" {
+		existing, err := parseCluster([]byte(target))
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case existing.Metadata.Labels[managedByLabel] != managedBy:
+			errs = append(errs, fmt.Errorf("cluster %s already exists and was not created by the upgrade", u.Target))
+		default:
+			u.Log.Info("cluster of an earlier attempt found, it is reused", "cluster", u.Target)
+		}
+	}
+
+	pod, err := u.primary(u.Source)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	version, err := u.psql(pod, "postgres", "SHOW server_version_num")
+	if err != nil || len(version) == 0 {
+		return errors.Join(append(errs, fmt.Errorf("failed to read the version of %s: %w", u.Source, err))...)
+	}
+	major, err := majorVersion(version[0])
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	if major >= u.Major {
+		errs = append(errs, fmt.Errorf("cluster %s runs PostgreSQL %d, which is not older than %d", u.Source, major,
+			u.Major))
+	}
+	u.Log.Info("source cluster", "cluster", u.Source, "primary", pod, "major", major, "targetMajor", u.Major)
+
+	for _, check := range catalogChecks {
+		objects, err := u.psql(pod, "postgres", check.Query)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(objects) > 0 {
+			errs = append(errs, fmt.Errorf("%s: %s", check.Problem, strings.Join(objects, ", ")))
+		}
+	}
+
+	// The dump doesn't hold the roles, the new cluster creates them with the passwords of their secrets.
+	roles, err := u.psql(pod, "postgres", "SELECT rolname FROM pg_roles WHERE rolname !~ '^pg_' ORDER BY 1")
+	if err != nil {
+		errs = append(errs, err)
+	} else if unmanaged := missing(roles, source.roles()); len(unmanaged) > 0 {
+		errs = append(errs, fmt.Errorf("roles that are not managed by cluster %s don't exist in the new cluster: %s",
+			u.Source, strings.Join(unmanaged, ", ")))
+	}
+
+	extensions, err := u.extensions(pod)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		u.Log.Info("extensions in use", "extensions", extensions)
+	}
+	return errors.Join(errs...)
+}
+
+// extensions returns the extensions installed in the databases of the cluster other than plpgsql, which every
+// database has.
+func (u *Upgrader) extensions(pod string) ([]string, error) {
+	databases, err := u.databases(pod)
+	if err != nil {
+		return nil, err
+	}
+	var extensions []string
+	for _, database := range databases {
+		names, err := u.psql(pod, database, "SELECT extname FROM pg_extension WHERE extname <> 'plpgsql'")
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, names...)
+	}
+	return missing(extensions, nil), nil
+}
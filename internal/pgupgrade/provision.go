@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provision creates the cluster of the new version from the spec of the source cluster, waits for it to be healthy
+// and checks that it runs the new version and offers the extensions the databases use.
+func (u *Upgrader) Provision() error {
+	source, err := u.X.Query("kubectl", "get", clusterResource, u.Source, "-n", u.Namespace, "-o", "json")
+	if err != nil {
+		return fmt.Errorf("failed to read cluster %s: %w", u.Source, err)
+	}
+	manifest, err := targetCluster([]byte(source), u.Target, u.Image)
+	if err != nil {
+		return err
+	}
+	if err := u.X.RunInput(string(manifest), "kubectl", "apply", "-f", "-"); err != nil {
+		return fmt.Errorf("failed to create cluster %s: %w", u.Target, err)
+	}
+	if err := u.waitFor(fmt.Sprintf("cluster %s is healthy", u.Target), func() (bool, error) {
+		data, err := u.X.Query("kubectl", "get", clusterResource, u.Target, "-n", u.Namespace, "-o", "json")
+		if err != nil {
+			return false, err
+		}
+		target, err := parseCluster([]byte(data))
+		return target.healthy(), err
+	}); err != nil {
+		return err
+	}
+
+	pod, err := u.primary(u.Target)
+	if err != nil {
+		return err
+	}
+	version, err := u.psql(pod, "postgres", "SHOW server_version_num")
+	if err != nil || len(version) == 0 {
+		return fmt.Errorf("failed to read the version of %s: %w", u.Target, err)
+	}
+	major, err := majorVersion(version[0])
+	if err != nil {
+		return err
+	}
+	if major != u.Major {
+		return fmt.Errorf("cluster %s runs PostgreSQL %d instead of %d, check the image %s", u.Target, major, u.Major,
+			u.Image)
+	}
+
+	sourcePod, err := u.primary(u.Source)
+	if err != nil {
+		return err
+	}
+	extensions, err := u.extensions(sourcePod)
+	if err != nil {
+		return err
+	}
+	available, err := u.psql(pod, "postgres", "SELECT name FROM pg_available_extensions")
+	if err != nil {
+		return err
+	}
+	if unavailable := missing(extensions, available); len(unavailable) > 0 {
+		return fmt.Errorf("extensions are not available in the image %s: %s", u.Image,
+			strings.Join(unavailable, ", "))
+	}
+	u.Log.Info("cluster provisioned", "cluster", u.Target, "primary", pod, "major", major)
+	return nil
+}
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	// selectorsFile holds the selectors of the services of the source cluster before the upgrade changed them.
+	selectorsFile = "services.json"
+	// fenceLabel is added to the selectors of the services of the source cluster to stop the writes: no pod has it,
+	// so the services have no endpoints.
+	fenceLabel = "edge-orchestrator.intel.com/postgres-upgrade"
+	fenceValue = "fenced"
+)
+
+// serviceSuffixes are the suffixes of the services of a cluster: read-write, read-only and read.
+var serviceSuffixes = []string{"-rw", "-ro", "-r"}
+
+// selectors returns the selectors of the services of the source cluster before the upgrade, saving them on first
+// use so that they can be restored after the upgrade changed them.
+func (u *Upgrader) selectors() (map[string]map[string]string, error) {
+	path := u.backupPath(selectorsFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var selectors map[string]map[string]string
+		if err := json.Unmarshal(data, &selectors); err != nil {
+			return nil, fmt.Errorf("failed to parse the service selectors in %s: %w", path, err)
+		}
+		return selectors, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the service selectors: %w", err)
+	}
+
+	selectors := map[string]map[string]string{}
+	for _, suffix := range serviceSuffixes {
+		name := u.Source + suffix
+		out, err := u.X.Query("kubectl", "get", "service", name, "-n", u.Namespace, "--ignore-not-found",
+			"-o", "jsonpath={.spec.selector}")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service %s: %w", name, err)
+		}
+		if strings.TrimSpace(out) == "" {
+			continue
+		}
+		var selector map[string]string
+		if err := json.Unmarshal([]byte(out), &selector); err != nil {
+			return nil, fmt.Errorf("failed to parse the selector of service %s: %w", name, err)
+		}
+		if selector[fenceLabel] != "" || selector[clusterLabel] != u.Source {
+			return nil, fmt.Errorf("service %s was changed by an earlier attempt and %s is missing, restore its "+
+				"selector by hand", name, path)
+		}
+		selectors[name] = selector
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("cluster %s has no services", u.Source)
+	}
+	if err := u.X.MkdirAll(u.BackupDir, 0o700); err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(selectors, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := u.X.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to save the service selectors: %w", err)
+	}
+	return selectors, nil
+}
+
+// pointServices sets the selectors of the services of the source cluster to select the pods of cluster, or no pod
+// at all if fenced. Releasing the services from the source cluster keeps them when it is deleted.
+func (u *Upgrader) pointServices(cluster string, fenced, release bool) error {
+	selectors, err := u.selectors()
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(selectors)) {
+		selector := map[string]any{}
+		for key, value := range selectors[name] {
+			selector[key] = value
+		}
+		selector[clusterLabel] = cluster
+		// A null removes the label from the selector of the merge patch.
+		selector[fenceLabel] = nil
+		if fenced {
+			selector[fenceLabel] = fenceValue
+		}
+		patch := map[string]any{"spec": map[string]any{"selector": selector}}
+		if release {
+			patch["metadata"] = map[string]any{"ownerReferences": nil}
+		}
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		if err := u.X.Run("kubectl", "patch", "service", name, "-n", u.Namespace, "--type", "merge",
+			"-p", string(data)); err != nil {
+			return fmt.Errorf("failed to patch service %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// reconcile enables or disables the reconciliation of the source cluster by the operator, which would otherwise
+// revert the selectors of its services.
+func (u *Upgrader) reconcile(enabled bool) error {
+	annotation := reconciliationAnnotation + "=disabled"
+	if enabled {
+		annotation = reconciliationAnnotation + "-"
+	}
+	if err := u.X.Run("kubectl", "annotate", clusterResource, u.Source, "-n", u.Namespace, "--overwrite",
+		annotation); err != nil {
+		return fmt.Errorf("failed to annotate cluster %s: %w", u.Source, err)
+	}
+	return nil
+}
+
+// disconnectClients terminates the client connections to the primary of cluster, so that the clients reconnect
+// through the services.
+func (u *Upgrader) disconnectClients(cluster string) error {
+	pod, err := u.primary(cluster)
+	if err != nil {
+		return err
+	}
+	return u.run(pod, "postgres", "SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity "+
+		"WHERE backend_type = 'client backend' AND pid <> pg_backend_pid()")
+}
+
+// SwitchOver points the services of the source cluster, which the clients connect to, to the new cluster. The
+// source cluster is kept, not reconciled and without clients, until it is retired or the switch-over rolled back.
+func (u *Upgrader) SwitchOver() error {
+	if err := u.pointServices(u.Target, false, true); err != nil {
+		return err
+	}
+	u.Log.Info("services switched over", "from", u.Source, "to", u.Target)
+	return nil
+}
+
+// Rollback points the services back to the source cluster and enables its reconciliation again. Writes made to the
+// new cluster since the switch-over are not moved back. The new cluster is kept.
+func (u *Upgrader) Rollback() error {
+	if err := u.pointServices(u.Source, false, false); err != nil {
+		return err
+	}
+	if err := u.reconcile(true); err != nil {
+		return err
+	}
+	if err := u.disconnectClients(u.Target); err != nil {
+		u.Log.Warn("failed to disconnect the clients of the new cluster", "cluster", u.Target, "error", err)
+	}
+	u.Log.Info("switch-over rolled back", "cluster", u.Source)
+	return nil
+}
+
+// Retire deletes the pods and volumes of the source cluster once its services point to the new cluster. The
+// cluster resource itself is kept, without reconciliation, since the deployment may still manage it.
+func (u *Upgrader) Retire() error {
+	name := u.Source + "-rw"
+	out, err := u.X.Query("kubectl", "get", "service", name, "-n", u.Namespace, "-o", "jsonpath={.spec.selector}")
+	if err != nil {
+		return fmt.Errorf("failed to read service %s: %w", name, err)
+	}
+	var selected map[string]string
+	if err := json.Unmarshal([]byte(out), &selected); err != nil || selected[clusterLabel] != u.Target {
+		return fmt.Errorf("service %s doesn't point to cluster %s, switch over before retiring %s", name, u.Target,
+			u.Source)
+	}
+	selector := clusterLabel + "=" + u.Source
+	if err := u.X.Run("kubectl", "delete", "pods", "-n", u.Namespace, "-l", selector, "--ignore-not-found"); err != nil {
+		return fmt.Errorf("failed to delete the pods of cluster %s: %w", u.Source, err)
+	}
+	if err := u.X.Run("kubectl", "delete", "pvc", "-n", u.Namespace, "-l", selector, "--ignore-not-found"); err != nil {
+		return fmt.Errorf("failed to delete the volumes of cluster %s: %w", u.Source, err)
+	}
+	u.Log.Info("cluster retired", "cluster", u.Source)
+	return nil
+}
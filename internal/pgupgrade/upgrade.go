@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pgupgrade upgrades the CloudNativePG cluster of the Orchestrator to a new major version of PostgreSQL by
+// dump and restore into a new cluster: pre-checks of the catalog, provisioning of the new cluster, a dump of the old
+// one with writes stopped, a restore, a verification of the row counts of every table and a switch-over of the
+// services of the old cluster to the new one. The old cluster is kept, with its services pointing to the new one and
+// its reconciliation disabled, until the operator retires it or rolls the switch-over back.
+//
+// It moves a running cluster and is not part of an Orchestrator upgrade, which deletes the cluster with the PostgreSQL
+// applications so that the new release recreates it, and restores its own dump into that cluster (see orchupgrade).
+package pgupgrade
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/cnpg"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+// Phase names, in the order they run.
+const (
+	PhasePreChecks  = "pre-checks"
+	PhaseProvision  = "provision"
+	PhaseDump       = "dump"
+	PhaseRestore    = "restore"
+	PhaseVerify     = "verify"
+	PhaseSwitchOver = "switch-over"
+)
+
+const (
+	clusterResource = "clusters.postgresql.cnpg.io"
+	// clusterLabel selects the pods and volumes of a cluster.
+	clusterLabel = "cnpg.io/cluster"
+	// reconciliationAnnotation disables the reconciliation of a cluster by the operator, so that it doesn't revert
+	// the services the upgrade repoints.
+	reconciliationAnnotation = "cnpg.io/reconciliationLoop"
+	// managedByLabel marks the clusters the upgrade created.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "onprem-postgres-upgrade"
+	// remoteDumpDir is where the dumps are written in the primary pods, on the data volume since they can be large.
+	remoteDumpDir = "/var/lib/postgresql/data/orch-postgres-upgrade"
+)
+
+// DefaultImage returns the PostgreSQL image of CloudNativePG for a major version.
+func DefaultImage(major int) string {
+	return "ghcr.io/cloudnative-pg/postgresql:" + strconv.Itoa(major)
+}
+
+// Config selects the clusters of a major-version upgrade and the PostgreSQL version it moves to.
+type Config struct {
+	// Namespace is the namespace of the clusters.
+	Namespace string
+	// Source is the cluster that is upgraded, Target the cluster that is created for the new version.
+	Source string
+	Target string
+	// Major is the major version of PostgreSQL to upgrade to, Image the PostgreSQL image of that version.
+	Major int
+	Image string
+	// BackupDir holds the dumps, the row counts and the service selectors of the upgrade. The dumps are kept after
+	// the upgrade.
+	BackupDir string
+}
+
+// Upgrader moves the databases of the source cluster to the target cluster in the phases returned by Phases.
+type Upgrader struct {
+	Config
+	// PollInterval is how often the new cluster is checked while waiting for it, Timeout how long it is waited for.
+	// Zero values use the defaults.
+	PollInterval time.Duration
+	Timeout      time.Duration
+
+	X   executor.Executor
+	Log *slog.Logger
+	// Sleep pauses the upgrade, it defaults to time.Sleep.
+	Sleep func(time.Duration)
+}
+
+// Phases returns the upgrade phases, to be run by a steps.Runner. The new cluster is provisioned before the dump
+// so that writes are only stopped for the dump, the restore and the verification.
+func (u *Upgrader) Phases() []steps.Step {
+	return []steps.Step{
+		steps.Logged(&u.Log, PhasePreChecks, "Check that the catalog of the cluster can be moved to the new version",
+			u.PreChecks),
+		steps.Logged(&u.Log, PhaseProvision, "Create the cluster of the new version", u.Provision),
+		steps.Logged(&u.Log, PhaseDump, "Stop the writes to the cluster, count the rows and dump the databases", u.Dump),
+		steps.Logged(&u.Log, PhaseRestore, "Restore the databases to the new cluster", u.Restore),
+		steps.Logged(&u.Log, PhaseVerify, "Verify the row counts of every table in the new cluster", u.Verify),
+		steps.Logged(&u.Log, PhaseSwitchOver, "Point the services of the cluster to the new cluster", u.SwitchOver),
+	}
+}
+
+func (u *Upgrader) backupPath(name string) string {
+	return filepath.Join(u.BackupDir, name)
+}
+
+// waitFor polls condition until it returns true or the timeout passes. Errors returned by condition are retried,
+// since the new cluster is expected to be in flux while it starts.
+func (u *Upgrader) waitFor(description string, condition func() (bool, error)) error {
+	poll := executor.Poll{
+		Interval: cmp.Or(u.PollInterval, 5*time.Second),
+		Timeout:  cmp.Or(u.Timeout, 15*time.Minute),
+		Sleep:    u.Sleep,
+		Log:      u.Log,
+	}
+	return executor.WaitFor(u.X, description, poll, condition)
+}
+
+// primary returns the primary pod of cluster. It is found by the labels of the cluster rather than through its
+// services, since the switch-over points the services of the source cluster to the target.
+func (u *Upgrader) primary(cluster string) (string, error) {
+	return cnpg.Primary(u.X, u.Namespace, cluster)
+}
+
+// exec returns the arguments of kubectl to run a PostgreSQL client in pod as the postgres user, connected over the
+// local socket so that it works while the services of the cluster are repointed.
+func (u *Upgrader) exec(pod, client string, args ...string) []string {
+	return append([]string{"exec", "-n", u.Namespace, pod, "-c", "postgres", "--", client, "-U", "postgres"},
+		args...)
+}
+
+// psql runs query on database in pod and returns its rows, one per line with the columns separated by |.
+func (u *Upgrader) psql(pod, database, query string) ([]string, error) {
+	out, err := u.X.Query("kubectl", u.exec(pod, "psql", "-X", "-d", database, "-At", "-c", query)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s in %s: %w", database, pod, err)
+	}
+	var rows []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rows = append(rows, line)
+		}
+	}
+	return rows, nil
+}
+
+// run runs a statement that changes database in pod.
+func (u *Upgrader) run(pod, database, statement string) error {
+	if err := u.X.Run("kubectl", u.exec(pod, "psql", "-X", "-d", database, "-v", "ON_ERROR_STOP=1",
+		"-c", statement)...); err != nil {
+		return fmt.Errorf("failed to run %q on %s in %s: %w", statement, database, pod, err)
+	}
+	return nil
+}
+
+// databases returns the databases of the cluster that are moved: all but the templates and the postgres database,
+// which the new cluster creates itself.
+func (u *Upgrader) databases(pod string) ([]string, error) {
+	return u.psql(pod, "postgres",
+		"SELECT datname FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres' ORDER BY datname")
+}
+
+// quoteIdent quotes an SQL identifier.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// rowCountsFile holds the row counts of the tables of the source cluster, taken with the dumps.
+const rowCountsFile = "row-counts.json"
+
+// countRowsQuery counts the rows of every table of a database in a single query. Partitioned tables are counted by
+// their partitions.
+const countRowsQuery = `SELECT format('%I.%I', n.nspname, c.relname),
+	(xpath('/row/c/text()', query_to_xml(format('SELECT count(*) AS c FROM %I.%I', n.nspname, c.relname),
+		false, true, '')))[1]::text::bigint
+	FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind = 'r' AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%'
+	ORDER BY 1`
+
+// rowCounts are the row counts of the tables of each database.
+type rowCounts map[string]map[string]int64
+
+func (u *Upgrader) countRows(pod, database string) (map[string]int64, error) {
+	rows, err := u.psql(pod, database, countRowsQuery)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		i := strings.LastIndex(row, "|")
+		if i < 0 {
+			return nil, fmt.Errorf("unexpected row count %q of database %s", row, database)
+		}
+		count, err := strconv.ParseInt(row[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected row count %q of database %s: %w", row, database, err)
+		}
+		counts[row[:i]] = count
+	}
+	return counts, nil
+}
+
+func (u *Upgrader) saveRowCounts(counts rowCounts) error {
+	data, err := json.MarshalIndent(counts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := u.X.WriteFile(u.backupPath(rowCountsFile), data, 0o600); err != nil {
+		return fmt.Errorf("failed to save the row counts: %w", err)
+	}
+	return nil
+}
+
+// loadRowCounts reads the row counts saved by the dump. They are empty when planning, since nothing was dumped.
+func (u *Upgrader) loadRowCounts() (rowCounts, error) {
+	path := u.backupPath(rowCountsFile)
+	var counts rowCounts
+	err := u.X.Apply("read the row counts from "+path, func() error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the row counts of the dump: %w", err)
+		}
+		return json.Unmarshal(data, &counts)
+	})
+	return counts, err
+}
+
+// Verify compares the row counts of every table of the new cluster with those of the source cluster taken with the
+// dumps.
+func (u *Upgrader) Verify() error {
+	want, err := u.loadRowCounts()
+	if err != nil {
+		return err
+	}
+	pod, err := u.primary(u.Target)
+	if err != nil {
+		return err
+	}
+	var mismatches []string
+	for _, database := range slices.Sorted(maps.Keys(want)) {
+		got, err := u.countRows(pod, database)
+		if err != nil {
+			return err
+		}
+		mismatches = append(mismatches, compareRowCounts(database, want[database], got)...)
+		u.Log.Info("row counts verified", "database", database, "tables", len(want[database]))
+	}
+	if len(mismatches) > 0 {
+		return errors.New("the row counts of the new cluster differ: " + strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// compareRowCounts describes the tables of database whose row counts got differ from want.
+func compareRowCounts(database string, want, got map[string]int64) []string {
+	var mismatches []string
+	for _, table := range slices.Sorted(maps.Keys(want)) {
+		count, ok := got[table]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s: table %s is missing", database, table))
+		case count != want[table]:
+			mismatches = append(mismatches, fmt.Sprintf("%s: table %s has %d rows instead of %d", database, table,
+				count, want[table]))
+		}
+	}
+	for _, table := range slices.Sorted(maps.Keys(got)) {
+		if _, ok := want[table]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s: table %s is unexpected", database, table))
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package preflight
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Host gives the checks access to the machine being checked.
+type Host interface {
+	CPUs() int
+	MemoryBytes() (uint64, error)
+	FreeDiskBytes(path string) (uint64, error)
+	BlockDevices() ([]BlockInfo, error)
+	KernelRelease() (string, error)
+	// Module reports whether a kernel module is loaded, or built in, and whether it can be loaded otherwise.
+	Module(name string) (loaded bool, available bool)
+	PortInUse(port int) bool
+	LookupHost(name string) ([]string, error)
+	TimeSynchronized() (bool, error)
+	Getenv(name string) string
+}
+
+// BlockInfo is a block device as reported by lsblk --json.
+type BlockInfo struct {
+	Name        string      `json:"name"`
+	Size        int64       `json:"size"`
+	Type        string      `json:"type"`
+	MountPoints []string    `json:"mountpoints"`
+	Children    []BlockInfo `json:"children,omitempty"`
+}
+
+// ParseLsblk parses the output of lsblk --json --bytes --output NAME,SIZE,TYPE,MOUNTPOINTS.
+func ParseLsblk(data []byte) ([]BlockInfo, error) {
+	var out struct {
+		BlockDevices []BlockInfo `json:"blockdevices"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse lsblk output: %w", err)
+	}
+	return out.BlockDevices, nil
+}
+
+// LocalHost is the machine the checks run on.
+type LocalHost struct{}
+
+var _ Host = LocalHost{}
+
+func (LocalHost) CPUs() int {
+	return runtime.NumCPU()
+}
+
+func (LocalHost) MemoryBytes() (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kib, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse MemTotal: %w", err)
+			}
+			return kib * 1024, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("MemTotal not found in /proc/meminfo")
+}
+
+func (LocalHost) FreeDiskBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil //nolint: gosec
+}
+
+func (LocalHost) BlockDevices() ([]BlockInfo, error) {
+	out, err := exec.Command("lsblk", "--json", "--bytes", "--output", "NAME,SIZE,TYPE,MOUNTPOINTS").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run lsblk: %w", err)
+	}
+	return ParseLsblk(out)
+}
+
+func (LocalHost) KernelRelease() (string, error) {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(release)), nil
+}
+
+func (LocalHost) Module(name string) (bool, bool) {
+	if _, err := os.Stat(filepath.Join("/sys/module", strings.ReplaceAll(name, "-", "_"))); err == nil {
+		return true, true
+	}
+	// Dry run only resolves the module and its dependencies.
+	return false, exec.Command("modprobe", "--dry-run", name).Run() == nil
+}
+
+func (LocalHost) PortInUse(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return true
+	}
+	_ = listener.Close()
+	return false
+}
+
+func (LocalHost) LookupHost(name string) ([]string, error) {
+	return net.LookupHost(name)
+}
+
+func (LocalHost) TimeSynchronized() (bool, error) {
+	out, err := exec.Command("timedatectl", "show", "--property", "NTPSynchronized", "--value").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to run timedatectl: %w", err)
+	}
+	return strings.TrimSpace(string(out)) == "yes", nil
+}
+
+func (LocalHost) Getenv(name string) string {
+	return os.Getenv(name)
+}
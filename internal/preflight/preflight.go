@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package preflight checks whether a host meets the requirements of an on-prem Orchestrator installation profile.
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+const gib = 1 << 30
+
+// Config describes the installation the host is checked for.
+type Config struct {
+	// Profile is the installation profile, i.e. ORCH_INSTALLER_PROFILE.
+	Profile string
+	// ClusterDomain is the Orchestrator domain, e.g. cluster.onprem.
+	ClusterDomain string
+	// ReleaseServiceURL is the registry the installers download artifacts from.
+	ReleaseServiceURL string
+	// DataDir is the filesystem RKE2 and the local persistent volumes are stored on.
+	DataDir string
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Profile      string       `json:"profile"`
+	Requirements Requirements `json:"requirements"`
+	Results      []Result     `json:"results"`
+	Status       Status       `json:"status"`
+}
+
+// Run checks host against the requirements of cfg.Profile.
+func Run(host Host, cfg Config) Report {
+	req := RequirementsFor(cfg.Profile)
+	report := Report{Profile: cfg.Profile, Requirements: req, Status: StatusPass}
+
+	for _, check := range []func(Host, Config, Requirements) []Result{
+		checkCPUs,
+		checkMemory,
+		checkDisk,
+		checkBlockDevices,
+		checkKernel,
+		checkModules,
+		checkPorts,
+		checkDNS,
+		checkTimeSync,
+		checkProxy,
+	} {
+		for _, result := range check(host, cfg, req) {
+			report.Results = append(report.Results, result)
+			if result.Status == StatusFail || (result.Status == StatusWarn && report.Status == StatusPass) {
+				report.Status = result.Status
+			}
+		}
+	}
+	return report
+}
+
+// WriteText writes the report in a human readable form.
+func (r Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Preflight checks for profile %s:\n", r.Profile); err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		if _, err := fmt.Fprintf(w, "  [%s] %-16s %s\n", strings.ToUpper(string(result.Status)), result.Check,
+			result.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "Result: %s\n", strings.ToUpper(string(r.Status)))
+	return err
+}
+
+// WriteJSON writes the report as JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func result(check string, status Status, format string, args ...any) Result {
+	return Result{Check: check, Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+func checkCPUs(host Host, _ Config, req Requirements) []Result {
+	cpus := host.CPUs()
+	if cpus < req.MinCPUs {
+		return []Result{result("cpu", StatusFail, "%d CPUs, at least %d required", cpus, req.MinCPUs)}
+	}
+	return []Result{result("cpu", StatusPass, "%d CPUs", cpus)}
+}
+
+func checkMemory(host Host, _ Config, req Requirements) []Result {
+	memory, err := host.MemoryBytes()
+	if err != nil {
+		return []Result{result("memory", StatusFail, "unable to read memory size: %v", err)}
+	}
+
+	// The kernel reserves part of the installed memory, so a host sized exactly at the minimum reports slightly less.
+	memoryGiB := float64(memory) / gib
+	switch {
+	case memoryGiB < 0.9*float64(req.MinMemoryGiB):
+		return []Result{result("memory", StatusFail, "%.1f GiB, at least %d GiB required", memoryGiB, req.MinMemoryGiB)}
+	case memoryGiB < float64(req.MinMemoryGiB):
+		return []Result{result("memory", StatusWarn, "%.1f GiB, %d GiB recommended", memoryGiB, req.MinMemoryGiB)}
+	default:
+		return []Result{result("memory", StatusPass, "%.1f GiB", memoryGiB)}
+	}
+}
+
+func checkDisk(host Host, cfg Config, req Requirements) []Result {
+	free, err := host.FreeDiskBytes(cfg.DataDir)
+	if err != nil {
+		return []Result{result("disk", StatusFail, "unable to read free space of %s: %v", cfg.DataDir, err)}
+	}
+
+	freeGiB := float64(free) / gib
+	if freeGiB < float64(req.MinDiskGiB) {
+		return []Result{result("disk", StatusFail, "%.1f GiB free on %s, at least %d GiB required",
+			freeGiB, cfg.DataDir, req.MinDiskGiB)}
+	}
+	return []Result{result("disk", StatusPass, "%.1f GiB free on %s", freeGiB, cfg.DataDir)}
+}
+
+func checkBlockDevices(host Host, _ Config, _ Requirements) []Result {
+	devices, err := host.BlockDevices()
+	if err != nil {
+		return []Result{result("block-devices", StatusWarn, "unable to list block devices: %v", err)}
+	}
+
+	var disks []string
+	for _, device := range devices {
+		if device.Type == "disk" {
+			disks = append(disks, fmt.Sprintf("%s (%.1f GiB)", device.Name, float64(device.Size)/gib))
+		}
+	}
+	if len(disks) == 0 {
+		return []Result{result("block-devices", StatusFail, "no disks found")}
+	}
+	return []Result{result("block-devices", StatusPass, "%s", strings.Join(disks, ", "))}
+}
+
+func checkKernel(host Host, _ Config, req Requirements) []Result {
+	release, err := host.KernelRelease()
+	if err != nil {
+		return []Result{result("kernel", StatusFail, "unable to read kernel release: %v", err)}
+	}
+
+	if compareKernel(release, req.MinKernel) < 0 {
+		return []Result{result("kernel", StatusFail, "kernel %s, at least %s required", release, req.MinKernel)}
+	}
+	return []Result{result("kernel", StatusPass, "kernel %s", release)}
+}
+
+// compareKernel compares the MAJOR.MINOR part of two kernel releases, e.g. 5.15.0-91-generic and 5.15.
+func compareKernel(a, b string) int {
+	parse := func(release string) [2]int {
+		var version [2]int
+		parts := strings.SplitN(release, ".", 3)
+		for i := 0; i < len(version) && i < len(parts); i++ {
+			part := parts[i]
+			end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+			if end >= 0 {
+				part = part[:end]
+			}
+			version[i], _ = strconv.Atoi(part)
+		}
+		return version
+	}
+
+	va, vb := parse(a), parse(b)
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func checkModules(host Host, _ Config, req Requirements) []Result {
+	var results []Result
+	for _, module := range req.Modules {
+		loaded, available := host.Module(module)
+		switch {
+		case loaded:
+			results = append(results, result("module", StatusPass, "%s loaded", module))
+		case available:
+			results = append(results, result("module", StatusWarn, "%s not loaded, the installers load it", module))
+		default:
+			results = append(results, result("module", StatusFail, "%s not available", module))
+		}
+	}
+	return results
+}
+
+func checkPorts(host Host, _ Config, req Requirements) []Result {
+	var inUse []string
+	for _, port := range req.Ports {
+		if host.PortInUse(port) {
+			inUse = append(inUse, strconv.Itoa(port))
+		}
+	}
+	if len(inUse) > 0 {
+		return []Result{result("ports", StatusFail, "ports %s are in use", strings.Join(inUse, ", "))}
+	}
+	return []Result{result("ports", StatusPass, "%d required ports are free", len(req.Ports))}
+}
+
+func checkDNS(host Host, cfg Config, _ Requirements) []Result {
+	var results []Result
+
+	if cfg.ReleaseServiceURL != "" {
+		if _, err := host.LookupHost(cfg.ReleaseServiceURL); err != nil {
+			results = append(results, result("dns", StatusFail, "unable to resolve release service %s: %v",
+				cfg.ReleaseServiceURL, err))
+		} else {
+			results = append(results, result("dns", StatusPass, "release service %s resolves", cfg.ReleaseServiceURL))
+		}
+	}
+
+	// The Orchestrator domain usually only resolves once Orchestrator is installed and local DNS has been set up.
+	name := "web-ui." + cfg.ClusterDomain
+	if addrs, err := host.LookupHost(name); err != nil {
+		results = append(results, result("dns", StatusWarn, "%s does not resolve yet, configure DNS for *.%s",
+			name, cfg.ClusterDomain))
+	} else {
+		results = append(results, result("dns", StatusPass, "%s resolves to %s", name, strings.Join(addrs, ", ")))
+	}
+
+	return results
+}
+
+func checkTimeSync(host Host, _ Config, _ Requirements) []Result {
+	synchronized, err := host.TimeSynchronized()
+	switch {
+	case err != nil:
+		return []Result{result("time-sync", StatusWarn, "unable to check time synchronization: %v", err)}
+	case !synchronized:
+		return []Result{result("time-sync", StatusWarn, "system clock is not synchronized, enable NTP")}
+	default:
+		return []Result{result("time-sync", StatusPass, "system clock is synchronized")}
+	}
+}
+
+func checkProxy(host Host, cfg Config, req Requirements) []Result {
+	getenv := func(names ...string) string {
+		for _, name := range names {
+			if value := host.Getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	httpsProxy := getenv("ORCH_HTTPS_PROXY", "https_proxy", "HTTPS_PROXY")
+	noProxy := getenv("ORCH_NO_PROXY", "no_proxy", "NO_PROXY")
+	explicitProxy := req.ExplicitProxy || host.Getenv("ENABLE_EXPLICIT_PROXY") == "true"
+
+	var results []Result
+	switch {
+	case explicitProxy && httpsProxy == "":
+		return []Result{result("proxy", StatusFail, "profile %s requires a proxy but ORCH_HTTPS_PROXY is not set",
+			cfg.Profile)}
+	case explicitProxy:
+		results = append(results, result("proxy", StatusPass, "using proxy %s", httpsProxy))
+	case httpsProxy != "":
+		results = append(results, result("proxy", StatusWarn,
+			"proxy %s is set but profile %s doesn't use an explicit proxy", httpsProxy, cfg.Profile))
+	default:
+		return []Result{result("proxy", StatusPass, "no proxy configured")}
+	}
+
+	if !strings.Contains(noProxy, cfg.ClusterDomain) {
+		results = append(results, result("proxy", StatusWarn, "no_proxy does not include %s", cfg.ClusterDomain))
+	}
+	return results
+}
@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package preflight_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/preflight"
+)
+
+const gib = 1 << 30
+
+type fakeHost struct {
+	cpus         int
+	memory       uint64
+	freeDisk     uint64
+	devices      []preflight.BlockInfo
+	kernel       string
+	modules      map[string][2]bool
+	portsInUse   map[int]bool
+	hosts        map[string][]string
+	synchronized bool
+	env          map[string]string
+}
+
+func (h *fakeHost) CPUs() int                                    { return h.cpus }
+func (h *fakeHost) MemoryBytes() (uint64, error)                 { return h.memory, nil }
+func (h *fakeHost) FreeDiskBytes(string) (uint64, error)         { return h.freeDisk, nil }
+func (h *fakeHost) BlockDevices() ([]preflight.BlockInfo, error) { return h.devices, nil }
+func (h *fakeHost) KernelRelease() (string, error)               { return h.kernel, nil }
+func (h *fakeHost) PortInUse(port int) bool                      { return h.portsInUse[port] }
+func (h *fakeHost) TimeSynchronized() (bool, error)              { return h.synchronized, nil }
+func (h *fakeHost) Getenv(name string) string                    { return h.env[name] }
+
+func (h *fakeHost) Module(name string) (bool, bool) {
+	state, ok := h.modules[name]
+	if !ok {
+		return true, true
+	}
+	return state[0], state[1]
+}
+
+func (h *fakeHost) LookupHost(name string) ([]string, error) {
+	if addrs, ok := h.hosts[name]; ok {
+		return addrs, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+var _ = Describe("Run", func() {
+	var (
+		host *fakeHost
+		cfg  preflight.Config
+	)
+
+	BeforeEach(func() {
+		host = &fakeHost{
+			cpus:     16,
+			memory:   64 * gib,
+			freeDisk: 200 * gib,
+			devices:  []preflight.BlockInfo{{Name: "nvme0n1", Size: 512 * gib, Type: "disk"}},
+			kernel:   "5.15.0-91-generic",
+			hosts: map[string][]string{
+				"registry-rs.edgeorchestration.intel.com": {"10.0.0.1"},
+				"web-ui.cluster.onprem":                   {"10.0.0.2"},
+			},
+			synchronized: true,
+			env:          map[string]string{},
+		}
+		cfg = preflight.Config{
+			Profile:           "onprem",
+			ClusterDomain:     "cluster.onprem",
+			ReleaseServiceURL: "registry-rs.edgeorchestration.intel.com",
+			DataDir:           "/var",
+		}
+	})
+
+	statusOf := func(report preflight.Report, check string) []preflight.Status {
+		var statuses []preflight.Status
+		for _, result := range report.Results {
+			if result.Check == check {
+				statuses = append(statuses, result.Status)
+			}
+		}
+		return statuses
+	}
+
+	It("passes a host meeting all requirements", func() {
+		report := preflight.Run(host, cfg)
+		Expect(report.Status).To(Equal(preflight.StatusPass))
+		for _, result := range report.Results {
+			Expect(result.Status).To(Equal(preflight.StatusPass), result.Check+": "+result.Message)
+		}
+	})
+
+	It("applies the requirements of the profile", func() {
+		cfg.Profile = "onprem-1k"
+		report := preflight.Run(host, cfg)
+		Expect(report.Requirements.MinCPUs).To(Equal(32))
+		Expect(statusOf(report, "cpu")).To(Equal([]preflight.Status{preflight.StatusFail}))
+		Expect(statusOf(report, "disk")).To(Equal([]preflight.Status{preflight.StatusPass}))
+		Expect(report.Status).To(Equal(preflight.StatusFail))
+	})
+
+	It("warns when memory is just below the minimum", func() {
+		host.memory = 62 * gib
+		Expect(statusOf(preflight.Run(host, cfg), "memory")).To(Equal([]preflight.Status{preflight.StatusWarn}))
+
+		host.memory = 32 * gib
+		Expect(statusOf(preflight.Run(host, cfg), "memory")).To(Equal([]preflight.Status{preflight.StatusFail}))
+	})
+
+	It("fails without a disk", func() {
+		host.devices = []preflight.BlockInfo{{Name: "loop0", Type: "loop"}}
+		Expect(statusOf(preflight.Run(host, cfg), "block-devices")).To(Equal([]preflight.Status{preflight.StatusFail}))
+	})
+
+	It("compares kernel releases numerically", func() {
+		host.kernel = "5.4.0-150-generic"
+		Expect(statusOf(preflight.Run(host, cfg), "kernel")).To(Equal([]preflight.Status{preflight.StatusFail}))
+
+		host.kernel = "6.8.0-45-generic"
+		Expect(statusOf(preflight.Run(host, cfg), "kernel")).To(Equal([]preflight.Status{preflight.StatusPass}))
+	})
+
+	It("distinguishes unloaded from unavailable modules", func() {
+		host.modules = map[string][2]bool{"br_netfilter": {false, true}, "dm-mirror": {false, false}}
+		Expect(statusOf(preflight.Run(host, cfg), "module")).To(Equal([]preflight.Status{
+			preflight.StatusPass, preflight.StatusWarn, preflight.StatusPass, preflight.StatusFail,
+		}))
+	})
+
+	It("fails when a required port is in use", func() {
+		host.portsInUse = map[int]bool{6443: true}
+		report := preflight.Run(host, cfg)
+		Expect(statusOf(report, "ports")).To(Equal([]preflight.Status{preflight.StatusFail}))
+		Expect(report.Results).To(ContainElement(HaveField("Message", ContainSubstring("6443"))))
+	})
+
+	It("only warns when the orchestrator domain does not resolve yet", func() {
+		delete(host.hosts, "web-ui.cluster.onprem")
+		Expect(statusOf(preflight.Run(host, cfg), "dns")).To(Equal([]preflight.Status{
+			preflight.StatusPass, preflight.StatusWarn,
+		}))
+
+		delete(host.hosts, "registry-rs.edgeorchestration.intel.com")
+		Expect(statusOf(preflight.Run(host, cfg), "dns")).To(Equal([]preflight.Status{
+			preflight.StatusFail, preflight.StatusWarn,
+		}))
+	})
+
+	It("warns when the clock is not synchronized", func() {
+		host.synchronized = false
+		Expect(statusOf(preflight.Run(host, cfg), "time-sync")).To(Equal([]preflight.Status{preflight.StatusWarn}))
+	})
+
+	Describe("proxy", func() {
+		It("requires a proxy for explicit proxy profiles", func() {
+			cfg.Profile = "onprem-explicit-proxy"
+			Expect(statusOf(preflight.Run(host, cfg), "proxy")).To(Equal([]preflight.Status{preflight.StatusFail}))
+
+			host.env["ORCH_HTTPS_PROXY"] = "http://proxy.example.com:912"
+			host.env["ORCH_NO_PROXY"] = "localhost,.cluster.onprem"
+			Expect(statusOf(preflight.Run(host, cfg), "proxy")).To(Equal([]preflight.Status{preflight.StatusPass}))
+		})
+
+		It("warns when no_proxy misses the cluster domain", func() {
+			host.env["ENABLE_EXPLICIT_PROXY"] = "true"
+			host.env["https_proxy"] = "http://proxy.example.com:912"
+			Expect(statusOf(preflight.Run(host, cfg), "proxy")).To(Equal([]preflight.Status{
+				preflight.StatusPass, preflight.StatusWarn,
+			}))
+		})
+
+		It("warns about a proxy the profile does not use", func() {
+			host.env["HTTPS_PROXY"] = "http://proxy.example.com:912"
+			host.env["NO_PROXY"] = ".cluster.onprem"
+			Expect(statusOf(preflight.Run(host, cfg), "proxy")).To(Equal([]preflight.Status{preflight.StatusWarn}))
+		})
+	})
+
+	Describe("report", func() {
+		It("renders as text", func() {
+			host.synchronized = false
+			var out bytes.Buffer
+			Expect(preflight.Run(host, cfg).WriteText(&out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("[PASS] cpu"))
+			Expect(out.String()).To(ContainSubstring("[WARN] time-sync"))
+			Expect(out.String()).To(HaveSuffix("Result: WARN\n"))
+		})
+
+		It("renders as JSON", func() {
+			var out bytes.Buffer
+			Expect(preflight.Run(host, cfg).WriteJSON(&out)).To(Succeed())
+
+			var report preflight.Report
+			Expect(json.Unmarshal(out.Bytes(), &report)).To(Succeed())
+			Expect(report.Profile).To(Equal("onprem"))
+			Expect(report.Status).To(Equal(preflight.StatusPass))
+			Expect(report.Results).NotTo(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("ParseLsblk", func() {
+	It("parses nested block devices", func() {
+		devices, err := preflight.ParseLsblk([]byte(`{"blockdevices": [
+			{"name": "sda", "size": 536870912000, "type": "disk", "mountpoints": [null],
+			 "children": [{"name": "sda1", "size": 1073741824, "type": "part", "mountpoints": ["/boot"]}]}
+		]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(devices).To(HaveLen(1))
+		Expect(devices[0].Name).To(Equal("sda"))
+		Expect(devices[0].Size).To(Equal(int64(536870912000)))
+		Expect(devices[0].Children[0].MountPoints).To(Equal([]string{"/boot"}))
+	})
+
+	It("rejects invalid output", func() {
+		_, err := preflight.ParseLsblk([]byte("not json"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package preflight
+
+import "strings"
+
+// Requirements are the host resources and settings an installation profile needs.
+type Requirements struct {
+	MinCPUs      int `json:"minCPUs"`
+	MinMemoryGiB int `json:"minMemoryGiB"`
+	MinDiskGiB   int `json:"minDiskGiB"`
+	// MinKernel is the oldest supported kernel as MAJOR.MINOR.
+	MinKernel string   `json:"minKernel"`
+	Modules   []string `json:"modules"`
+	// Ports must be free since RKE2 binds them on the host.
+	Ports []int `json:"ports"`
+	// ExplicitProxy is set for profiles that reach the internet through a proxy.
+	ExplicitProxy bool `json:"explicitProxy"`
+}
+
+// defaultRequirements match the resources a default on-prem Orchestrator is sized for.
+var defaultRequirements = Requirements{
+	MinCPUs:      16,
+	MinMemoryGiB: 64,
+	MinDiskGiB:   140,
+	MinKernel:    "5.15",
+	Modules:      []string{"overlay", "br_netfilter", "dm-snapshot", "dm-mirror"},
+	Ports:        []int{2379, 2380, 6443, 9345, 10250, 10257, 10259},
+}
+
+// RequirementsFor returns the requirements of an installation profile, i.e. the name of a cluster config in
+// orch-configs/clusters.
+func RequirementsFor(profile string) Requirements {
+	req := defaultRequirements
+
+	// Scaled up Edge Infrastructure for 1000 edge nodes.
+	if strings.HasPrefix(profile, "onprem-1k") {
+		req.MinCPUs = 32
+		req.MinMemoryGiB = 128
+		req.MinDiskGiB = 200
+	}
+
+	if strings.Contains(profile, "explicit-proxy") {
+		req.ExplicitProxy = true
+	}
+
+	return req
+}
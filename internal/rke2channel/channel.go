@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rke2channel reads the RKE2 upgrade channel, the file listing the RKE2 versions the on-prem installers can
+// upgrade to, and computes the sequence of upgrades from an installed version to a target.
+package rke2channel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+// SchemaVersion is the channel file format this package reads.
+const SchemaVersion = 1
+
+// Release is a version in the channel.
+type Release struct {
+	Version semver.RKE2Version
+	// Hop is set for versions every upgrade passing them must stop at.
+	Hop bool
+}
+
+// Channel lists the supported RKE2 versions in ascending order.
+type Channel struct {
+	Default  semver.RKE2Version
+	Releases []Release
+}
+
+type channelFile struct {
+	SchemaVersion int    `yaml:"schemaVersion"`
+	Default       string `yaml:"default"`
+	Versions      []struct {
+		Version string `yaml:"version"`
+		Hop     bool   `yaml:"hop"`
+	} `yaml:"versions"`
+}
+
+// Load reads and validates the channel file at path.
+func Load(path string) (*Channel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RKE2 upgrade channel: %w", err)
+	}
+	channel, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RKE2 upgrade channel %s: %w", path, err)
+	}
+	return channel, nil
+}
+
+// Parse parses and validates a channel file.
+func Parse(data []byte) (*Channel, error) {
+	var file channelFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schemaVersion %d, expected %d", file.SchemaVersion, SchemaVersion)
+	}
+	if len(file.Versions) == 0 {
+		return nil, errors.New("no versions listed")
+	}
+
+	channel := &Channel{}
+	for i, entry := range file.Versions {
+		version, err := semver.ParseRKE2(entry.Version)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && !channel.Releases[i-1].Version.LessThan(version) {
+			return nil, fmt.Errorf("versions must be listed in ascending order, %s follows %s", version,
+				channel.Releases[i-1].Version)
+		}
+		channel.Releases = append(channel.Releases, Release{Version: version, Hop: entry.Hop})
+	}
+
+	defaultVersion, err := semver.ParseRKE2(file.Default)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+	if !channel.Supports(defaultVersion) {
+		return nil, fmt.Errorf("default %s is not listed in versions", defaultVersion)
+	}
+	channel.Default = defaultVersion
+
+	return channel, nil
+}
+
+// Supports reports whether version is listed in the channel.
+func (c *Channel) Supports(version semver.RKE2Version) bool {
+	for _, release := range c.Releases {
+		if release.Version.Equal(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// Path returns the versions to upgrade through, in order, to get from current to target: every hop newer than
+// current and older than target, followed by target. The path is empty if current is target.
+func (c *Channel) Path(current, target semver.RKE2Version) ([]semver.RKE2Version, error) {
+	if !c.Supports(target) {
+		return nil, fmt.Errorf("target version %s is not a supported upgrade version, supported versions are %v",
+			target, c.versions())
+	}
+	if target.LessThan(current) {
+		return nil, fmt.Errorf("target version %s is older than the installed version %s", target, current)
+	}
+	if target.Equal(current) {
+		return nil, nil
+	}
+
+	var path []semver.RKE2Version
+	for _, release := range c.Releases {
+		if release.Hop && current.LessThan(release.Version) && release.Version.LessThan(target) {
+			path = append(path, release.Version)
+		}
+	}
+	path = append(path, target)
+
+	// Refuse paths Kubernetes does not support rather than failing halfway through the upgrade.
+	from := current
+	for _, hop := range path {
+		if hop.Major != from.Major || hop.Minor > from.Minor+1 {
+			return nil, fmt.Errorf("no supported path from %s to %s, the channel has no hop for every minor version "+
+				"in between", from, hop)
+		}
+		from = hop
+	}
+
+	return path, nil
+}
+
+func (c *Channel) versions() []string {
+	versions := make([]string, len(c.Releases))
+	for i, release := range c.Releases {
+		versions[i] = release.Version.String()
+	}
+	return versions
+}
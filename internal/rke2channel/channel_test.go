@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rke2channel_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2channel"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+const channelFile = `
+schemaVersion: 1
+default: v1.32.2+rke2r1
+versions:
+  - version: v1.30.14+rke2r2
+    hop: true
+  - version: v1.31.13+rke2r1
+    hop: true
+  - version: v1.31.14+rke2r1
+  - version: v1.32.2+rke2r1
+`
+
+var _ = Describe("Channel", func() {
+	var channel *rke2channel.Channel
+
+	BeforeEach(func() {
+		var err error
+		channel, err = rke2channel.Parse([]byte(channelFile))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	path := func(current, target string) ([]string, error) {
+		hops, err := channel.Path(semver.MustParseRKE2(current), semver.MustParseRKE2(target))
+		var versions []string
+		for _, hop := range hops {
+			versions = append(versions, hop.String())
+		}
+		return versions, err
+	}
+
+	It("parses the default version", func() {
+		Expect(channel.Default.String()).To(Equal("v1.32.2+rke2r1"))
+	})
+
+	DescribeTable("computes the upgrade path",
+		func(current, target string, expected []string) {
+			Expect(path(current, target)).To(Equal(expected))
+		},
+		Entry("through every hop", "v1.30.10+rke2r1", "v1.32.2+rke2r1",
+			[]string{"v1.30.14+rke2r2", "v1.31.13+rke2r1", "v1.32.2+rke2r1"}),
+		Entry("skipping hops already passed", "v1.31.13+rke2r1", "v1.32.2+rke2r1", []string{"v1.32.2+rke2r1"}),
+		Entry("to a version that is not a hop", "v1.30.14+rke2r2", "v1.31.14+rke2r1",
+			[]string{"v1.31.13+rke2r1", "v1.31.14+rke2r1"}),
+		Entry("without a change when already at the target", "v1.31.14+rke2r1", "v1.31.14+rke2r1", nil),
+	)
+
+	It("rejects unsupported targets", func() {
+		_, err := path("v1.30.14+rke2r2", "v1.31.1+rke2r1")
+		Expect(err).To(MatchError(ContainSubstring("not a supported upgrade version")))
+	})
+
+	It("rejects downgrades", func() {
+		_, err := path("v1.32.2+rke2r1", "v1.31.14+rke2r1")
+		Expect(err).To(MatchError(ContainSubstring("older than the installed version")))
+	})
+
+	It("rejects paths skipping a minor version", func() {
+		_, err := path("v1.28.5+rke2r1", "v1.32.2+rke2r1")
+		Expect(err).To(MatchError(ContainSubstring("no supported path from v1.28.5+rke2r1 to v1.30.14+rke2r2")))
+	})
+
+	DescribeTable("rejects invalid channel files",
+		func(data, message string) {
+			_, err := rke2channel.Parse([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("of another schema version", "schemaVersion: 2\n", "unsupported schemaVersion 2"),
+		Entry("without versions", "schemaVersion: 1\n", "no versions listed"),
+		Entry("out of order", "schemaVersion: 1\ndefault: v1.30.1+rke2r1\nversions:\n"+
+			"  - version: v1.31.1+rke2r1\n  - version: v1.30.1+rke2r1\n", "ascending order"),
+		Entry("with an unlisted default", "schemaVersion: 1\ndefault: v1.31.1+rke2r1\nversions:\n"+
+			"  - version: v1.30.1+rke2r1\n", "default v1.31.1+rke2r1 is not listed"),
+	)
+})
+
+var _ = Describe("shipped channel", func() {
+	It("upgrades every supported release to the default", func() {
+		channel, err := rke2channel.Load("../../on-prem-installers/rke2/upgrade-channel.yaml")
+		Expect(err).NotTo(HaveOccurred())
+
+		hops, err := channel.Path(semver.MustParseRKE2("v1.30.10+rke2r1"), channel.Default)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hops).NotTo(BeEmpty())
+		Expect(hops[len(hops)-1].Equal(channel.Default)).To(BeTrue())
+	})
+})
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rke2channel_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRKE2Channel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RKE2 Channel Suite")
+}
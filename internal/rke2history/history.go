@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rke2history records the RKE2 version and the etcd snapshot taken before each upgrade hop, so that a failed
+// or unwanted upgrade can be rolled back.
+package rke2history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is an upgrade hop.
+type Entry struct {
+	// From is the version the cluster ran before the hop.
+	From string `json:"from"`
+	// To is the version the hop upgraded to.
+	To string `json:"to"`
+	// Snapshot is the path of the etcd snapshot taken before the hop.
+	Snapshot string    `json:"snapshot"`
+	Time     time.Time `json:"time"`
+	// Completed is set once every node runs To.
+	Completed bool `json:"completed"`
+}
+
+// History lists the upgrade hops in the order they were started.
+type History struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the history at path. A missing file is an empty history.
+func Load(path string) (*History, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &History{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RKE2 upgrade history: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("invalid RKE2 upgrade history %s: %w", path, err)
+	}
+	return &history, nil
+}
+
+// Save writes the history to path.
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write RKE2 upgrade history: %w", err)
+	}
+	return nil
+}
+
+// Last returns the most recent hop, or nil if there is none.
+func (h *History) Last() *Entry {
+	if len(h.Entries) == 0 {
+		return nil
+	}
+	return &h.Entries[len(h.Entries)-1]
+}
+
+// Pop removes the most recent hop, once it has been rolled back.
+func (h *History) Pop() {
+	if len(h.Entries) > 0 {
+		h.Entries = h.Entries[:len(h.Entries)-1]
+	}
+}
+
+// SnapshotName returns the etcd snapshot name for the hop to version. Snapshot names cannot contain '+'.
+func SnapshotName(to string) string {
+	return "pre-upgrade-" + strings.ReplaceAll(to, "+", "-")
+}
+
+// FindSnapshot returns the path of the newest local snapshot called name in the output of `rke2 etcd-snapshot list`.
+// RKE2 appends the node name and a timestamp to the name of each snapshot.
+func FindSnapshot(list, name string) (string, error) {
+	var (
+		found   string
+		created time.Time
+	)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], name+"-") || !strings.HasPrefix(fields[1], "file://") {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			continue
+		}
+		if found == "" || t.After(created) {
+			found, created = strings.TrimPrefix(fields[1], "file://"), t
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("snapshot %s not found", name)
+	}
+	return found, nil
+}
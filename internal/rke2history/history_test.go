@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rke2history_test
+
+import (
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2history"
+)
+
+var _ = Describe("History", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "state", "rke2-upgrade-history.json")
+	})
+
+	It("starts empty", func() {
+		history, err := rke2history.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history.Last()).To(BeNil())
+	})
+
+	It("round-trips entries and pops the most recent one", func() {
+		history := &rke2history.History{}
+		history.Entries = append(history.Entries,
+			rke2history.Entry{From: "v1.33.5+rke2r1", To: "v1.34.1+rke2r1", Snapshot: "/s/1", Completed: true,
+				Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+			rke2history.Entry{From: "v1.34.1+rke2r1", To: "v1.34.4+rke2r1", Snapshot: "/s/2",
+				Time: time.Date(2026, 1, 2, 4, 4, 5, 0, time.UTC)},
+		)
+		Expect(history.Save(path)).To(Succeed())
+
+		loaded, err := rke2history.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(history))
+		Expect(loaded.Last().To).To(Equal("v1.34.4+rke2r1"))
+
+		loaded.Pop()
+		Expect(loaded.Last().To).To(Equal("v1.34.1+rke2r1"))
+	})
+})
+
+var _ = Describe("FindSnapshot", func() {
+	const list = `Name                                       Location                                                                          Size     Created
+pre-upgrade-v1.34.4-rke2r1-orch-1-1767322000 file:///var/lib/rancher/rke2/server/db/snapshots/pre-upgrade-v1.34.4-rke2r1-orch-1-1767322000 12345678 2026-01-02T03:00:00Z
+pre-upgrade-v1.34.4-rke2r1-orch-1-1767325600 file:///var/lib/rancher/rke2/server/db/snapshots/pre-upgrade-v1.34.4-rke2r1-orch-1-1767325600 12345678 2026-01-02T04:00:00Z
+pre-upgrade-v1.34.4-rke2r1-orch-1-1767329200 s3://bucket/pre-upgrade-v1.34.4-rke2r1-orch-1-1767329200 12345678 2026-01-02T05:00:00Z
+etcd-snapshot-orch-1-1767329200            file:///var/lib/rancher/rke2/server/db/snapshots/etcd-snapshot-orch-1-1767329200 12345678 2026-01-02T05:00:00Z
+`
+
+	It("returns the newest local snapshot with the name", func() {
+		Expect(rke2history.FindSnapshot(list, rke2history.SnapshotName("v1.34.4+rke2r1"))).To(Equal(
+			"/var/lib/rancher/rke2/server/db/snapshots/pre-upgrade-v1.34.4-rke2r1-orch-1-1767325600"))
+	})
+
+	It("fails when there is no such snapshot", func() {
+		_, err := rke2history.FindSnapshot(list, rke2history.SnapshotName("v1.33.5+rke2r1"))
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+})
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Bump kinds supported by Version.Bump.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+	BumpRC    = "rc"
+	BumpDev   = "dev"
+)
+
+var rcIdentifier = regexp.MustCompile(`^rc([1-9][0-9]*)$`)
+
+// Bump returns the next version of the given kind. Build metadata is always dropped.
+//
+//   - major, minor and patch release a pre-release of that kind as is (3.1.0-rc2 minor -> 3.1.0) and otherwise
+//     increment the component, resetting the lower ones (3.1.0 minor -> 3.2.0).
+//   - rc increments the release candidate number (3.1.0-rc1 -> 3.1.0-rc2), turns a development version into the first
+//     release candidate (3.1.0-dev -> 3.1.0-rc1) and starts a patch release candidate from a release
+//     (3.1.0 -> 3.1.1-rc1).
+//   - dev starts the development cycle of the next minor version from a release (3.1.0 -> 3.2.0-dev).
+//
+// The bumped version always has higher precedence than v.
+func (v Version) Bump(kind string) (Version, error) {
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	switch kind {
+	case BumpMajor:
+		if !v.IsPrerelease() || v.Minor != 0 || v.Patch != 0 {
+			next = Version{Major: v.Major + 1}
+		}
+	case BumpMinor:
+		if !v.IsPrerelease() || v.Patch != 0 {
+			next = Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+	case BumpPatch:
+		if !v.IsPrerelease() {
+			next.Patch++
+		}
+	case BumpRC:
+		switch {
+		case !v.IsPrerelease():
+			next.Patch++
+			next.Prerelease = []string{"rc1"}
+		case v.IsDev():
+			next.Prerelease = []string{"rc1"}
+		default:
+			rc, err := v.releaseCandidate()
+			if err != nil {
+				return Version{}, err
+			}
+			next.Prerelease = []string{fmt.Sprintf("rc%d", rc+1)}
+		}
+	case BumpDev:
+		if v.IsPrerelease() {
+			return Version{}, fmt.Errorf("cannot start a development cycle from pre-release %s, release it first", v)
+		}
+		next = Version{Major: v.Major, Minor: v.Minor + 1, Prerelease: []string{"dev"}}
+	default:
+		return Version{}, fmt.Errorf("unknown bump kind %q, expected one of %s, %s, %s, %s or %s",
+			kind, BumpMajor, BumpMinor, BumpPatch, BumpRC, BumpDev)
+	}
+
+	if !v.LessThan(next) {
+		return Version{}, fmt.Errorf("bumping %s of %s does not produce a newer version", kind, v)
+	}
+	return next, nil
+}
+
+// releaseCandidate returns N for a release candidate version X.Y.Z-rcN.
+func (v Version) releaseCandidate() (uint64, error) {
+	if len(v.Prerelease) == 1 {
+		if match := rcIdentifier.FindStringSubmatch(v.Prerelease[0]); match != nil {
+			return strconv.ParseUint(match[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("pre-release %s is not a release candidate of the form X.Y.Z-rcN", v)
+}
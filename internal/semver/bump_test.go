@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package semver_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+var _ = Describe("Bump", func() {
+	DescribeTable("valid bumps",
+		func(current, kind, expected string) {
+			next, err := semver.MustParse(current).Bump(kind)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.String()).To(Equal(expected))
+			Expect(semver.MustParse(current).LessThan(next)).To(BeTrue())
+		},
+		Entry("major of release", "3.1.2", semver.BumpMajor, "4.0.0"),
+		Entry("major of major pre-release", "4.0.0-rc1", semver.BumpMajor, "4.0.0"),
+		Entry("major of minor pre-release", "3.1.0-dev", semver.BumpMajor, "4.0.0"),
+		Entry("minor of release", "3.1.2", semver.BumpMinor, "3.2.0"),
+		Entry("minor of minor pre-release", "2026.1.0-dev", semver.BumpMinor, "2026.1.0"),
+		Entry("minor of patch pre-release", "3.1.1-rc1", semver.BumpMinor, "3.2.0"),
+		Entry("patch of release", "3.1.2", semver.BumpPatch, "3.1.3"),
+		Entry("patch of pre-release", "3.1.0-rc2", semver.BumpPatch, "3.1.0"),
+		Entry("build metadata is dropped", "3.1.2+build.7", semver.BumpPatch, "3.1.3"),
+		Entry("rc of dev", "2026.1.0-dev", semver.BumpRC, "2026.1.0-rc1"),
+		Entry("rc of rc", "3.1.0-rc1", semver.BumpRC, "3.1.0-rc2"),
+		Entry("rc of release", "3.1.0", semver.BumpRC, "3.1.1-rc1"),
+		Entry("dev of release", "3.1.0", semver.BumpDev, "3.2.0-dev"),
+	)
+
+	DescribeTable("invalid bumps",
+		func(current, kind string) {
+			_, err := semver.MustParse(current).Bump(kind)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("unknown kind", "3.1.0", "release"),
+		Entry("dev of dev", "3.1.0-dev", semver.BumpDev),
+		Entry("dev of rc", "3.1.0-rc1", semver.BumpDev),
+		Entry("rc of unknown pre-release", "3.1.0-beta.1", semver.BumpRC),
+		Entry("rc of dotted rc", "3.1.0-rc.1", semver.BumpRC),
+		// rc10 sorts before rc9 since alphanumeric identifiers are compared lexically.
+		Entry("rc past rc9", "3.1.0-rc9", semver.BumpRC),
+	)
+})
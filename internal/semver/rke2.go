@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const rke2BuildPrefix = "rke2r"
+
+// RKE2Version is an RKE2 release version such as v1.30.14+rke2r2. RKE2 encodes its own release revision in the build
+// metadata, so unlike plain semantic versions the revision takes part in ordering.
+type RKE2Version struct {
+	Version
+	Revision uint64
+}
+
+// ParseRKE2 parses an RKE2 release version. The "+rke2rN" build metadata is required; the Kubernetes node
+// kubeletVersion form and the "-rke2rN" form used in URL-safe contexts are both accepted.
+func ParseRKE2(s string) (RKE2Version, error) {
+	str := s
+	if idx := strings.LastIndex(str, "-"+rke2BuildPrefix); idx >= 0 && !strings.Contains(str, "+") {
+		str = str[:idx] + "+" + str[idx+1:]
+	}
+
+	v, err := Parse(str)
+	if err != nil {
+		return RKE2Version{}, fmt.Errorf("invalid RKE2 version: %w", err)
+	}
+	if len(v.Build) != 1 || !strings.HasPrefix(v.Build[0], rke2BuildPrefix) {
+		return RKE2Version{}, fmt.Errorf("invalid RKE2 version %q: expected +%sN build metadata", s, rke2BuildPrefix)
+	}
+
+	revision, err := strconv.ParseUint(strings.TrimPrefix(v.Build[0], rke2BuildPrefix), 10, 64)
+	if err != nil {
+		return RKE2Version{}, fmt.Errorf("invalid RKE2 version %q: invalid revision: %w", s, err)
+	}
+
+	return RKE2Version{Version: v, Revision: revision}, nil
+}
+
+// MustParseRKE2 is like ParseRKE2 but panics if the version cannot be parsed. It is meant for version literals.
+func MustParseRKE2(s string) RKE2Version {
+	v, err := ParseRKE2(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the version in the form used by RKE2 releases and node kubeletVersion, e.g. v1.30.14+rke2r2.
+func (v RKE2Version) String() string {
+	return "v" + v.Version.String()
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, equal to or newer than o. Versions with the same
+// Kubernetes version are ordered by their RKE2 revision.
+func (v RKE2Version) Compare(o RKE2Version) int {
+	if c := v.Version.Compare(o.Version); c != 0 {
+		return c
+	}
+	return compareUint(v.Revision, o.Revision)
+}
+
+// LessThan reports whether v is older than o.
+func (v RKE2Version) LessThan(o RKE2Version) bool {
+	return v.Compare(o) < 0
+}
+
+// Equal reports whether v and o are the same RKE2 release.
+func (v RKE2Version) Equal(o RKE2Version) bool {
+	return v.Compare(o) == 0
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package semver_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+var _ = Describe("ParseRKE2", func() {
+	DescribeTable("valid versions",
+		func(input string, major, minor, patch, revision uint64, canonical string) {
+			v, err := semver.ParseRKE2(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v.Major).To(Equal(major))
+			Expect(v.Minor).To(Equal(minor))
+			Expect(v.Patch).To(Equal(patch))
+			Expect(v.Revision).To(Equal(revision))
+			Expect(v.String()).To(Equal(canonical))
+		},
+		Entry("release", "v1.30.14+rke2r2", uint64(1), uint64(30), uint64(14), uint64(2), "v1.30.14+rke2r2"),
+		Entry("without v", "1.34.4+rke2r1", uint64(1), uint64(34), uint64(4), uint64(1), "v1.34.4+rke2r1"),
+		Entry("hyphenated form", "v1.31.13-rke2r1", uint64(1), uint64(31), uint64(13), uint64(1), "v1.31.13+rke2r1"),
+	)
+
+	DescribeTable("invalid versions",
+		func(input string) {
+			_, err := semver.ParseRKE2(input)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("missing build", "v1.30.14"),
+		Entry("other build", "v1.30.14+k3s1"),
+		Entry("missing revision", "v1.30.14+rke2r"),
+		Entry("non numeric revision", "v1.30.14+rke2rx"),
+		Entry("extra build identifiers", "v1.30.14+rke2r1.1"),
+		Entry("invalid core", "v1.30+rke2r1"),
+	)
+
+	DescribeTable("Compare",
+		func(a, b string, expected int) {
+			Expect(semver.MustParseRKE2(a).Compare(semver.MustParseRKE2(b))).To(Equal(expected))
+			Expect(semver.MustParseRKE2(b).Compare(semver.MustParseRKE2(a))).To(Equal(-expected))
+		},
+		Entry("equal", "v1.30.14+rke2r2", "v1.30.14+rke2r2", 0),
+		Entry("revision", "v1.30.14+rke2r1", "v1.30.14+rke2r2", -1),
+		Entry("patch wins over revision", "v1.30.13+rke2r3", "v1.30.14+rke2r1", -1),
+		Entry("numeric minor", "v1.3.0+rke2r1", "v1.30.0+rke2r1", -1),
+		Entry("hyphenated form is equal", "v1.31.13-rke2r1", "v1.31.13+rke2r1", 0),
+	)
+})
@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package semver parses and orders versions following Semantic Versioning 2.0.0 (https://semver.org).
+package semver
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. The zero value is 0.0.0.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// Parse parses a semantic version. A leading "v" is accepted and dropped, e.g. "v1.2.3-rc.1+build.5".
+func Parse(s string) (Version, error) {
+	var v Version
+
+	str := strings.TrimPrefix(s, "v")
+	if str == "" {
+		return v, fmt.Errorf("invalid version %q: empty version", s)
+	}
+
+	if core, build, found := strings.Cut(str, "+"); found {
+		ids, err := parseIdentifiers(build, false)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q: build metadata: %w", s, err)
+		}
+		v.Build = ids
+		str = core
+	}
+
+	if core, prerelease, found := strings.Cut(str, "-"); found {
+		ids, err := parseIdentifiers(prerelease, true)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q: pre-release: %w", s, err)
+		}
+		v.Prerelease = ids
+		str = core
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+
+	nums := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := parseNumeric(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// MustParse is like Parse but panics if the version cannot be parsed. It is meant for version literals.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the canonical form of the version without a leading "v".
+func (v Version) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		sb.WriteString("-" + strings.Join(v.Prerelease, "."))
+	}
+	if len(v.Build) > 0 {
+		sb.WriteString("+" + strings.Join(v.Build, "."))
+	}
+	return sb.String()
+}
+
+// Compare returns -1, 0 or +1 depending on whether v has lower, equal or higher precedence than o. Build metadata
+// does not take part in precedence.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan reports whether v has lower precedence than o.
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// Equal reports whether v and o are identical, including their build metadata.
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0 && slices.Equal(v.Build, o.Build)
+}
+
+// IsPrerelease reports whether v has pre-release identifiers.
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// IsDev reports whether v is a development version, i.e. one of its pre-release identifiers is "dev" or is a
+// hyphenated identifier with a "dev" component such as "rc1-dev".
+func (v Version) IsDev() bool {
+	for _, id := range v.Prerelease {
+		for _, part := range strings.Split(id, "-") {
+			if part == "dev" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SameMinor reports whether v and o share the same major and minor version.
+func (v Version) SameMinor(o Version) bool {
+	return v.Major == o.Major && v.Minor == o.Minor
+}
+
+func parseNumeric(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty numeric identifier")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("numeric identifier %q has a leading zero", s)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("numeric identifier %q contains non-digit characters", s)
+		}
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func parseIdentifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("empty identifier")
+		}
+		for _, r := range id {
+			if !isIdentifierChar(r) {
+				return nil, fmt.Errorf("identifier %q contains invalid character %q", id, r)
+			}
+		}
+		// Numeric pre-release identifiers must not include leading zeroes, build identifiers may.
+		if prerelease && isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", id)
+		}
+	}
+	return ids, nil
+}
+
+func isIdentifierChar(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-'
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func comparePrerelease(a, b []string) int {
+	// A version without pre-release identifiers has higher precedence than one with them.
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(a)), uint64(len(b)))
+}
+
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		an, _ := strconv.ParseUint(a, 10, 64)
+		bn, _ := strconv.ParseUint(b, 10, 64)
+		return compareUint(an, bn)
+	case aNum:
+		// Numeric identifiers always have lower precedence than alphanumeric identifiers.
+		return -1
+	case bNum:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
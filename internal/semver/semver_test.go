@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package semver_test
+
+import (
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable("valid versions",
+		func(input string, expected semver.Version, canonical string) {
+			v, err := semver.Parse(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(expected))
+			Expect(v.String()).To(Equal(canonical))
+		},
+		Entry("release", "1.2.3", semver.Version{Major: 1, Minor: 2, Patch: 3}, "1.2.3"),
+		Entry("leading v", "v3.1.0", semver.Version{Major: 3, Minor: 1}, "3.1.0"),
+		Entry("zero version", "0.0.0", semver.Version{}, "0.0.0"),
+		Entry("dev pre-release", "2026.1.0-dev",
+			semver.Version{Major: 2026, Minor: 1, Prerelease: []string{"dev"}}, "2026.1.0-dev"),
+		Entry("dotted pre-release", "1.0.0-rc.1",
+			semver.Version{Major: 1, Prerelease: []string{"rc", "1"}}, "1.0.0-rc.1"),
+		Entry("hyphenated pre-release", "3.0.0-rc1-7d763f9",
+			semver.Version{Major: 3, Prerelease: []string{"rc1-7d763f9"}}, "3.0.0-rc1-7d763f9"),
+		Entry("build metadata", "1.0.0+20130313144700",
+			semver.Version{Major: 1, Build: []string{"20130313144700"}}, "1.0.0+20130313144700"),
+		Entry("build metadata with leading zero", "1.0.0+001",
+			semver.Version{Major: 1, Build: []string{"001"}}, "1.0.0+001"),
+		Entry("pre-release and build metadata", "v1.0.0-beta+exp.sha.5114f85",
+			semver.Version{Major: 1, Prerelease: []string{"beta"}, Build: []string{"exp", "sha", "5114f85"}},
+			"1.0.0-beta+exp.sha.5114f85"),
+	)
+
+	DescribeTable("invalid versions",
+		func(input string) {
+			_, err := semver.Parse(input)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("only v", "v"),
+		Entry("missing patch", "1.2"),
+		Entry("too many components", "1.2.3.4"),
+		Entry("leading zero major", "01.2.3"),
+		Entry("leading zero minor", "1.02.3"),
+		Entry("non numeric patch", "1.2.x"),
+		Entry("negative number", "1.-2.3"),
+		Entry("empty pre-release", "1.2.3-"),
+		Entry("empty pre-release identifier", "1.2.3-rc..1"),
+		Entry("leading zero numeric pre-release", "1.2.3-rc.01"),
+		Entry("invalid pre-release character", "1.2.3-rc_1"),
+		Entry("empty build metadata", "1.2.3+"),
+		Entry("invalid build character", "1.2.3+build!"),
+		Entry("whitespace", " 1.2.3"),
+	)
+
+	It("should panic on invalid literals in MustParse", func() {
+		Expect(func() { semver.MustParse("1.2") }).To(Panic())
+	})
+})
+
+var _ = Describe("Compare", func() {
+	DescribeTable("precedence",
+		func(a, b string, expected int) {
+			Expect(semver.MustParse(a).Compare(semver.MustParse(b))).To(Equal(expected))
+			Expect(semver.MustParse(b).Compare(semver.MustParse(a))).To(Equal(-expected))
+		},
+		Entry("equal", "1.2.3", "1.2.3", 0),
+		Entry("major", "1.0.0", "2.0.0", -1),
+		Entry("minor", "2.0.0", "2.1.0", -1),
+		Entry("patch", "2.1.0", "2.1.1", -1),
+		Entry("numeric not lexical minor", "1.3.0", "1.30.0", -1),
+		Entry("numeric not lexical patch", "1.2.9", "1.2.10", -1),
+		Entry("pre-release lower than release", "1.0.0-alpha", "1.0.0", -1),
+		Entry("alphanumeric identifiers compared lexically", "1.0.0-alpha", "1.0.0-beta", -1),
+		Entry("longer identifier set is higher", "1.0.0-alpha", "1.0.0-alpha.1", -1),
+		Entry("numeric lower than alphanumeric", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1),
+		Entry("numeric identifiers compared numerically", "1.0.0-beta.2", "1.0.0-beta.11", -1),
+		Entry("build metadata ignored", "1.0.0+build.1", "1.0.0+build.2", 0),
+		Entry("dev lower than rc", "3.0.0-dev", "3.0.0-rc1", -1),
+	)
+
+	It("should sort the SemVer specification example in order", func() {
+		ordered := []string{
+			"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+			"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+		}
+		versions := []semver.Version{}
+		for i := len(ordered) - 1; i >= 0; i-- {
+			versions = append(versions, semver.MustParse(ordered[i]))
+		}
+
+		sort.Slice(versions, func(i, j int) bool { return versions[i].LessThan(versions[j]) })
+
+		sorted := []string{}
+		for _, v := range versions {
+			sorted = append(sorted, v.String())
+		}
+		Expect(sorted).To(Equal(ordered))
+	})
+})
+
+var _ = Describe("Version helpers", func() {
+	DescribeTable("Equal",
+		func(a, b string, expected bool) {
+			Expect(semver.MustParse(a).Equal(semver.MustParse(b))).To(Equal(expected))
+		},
+		Entry("identical", "1.2.3-rc.1+build.1", "1.2.3-rc.1+build.1", true),
+		Entry("leading v", "v1.2.3", "1.2.3", true),
+		Entry("different build metadata", "1.2.3+build.1", "1.2.3+build.2", false),
+		Entry("different pre-release", "1.2.3-rc.1", "1.2.3-rc.2", false),
+	)
+
+	DescribeTable("IsDev",
+		func(input string, expected bool) {
+			Expect(semver.MustParse(input).IsDev()).To(Equal(expected))
+		},
+		Entry("dev", "2026.1.0-dev", true),
+		Entry("dev with revision", "3.0.0-dev-7d763f9", true),
+		Entry("rc dev", "3.0.0-rc1-dev", true),
+		Entry("release", "3.0.0", false),
+		Entry("rc", "3.0.0-rc1", false),
+		Entry("identifier containing dev", "3.0.0-devel", false),
+		Entry("dev in build metadata", "3.0.0+dev", false),
+	)
+
+	DescribeTable("SameMinor",
+		func(a, b string, expected bool) {
+			Expect(semver.MustParse(a).SameMinor(semver.MustParse(b))).To(Equal(expected))
+		},
+		Entry("same minor", "1.30.1", "1.30.14", true),
+		Entry("prefix of minor", "1.3.0", "1.30.0", false),
+		Entry("different major", "1.30.0", "2.30.0", false),
+	)
+})
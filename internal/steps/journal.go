@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package steps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Status is the state of a step as recorded in the journal.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// StepRecord is the journal entry of a single step.
+type StepRecord struct {
+	Name       string     `json:"name"`
+	Status     Status     `json:"status"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Journal persists the progress of a run so that it can be resumed after a failure. Inputs records the parameters the
+// run was started with; a run can only be resumed with the same inputs.
+type Journal struct {
+	Inputs    map[string]string `json:"inputs"`
+	Steps     []StepRecord      `json:"steps"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+
+	path string
+}
+
+// LoadJournal reads the journal at path. An empty journal is returned if the file does not exist yet.
+func LoadJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+	return j, nil
+}
+
+// Save atomically writes the journal to disk, creating its directory if needed.
+func (j *Journal) Save() error {
+	j.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Record returns the journal entry of the named step, or nil if the step has not been recorded.
+func (j *Journal) Record(name string) *StepRecord {
+	for i := range j.Steps {
+		if j.Steps[i].Name == name {
+			return &j.Steps[i]
+		}
+	}
+	return nil
+}
+
+func (j *Journal) sameInputs(inputs map[string]string) bool {
+	return maps.Equal(j.Inputs, inputs)
+}
+
+// reset starts a new run with the given inputs and steps, all pending.
+func (j *Journal) reset(inputs map[string]string, steps []Step) {
+	j.Inputs = inputs
+	j.Steps = make([]StepRecord, len(steps))
+	for i, step := range steps {
+		j.Steps[i] = StepRecord{Name: step.Name, Status: StatusPending}
+	}
+}
+
+// ensure adds pending records for steps the journal doesn't know about yet, e.g. after the installer was upgraded.
+func (j *Journal) ensure(steps []Step) {
+	records := make([]StepRecord, 0, len(steps))
+	for _, step := range steps {
+		if r := j.Record(step.Name); r != nil {
+			records = append(records, *r)
+		} else {
+			records = append(records, StepRecord{Name: step.Name, Status: StatusPending})
+		}
+	}
+	j.Steps = records
+}
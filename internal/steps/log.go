@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package steps
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Logged returns a step that logs to *log when it starts, completes or fails, with its duration. While it runs, *log
+// is annotated with the step name as phase, so that the step's own logs through it can be told apart.
+func Logged(log **slog.Logger, name, description string, run func() error) Step {
+	return Step{
+		Name:        name,
+		Description: description,
+		Run: func() error {
+			parent := *log
+			*log = parent.With("phase", name)
+			defer func() { *log = parent }()
+
+			start := time.Now()
+			(*log).Info("phase started")
+			if err := run(); err != nil {
+				(*log).Error("phase failed", "error", err, "duration", time.Since(start).Round(time.Second))
+				return err
+			}
+			(*log).Info("phase completed", "duration", time.Since(start).Round(time.Second))
+			return nil
+		},
+	}
+}
+
+// NewLogger returns a logger for the run of tool that writes to stderr at info level, or debug if verbose, and
+// everything to a new JSON file in logDir. It returns the path of the file too.
+func NewLogger(logDir, tool string, verbose bool) (*slog.Logger, string, error) {
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return nil, "", fmt.Errorf("failed to create log directory %s: %w", logDir, err)
+	}
+	path := filepath.Join(logDir, tool+"_"+time.Now().Format("20060102_150405")+".json")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewMultiHandler(
+		slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}),
+		slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)), path, nil
+}
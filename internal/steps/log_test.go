@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package steps_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+var _ = Describe("Logged", func() {
+	It("annotates the logs of the step with its name and restores the logger", func() {
+		var out bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&out, nil))
+		parent := log
+		step := steps.Logged(&log, "backup", "Back up", func() error {
+			log.Info("saving")
+			return errors.New("disk full")
+		})
+
+		Expect(step.Name).To(Equal("backup"))
+		Expect(step.Description).To(Equal("Back up"))
+		Expect(step.Run()).To(MatchError("disk full"))
+		Expect(log).To(BeIdenticalTo(parent))
+		Expect(out.String()).To(ContainSubstring(`msg="phase started" phase=backup`))
+		Expect(out.String()).To(ContainSubstring(`msg=saving phase=backup`))
+		Expect(out.String()).To(ContainSubstring(`msg="phase failed" phase=backup error="disk full"`))
+	})
+})
+
+var _ = Describe("NewLogger", func() {
+	It("logs everything to a JSON file named after the tool", func() {
+		dir := filepath.Join(GinkgoT().TempDir(), "logs")
+		log, path, err := steps.NewLogger(dir, "onprem-upgrade", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Dir(path)).To(Equal(dir))
+		Expect(filepath.Base(path)).To(MatchRegexp(`^onprem-upgrade_\d{8}_\d{6}\.json$`))
+
+		log.Debug("details")
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"msg":"details"`))
+	})
+})
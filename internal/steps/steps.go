@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package steps runs installers as a sequence of named, idempotent steps whose progress is persisted in a journal so
+// that a failed run can be resumed.
+package steps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Step is a single unit of work. Run must be idempotent since a step that failed, or was interrupted, is run again on
+// resume.
+type Step struct {
+	Name        string
+	Description string
+	Run         func() error
+}
+
+// Options select which steps are run. At most one of them may be set; when none is set all steps are run from
+// scratch.
+type Options struct {
+	// Resume runs the steps that have not completed in the previous run.
+	Resume bool
+	// FromStep runs the named step and all steps after it.
+	FromStep string
+	// OnlyStep runs only the named step.
+	OnlyStep string
+}
+
+// Runner runs steps in order and records their progress in Journal.
+type Runner struct {
+	Steps   []Step
+	Journal *Journal
+	// Inputs are the parameters the run depends on. Resuming a run started with different inputs is refused.
+	Inputs map[string]string
+	Out    io.Writer
+}
+
+// Names returns the names of the runner's steps in order.
+func (r *Runner) Names() []string {
+	names := make([]string, len(r.Steps))
+	for i, step := range r.Steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+// Run runs the steps selected by opts and prints a summary of every step's status once done.
+func (r *Runner) Run(opts Options) error {
+	if err := r.prepare(opts); err != nil {
+		return err
+	}
+
+	runErr := r.run(r.selected(opts))
+	r.printSummary()
+	return runErr
+}
+
+// Plan prints the steps selected by opts and runs them without touching the journal. The steps must be built on an
+// executor that only records changes, such as executor.Plan.
+func (r *Runner) Plan(opts Options) error {
+	if err := r.load(opts); err != nil {
+		return err
+	}
+
+	for i, selected := range r.selected(opts) {
+		step := r.Steps[i]
+		if !selected {
+			fmt.Fprintf(r.Out, "[%d/%d] %s: skipped\n", i+1, len(r.Steps), step.Name)
+			continue
+		}
+
+		fmt.Fprintf(r.Out, "[%d/%d] %s: %s\n", i+1, len(r.Steps), step.Name, step.Description)
+		if err := step.Run(); err != nil {
+			return fmt.Errorf("failed to plan step %s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) prepare(opts Options) error {
+	if err := r.load(opts); err != nil {
+		return err
+	}
+	return r.Journal.Save()
+}
+
+// load validates opts and brings the in-memory journal in line with the run they describe.
+func (r *Runner) load(opts Options) error {
+	set := 0
+	for _, isSet := range []bool{opts.Resume, opts.FromStep != "", opts.OnlyStep != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.New("only one of resume, from-step and only-step can be used")
+	}
+
+	for _, name := range []string{opts.FromStep, opts.OnlyStep} {
+		if name != "" && r.index(name) < 0 {
+			return fmt.Errorf("unknown step %q, valid steps are %v", name, r.Names())
+		}
+	}
+
+	switch {
+	case opts.Resume:
+		if len(r.Journal.Steps) == 0 {
+			return errors.New("no previous run found to resume")
+		}
+		if !r.Journal.sameInputs(r.Inputs) {
+			return fmt.Errorf("previous run used different inputs %v, rerun without resume to start over",
+				r.Journal.Inputs)
+		}
+		r.Journal.ensure(r.Steps)
+	case opts.FromStep != "" || opts.OnlyStep != "":
+		if r.Journal.sameInputs(r.Inputs) {
+			r.Journal.ensure(r.Steps)
+		} else {
+			r.Journal.reset(r.Inputs, r.Steps)
+		}
+	default:
+		r.Journal.reset(r.Inputs, r.Steps)
+	}
+	return nil
+}
+
+// selected reports for every step whether it is run.
+func (r *Runner) selected(opts Options) []bool {
+	selected := make([]bool, len(r.Steps))
+	for i, step := range r.Steps {
+		switch {
+		case opts.Resume:
+			selected[i] = r.Journal.Record(step.Name).Status != StatusDone
+		case opts.FromStep != "":
+			selected[i] = i >= r.index(opts.FromStep)
+		case opts.OnlyStep != "":
+			selected[i] = step.Name == opts.OnlyStep
+		default:
+			selected[i] = true
+		}
+	}
+	return selected
+}
+
+func (r *Runner) run(selected []bool) error {
+	for i, step := range r.Steps {
+		record := r.Journal.Record(step.Name)
+
+		if !selected[i] {
+			if record.Status == StatusDone {
+				fmt.Fprintf(r.Out, "[%d/%d] %s: skipped, already done\n", i+1, len(r.Steps), step.Name)
+				continue
+			}
+			// Keep the outcome of an earlier failed attempt so that it is still picked up on resume.
+			if record.Status == StatusFailed {
+				fmt.Fprintf(r.Out, "[%d/%d] %s: skipped, failed previously\n", i+1, len(r.Steps), step.Name)
+				continue
+			}
+			record.Status = StatusSkipped
+			if err := r.Journal.Save(); err != nil {
+				return err
+			}
+			fmt.Fprintf(r.Out, "[%d/%d] %s: skipped\n", i+1, len(r.Steps), step.Name)
+			continue
+		}
+
+		fmt.Fprintf(r.Out, "[%d/%d] %s: %s\n", i+1, len(r.Steps), step.Name, step.Description)
+
+		started := time.Now().UTC()
+		record.Status = StatusRunning
+		record.StartedAt = &started
+		record.FinishedAt = nil
+		record.Error = ""
+		if err := r.Journal.Save(); err != nil {
+			return err
+		}
+
+		stepErr := step.Run()
+
+		finished := time.Now().UTC()
+		record.FinishedAt = &finished
+		if stepErr != nil {
+			record.Status = StatusFailed
+			record.Error = stepErr.Error()
+		} else {
+			record.Status = StatusDone
+		}
+		if err := r.Journal.Save(); err != nil {
+			return err
+		}
+
+		if stepErr != nil {
+			fmt.Fprintf(r.Out, "[%d/%d] %s: failed\n", i+1, len(r.Steps), step.Name)
+			return fmt.Errorf("step %s failed: %w", step.Name, stepErr)
+		}
+		fmt.Fprintf(r.Out, "[%d/%d] %s: done in %s\n", i+1, len(r.Steps), step.Name,
+			finished.Sub(started).Round(time.Second))
+	}
+	return nil
+}
+
+func (r *Runner) printSummary() {
+	fmt.Fprintln(r.Out, "\nStep summary:")
+	for _, step := range r.Steps {
+		record := r.Journal.Record(step.Name)
+		if record.Error != "" && record.Status == StatusFailed {
+			fmt.Fprintf(r.Out, "  %-8s %s: %s\n", record.Status, step.Name, record.Error)
+			continue
+		}
+		fmt.Fprintf(r.Out, "  %-8s %s\n", record.Status, step.Name)
+	}
+}
+
+func (r *Runner) index(name string) int {
+	for i, step := range r.Steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package steps_test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		journalPath string
+		ran         []string
+		failing     map[string]bool
+		inputs      map[string]string
+	)
+
+	newRunner := func() *steps.Runner {
+		journal, err := steps.LoadJournal(journalPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		var stepList []steps.Step
+		for _, name := range []string{"one", "two", "three"} {
+			stepList = append(stepList, steps.Step{
+				Name: name,
+				Run: func() error {
+					ran = append(ran, name)
+					if failing[name] {
+						return fmt.Errorf("%s broke", name)
+					}
+					return nil
+				},
+			})
+		}
+
+		return &steps.Runner{Steps: stepList, Journal: journal, Inputs: inputs, Out: io.Discard}
+	}
+
+	statuses := func() []steps.Status {
+		journal, err := steps.LoadJournal(journalPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		var statuses []steps.Status
+		for _, record := range journal.Steps {
+			statuses = append(statuses, record.Status)
+		}
+		return statuses
+	}
+
+	BeforeEach(func() {
+		journalPath = filepath.Join(GinkgoT().TempDir(), "state", "journal.json")
+		ran = nil
+		failing = map[string]bool{}
+		inputs = map[string]string{"profile": "onprem"}
+	})
+
+	It("should run all steps and record them as done", func() {
+		Expect(newRunner().Run(steps.Options{})).To(Succeed())
+		Expect(ran).To(Equal([]string{"one", "two", "three"}))
+		Expect(statuses()).To(Equal([]steps.Status{steps.StatusDone, steps.StatusDone, steps.StatusDone}))
+	})
+
+	It("should stop at the first failing step and record the failure", func() {
+		failing["two"] = true
+
+		Expect(newRunner().Run(steps.Options{})).To(MatchError(ContainSubstring("step two failed: two broke")))
+		Expect(ran).To(Equal([]string{"one", "two"}))
+		Expect(statuses()).To(Equal([]steps.Status{steps.StatusDone, steps.StatusFailed, steps.StatusPending}))
+
+		journal, err := steps.LoadJournal(journalPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(journal.Record("two").Error).To(Equal("two broke"))
+	})
+
+	It("should resume from the failed step", func() {
+		failing["two"] = true
+		Expect(newRunner().Run(steps.Options{})).NotTo(Succeed())
+
+		ran = nil
+		failing["two"] = false
+		Expect(newRunner().Run(steps.Options{Resume: true})).To(Succeed())
+		Expect(ran).To(Equal([]string{"two", "three"}))
+		Expect(statuses()).To(Equal([]steps.Status{steps.StatusDone, steps.StatusDone, steps.StatusDone}))
+	})
+
+	It("should refuse to resume without a previous run", func() {
+		Expect(newRunner().Run(steps.Options{Resume: true})).To(MatchError(ContainSubstring("no previous run")))
+	})
+
+	It("should refuse to resume a run started with different inputs", func() {
+		failing["one"] = true
+		Expect(newRunner().Run(steps.Options{})).NotTo(Succeed())
+
+		inputs = map[string]string{"profile": "onprem-oxm"}
+		Expect(newRunner().Run(steps.Options{Resume: true})).To(MatchError(ContainSubstring("different inputs")))
+	})
+
+	It("should run from the given step and skip the ones before it", func() {
+		Expect(newRunner().Run(steps.Options{FromStep: "two"})).To(Succeed())
+		Expect(ran).To(Equal([]string{"two", "three"}))
+		Expect(statuses()).To(Equal([]steps.Status{steps.StatusSkipped, steps.StatusDone, steps.StatusDone}))
+	})
+
+	It("should run only the given step", func() {
+		failing["three"] = true
+		Expect(newRunner().Run(steps.Options{})).NotTo(Succeed())
+
+		ran = nil
+		Expect(newRunner().Run(steps.Options{OnlyStep: "two"})).To(Succeed())
+		Expect(ran).To(Equal([]string{"two"}))
+		Expect(statuses()).To(Equal([]steps.Status{steps.StatusDone, steps.StatusDone, steps.StatusFailed}))
+	})
+
+	It("should plan the selected steps without writing the journal", func() {
+		Expect(newRunner().Plan(steps.Options{FromStep: "two"})).To(Succeed())
+		Expect(ran).To(Equal([]string{"two", "three"}))
+		Expect(journalPath).NotTo(BeAnExistingFile())
+	})
+
+	DescribeTable("should reject invalid options",
+		func(opts steps.Options, expected string) {
+			Expect(newRunner().Run(opts)).To(MatchError(ContainSubstring(expected)))
+			Expect(ran).To(BeEmpty())
+		},
+		Entry("unknown from-step", steps.Options{FromStep: "four"}, `unknown step "four"`),
+		Entry("unknown only-step", steps.Options{OnlyStep: "four"}, `unknown step "four"`),
+		Entry("conflicting options", steps.Options{Resume: true, OnlyStep: "one"}, "only one of"),
+	)
+})
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package systemd renders the settings of the systemd units the on-prem tools install.
+package systemd
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// commandEscaper escapes the specifiers and the variables systemd expands in ExecStart.
+	commandEscaper = strings.NewReplacer("%", "%%", "$", "$$")
+	// environmentEscaper escapes the specifiers only, since systemd doesn't expand variables in Environment.
+	environmentEscaper = strings.NewReplacer("%", "%%")
+)
+
+// Quote escapes the specifiers and variables of systemd in an argument of an ExecStart setting and quotes it if it
+// has spaces or quotes.
+func Quote(arg string) string {
+	return quote(commandEscaper.Replace(arg))
+}
+
+func quote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	return strconv.Quote(arg)
+}
+
+// Command returns the value of an ExecStart setting running the command with its arguments.
+func Command(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, Quote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Environment returns the values of the Environment settings of env, sorted by name.
+func Environment(env map[string]string) []string {
+	var settings []string
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		settings = append(settings, quote(environmentEscaper.Replace(name+"="+env[name])))
+	}
+	return settings
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package systemd_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/systemd"
+)
+
+var _ = Describe("Unit settings", func() {
+	It("leaves plain arguments alone", func() {
+		Expect(systemd.Quote("/usr/bin/onprem-backup")).To(Equal("/usr/bin/onprem-backup"))
+	})
+
+	It("escapes specifiers and variables and quotes arguments with spaces or quotes", func() {
+		Expect(systemd.Quote("50%")).To(Equal("50%%"))
+		Expect(systemd.Quote("$HOME")).To(Equal("$$HOME"))
+		Expect(systemd.Quote("a b")).To(Equal(`"a b"`))
+		Expect(systemd.Quote(`say "hi"`)).To(Equal(`"say \"hi\""`))
+		Expect(systemd.Quote("")).To(Equal(`""`))
+	})
+
+	It("renders commands and sorted environments", func() {
+		Expect(systemd.Command([]string{"/usr/bin/onprem-backup", "--dir", "/mnt/my backups"})).
+			To(Equal(`/usr/bin/onprem-backup --dir "/mnt/my backups"`))
+		Expect(systemd.Environment(map[string]string{"B": "2", "A": "1 2"})).To(Equal([]string{`"A=1 2"`, "B=2"}))
+	})
+
+	It("keeps variables in environment values, which systemd doesn't expand", func() {
+		Expect(systemd.Environment(map[string]string{"PASSWORD": "pa$s", "QUOTA": "50%"})).
+			To(Equal([]string{"PASSWORD=pa$s", "QUOTA=50%%"}))
+	})
+})
@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package uninstall records the resources the on-prem installers create in an inventory and removes them in reverse
+// dependency order, reporting what was left behind.
+package uninstall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+// InventoryPath is the inventory of an on-prem installation, relative to the root filesystem. It is kept out of
+// /var/lib/orch-installer, which the orch-installer package removes.
+const InventoryPath = "var/lib/orch-uninstall/inventory.json"
+
+// Kind is the kind of a resource.
+type Kind string
+
+const (
+	// KindPackage is a DEB package, purged with dpkg.
+	KindPackage Kind = "package"
+	// KindRKE2 is the RKE2 installation, removed by its uninstall script.
+	KindRKE2 Kind = "rke2"
+	// KindSysctl is a sysctl.d or modules-load.d drop-in file. The names of files and directories are absolute paths.
+	KindSysctl Kind = "sysctl"
+	// KindDirectory is a directory such as an OpenEBS hostpath directory.
+	KindDirectory Kind = "directory"
+	// KindFile is any other file.
+	KindFile Kind = "file"
+	// KindSystemdUnit is a unit file in /etc/systemd/system.
+	KindSystemdUnit Kind = "systemd-unit"
+	// KindNamespace is a Kubernetes namespace.
+	KindNamespace Kind = "namespace"
+	// KindCRD is a Kubernetes custom resource definition.
+	KindCRD Kind = "crd"
+	// KindLibvirtNetwork is a libvirt network.
+	KindLibvirtNetwork Kind = "libvirt-network"
+	// KindLibvirtPool is a libvirt storage pool.
+	KindLibvirtPool Kind = "libvirt-pool"
+	// KindHostsEntry is a line of /etc/hosts, e.g. "192.168.1.10 web-ui.cluster.onprem".
+	KindHostsEntry Kind = "hosts-entry"
+)
+
+// Kinds are the kinds of resources in removal order: the units that use the cluster first, then the Kubernetes
+// resources while the cluster is up, the packages and RKE2, and the host resources last.
+var Kinds = []Kind{
+	KindSystemdUnit,
+	KindNamespace,
+	KindCRD,
+	KindPackage,
+	KindRKE2,
+	KindHostsEntry,
+	KindLibvirtNetwork,
+	KindLibvirtPool,
+	KindDirectory,
+	KindSysctl,
+	KindFile,
+}
+
+// ParseKind returns the kind of a name.
+func ParseKind(name string) (Kind, error) {
+	if !slices.Contains(Kinds, Kind(name)) {
+		return "", fmt.Errorf("unknown resource kind %q, expected one of %v", name, Kinds)
+	}
+	return Kind(name), nil
+}
+
+// Resource is a resource created by an installer.
+type Resource struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+	// Data marks the resources that hold the data of the Orchestrator, which are kept when uninstalling with KeepData.
+	Data bool `json:"data,omitempty"`
+}
+
+func (r Resource) String() string {
+	return string(r.Kind) + " " + r.Name
+}
+
+// Inventory is the list of resources created by the installers, in the order they were recorded.
+type Inventory struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Load reads the inventory at path. ok is false if there is no inventory.
+func Load(path string) (inv *Inventory, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Inventory{}, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading inventory: %w", err)
+	}
+	inv = &Inventory{}
+	if err := json.Unmarshal(data, inv); err != nil {
+		return nil, false, fmt.Errorf("parsing inventory %s: %w", path, err)
+	}
+	return inv, true, nil
+}
+
+// Add adds the resources that aren't in the inventory yet. A resource recorded again as data stays data.
+func (inv *Inventory) Add(resources ...Resource) {
+	for _, r := range resources {
+		i := slices.IndexFunc(inv.Resources, func(existing Resource) bool {
+			return existing.Kind == r.Kind && existing.Name == r.Name
+		})
+		if i < 0 {
+			inv.Resources = append(inv.Resources, r)
+			continue
+		}
+		inv.Resources[i].Data = inv.Resources[i].Data || r.Data
+	}
+}
+
+// Remove removes a resource from the inventory.
+func (inv *Inventory) Remove(r Resource) {
+	inv.Resources = slices.DeleteFunc(inv.Resources, func(existing Resource) bool {
+		return existing.Kind == r.Kind && existing.Name == r.Name
+	})
+}
+
+// Save writes the inventory to path, or removes it if it is empty.
+func (inv *Inventory) Save(x executor.Executor, path string) error {
+	if len(inv.Resources) == 0 {
+		return x.RemoveAll(path)
+	}
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := x.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := x.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing inventory: %w", err)
+	}
+	return nil
+}
+
+// Record adds resources to the inventory at path.
+func Record(x executor.Executor, path string, resources ...Resource) error {
+	inv, _, err := Load(path)
+	if err != nil {
+		return err
+	}
+	inv.Add(resources...)
+	return inv.Save(x, path)
+}
+
+// Legacy returns the resources of an on-prem installation by installers that didn't record an inventory.
+func Legacy() *Inventory {
+	inv := &Inventory{}
+	for _, pkg := range []string{
+		"onprem-config-installer",
+		"onprem-ke-installer",
+		"onprem-argocd-installer",
+		"onprem-gitea-installer",
+		"onprem-orch-installer",
+	} {
+		inv.Add(Resource{Kind: KindPackage, Name: pkg})
+	}
+	inv.Add(
+		Resource{Kind: KindRKE2, Name: "rke2"},
+		Resource{Kind: KindSysctl, Name: "/etc/sysctl.d/99-orchestrator.conf"},
+		Resource{Kind: KindSysctl, Name: "/etc/modules-load.d/99-orchestrator.conf"},
+		Resource{Kind: KindFile, Name: "/etc/default/rke2-server"},
+		Resource{Kind: KindDirectory, Name: "/var/openebs", Data: true},
+	)
+	return inv
+}
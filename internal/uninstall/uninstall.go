@@ -0,0 +1,289 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package uninstall
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+)
+
+const (
+	rke2KillAllScript   = "usr/local/bin/rke2-killall.sh"
+	rke2UninstallScript = "usr/local/bin/rke2-uninstall.sh"
+	unitDir             = "etc/systemd/system"
+	hostsFile           = "etc/hosts"
+	libvirtURI          = "qemu:///system"
+)
+
+// Uninstaller removes the resources of an inventory.
+type Uninstaller struct {
+	// Root is the root filesystem, / unless testing.
+	Root string
+	Exec executor.Executor
+	Out  io.Writer
+	// KeepData keeps the resources that hold data, and the namespaces with persistent volume claims since deleting
+	// them deletes their volumes.
+	KeepData bool
+	// Kinds limits the removal to resources of these kinds, all kinds if empty.
+	Kinds []Kind
+}
+
+// Failure is a resource that couldn't be removed.
+type Failure struct {
+	Resource Resource
+	Err      error
+}
+
+// Report is the outcome of an uninstallation.
+type Report struct {
+	Removed []Resource
+	Kept    []Resource
+	Failed  []Failure
+	// Leftovers are the resources that still exist after the uninstallation, other than the kept ones.
+	Leftovers []Resource
+}
+
+// Err returns an error if resources failed to be removed or were left behind.
+func (r Report) Err() error {
+	if len(r.Failed) == 0 && len(r.Leftovers) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d resources failed to be removed, %d left behind", len(r.Failed), len(r.Leftovers))
+}
+
+// Print writes the report to w.
+func (r Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Removed %d resources\n", len(r.Removed))
+	if len(r.Kept) > 0 {
+		fmt.Fprintf(w, "Kept %d resources with data:\n", len(r.Kept))
+		for _, resource := range r.Kept {
+			fmt.Fprintf(w, "  %s\n", resource)
+		}
+	}
+	if len(r.Failed) > 0 {
+		fmt.Fprintf(w, "Failed to remove %d resources:\n", len(r.Failed))
+		for _, failure := range r.Failed {
+			fmt.Fprintf(w, "  %s: %v\n", failure.Resource, failure.Err)
+		}
+	}
+	if len(r.Leftovers) > 0 {
+		fmt.Fprintf(w, "Left behind %d resources:\n", len(r.Leftovers))
+		for _, resource := range r.Leftovers {
+			fmt.Fprintf(w, "  %s\n", resource)
+		}
+	}
+}
+
+// Run removes the resources of the inventory in the order of Kinds, the most recently recorded first within a kind,
+// and saves the inventory at path after every removal so that an interrupted uninstallation can be run again. A
+// resource that fails to be removed doesn't stop the uninstallation. The removed resources that still exist
+// afterwards are reported as leftovers.
+func (u Uninstaller) Run(inv *Inventory, path string) (Report, error) {
+	var report Report
+	for _, kind := range Kinds {
+		if len(u.Kinds) > 0 && !slices.Contains(u.Kinds, kind) {
+			continue
+		}
+		for i := len(inv.Resources) - 1; i >= 0; i-- {
+			resource := inv.Resources[i]
+			if resource.Kind != kind {
+				continue
+			}
+			keep, err := u.keep(resource)
+			if err != nil {
+				report.Failed = append(report.Failed, Failure{Resource: resource, Err: err})
+				continue
+			}
+			if keep {
+				fmt.Fprintf(u.Out, "Keeping %s\n", resource)
+				report.Kept = append(report.Kept, resource)
+				continue
+			}
+
+			fmt.Fprintf(u.Out, "Removing %s\n", resource)
+			if err := u.remove(resource); err != nil {
+				fmt.Fprintf(u.Out, "Warning: failed to remove %s: %v\n", resource, err)
+				report.Failed = append(report.Failed, Failure{Resource: resource, Err: err})
+				continue
+			}
+			report.Removed = append(report.Removed, resource)
+			inv.Remove(resource)
+			if err := inv.Save(u.Exec, path); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	for _, resource := range append(slices.Clone(report.Removed), failedResources(report.Failed)...) {
+		exists, err := u.exists(resource)
+		if err != nil || exists {
+			report.Leftovers = append(report.Leftovers, resource)
+		}
+	}
+	// Leftovers stay in the inventory for the next run.
+	inv.Add(report.Leftovers...)
+	if err := inv.Save(u.Exec, path); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func failedResources(failures []Failure) []Resource {
+	var resources []Resource
+	for _, failure := range failures {
+		resources = append(resources, failure.Resource)
+	}
+	return resources
+}
+
+// keep returns whether a resource is kept to keep the data.
+func (u Uninstaller) keep(r Resource) (bool, error) {
+	if !u.KeepData {
+		return false, nil
+	}
+	if r.Data {
+		return true, nil
+	}
+	if r.Kind != KindNamespace {
+		return false, nil
+	}
+	exists, err := u.exists(r)
+	if err != nil || !exists {
+		return false, err
+	}
+	claims, err := u.Exec.Query("kubectl", "get", "pvc", "-n", r.Name, "-o", "name")
+	if err != nil {
+		return false, fmt.Errorf("listing persistent volume claims of %s: %w", r.Name, err)
+	}
+	return strings.TrimSpace(claims) != "", nil
+}
+
+// exists returns whether a resource exists. Kubernetes resources don't exist if the cluster is gone.
+func (u Uninstaller) exists(r Resource) (bool, error) {
+	switch r.Kind {
+	case KindPackage:
+		status, err := u.Exec.Query("dpkg-query", "-W", "-f=${Status}", r.Name)
+		return err == nil && strings.HasSuffix(strings.TrimSpace(status), " installed"), nil
+	case KindRKE2:
+		return u.fileExists(rke2UninstallScript)
+	case KindSysctl, KindDirectory, KindFile:
+		return u.fileExists(r.Name)
+	case KindSystemdUnit:
+		return u.fileExists(filepath.Join(unitDir, r.Name))
+	case KindNamespace, KindCRD:
+		out, err := u.Exec.Query("kubectl", "get", kubernetesKind(r.Kind), r.Name, "--ignore-not-found", "-o", "name")
+		return err == nil && strings.TrimSpace(out) != "", nil
+	case KindLibvirtNetwork:
+		_, err := u.Exec.Query("virsh", "-c", libvirtURI, "net-info", r.Name)
+		return err == nil, nil
+	case KindLibvirtPool:
+		_, err := u.Exec.Query("virsh", "-c", libvirtURI, "pool-info", r.Name)
+		return err == nil, nil
+	case KindHostsEntry:
+		lines, err := u.hostsLines()
+		if err != nil {
+			return false, err
+		}
+		return slices.ContainsFunc(lines, func(line string) bool { return sameHostsEntry(line, r.Name) }), nil
+	}
+	return false, fmt.Errorf("unknown resource kind %q", r.Kind)
+}
+
+// remove removes a resource, doing nothing if it doesn't exist.
+func (u Uninstaller) remove(r Resource) error {
+	exists, err := u.exists(r)
+	if err != nil || !exists {
+		return err
+	}
+
+	switch r.Kind {
+	case KindPackage:
+		return u.Exec.Run("dpkg", "--purge", "--force-remove-reinstreq", r.Name)
+	case KindRKE2:
+		if err := u.Exec.Run(u.path(rke2KillAllScript)); err != nil {
+			fmt.Fprintf(u.Out, "Warning: failed to stop RKE2: %v\n", err)
+		}
+		return u.Exec.Run(u.path(rke2UninstallScript))
+	case KindSysctl:
+		if err := u.Exec.RemoveAll(u.path(r.Name)); err != nil {
+			return err
+		}
+		// Nothing reverts the live values of the removed file, reloading at least applies the remaining settings.
+		return u.Exec.Run("sysctl", "--system")
+	case KindDirectory, KindFile:
+		return u.Exec.RemoveAll(u.path(r.Name))
+	case KindSystemdUnit:
+		if err := u.Exec.Run("systemctl", "disable", "--now", r.Name); err != nil {
+			fmt.Fprintf(u.Out, "Warning: failed to stop %s: %v\n", r.Name, err)
+		}
+		if err := u.Exec.RemoveAll(u.path(filepath.Join(unitDir, r.Name))); err != nil {
+			return err
+		}
+		return u.Exec.Run("systemctl", "daemon-reload")
+	case KindNamespace, KindCRD:
+		return u.Exec.Run("kubectl", "delete", kubernetesKind(r.Kind), r.Name, "--ignore-not-found", "--timeout=10m")
+	case KindLibvirtNetwork:
+		// An inactive network can't be destroyed, only undefined.
+		_ = u.Exec.Run("virsh", "-c", libvirtURI, "net-destroy", r.Name)
+		return u.Exec.Run("virsh", "-c", libvirtURI, "net-undefine", r.Name)
+	case KindLibvirtPool:
+		_ = u.Exec.Run("virsh", "-c", libvirtURI, "pool-destroy", r.Name)
+		if err := u.Exec.Run("virsh", "-c", libvirtURI, "pool-delete", r.Name); err != nil {
+			fmt.Fprintf(u.Out, "Warning: failed to delete the volumes of pool %s: %v\n", r.Name, err)
+		}
+		return u.Exec.Run("virsh", "-c", libvirtURI, "pool-undefine", r.Name)
+	case KindHostsEntry:
+		lines, err := u.hostsLines()
+		if err != nil {
+			return err
+		}
+		lines = slices.DeleteFunc(lines, func(line string) bool { return sameHostsEntry(line, r.Name) })
+		return u.Exec.WriteFile(u.path(hostsFile), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+	}
+	return fmt.Errorf("unknown resource kind %q", r.Kind)
+}
+
+func (u Uninstaller) path(rel string) string {
+	return filepath.Join(u.Root, rel)
+}
+
+func (u Uninstaller) fileExists(rel string) (bool, error) {
+	_, err := os.Lstat(u.path(rel))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (u Uninstaller) hostsLines() ([]string, error) {
+	data, err := os.ReadFile(u.path(hostsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", hostsFile, err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+// sameHostsEntry returns whether a line of /etc/hosts is the entry, ignoring whitespace.
+func sameHostsEntry(line, entry string) bool {
+	return slices.Equal(strings.Fields(line), strings.Fields(entry))
+}
+
+func kubernetesKind(kind Kind) string {
+	if kind == KindCRD {
+		return "customresourcedefinition"
+	}
+	return string(kind)
+}
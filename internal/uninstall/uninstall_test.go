@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package uninstall_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
+)
+
+// host applies file changes to the temporary root, answers queries from the state of a fake host and records the
+// commands that change it.
+type host struct {
+	executor.Local
+	packages   map[string]bool
+	namespaces map[string]bool
+	claims     map[string]bool
+	networks   map[string]bool
+	commands   []string
+	// failing are the commands that fail.
+	failing []string
+}
+
+func (h *host) Run(name string, args ...string) error {
+	command := strings.Join(append([]string{name}, args...), " ")
+	h.commands = append(h.commands, command)
+	if slices.Contains(h.failing, command) {
+		return fmt.Errorf("%s failed", command)
+	}
+	switch {
+	case name == "dpkg":
+		delete(h.packages, args[len(args)-1])
+	case name == "kubectl" && args[1] == "namespace":
+		delete(h.namespaces, args[2])
+	case name == "virsh" && args[2] == "net-undefine":
+		delete(h.networks, args[3])
+	case strings.HasSuffix(name, "rke2-uninstall.sh"):
+		return os.Remove(name)
+	}
+	return nil
+}
+
+func (h *host) Query(name string, args ...string) (string, error) {
+	switch {
+	case name == "dpkg-query" && h.packages[args[len(args)-1]]:
+		return "install ok installed", nil
+	case name == "dpkg-query":
+		return "", fmt.Errorf("package %s not found", args[len(args)-1])
+	case name == "kubectl" && args[1] == "namespace" && h.namespaces[args[2]]:
+		return "namespace/" + args[2], nil
+	case name == "kubectl" && args[1] == "pvc" && h.claims[args[3]]:
+		return "persistentvolumeclaim/data", nil
+	case name == "kubectl":
+		return "", nil
+	case name == "virsh" && h.networks[args[3]]:
+		return "Name: " + args[3], nil
+	}
+	return "", fmt.Errorf("%s %v: not found", name, args)
+}
+
+var _ = Describe("Uninstaller", func() {
+	var (
+		root        string
+		h           *host
+		uninstaller uninstall.Uninstaller
+		inv         *uninstall.Inventory
+		path        string
+	)
+
+	write := func(rel, content string) {
+		p := filepath.Join(root, rel)
+		Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+		Expect(os.WriteFile(p, []byte(content), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		h = &host{
+			packages:   map[string]bool{"onprem-config-installer": true, "onprem-ke-installer": true},
+			namespaces: map[string]bool{"orch-database": true, "orch-ui": true},
+			claims:     map[string]bool{"orch-database": true},
+			networks:   map[string]bool{"edge": true},
+		}
+		uninstaller = uninstall.Uninstaller{Root: root, Exec: h, Out: io.Discard}
+		path = filepath.Join(root, uninstall.InventoryPath)
+
+		write("etc/sysctl.d/99-orchestrator.conf", "fs.inotify.max_user_watches = 1048576\n")
+		write("var/openebs/local/pvc-1/data", "data")
+		write("etc/hosts", "127.0.0.1 localhost\n192.168.1.10  web-ui.cluster.onprem\n")
+		write("usr/local/bin/rke2-uninstall.sh", "")
+
+		Expect(uninstall.Record(h, path,
+			uninstall.Resource{Kind: uninstall.KindSysctl, Name: "/etc/sysctl.d/99-orchestrator.conf"},
+			uninstall.Resource{Kind: uninstall.KindDirectory, Name: "/var/openebs/local", Data: true},
+			uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-config-installer"},
+			uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-ke-installer"},
+			uninstall.Resource{Kind: uninstall.KindRKE2, Name: "rke2"},
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "orch-database"},
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "orch-ui"},
+			uninstall.Resource{Kind: uninstall.KindHostsEntry, Name: "192.168.1.10 web-ui.cluster.onprem"},
+			uninstall.Resource{Kind: uninstall.KindLibvirtNetwork, Name: "edge"},
+		)).To(Succeed())
+		var ok bool
+		var err error
+		inv, ok, err = uninstall.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("removes everything in reverse dependency order and the inventory", func() {
+		report, err := uninstaller.Run(inv, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Err()).NotTo(HaveOccurred())
+		Expect(report.Removed).To(HaveLen(9))
+		Expect(report.Kept).To(BeEmpty())
+
+		Expect(h.commands).To(Equal([]string{
+			"kubectl delete namespace orch-ui --ignore-not-found --timeout=10m",
+			"kubectl delete namespace orch-database --ignore-not-found --timeout=10m",
+			"dpkg --purge --force-remove-reinstreq onprem-ke-installer",
+			"dpkg --purge --force-remove-reinstreq onprem-config-installer",
+			filepath.Join(root, "usr/local/bin/rke2-killall.sh"),
+			filepath.Join(root, "usr/local/bin/rke2-uninstall.sh"),
+			"virsh -c qemu:///system net-destroy edge",
+			"virsh -c qemu:///system net-undefine edge",
+			"sysctl --system",
+		}))
+		Expect(filepath.Join(root, "var/openebs/local")).NotTo(BeADirectory())
+		Expect(filepath.Join(root, "etc/sysctl.d/99-orchestrator.conf")).NotTo(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(root, "etc/hosts"))).To(Equal([]byte("127.0.0.1 localhost\n")))
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
+	It("keeps the data and the namespaces with volume claims", func() {
+		uninstaller.KeepData = true
+		report, err := uninstaller.Run(inv, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Kept).To(ConsistOf(
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "orch-database"},
+			uninstall.Resource{Kind: uninstall.KindDirectory, Name: "/var/openebs/local", Data: true},
+		))
+		Expect(h.commands).NotTo(ContainElement(ContainSubstring("delete namespace orch-database")))
+		Expect(filepath.Join(root, "var/openebs/local/pvc-1/data")).To(BeAnExistingFile())
+
+		inv, ok, err := uninstall.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(inv.Resources).To(ConsistOf(report.Kept))
+	})
+
+	It("reports the resources that failed to be removed and keeps them in the inventory", func() {
+		h.failing = []string{"dpkg --purge --force-remove-reinstreq onprem-ke-installer"}
+		report, err := uninstaller.Run(inv, path)
+		Expect(err).NotTo(HaveOccurred())
+		ke := uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-ke-installer"}
+		Expect(report.Failed).To(HaveLen(1))
+		Expect(report.Failed[0].Resource).To(Equal(ke))
+		Expect(report.Leftovers).To(Equal([]uninstall.Resource{ke}))
+		Expect(report.Err()).To(MatchError("1 resources failed to be removed, 1 left behind"))
+		// The removal goes on after a failure.
+		Expect(h.commands).To(ContainElement("dpkg --purge --force-remove-reinstreq onprem-config-installer"))
+
+		inv, _, err := uninstall.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.Resources).To(Equal([]uninstall.Resource{ke}))
+	})
+
+	It("reports removed resources that still exist as leftovers", func() {
+		uninstaller.Kinds = []uninstall.Kind{uninstall.KindNamespace}
+		// The namespace is stuck terminating.
+		stuck := &stuckNamespace{host: h, name: "orch-ui"}
+		uninstaller.Exec = stuck
+
+		report, err := uninstaller.Run(inv, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Leftovers).To(Equal([]uninstall.Resource{{Kind: uninstall.KindNamespace, Name: "orch-ui"}}))
+		Expect(stuck.commands).To(HaveLen(2))
+
+		inv, _, err := uninstall.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.Resources).To(ContainElement(uninstall.Resource{Kind: uninstall.KindNamespace, Name: "orch-ui"}))
+		Expect(inv.Resources).NotTo(ContainElement(
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "orch-database"}))
+		Expect(inv.Resources).To(ContainElement(
+			uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-ke-installer"}))
+	})
+})
+
+// stuckNamespace is a host whose namespace is never deleted.
+type stuckNamespace struct {
+	*host
+	name string
+}
+
+func (s *stuckNamespace) Run(name string, args ...string) error {
+	err := s.host.Run(name, args...)
+	s.namespaces[s.name] = true
+	return err
+}
+
+var _ = Describe("Inventory", func() {
+	It("records every resource once, in the order it was first recorded", func() {
+		path := filepath.Join(GinkgoT().TempDir(), uninstall.InventoryPath)
+		x := executor.Local{}
+		Expect(uninstall.Record(x, path,
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "gitea"},
+			uninstall.Resource{Kind: uninstall.KindDirectory, Name: "/var/backups/orch"},
+		)).To(Succeed())
+		Expect(uninstall.Record(x, path,
+			uninstall.Resource{Kind: uninstall.KindDirectory, Name: "/var/backups/orch", Data: true},
+			uninstall.Resource{Kind: uninstall.KindNamespace, Name: "gitea"},
+			uninstall.Resource{Kind: uninstall.KindCRD, Name: "applications.argoproj.io"},
+		)).To(Succeed())
+
+		inv, ok, err := uninstall.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(inv.Resources).To(Equal([]uninstall.Resource{
+			{Kind: uninstall.KindNamespace, Name: "gitea"},
+			{Kind: uninstall.KindDirectory, Name: "/var/backups/orch", Data: true},
+			{Kind: uninstall.KindCRD, Name: "applications.argoproj.io"},
+		}))
+	})
+
+	It("has no inventory before anything is recorded", func() {
+		inv, ok, err := uninstall.Load(filepath.Join(GinkgoT().TempDir(), "inventory.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(inv.Resources).To(BeEmpty())
+	})
+
+	It("rejects unknown kinds", func() {
+		_, err := uninstall.ParseKind("vm")
+		Expect(err).To(MatchError(ContainSubstring(`unknown resource kind "vm"`)))
+		Expect(uninstall.ParseKind("libvirt-pool")).To(Equal(uninstall.KindLibvirtPool))
+	})
+})
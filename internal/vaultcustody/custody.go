@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultcustody
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// ShareSuffix is the suffix of the share files, named after their custodian.
+const ShareSuffix = ".share.json"
+
+var custodianName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Custodian is a holder of a key share.
+type Custodian struct {
+	// Name names the share file of the custodian, e.g. a user name.
+	Name       string
+	Passphrase []byte
+}
+
+// Split encrypts one key share for each custodian, the first shares in the order of the custodians. There must be at
+// least threshold custodians, so that Vault can be unsealed, and threshold must be at least 2, so that no single share
+// unseals Vault. With fewer custodians than shares, the shares listed by Uncovered are not kept in custody.
+func Split(keys []string, threshold int, custodians []Custodian) ([]*ShareFile, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("with a threshold of %d a single share unseals Vault, rekey Vault with a threshold of "+
+			"at least 2 first", threshold)
+	}
+	if len(custodians) < threshold {
+		return nil, fmt.Errorf("%d custodians can't unseal Vault with a threshold of %d", len(custodians), threshold)
+	}
+	if len(custodians) > len(keys) {
+		return nil, fmt.Errorf("%d custodians for %d key shares, each custodian needs their own share",
+			len(custodians), len(keys))
+	}
+	var names []string
+	for _, custodian := range custodians {
+		if !custodianName.MatchString(custodian.Name) {
+			return nil, fmt.Errorf("invalid custodian name %q", custodian.Name)
+		}
+		if slices.Contains(names, custodian.Name) {
+			return nil, fmt.Errorf("custodian %s is listed twice", custodian.Name)
+		}
+		names = append(names, custodian.Name)
+	}
+
+	set := make([]byte, 8)
+	if _, err := rand.Read(set); err != nil {
+		return nil, err
+	}
+	var files []*ShareFile
+	for i, custodian := range custodians {
+		f := &ShareFile{
+			Custodian: custodian.Name,
+			Set:       hex.EncodeToString(set),
+			Index:     i + 1,
+			Threshold: threshold,
+			Shares:    len(custodians),
+		}
+		if err := f.seal(keys[i], custodian.Passphrase); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// Uncovered returns the 1-based indexes of the key shares Split doesn't give to any of the custodians.
+func Uncovered(keys []string, custodians []Custodian) []int {
+	var indexes []int
+	for i := len(custodians); i < len(keys); i++ {
+		indexes = append(indexes, i+1)
+	}
+	return indexes
+}
+
+// Write writes the share files to dir, readable only by their owner, and returns their paths. Existing share files
+// are never overwritten, since they may be the only copy of the shares of a Vault.
+func Write(dir string, files []*ShareFile) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating share directory: %w", err)
+	}
+	var paths []string
+	for _, f := range files {
+		data, err := json.MarshalIndent(f, "", "  ")
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, f.Custodian+ShareSuffix)
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return paths, fmt.Errorf("writing share: %w", err)
+		}
+		_, err = out.Write(append(data, '\n'))
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, fmt.Errorf("writing share %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// SharePaths returns the share files named by paths, which are share files or directories of share files.
+func SharePaths(paths []string) ([]string, error) {
+	var shares []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("finding shares: %w", err)
+		}
+		if !info.IsDir() {
+			shares = append(shares, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*"+ShareSuffix))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		shares = append(shares, matches...)
+	}
+	if len(shares) == 0 {
+		return nil, errors.New("no share files found")
+	}
+	return shares, nil
+}
+
+// Collector collects the key shares of a set until there are enough of them to unseal Vault.
+type Collector struct {
+	set       string
+	threshold int
+	shares    map[int]string
+}
+
+// Add decrypts a share file with the passphrase of its custodian and collects its share.
+func (c *Collector) Add(f *ShareFile, passphrase []byte) error {
+	if c.shares != nil && f.Set != c.set {
+		return fmt.Errorf("the share of %s belongs to another set of shares", f.Custodian)
+	}
+	if _, ok := c.shares[f.Index]; ok {
+		return fmt.Errorf("the share of %s is already collected", f.Custodian)
+	}
+	share, err := f.Open(passphrase)
+	if err != nil {
+		return fmt.Errorf("opening the share of %s: %w", f.Custodian, err)
+	}
+	// The set is taken from the first share that opens, so that a stale share file that can't be opened doesn't
+	// make the collector reject the shares of the current set.
+	if c.shares == nil {
+		c.set, c.threshold, c.shares = f.Set, f.Threshold, map[int]string{}
+	}
+	c.shares[f.Index] = share
+	return nil
+}
+
+// Needed returns the number of shares still needed, unknown before the first share is added.
+func (c *Collector) Needed() (n int, known bool) {
+	if c.shares == nil {
+		return 0, false
+	}
+	return max(c.threshold-len(c.shares), 0), true
+}
+
+// Complete returns an error unless enough shares were collected to unseal Vault.
+func (c *Collector) Complete() error {
+	needed, known := c.Needed()
+	switch {
+	case !known:
+		return errors.New("no share could be opened, check the passphrases and share files")
+	case needed > 0:
+		return fmt.Errorf("not enough shares to unseal vault, %d more needed", needed)
+	}
+	return nil
+}
+
+// Keys returns the collected shares in the order of their index.
+func (c *Collector) Keys() []string {
+	var indexes []int
+	for index := range c.shares {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	var keys []string
+	for _, index := range indexes {
+		keys = append(keys, c.shares[index])
+	}
+	return keys
+}
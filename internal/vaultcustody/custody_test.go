@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultcustody_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultcustody"
+)
+
+var _ = Describe("Custody", func() {
+	keys := []string{"a2V5MQ==", "a2V5Mg==", "a2V5Mw=="}
+	custodians := []vaultcustody.Custodian{
+		{Name: "alice", Passphrase: []byte("correct horse battery")},
+		{Name: "bob", Passphrase: []byte("staple of the battery")},
+		{Name: "carol", Passphrase: []byte("horse staple correct")},
+	}
+
+	It("writes every share encrypted to the file of its custodian", func() {
+		files, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+		dir := filepath.Join(GinkgoT().TempDir(), "vault-shares")
+		paths, err := vaultcustody.Write(dir, files)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(Equal([]string{
+			filepath.Join(dir, "alice.share.json"),
+			filepath.Join(dir, "bob.share.json"),
+			filepath.Join(dir, "carol.share.json"),
+		}))
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			for _, key := range keys {
+				Expect(string(data)).NotTo(ContainSubstring(key))
+			}
+		}
+
+		// Existing shares are never overwritten.
+		_, err = vaultcustody.Write(dir, files)
+		Expect(err).To(MatchError(ContainSubstring("file exists")))
+	})
+
+	It("collects the threshold of shares from the files of the custodians", func() {
+		files, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+		dir := GinkgoT().TempDir()
+		_, err = vaultcustody.Write(dir, files)
+		Expect(err).NotTo(HaveOccurred())
+		paths, err := vaultcustody.SharePaths([]string{dir})
+		Expect(err).NotTo(HaveOccurred())
+
+		var collector vaultcustody.Collector
+		_, known := collector.Needed()
+		Expect(known).To(BeFalse())
+
+		carol, err := vaultcustody.ReadShare(paths[2])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(collector.Add(carol, custodians[2].Passphrase)).To(Succeed())
+		Expect(collector.Add(carol, custodians[2].Passphrase)).To(MatchError("the share of carol is already collected"))
+		needed, known := collector.Needed()
+		Expect(known).To(BeTrue())
+		Expect(needed).To(Equal(1))
+		Expect(collector.Complete()).To(MatchError("not enough shares to unseal vault, 1 more needed"))
+
+		alice, err := vaultcustody.ReadShare(paths[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(collector.Add(alice, []byte("wrong passphrase"))).To(MatchError(vaultcustody.ErrWrongPassphrase))
+		Expect(collector.Add(alice, custodians[0].Passphrase)).To(Succeed())
+		needed, _ = collector.Needed()
+		Expect(needed).To(BeZero())
+		Expect(collector.Complete()).To(Succeed())
+		Expect(collector.Keys()).To(Equal([]string{"a2V5MQ==", "a2V5Mw=="}))
+	})
+
+	It("tells when no share could be opened", func() {
+		files, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+
+		var collector vaultcustody.Collector
+		Expect(collector.Add(files[0], []byte("wrong passphrase"))).To(MatchError(vaultcustody.ErrWrongPassphrase))
+		Expect(collector.Complete()).To(MatchError("no share could be opened, check the passphrases and share files"))
+	})
+
+	It("doesn't combine shares of different sets", func() {
+		first, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+
+		var collector vaultcustody.Collector
+		Expect(collector.Add(first[0], custodians[0].Passphrase)).To(Succeed())
+		Expect(collector.Add(second[1], custodians[1].Passphrase)).
+			To(MatchError("the share of bob belongs to another set of shares"))
+	})
+
+	It("takes the set from the first share that opens", func() {
+		stale, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+		current, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The passphrase of alice changed since the stale set.
+		var collector vaultcustody.Collector
+		Expect(collector.Add(stale[0], []byte("new passphrase of alice"))).To(MatchError(vaultcustody.ErrWrongPassphrase))
+		_, known := collector.Needed()
+		Expect(known).To(BeFalse())
+		Expect(collector.Add(current[1], custodians[1].Passphrase)).To(Succeed())
+		Expect(collector.Add(current[2], custodians[2].Passphrase)).To(Succeed())
+		needed, _ := collector.Needed()
+		Expect(needed).To(BeZero())
+	})
+
+	It("lists the shares left without custodian", func() {
+		Expect(vaultcustody.Uncovered(keys, custodians)).To(BeEmpty())
+		Expect(vaultcustody.Uncovered(keys, custodians[:2])).To(Equal([]int{3}))
+	})
+
+	It("rejects a share whose clear text fields were changed", func() {
+		files, err := vaultcustody.Split(keys, 2, custodians)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := json.Marshal(files[0])
+		Expect(err).NotTo(HaveOccurred())
+		tampered := &vaultcustody.ShareFile{}
+		Expect(json.Unmarshal(data, tampered)).To(Succeed())
+		tampered.Index = 2
+
+		_, err = tampered.Open(custodians[0].Passphrase)
+		Expect(err).To(MatchError(vaultcustody.ErrWrongPassphrase))
+		Expect(files[0].Open(custodians[0].Passphrase)).To(Equal("a2V5MQ=="))
+	})
+
+	It("refuses custodies in which a single share unseals Vault", func() {
+		_, err := vaultcustody.Split(keys, 1, custodians)
+		Expect(err).To(MatchError(ContainSubstring("a single share unseals Vault")))
+
+		_, err = vaultcustody.Split(keys, 3, custodians[:2])
+		Expect(err).To(MatchError("2 custodians can't unseal Vault with a threshold of 3"))
+
+		_, err = vaultcustody.Split(keys, 2, []vaultcustody.Custodian{
+			{Name: "alice", Passphrase: []byte("short")}, custodians[1],
+		})
+		Expect(err).To(MatchError(ContainSubstring("shorter than 12 characters")))
+
+		_, err = vaultcustody.Split(keys, 2, []vaultcustody.Custodian{custodians[0], custodians[0]})
+		Expect(err).To(MatchError("custodian alice is listed twice"))
+
+		_, err = vaultcustody.Split(keys, 2, []vaultcustody.Custodian{{Name: "../alice"}, custodians[1]})
+		Expect(err).To(MatchError(`invalid custodian name "../alice"`))
+	})
+})
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vaultcustody keeps the Shamir key shares of Vault in the custody of several custodians: every share is
+// written to its own file, encrypted with the passphrase of its custodian, so that no single file or passphrase can
+// unseal Vault. Unsealing needs the files and passphrases of as many custodians as the Vault threshold.
+package vaultcustody
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Version is the version of the share file format.
+	Version = 1
+	// MinPassphraseLength is the minimum length of a passphrase in bytes.
+	MinPassphraseLength = 12
+
+	// The scrypt parameters recommended for interactive logins as of 2017, which take about 100ms.
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	saltLength   = 16
+	aesKeyLength = 32
+)
+
+// ErrWrongPassphrase is returned when a share can't be decrypted, because the passphrase is wrong or the file was
+// tampered with.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupt share")
+
+// KDF is the key derivation of a share file.
+type KDF struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// ShareFile is a key share encrypted with the passphrase of its custodian. Everything but the share itself is in
+// clear text, so that the files can be told apart without their passphrases, and authenticated with the share.
+type ShareFile struct {
+	Version   int    `json:"version"`
+	Custodian string `json:"custodian"`
+	// Set identifies the shares split together, shares of different sets are never combined.
+	Set string `json:"set"`
+	// Index is the index of the share among the Shares shares, starting at 1.
+	Index     int    `json:"index"`
+	Threshold int    `json:"threshold"`
+	Shares    int    `json:"shares"`
+	KDF       KDF    `json:"kdf"`
+	Nonce     []byte `json:"nonce"`
+	// Ciphertext is the base64 key share encrypted with AES-256-GCM.
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// seal encrypts a key share into the file with a passphrase.
+func (f *ShareFile) seal(share string, passphrase []byte) error {
+	if len(passphrase) < MinPassphraseLength {
+		return fmt.Errorf("the passphrase of %s is shorter than %d characters", f.Custodian, MinPassphraseLength)
+	}
+	f.Version = Version
+	f.KDF = KDF{Name: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, saltLength)}
+	if _, err := rand.Read(f.KDF.Salt); err != nil {
+		return err
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, []byte(share), f.additionalData())
+	return nil
+}
+
+// Open decrypts the key share of the file with the passphrase of its custodian.
+func (f *ShareFile) Open(passphrase []byte) (string, error) {
+	if f.Version != Version {
+		return "", fmt.Errorf("unsupported share file version %d", f.Version)
+	}
+	if f.KDF.Name != "scrypt" {
+		return "", fmt.Errorf("unsupported key derivation %q", f.KDF.Name)
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+	share, err := aead.Open(nil, f.Nonce, f.Ciphertext, f.additionalData())
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(share), nil
+}
+
+func (f *ShareFile) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, f.KDF.Salt, f.KDF.N, f.KDF.R, f.KDF.P, aesKeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData authenticates the clear text fields, so that a share can't be passed off as another one.
+func (f *ShareFile) additionalData() []byte {
+	return []byte(strconv.Itoa(f.Version) + "\x00" + f.Custodian + "\x00" + f.Set + "\x00" + strconv.Itoa(f.Index) +
+		"\x00" + strconv.Itoa(f.Threshold) + "\x00" + strconv.Itoa(f.Shares))
+}
+
+// ReadShare reads a share file.
+func ReadShare(path string) (*ShareFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading share: %w", err)
+	}
+	f := &ShareFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("parsing share %s: %w", path, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultcustody_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVaultCustody(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Vault Custody Suite")
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Secret is a secret of a KV secrets engine.
+type Secret struct {
+	// Path is the path of the secret including its mount, e.g. secret/ma_git_service.
+	Path string
+	// KVVersion is the version of the KV secrets engine, 1 or 2.
+	KVVersion int
+	// CurrentVersion, CreatedTime and UpdatedTime are only known for KV version 2.
+	CurrentVersion int
+	CreatedTime    time.Time
+	UpdatedTime    time.Time
+	// Policies are the policies granting access to the secret, which are named after the Orchestrator components
+	// that use them.
+	Policies []string
+}
+
+var policyPath = regexp.MustCompile(`(?m)^\s*path\s+"([^"]+)"`)
+
+// AuditSecrets returns the secrets of all KV secrets engines, sorted by path, with the policies granting access to
+// them.
+func AuditSecrets(ctx context.Context, client *api.Client) ([]Secret, error) {
+	mounts, err := client.Sys().ListMountsWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing secrets engines: %w", err)
+	}
+	policies, err := policyPaths(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []Secret
+	for mount, engine := range mounts {
+		if engine.Type != "kv" && engine.Type != "generic" {
+			continue
+		}
+		version := 1
+		if engine.Options["version"] == "2" {
+			version = 2
+		}
+		if err := walk(ctx, client, mount, version, "", &secrets); err != nil {
+			return nil, err
+		}
+	}
+	for i := range secrets {
+		secret := &secrets[i]
+		paths := []string{secret.Path}
+		if secret.KVVersion == 2 {
+			// The policies of KV version 2 grant access to the data and metadata paths.
+			mount, path, _ := strings.Cut(secret.Path, "/")
+			paths = append(paths, mount+"/data/"+path, mount+"/metadata/"+path)
+		}
+		for name, patterns := range policies {
+			if slices.ContainsFunc(patterns, func(pattern string) bool {
+				return slices.ContainsFunc(paths, func(path string) bool { return matchPolicyPath(pattern, path) })
+			}) {
+				secret.Policies = append(secret.Policies, name)
+			}
+		}
+		slices.Sort(secret.Policies)
+	}
+	slices.SortFunc(secrets, func(a, b Secret) int { return strings.Compare(a.Path, b.Path) })
+	return secrets, nil
+}
+
+// walk adds the secrets under prefix of a KV mount.
+func walk(ctx context.Context, client *api.Client, mount string, version int, prefix string, secrets *[]Secret) error {
+	listPath := mount + prefix
+	if version == 2 {
+		listPath = mount + "metadata/" + prefix
+	}
+	list, err := client.Logical().ListWithContext(ctx, listPath)
+	if err != nil {
+		return fmt.Errorf("listing %s: %w", listPath, err)
+	}
+	if list == nil {
+		return nil
+	}
+	keys, _ := list.Data["keys"].([]any)
+	for _, key := range keys {
+		name, _ := key.(string)
+		if strings.HasSuffix(name, "/") {
+			if err := walk(ctx, client, mount, version, prefix+name, secrets); err != nil {
+				return err
+			}
+			continue
+		}
+		secret := Secret{Path: mount + prefix + name, KVVersion: version}
+		if version == 2 {
+			if err := readMetadata(ctx, client, mount+"metadata/"+prefix+name, &secret); err != nil {
+				return err
+			}
+		}
+		*secrets = append(*secrets, secret)
+	}
+	return nil
+}
+
+func readMetadata(ctx context.Context, client *api.Client, path string, secret *Secret) error {
+	response, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if response == nil {
+		return nil
+	}
+	var metadata struct {
+		CurrentVersion int       `json:"current_version"`
+		CreatedTime    time.Time `json:"created_time"`
+		UpdatedTime    time.Time `json:"updated_time"`
+	}
+	if err := decode(response.Data, &metadata); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	secret.CurrentVersion, secret.CreatedTime, secret.UpdatedTime = metadata.CurrentVersion, metadata.CreatedTime,
+		metadata.UpdatedTime
+	return nil
+}
+
+// policyPaths returns the path patterns of the ACL policies by policy name.
+func policyPaths(ctx context.Context, client *api.Client) (map[string][]string, error) {
+	names, err := client.Sys().ListPoliciesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing policies: %w", err)
+	}
+	policies := map[string][]string{}
+	for _, name := range names {
+		// The root policy grants everything and says nothing about who uses a secret.
+		if name == "root" {
+			continue
+		}
+		rules, err := client.Sys().GetPolicyWithContext(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading policy %s: %w", name, err)
+		}
+		for _, match := range policyPath.FindAllStringSubmatch(rules, -1) {
+			policies[name] = append(policies[name], match[1])
+		}
+	}
+	return policies, nil
+}
+
+// matchPolicyPath returns whether a path matches the path pattern of a policy, in which + matches a path segment and
+// a trailing * any suffix.
+func matchPolicyPath(pattern, path string) bool {
+	prefix, glob := strings.CutSuffix(pattern, "*")
+	patternSegments := strings.Split(prefix, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(pathSegments) < len(patternSegments) || (!glob && len(pathSegments) != len(patternSegments)) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		last := i == len(patternSegments)-1
+		switch {
+		case segment == "+":
+		case last && glob:
+			return strings.HasPrefix(strings.Join(pathSegments[i:], "/"), segment)
+		case segment != pathSegments[i]:
+			return false
+		}
+	}
+	return true
+}
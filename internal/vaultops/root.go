@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultops
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// GenerateRoot generates a root token with the generate-root flow: Vault creates a one-time password, the key shares
+// are submitted until the threshold is reached, and the token Vault returns encoded with the one-time password is
+// decoded. A generation left in progress, e.g. by an interrupted run, is cancelled first since its one-time password
+// is unknown.
+func GenerateRoot(ctx context.Context, client *api.Client, keys []string) (string, error) {
+	status, err := client.Sys().GenerateRootStatusWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("reading root generation status: %w", err)
+	}
+	if status.Started {
+		if err := client.Sys().GenerateRootCancelWithContext(ctx); err != nil {
+			return "", fmt.Errorf("cancelling root generation in progress: %w", err)
+		}
+	}
+
+	status, err = client.Sys().GenerateRootInitWithContext(ctx, "", "")
+	if err != nil {
+		return "", fmt.Errorf("starting root generation: %w", err)
+	}
+	otp := status.OTP
+	if otp == "" {
+		_ = client.Sys().GenerateRootCancelWithContext(ctx)
+		return "", errors.New("vault returned no one-time password, it is too old for this root generation")
+	}
+	for i, key := range keys {
+		status, err = client.Sys().GenerateRootUpdateWithContext(ctx, strings.TrimSpace(key), status.Nonce)
+		if err != nil {
+			_ = client.Sys().GenerateRootCancelWithContext(ctx)
+			return "", fmt.Errorf("submitting key share %d: %w", i+1, err)
+		}
+		if status.Complete {
+			break
+		}
+	}
+	if !status.Complete {
+		_ = client.Sys().GenerateRootCancelWithContext(ctx)
+		return "", fmt.Errorf("%d key shares submitted, %d needed", status.Progress, status.Required)
+	}
+	return decodeRootToken(status.EncodedToken, otp)
+}
+
+// decodeRootToken decodes a token encoded with a one-time password, which is the base64 XOR of both.
+func decodeRootToken(encoded, otp string) (string, error) {
+	token, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return "", fmt.Errorf("decoding root token: %w", err)
+	}
+	if len(token) != len(otp) {
+		return "", fmt.Errorf("decoding root token: length %d doesn't match the one-time password", len(token))
+	}
+	for i := range token {
+		token[i] ^= otp[i]
+	}
+	return string(token), nil
+}
+
+// RevokeToken revokes a token, e.g. the previous root token after a rotation, with the token of client.
+func RevokeToken(ctx context.Context, client *api.Client, token string) error {
+	if err := client.Auth().Token().RevokeOrphanWithContext(ctx, token); err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultops
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// checkRaft returns an error unless Vault is unsealed and uses raft storage. Vault on another storage backend keeps
+// its data in the database, which is backed up and restored with the databases.
+func checkRaft(ctx context.Context, client *api.Client) error {
+	seal, err := client.Sys().SealStatusWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("reading seal status: %w", err)
+	}
+	if seal.Sealed {
+		return fmt.Errorf("vault is sealed")
+	}
+	if seal.StorageType != RaftStorage {
+		return fmt.Errorf("vault uses %s storage, which has no snapshots; its data is backed up with the databases",
+			seal.StorageType)
+	}
+	return nil
+}
+
+// Snapshot writes a raft snapshot of Vault to w.
+func Snapshot(ctx context.Context, client *api.Client, w io.Writer) error {
+	if err := checkRaft(ctx, client); err != nil {
+		return err
+	}
+	if err := client.Sys().RaftSnapshotWithContext(ctx, w); err != nil {
+		return fmt.Errorf("taking raft snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore restores a raft snapshot of Vault. force restores a snapshot of another Vault cluster, e.g. one taken before
+// Vault was installed again, whose data then needs the key shares of that cluster to be unsealed.
+func Restore(ctx context.Context, client *api.Client, r io.Reader, force bool) error {
+	if err := checkRaft(ctx, client); err != nil {
+		return err
+	}
+	if err := client.Sys().RaftSnapshotRestoreWithContext(ctx, r, force); err != nil {
+		return fmt.Errorf("restoring raft snapshot: %w", err)
+	}
+	return nil
+}
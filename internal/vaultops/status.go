@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vaultops implements the operational tasks of the Vault of the Orchestrator through the Vault API: reporting
+// its status, taking and restoring raft snapshots, rotating the root token and auditing the KV secrets.
+package vaultops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// RaftStorage is the storage type of Vault with integrated storage, the only one with peers and snapshots.
+const RaftStorage = "raft"
+
+// Peer is a server of the raft cluster.
+type Peer struct {
+	NodeID  string `json:"node_id"`
+	Address string `json:"address"`
+	Leader  bool   `json:"leader"`
+	Voter   bool   `json:"voter"`
+}
+
+// Status is the state of a Vault cluster as seen by one of its servers.
+type Status struct {
+	Seal   *api.SealStatusResponse
+	Leader *api.LeaderResponse
+	// Peers are the raft peers, empty if Vault doesn't use raft storage or is sealed.
+	Peers []Peer
+}
+
+// GetStatus returns the status of Vault. The raft peers need a token, the seal and HA status don't.
+func GetStatus(ctx context.Context, client *api.Client) (Status, error) {
+	var status Status
+	var err error
+	if status.Seal, err = client.Sys().SealStatusWithContext(ctx); err != nil {
+		return status, fmt.Errorf("reading seal status: %w", err)
+	}
+	if status.Seal.Sealed {
+		return status, nil
+	}
+	if status.Leader, err = client.Sys().LeaderWithContext(ctx); err != nil {
+		return status, fmt.Errorf("reading HA status: %w", err)
+	}
+	if status.Seal.StorageType != RaftStorage {
+		return status, nil
+	}
+	if status.Peers, err = raftPeers(ctx, client); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+func raftPeers(ctx context.Context, client *api.Client) ([]Peer, error) {
+	secret, err := client.Logical().ReadWithContext(ctx, "sys/storage/raft/configuration")
+	if err != nil {
+		return nil, fmt.Errorf("reading raft configuration: %w", err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	var config struct {
+		Config struct {
+			Servers []Peer `json:"servers"`
+		} `json:"config"`
+	}
+	if err := decode(secret.Data, &config); err != nil {
+		return nil, fmt.Errorf("parsing raft configuration: %w", err)
+	}
+	return config.Config.Servers, nil
+}
+
+// decode converts the data of a response to out through its JSON tags.
+func decode(data map[string]any, out any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
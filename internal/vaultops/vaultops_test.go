@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultops_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultops"
+)
+
+const rootToken = "hvs.root"
+
+// fakeVault stands in for a Vault server with raft storage, a KV version 2 engine at secret/ and a KV version 1
+// engine at legacy/. The API client trims the trailing slash of the paths it lists.
+type fakeVault struct {
+	mu        sync.Mutex
+	storage   string
+	threshold int
+	keys      []string
+	tokens    []string
+	// generation is the state of the root generation.
+	otp       string
+	nonce     string
+	submitted int
+	restored  []byte
+	force     bool
+}
+
+func (f *fakeVault) respond(w http.ResponseWriter, data any) {
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if path != "sys/seal-status" && path != "sys/leader" && !strings.HasPrefix(path, "sys/generate-root/") &&
+		!slices.Contains(f.tokens, r.Header.Get("X-Vault-Token")) {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	list := r.URL.Query().Get("list") == "true"
+
+	switch {
+	case path == "sys/seal-status":
+		f.respond(w, map[string]any{"type": "shamir", "initialized": true, "sealed": false, "t": f.threshold,
+			"n": len(f.keys), "version": "1.21.3", "storage_type": f.storage})
+	case path == "sys/leader":
+		f.respond(w, map[string]any{"ha_enabled": true, "is_self": true,
+			"leader_address": "http://vault-0.vault-internal:8200"})
+	case path == "sys/storage/raft/configuration":
+		f.respond(w, map[string]any{"data": map[string]any{"config": map[string]any{"servers": []any{
+			map[string]any{"node_id": "vault-0", "address": "vault-0.vault-internal:8201", "leader": true,
+				"voter": true},
+			map[string]any{"node_id": "vault-1", "address": "vault-1.vault-internal:8201", "voter": true},
+		}}}})
+	case path == "sys/storage/raft/snapshot" && r.Method == http.MethodGet:
+		_, _ = w.Write(snapshot("state"))
+	case strings.HasPrefix(path, "sys/storage/raft/snapshot") && r.Method == http.MethodPost:
+		f.restored, _ = io.ReadAll(r.Body)
+		f.force = path == "sys/storage/raft/snapshot-force"
+		w.WriteHeader(http.StatusNoContent)
+
+	case path == "sys/generate-root/attempt" && r.Method == http.MethodGet:
+		f.respond(w, f.generation())
+	case path == "sys/generate-root/attempt" && r.Method == http.MethodDelete:
+		f.otp, f.nonce, f.submitted = "", "", 0
+		w.WriteHeader(http.StatusNoContent)
+	case path == "sys/generate-root/attempt":
+		f.otp, f.nonce, f.submitted = strings.Repeat("o", len("hvs.rotated")), "nonce-1", 0
+		status := f.generation()
+		status["otp"] = f.otp
+		f.respond(w, status)
+	case path == "sys/generate-root/update":
+		var body struct{ Key, Nonce string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Nonce != f.nonce || !slices.Contains(f.keys, body.Key) {
+			http.Error(w, `{"errors":["invalid key or nonce"]}`, http.StatusBadRequest)
+			return
+		}
+		f.submitted++
+		status := f.generation()
+		if f.submitted >= f.threshold {
+			encoded := []byte("hvs.rotated")
+			for i := range encoded {
+				encoded[i] ^= f.otp[i]
+			}
+			f.tokens = append(f.tokens, "hvs.rotated")
+			status["complete"] = true
+			status["encoded_token"] = base64.RawStdEncoding.EncodeToString(encoded)
+		}
+		f.respond(w, status)
+	case path == "auth/token/revoke-orphan":
+		var body struct{ Token string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.tokens = slices.DeleteFunc(f.tokens, func(token string) bool { return token == body.Token })
+		w.WriteHeader(http.StatusNoContent)
+
+	case path == "sys/mounts":
+		f.respond(w, map[string]any{"data": map[string]any{
+			"secret/": map[string]any{"type": "kv", "options": map[string]any{"version": "2"}},
+			"legacy/": map[string]any{"type": "kv", "options": map[string]any{"version": "1"}},
+			"sys/":    map[string]any{"type": "system"},
+		}})
+	case path == "sys/policies/acl" && list:
+		f.respond(w, map[string]any{"data": map[string]any{"keys": []any{"root", "app-orch", "git-service"}}})
+	case path == "sys/policies/acl/app-orch":
+		f.respond(w, map[string]any{"data": map[string]any{"policy": `path "secret/data/app-orch/*" {
+  capabilities = ["read"]
+}`}})
+	case path == "sys/policies/acl/git-service":
+		f.respond(w, map[string]any{"data": map[string]any{"policy": `
+path "secret/data/ma_git_service" { capabilities = ["read"] }
+path "legacy/+/token" { capabilities = ["read"] }`}})
+	case path == "secret/metadata" && list:
+		f.respond(w, map[string]any{"data": map[string]any{"keys": []any{"app-orch/", "ma_git_service"}}})
+	case path == "secret/metadata/app-orch" && list:
+		f.respond(w, map[string]any{"data": map[string]any{"keys": []any{"catalog"}}})
+	case path == "secret/metadata/app-orch/catalog":
+		f.respond(w, map[string]any{"data": map[string]any{"current_version": 3,
+			"created_time": "2026-01-02T03:04:05Z", "updated_time": "2026-09-10T11:12:13Z"}})
+	case path == "secret/metadata/ma_git_service":
+		f.respond(w, map[string]any{"data": map[string]any{"current_version": 1,
+			"created_time": "2026-01-02T03:04:05Z", "updated_time": "2026-01-02T03:04:05Z"}})
+	case path == "legacy" && list:
+		f.respond(w, map[string]any{"data": map[string]any{"keys": []any{"gitea/"}}})
+	case path == "legacy/gitea" && list:
+		f.respond(w, map[string]any{"data": map[string]any{"keys": []any{"token"}}})
+	default:
+		http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+	}
+}
+
+func (f *fakeVault) generation() map[string]any {
+	return map[string]any{"started": f.nonce != "", "nonce": f.nonce, "progress": f.submitted,
+		"required": f.threshold, "otp_length": len(f.otp)}
+}
+
+// snapshot returns a raft snapshot, a gzipped tar ending with the sealed checksums.
+func snapshot(state string) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct{ name, content string }{{"state.bin", state}, {"SHA256SUMS.sealed", "sums"}} {
+		Expect(tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0o600, Size: int64(len(file.content))})).
+			To(Succeed())
+		_, err := tw.Write([]byte(file.content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+var _ = Describe("Vault operations", func() {
+	var (
+		vault  *fakeVault
+		client *api.Client
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		vault = &fakeVault{storage: vaultops.RaftStorage, threshold: 2, keys: []string{"k1", "k2", "k3"},
+			tokens: []string{rootToken}}
+		server := httptest.NewServer(vault)
+		DeferCleanup(server.Close)
+		var err error
+		client, err = api.NewClient(&api.Config{Address: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client.SetToken(rootToken)
+	})
+
+	It("reports the seal, HA and raft status", func() {
+		status, err := vaultops.GetStatus(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Seal.Sealed).To(BeFalse())
+		Expect(status.Leader.HAEnabled).To(BeTrue())
+		Expect(status.Peers).To(Equal([]vaultops.Peer{
+			{NodeID: "vault-0", Address: "vault-0.vault-internal:8201", Leader: true, Voter: true},
+			{NodeID: "vault-1", Address: "vault-1.vault-internal:8201", Voter: true},
+		}))
+	})
+
+	It("has no raft peers without raft storage", func() {
+		vault.storage = "postgresql"
+		status, err := vaultops.GetStatus(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Peers).To(BeEmpty())
+	})
+
+	It("takes and restores raft snapshots", func() {
+		var b bytes.Buffer
+		Expect(vaultops.Snapshot(ctx, client, &b)).To(Succeed())
+		Expect(b.Bytes()).To(Equal(snapshot("state")))
+
+		Expect(vaultops.Restore(ctx, client, bytes.NewReader(b.Bytes()), true)).To(Succeed())
+		Expect(vault.restored).To(Equal(b.Bytes()))
+		Expect(vault.force).To(BeTrue())
+	})
+
+	It("has no snapshots without raft storage", func() {
+		vault.storage = "postgresql"
+		Expect(vaultops.Snapshot(ctx, client, io.Discard)).
+			To(MatchError(ContainSubstring("vault uses postgresql storage, which has no snapshots")))
+	})
+
+	It("generates a root token with the key shares and revokes the previous one", func() {
+		// A generation left in progress is started over.
+		_, err := client.Sys().GenerateRootInitWithContext(ctx, "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := vaultops.GenerateRoot(ctx, client, []string{"k3", "k1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("hvs.rotated"))
+
+		client.SetToken(token)
+		Expect(vaultops.RevokeToken(ctx, client, rootToken)).To(Succeed())
+		Expect(vault.tokens).To(Equal([]string{"hvs.rotated"}))
+	})
+
+	It("cancels the root generation when a key share is rejected", func() {
+		_, err := vaultops.GenerateRoot(ctx, client, []string{"k1", "wrong"})
+		Expect(err).To(MatchError(ContainSubstring("submitting key share 2")))
+		Expect(vault.nonce).To(BeEmpty())
+
+		_, err = vaultops.GenerateRoot(ctx, client, []string{"k1"})
+		Expect(err).To(MatchError("1 key shares submitted, 2 needed"))
+	})
+
+	It("lists the KV secrets with their last modification and the policies using them", func() {
+		secrets, err := vaultops.AuditSecrets(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secrets).To(HaveLen(3))
+
+		Expect(secrets[0].Path).To(Equal("legacy/gitea/token"))
+		Expect(secrets[0].KVVersion).To(Equal(1))
+		Expect(secrets[0].UpdatedTime.IsZero()).To(BeTrue())
+		Expect(secrets[0].Policies).To(Equal([]string{"git-service"}))
+
+		Expect(secrets[1].Path).To(Equal("secret/app-orch/catalog"))
+		Expect(secrets[1].CurrentVersion).To(Equal(3))
+		Expect(secrets[1].UpdatedTime.Format("2006-01-02")).To(Equal("2026-09-10"))
+		Expect(secrets[1].Policies).To(Equal([]string{"app-orch"}))
+
+		Expect(secrets[2].Path).To(Equal("secret/ma_git_service"))
+		Expect(secrets[2].Policies).To(Equal([]string{"git-service"}))
+	})
+})
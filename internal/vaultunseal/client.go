@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vaultunseal watches the seal status of the Vault servers of the Orchestrator through the Vault HTTP API and
+// unseals them with the key shares of a key source, so that Vault doesn't stay sealed after a node reboot until
+// someone runs mage vault:unseal.
+package vaultunseal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SealStatus is the response of the sys/seal-status and sys/unseal endpoints.
+type SealStatus struct {
+	Type        string `json:"type"`
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	// Threshold is the number of key shares needed to unseal.
+	Threshold int `json:"t"`
+	Shares    int `json:"n"`
+	// Progress is the number of key shares submitted towards the current unseal.
+	Progress int    `json:"progress"`
+	Version  string `json:"version"`
+}
+
+// Client calls the unauthenticated seal endpoints of a Vault server.
+type Client struct {
+	// Address is the URL of the server, e.g. http://10.42.0.12:8200.
+	Address string
+	// HTTP is the client of the requests, one with a 10s timeout if nil.
+	HTTP *http.Client
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// SealStatus returns the seal status of the server.
+func (c Client) SealStatus(ctx context.Context) (SealStatus, error) {
+	return c.do(ctx, http.MethodGet, "sys/seal-status", nil)
+}
+
+// Unseal submits a key share and returns the seal status after it.
+func (c Client) Unseal(ctx context.Context, key string) (SealStatus, error) {
+	return c.do(ctx, http.MethodPut, "sys/unseal", map[string]any{"key": key})
+}
+
+// ResetUnseal discards the key shares submitted so far, e.g. those of an unseal that was interrupted.
+func (c Client) ResetUnseal(ctx context.Context) (SealStatus, error) {
+	return c.do(ctx, http.MethodPut, "sys/unseal", map[string]any{"reset": true})
+}
+
+func (c Client) do(ctx context.Context, method, path string, body any) (SealStatus, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return SealStatus{}, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	url := strings.TrimSuffix(c.Address, "/") + "/v1/" + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return SealStatus{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return SealStatus{}, fmt.Errorf("calling %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SealStatus{}, fmt.Errorf("reading response of %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Vault returns the errors as {"errors": [...]}, which is kept as is. Key shares are never part of it.
+		return SealStatus{}, fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	var status SealStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return SealStatus{}, fmt.Errorf("parsing response of %s: %w", path, err)
+	}
+	return status, nil
+}
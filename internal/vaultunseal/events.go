@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Reasons of the events.
+const (
+	ReasonSealed         = "VaultSealed"
+	ReasonUnsealed       = "VaultUnsealed"
+	ReasonUnsealFailed   = "VaultUnsealFailed"
+	ReasonNotInitialized = "VaultNotInitialized"
+)
+
+// Recorder records the events of the watcher.
+type Recorder interface {
+	// Event records an event about a target. eventType is corev1.EventTypeNormal or corev1.EventTypeWarning.
+	Event(ctx context.Context, target Target, eventType, reason, message string) error
+}
+
+// KubeRecorder records the events as Kubernetes events of the Vault pods, shown by kubectl describe pod.
+type KubeRecorder struct {
+	Client kubernetes.Interface
+	// Component is the source of the events.
+	Component string
+	// Host is the node the watcher runs on, if known.
+	Host string
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+func (k KubeRecorder) Event(ctx context.Context, target Target, eventType, reason, message string) error {
+	if target.Namespace == "" {
+		// A server outside Kubernetes has no object to attach the event to.
+		return nil
+	}
+	now := time.Now
+	if k.Now != nil {
+		now = k.Now
+	}
+	at := now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// The name of the events of client-go's recorder.
+			Name:      fmt.Sprintf("%s.%x", target.Name, at.UnixNano()),
+			Namespace: target.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       target.Name,
+			Namespace:  target.Namespace,
+			UID:        types.UID(target.UID),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: k.Component, Host: k.Host},
+		FirstTimestamp: metav1.NewTime(at),
+		LastTimestamp:  metav1.NewTime(at),
+		Count:          1,
+	}
+	if _, err := k.Client.CoreV1().Events(target.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("creating event %s of %s: %w", reason, target.Name, err)
+	}
+	return nil
+}
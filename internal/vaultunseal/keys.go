@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KeySource returns the unseal key shares. Sources are read again for every unseal, so that rotated keys are used
+// without restarting the watcher.
+type KeySource interface {
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// initResponse is the response of sys/init, as stored in the vault-keys secret.
+type initResponse struct {
+	KeysBase64 []string `json:"keys_base64"`
+}
+
+// ParseKeys returns the base64 key shares of a sys/init response.
+func ParseKeys(data []byte) ([]string, error) {
+	var init initResponse
+	if err := json.Unmarshal(data, &init); err != nil {
+		return nil, fmt.Errorf("parsing keys: %w", err)
+	}
+	if len(init.KeysBase64) == 0 {
+		return nil, errors.New("no keys_base64 in keys")
+	}
+	return init.KeysBase64, nil
+}
+
+// FileKeys reads the key shares from a file, e.g. the vault-keys secret mounted in the watcher pod.
+type FileKeys struct {
+	Path string
+}
+
+func (f FileKeys) Keys(_ context.Context) ([]string, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading keys: %w", err)
+	}
+	return ParseKeys(data)
+}
+
+// SecretKeys reads the key shares from a Kubernetes secret.
+type SecretKeys struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	// Key is the secret key holding the sys/init response.
+	Key string
+}
+
+func (s SecretKeys) Keys(ctx context.Context) ([]string, error) {
+	secret, err := s.Client.CoreV1().Secrets(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %s/%s: %w", s.Namespace, s.Name, err)
+	}
+	data, ok := secret.Data[s.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %s", s.Namespace, s.Name, s.Key)
+	}
+	return ParseKeys(data)
+}
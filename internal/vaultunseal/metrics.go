@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results of the unseals in the metrics.
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// Metrics are the Prometheus metrics of the watcher.
+type Metrics struct {
+	// Sealed is 1 while a server is sealed and 0 once it is unsealed, per pod.
+	Sealed *prometheus.GaugeVec
+	// Unseals counts the unseals per pod and result.
+	Unseals *prometheus.CounterVec
+	// StatusErrors counts the seal status checks that failed per pod, e.g. because the server didn't answer.
+	StatusErrors *prometheus.CounterVec
+	// LastCheck is the time of the last check of all servers, in seconds since the epoch.
+	LastCheck prometheus.Gauge
+}
+
+// NewMetrics returns the metrics of the watcher registered with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		Sealed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "orch_vault_sealed",
+			Help: "Whether the Vault server is sealed.",
+		}, []string{"pod"}),
+		Unseals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "orch_vault_unseals_total",
+			Help: "Unseals of the Vault servers by result.",
+		}, []string{"pod", "result"}),
+		StatusErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "orch_vault_seal_status_errors_total",
+			Help: "Seal status checks of the Vault servers that failed.",
+		}, []string{"pod"}),
+		LastCheck: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "orch_vault_unseal_last_check_timestamp_seconds",
+			Help: "Time of the last seal status check of the Vault servers.",
+		}),
+	}
+	reg.MustRegister(m.Sealed, m.Unseals, m.StatusErrors, m.LastCheck)
+	return m
+}
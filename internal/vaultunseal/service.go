@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/systemd"
+)
+
+// ServiceUnit is the systemd service of a watcher running on a node of the Orchestrator.
+const ServiceUnit = "orch-vault-unseal.service"
+
+// Service describes the systemd service of a watcher.
+type Service struct {
+	// Command is the command of the service and its arguments.
+	Command []string
+	// Env is the environment of the command.
+	Env map[string]string
+}
+
+var serviceTemplate = template.Must(template.New("service").Parse(`[Unit]
+Description=Edge Orchestrator Vault auto-unseal
+Wants=network-online.target
+After=network-online.target rke2-server.service
+
+[Service]
+{{- range .Env }}
+Environment={{ . }}
+{{- end }}
+ExecStart={{ .Command }}
+Restart=always
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// Unit returns the content of the service unit.
+func (s Service) Unit() (string, error) {
+	if len(s.Command) == 0 {
+		return "", errors.New("a Vault unseal service needs a command")
+	}
+	data := map[string]any{"Command": systemd.Command(s.Command), "Env": systemd.Environment(s.Env)}
+	var b bytes.Buffer
+	if err := serviceTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering %s: %w", ServiceUnit, err)
+	}
+	return b.String(), nil
+}
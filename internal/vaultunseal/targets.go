@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Target is a Vault server. Every server of a cluster is sealed on its own, so each one is unsealed through its own
+// address rather than through the service.
+type Target struct {
+	// Name is the pod of the server, or the address of a server outside Kubernetes.
+	Name      string
+	Namespace string
+	// UID is the UID of the pod, which the events refer to. It is empty outside Kubernetes.
+	UID     string
+	Address string
+}
+
+// Targets returns the Vault servers to watch.
+type Targets interface {
+	Targets(ctx context.Context) ([]Target, error)
+}
+
+// StaticTarget is a single server at a fixed address.
+type StaticTarget string
+
+func (s StaticTarget) Targets(_ context.Context) ([]Target, error) {
+	return []Target{{Name: string(s), Address: string(s)}}, nil
+}
+
+// PodTargets are the running pods of the Vault statefulset.
+type PodTargets struct {
+	Client    kubernetes.Interface
+	Namespace string
+	// Selector is the label selector of the server pods.
+	Selector string
+	Port     int
+	// Scheme is http or https.
+	Scheme string
+}
+
+func (p PodTargets) Targets(ctx context.Context) ([]Target, error) {
+	pods, err := p.Client.CoreV1().Pods(p.Namespace).List(ctx, metav1.ListOptions{LabelSelector: p.Selector})
+	if err != nil {
+		return nil, fmt.Errorf("listing Vault pods: %w", err)
+	}
+	var targets []Target
+	for _, pod := range pods.Items {
+		// Pods that aren't running yet have no server to unseal, the next check gets them.
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		targets = append(targets, Target{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+			Address:   p.Scheme + "://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(p.Port)),
+		})
+	}
+	return targets, nil
+}
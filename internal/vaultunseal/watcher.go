@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultInterval is the time between two checks of the seal status.
+const DefaultInterval = 30 * time.Second
+
+// Watcher checks the seal status of the Vault servers at an interval and unseals the sealed ones. A Watcher keeps
+// track of the servers between checks to record an event only when their state changes, so the same Watcher should be
+// used for all checks.
+type Watcher struct {
+	Targets Targets
+	Keys    KeySource
+	// Interval is the time between two checks, DefaultInterval if zero.
+	Interval time.Duration
+	// Events records the events, nothing is recorded if nil.
+	Events Recorder
+	// Metrics are updated by the checks, if not nil.
+	Metrics *Metrics
+	Log     *slog.Logger
+	// HTTP is the client of the Vault API, one with a 10s timeout if nil.
+	HTTP *http.Client
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+
+	// sealed are the targets seen sealed and not unsealed since.
+	sealed map[string]bool
+	// failures are the last unseal failures of the targets, recorded as events once until they change.
+	failures map[string]string
+}
+
+// Run checks the servers at once and then every Interval until ctx is done. A failed check is logged and retried at
+// the next interval.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(ctx); err != nil && ctx.Err() == nil {
+			w.Log.Error("checking Vault seal status", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the seal status of every server once and unseals the sealed ones.
+func (w *Watcher) Check(ctx context.Context) error {
+	targets, err := w.Targets.Targets(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, target := range targets {
+		if err := w.check(ctx, target); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Name, err))
+		}
+	}
+	if w.Metrics != nil {
+		w.Metrics.LastCheck.Set(float64(w.now().Unix()))
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Watcher) check(ctx context.Context, target Target) error {
+	if w.sealed == nil {
+		w.sealed = map[string]bool{}
+		w.failures = map[string]string{}
+	}
+	client := Client{Address: target.Address, HTTP: w.HTTP}
+	status, err := client.SealStatus(ctx)
+	if err != nil {
+		if w.Metrics != nil {
+			w.Metrics.StatusErrors.WithLabelValues(target.Name).Inc()
+		}
+		return err
+	}
+	w.setSealed(target, status.Sealed)
+	if !status.Initialized {
+		// Nothing can be unsealed before the server is initialized, which the Vault chart's post-install job does.
+		w.fail(ctx, target, ReasonNotInitialized, "Vault is not initialized, nothing to unseal")
+		return nil
+	}
+	if !status.Sealed {
+		delete(w.sealed, target.Name)
+		delete(w.failures, target.Name)
+		return nil
+	}
+
+	if !w.sealed[target.Name] {
+		w.sealed[target.Name] = true
+		w.Log.Warn("Vault is sealed", "pod", target.Name)
+		w.event(ctx, target, corev1.EventTypeWarning, ReasonSealed, "Vault is sealed, unsealing it")
+	}
+	if err := w.unseal(ctx, client, status); err != nil {
+		if w.Metrics != nil {
+			w.Metrics.Unseals.WithLabelValues(target.Name, ResultFailed).Inc()
+		}
+		w.fail(ctx, target, ReasonUnsealFailed, "Failed to unseal Vault: "+err.Error())
+		return err
+	}
+
+	delete(w.sealed, target.Name)
+	delete(w.failures, target.Name)
+	w.setSealed(target, false)
+	if w.Metrics != nil {
+		w.Metrics.Unseals.WithLabelValues(target.Name, ResultSucceeded).Inc()
+	}
+	w.Log.Info("Vault unsealed", "pod", target.Name)
+	w.event(ctx, target, corev1.EventTypeNormal, ReasonUnsealed, "Vault unsealed")
+	return nil
+}
+
+// unseal submits the key shares until the server is unsealed.
+func (w *Watcher) unseal(ctx context.Context, client Client, status SealStatus) error {
+	keys, err := w.Keys.Keys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(keys) < status.Threshold {
+		return fmt.Errorf("%d key shares available, %d needed", len(keys), status.Threshold)
+	}
+	if status.Progress > 0 {
+		// The shares of an interrupted unseal can't be told apart from ours, start over.
+		if status, err = client.ResetUnseal(ctx); err != nil {
+			return err
+		}
+	}
+	for i, key := range keys {
+		if status, err = client.Unseal(ctx, key); err != nil {
+			return fmt.Errorf("submitting key share %d: %w", i+1, err)
+		}
+		if !status.Sealed {
+			return nil
+		}
+	}
+	return fmt.Errorf("still sealed after %d key shares, progress %d of %d", len(keys), status.Progress,
+		status.Threshold)
+}
+
+func (w *Watcher) setSealed(target Target, sealed bool) {
+	if w.Metrics == nil {
+		return
+	}
+	value := 0.0
+	if sealed {
+		value = 1
+	}
+	w.Metrics.Sealed.WithLabelValues(target.Name).Set(value)
+}
+
+// fail logs a failure and records it as a warning event, unless it is the same as the last failure of the target.
+func (w *Watcher) fail(ctx context.Context, target Target, reason, message string) {
+	w.Log.Error(message, "pod", target.Name)
+	if w.failures[target.Name] == reason+message {
+		return
+	}
+	w.failures[target.Name] = reason + message
+	w.event(ctx, target, corev1.EventTypeWarning, reason, message)
+}
+
+func (w *Watcher) event(ctx context.Context, target Target, eventType, reason, message string) {
+	if w.Events == nil {
+		return
+	}
+	if err := w.Events.Event(ctx, target, eventType, reason, message); err != nil {
+		w.Log.Warn("recording event", "reason", reason, "error", err)
+	}
+}
+
+func (w *Watcher) now() time.Time {
+	if w.Now != nil {
+		return w.Now()
+	}
+	return time.Now()
+}
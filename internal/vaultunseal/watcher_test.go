@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultunseal_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultunseal"
+)
+
+// devServer stands in for a Vault dev server: it serves the seal endpoints of a server sealed with Shamir key shares.
+type devServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	keys        []string
+	threshold   int
+	initialized bool
+	sealed      bool
+	submitted   []string
+	// requests are the methods and paths of the requests, without the key shares.
+	requests []string
+}
+
+func newDevServer(keys []string, threshold int) *devServer {
+	d := &devServer{keys: keys, threshold: threshold, initialized: true, sealed: true}
+	d.Server = httptest.NewServer(http.HandlerFunc(d.serve))
+	return d
+}
+
+func (d *devServer) serve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/sys/seal-status":
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/sys/unseal":
+		var body struct {
+			Key   string `json:"key"`
+			Reset bool   `json:"reset"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"errors":["invalid request"]}`, http.StatusBadRequest)
+			return
+		}
+		if body.Reset {
+			d.submitted = nil
+			break
+		}
+		if !d.sealed {
+			break
+		}
+		if !slices.Contains(d.keys, body.Key) {
+			d.submitted = nil
+			http.Error(w, `{"errors":["cipher: message authentication failed"]}`, http.StatusBadRequest)
+			return
+		}
+		if !slices.Contains(d.submitted, body.Key) {
+			d.submitted = append(d.submitted, body.Key)
+		}
+		if len(d.submitted) >= d.threshold {
+			d.sealed = false
+			d.submitted = nil
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":        "shamir",
+		"initialized": d.initialized,
+		"sealed":      d.sealed,
+		"t":           d.threshold,
+		"n":           len(d.keys),
+		"progress":    len(d.submitted),
+		"version":     "1.21.3",
+	})
+}
+
+// seal seals the server as a restart of its pod does.
+func (d *devServer) seal() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sealed = true
+}
+
+func (d *devServer) isSealed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sealed
+}
+
+func (d *devServer) unsealRequests() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, request := range d.requests {
+		if request == "PUT /v1/sys/unseal" {
+			n++
+		}
+	}
+	return n
+}
+
+// staticKeys are key shares held in memory.
+type staticKeys []string
+
+func (s staticKeys) Keys(_ context.Context) ([]string, error) {
+	return s, nil
+}
+
+var _ = Describe("Watcher", func() {
+	var (
+		server    *devServer
+		clientset *fake.Clientset
+		metrics   *vaultunseal.Metrics
+		watcher   *vaultunseal.Watcher
+		target    vaultunseal.Target
+	)
+
+	events := func() []string {
+		list, err := clientset.CoreV1().Events("orch-platform").List(context.Background(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		var reasons []string
+		for _, event := range list.Items {
+			Expect(event.InvolvedObject.Name).To(Equal("vault-0"))
+			reasons = append(reasons, event.Type+" "+event.Reason)
+		}
+		return reasons
+	}
+
+	BeforeEach(func() {
+		server = newDevServer([]string{"a2V5MQ==", "a2V5Mg==", "a2V5Mw=="}, 2)
+		DeferCleanup(server.Close)
+		clientset = fake.NewClientset()
+		metrics = vaultunseal.NewMetrics(prometheus.NewRegistry())
+		target = vaultunseal.Target{Name: "vault-0", Namespace: "orch-platform", UID: "1", Address: server.URL}
+		watcher = &vaultunseal.Watcher{
+			Targets: staticTargets{target},
+			Keys:    staticKeys(server.keys),
+			Events:  vaultunseal.KubeRecorder{Client: clientset, Component: "vault-unseal"},
+			Metrics: metrics,
+			Log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+	})
+
+	It("unseals a sealed server with the threshold of key shares", func() {
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(server.isSealed()).To(BeFalse())
+		Expect(server.unsealRequests()).To(Equal(2))
+		Expect(events()).To(Equal([]string{"Warning VaultSealed", "Normal VaultUnsealed"}))
+		Expect(testutil.ToFloat64(metrics.Sealed.WithLabelValues("vault-0"))).To(Equal(0.0))
+		Expect(testutil.ToFloat64(metrics.Unseals.WithLabelValues("vault-0", vaultunseal.ResultSucceeded))).
+			To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.LastCheck)).To(BeNumerically(">", 0))
+	})
+
+	It("leaves an unsealed server alone", func() {
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(server.unsealRequests()).To(Equal(2))
+		Expect(events()).To(HaveLen(2))
+	})
+
+	It("starts over an interrupted unseal", func() {
+		_, err := vaultunseal.Client{Address: server.URL}.Unseal(context.Background(), server.keys[2])
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(server.isSealed()).To(BeFalse())
+		// The reset and the two shares after the share of the interrupted unseal.
+		Expect(server.unsealRequests()).To(Equal(4))
+	})
+
+	It("reports a failed unseal once and keeps retrying", func() {
+		watcher.Keys = staticKeys{"d3Jvbmc=", "a2V5MQ=="}
+
+		err := watcher.Check(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("submitting key share 1")))
+		Expect(err).To(MatchError(ContainSubstring("cipher: message authentication failed")))
+		Expect(err).NotTo(MatchError(ContainSubstring("d3Jvbmc=")))
+		Expect(watcher.Check(context.Background())).NotTo(Succeed())
+		Expect(server.isSealed()).To(BeTrue())
+		Expect(events()).To(Equal([]string{"Warning VaultSealed", "Warning VaultUnsealFailed"}))
+		Expect(testutil.ToFloat64(metrics.Sealed.WithLabelValues("vault-0"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.Unseals.WithLabelValues("vault-0", vaultunseal.ResultFailed))).
+			To(Equal(2.0))
+
+		watcher.Keys = staticKeys(server.keys)
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(server.isSealed()).To(BeFalse())
+	})
+
+	It("doesn't submit key shares when there aren't enough of them", func() {
+		watcher.Keys = staticKeys{"a2V5MQ=="}
+		Expect(watcher.Check(context.Background())).To(MatchError(ContainSubstring("1 key shares available, 2 needed")))
+		Expect(server.unsealRequests()).To(BeZero())
+	})
+
+	It("doesn't unseal a server that isn't initialized", func() {
+		server.initialized = false
+		Expect(watcher.Check(context.Background())).To(Succeed())
+		Expect(server.unsealRequests()).To(BeZero())
+		Expect(events()).To(Equal([]string{"Warning VaultNotInitialized"}))
+	})
+
+	It("counts the servers that don't answer", func() {
+		server.Close()
+		Expect(watcher.Check(context.Background())).NotTo(Succeed())
+		Expect(testutil.ToFloat64(metrics.StatusErrors.WithLabelValues("vault-0"))).To(Equal(1.0))
+	})
+
+	It("unseals the server again after it is sealed by a restart", func() {
+		watcher.Interval = 10 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- watcher.Run(ctx) }()
+
+		Eventually(server.isSealed).Should(BeFalse())
+		server.seal()
+		Eventually(server.isSealed).Should(BeFalse())
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(testutil.ToFloat64(metrics.Unseals.WithLabelValues("vault-0", vaultunseal.ResultSucceeded))).
+			To(Equal(2.0))
+	})
+})
+
+// staticTargets are fixed targets.
+type staticTargets []vaultunseal.Target
+
+func (s staticTargets) Targets(_ context.Context) ([]vaultunseal.Target, error) {
+	return s, nil
+}
+
+var _ = Describe("PodTargets", func() {
+	It("returns the running Vault pods", func() {
+		pod := func(name string, phase corev1.PodPhase, ip string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "orch-platform",
+					UID:       types.UID("uid-" + name),
+					Labels:    map[string]string{"app.kubernetes.io/name": "vault"},
+				},
+				Status: corev1.PodStatus{Phase: phase, PodIP: ip},
+			}
+		}
+		clientset := fake.NewClientset(
+			pod("vault-0", corev1.PodRunning, "10.42.0.12"),
+			pod("vault-1", corev1.PodPending, ""),
+		)
+		targets, err := vaultunseal.PodTargets{
+			Client:    clientset,
+			Namespace: "orch-platform",
+			Selector:  "app.kubernetes.io/name=vault",
+			Port:      8200,
+			Scheme:    "http",
+		}.Targets(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets).To(Equal([]vaultunseal.Target{{
+			Name:      "vault-0",
+			Namespace: "orch-platform",
+			UID:       "uid-vault-0",
+			Address:   "http://10.42.0.12:8200",
+		}}))
+	})
+})
+
+var _ = Describe("Key sources", func() {
+	const keys = `{"keys": ["6b6579"], "keys_base64": ["a2V5MQ==", "a2V5Mg=="], "root_token": "hvs.root"}`
+
+	It("reads the key shares of the vault-keys secret", func() {
+		clientset := fake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault-keys", Namespace: "orch-platform"},
+			Data:       map[string][]byte{"vault-keys": []byte(keys)},
+		})
+		source := vaultunseal.SecretKeys{
+			Client: clientset, Namespace: "orch-platform", Name: "vault-keys", Key: "vault-keys",
+		}
+		Expect(source.Keys(context.Background())).To(Equal([]string{"a2V5MQ==", "a2V5Mg=="}))
+
+		source.Key = "keys"
+		_, err := source.Keys(context.Background())
+		Expect(err).To(MatchError("secret orch-platform/vault-keys has no keys"))
+	})
+
+	It("reads the key shares of a mounted secret", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "vault-keys")
+		Expect(os.WriteFile(path, []byte(keys), 0o600)).To(Succeed())
+		Expect(vaultunseal.FileKeys{Path: path}.Keys(context.Background())).To(Equal([]string{"a2V5MQ==", "a2V5Mg=="}))
+
+		Expect(os.WriteFile(path, []byte(`{"root_token": "hvs.root"}`), 0o600)).To(Succeed())
+		_, err := vaultunseal.FileKeys{Path: path}.Keys(context.Background())
+		Expect(err).To(MatchError("no keys_base64 in keys"))
+	})
+})
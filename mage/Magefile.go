@@ -28,6 +28,8 @@ import (
 	"github.com/magefile/mage/mg"
 	"github.com/magefile/mage/sh"
 	"gopkg.in/yaml.v3"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
 )
 
 const (
@@ -148,13 +150,21 @@ func AsdfPlugins() error {
 
 // Cleans up the local environment by removing all generated files and directories 🧹
 func Clean(ctx context.Context) error {
-	for _, path := range []string{
+	paths := []string{
 		// Keep list sorted in ascending order for easier maintenance
-		"cloudFull_edge-manageability-framework_*.tgz",
 		"COMMIT_ID",
-		"onpremFull_edge-manageability-framework_*.tgz",
 		"edge-manageability-framework",
-	} {
+	}
+
+	variants, err := loadTarballVariants()
+	if err != nil {
+		return fmt.Errorf("failed to load tarball variants: %w", err)
+	}
+	for _, variant := range variants {
+		paths = append(paths, fmt.Sprintf("%s_%s_*.tgz", variant.Name, edgeManageabilityFramework))
+	}
+
+	for _, path := range paths {
 		matches, err := filepath.Glob(path)
 		if err != nil {
 			return fmt.Errorf("failed to glob %s: %w", path, err)
@@ -435,6 +445,10 @@ func (u Undeploy) OnPrem(ctx context.Context) error {
 		u.EdgeStoragePool,
 	)
 
+	if err := u.removeLibvirtLeftovers(); err != nil {
+		return err
+	}
+
 	fmt.Println("Orchestrator deployment destroyed 🗑️")
 
 	return nil
@@ -660,7 +674,7 @@ func (d Deploy) EdgeNetwork(ctx context.Context) error {
 		return fmt.Errorf("terraform apply failed: %w", err)
 	}
 
-	return nil
+	return recordTerraformResource(uninstall.KindLibvirtNetwork, "edge-network")
 }
 
 // Sets the edge network DNS resolver as the default resolver on the host machine.
@@ -771,7 +785,7 @@ func (Deploy) EdgeStoragePool(ctx context.Context) error {
 		return fmt.Errorf("terraform apply failed: %w", err)
 	}
 
-	return nil
+	return recordTerraformResource(uninstall.KindLibvirtPool, "edge-storage-pool")
 }
 
 // Deploy orchestrator locally using edge-manageability-framework revision defined by local git HEAD.
@@ -1465,6 +1479,13 @@ func (a Argo) AddLocalRepos() error {
 	return nil
 }
 
+// Remediates the known failures of the ArgoCD applications of the lowest sync wave that is not synced and healthy, or
+// of the root-app once all others are. Remediations are rate limited and recorded in the audit log set by
+// ARGO_HEAL_AUDIT_LOG (default .argo-heal-audit.log).
+func (a Argo) Heal() error {
+	return a.heal()
+}
+
 // Lists all ArgoCD Applications, sorted by syncWave.
 func (a Argo) AppSeq() error {
 	return a.appSeq()
@@ -1544,19 +1565,83 @@ func (d Database) PSQL() error {
 	return d.psql()
 }
 
+// Reports the sizes of the databases, the tables with the most dead tuples, the connections per role, the
+// longest-running queries, the replication lag and the last successful backup of the postgres cluster behind the
+// read-write service. Prints tables, or JSON with DB_REPORT_FORMAT=json, and also writes the JSON to DB_REPORT_JSON if
+// set. Fails when a threshold is exceeded, so that it can gate CI; the thresholds are set with
+// DB_REPORT_MAX_DATABASE_SIZE, DB_REPORT_MAX_DEAD_RATIO, DB_REPORT_MIN_DEAD_TUPLES, DB_REPORT_MAX_CONNECTIONS_RATIO,
+// DB_REPORT_MAX_QUERY_DURATION, DB_REPORT_MAX_REPLICATION_LAG and DB_REPORT_MAX_BACKUP_AGE, 0 disabling a check.
+func (d Database) Report() error {
+	return d.report()
+}
+
+// Namespace contains Backup targets.
+type Backup mg.Namespace
+
+// Backs up the Orchestrator to a versioned archive in BACKUP_DIR (default backups): dumps of the postgres databases,
+// a Vault raft snapshot, the Secrets and ConfigMaps of the orch-* namespaces and the OpenEBS hostpath volumes. Run it
+// on the node of the volumes.
+func (b Backup) Create() error {
+	return b.create()
+}
+
+// Restores the Orchestrator from a backup archive after checking its integrity and compatibility with the
+// deployment. Set BACKUP_FORCE=true to restore an archive of another Orchestrator or postgres version.
+func (b Backup) Restore(archive string) error {
+	return b.restore(archive)
+}
+
+// Lists the backup archives in BACKUP_DIR (default backups) and the result of the last scheduled backup. Set
+// BACKUP_DIR=/var/backups/orch for the backups of the onprem-backup timer.
+func (b Backup) List() error {
+	return b.list()
+}
+
 // Namespace contains Vault targets.
 type Vault mg.Namespace
 
-// Retrieves vault keys and root token after deployment and writes vault keys to a local file.
+// Splits the vault key shares between custodians after deployment. Each share is written to its own file in
+// VAULT_SHARES_DIR (vault-shares by default), encrypted with the passphrase of its custodian, so that no single file
+// unseals vault. VAULT_CUSTODIANS is the comma-separated list of custodians, one per key share by default. Fewer
+// custodians than key shares leave the remaining shares out of custody and need VAULT_CUSTODY_PARTIAL=true.
 func (v Vault) Keys() error {
 	return v.keys()
 }
 
-// Unseals vault with the keys provided in a k8s secret.
+// Unseals vault with the share files or directories of share files in VAULT_SHARES, asking for the passphrases of
+// their custodians, with the key shares typed in if VAULT_UNSEAL_PROMPT is true, or else with the keys provided in a
+// k8s secret.
 func (v Vault) Unseal() error {
 	return v.unseal()
 }
 
+// Shows the seal status, the HA status and the raft peers of vault. VAULT_TOKEN is used instead of the root token of
+// the k8s secret if set.
+func (v Vault) Status() error {
+	return v.status()
+}
+
+// Writes a raft snapshot of vault to vault-<time>.snap.
+func (v Vault) Snapshot() error {
+	return v.snapshot()
+}
+
+// Restores a raft snapshot of vault. Set VAULT_RESTORE_FORCE=true to restore the snapshot of another vault cluster.
+func (v Vault) Restore(snapshot string) error {
+	return v.restore(snapshot)
+}
+
+// Generates a new vault root token with the key shares, stores it in the k8s secret and revokes the previous one. The
+// shares are read from the share files in VAULT_SHARES if set, as in vault:unseal.
+func (v Vault) RotateRoot() error {
+	return v.rotateRoot()
+}
+
+// Lists the KV secrets of vault with their last modification and the policies granting access to them.
+func (v Vault) AuditSecrets() error {
+	return v.auditSecrets()
+}
+
 // Namespace contains test targets.
 type Test mg.Namespace
 
@@ -1866,6 +1951,11 @@ func (g Gen) FirewallDoc() error {
 	return g.firewallDoc()
 }
 
+// Print Markdown release notes for the changes between two git refs: mage gen:releaseNotes <fromRef> <toRef>
+func (g Gen) ReleaseNotes(fromRef string, toRef string) error {
+	return g.releaseNotes(fromRef, toRef)
+}
+
 type Config mg.Namespace
 
 // Create a cluster deployment configuration from a cluster values file.
@@ -1952,19 +2042,29 @@ func (a App) Upload() error {
 
 type Tarball mg.Namespace
 
+// Variant Creates a Tarball of artifacts for a variant declared in mage/tarball-variants.yaml
+func (t Tarball) Variant(name string) error {
+	return t.setupCollectors(name)
+}
+
+// List Lists the Tarball variants declared in mage/tarball-variants.yaml
+func (t Tarball) List() error {
+	return t.list()
+}
+
 // OnpremFull Creates a Tarball of artifacts for OnPrem deployment of Full orchestrator
 func (t Tarball) OnpremFull() error {
-	return t.setupCollectors("onpremFull", []string{"onprem", "onprem-explicit-proxy", "onprem-1k"})
+	return t.setupCollectors("onpremFull")
 }
 
 // OnpremFullIntel Creates a Tarball of artifacts for OnPrem deployment of orchestrator inside Intel
 func (t Tarball) OnpremFullIntel() error {
-	return t.setupCollectors("onpremFullIntel", []string{"onprem-dev", "onprem-dev-explicit-proxy"})
+	return t.setupCollectors("onpremFullIntel")
 }
 
 // CloudFull Creates a Tarball of artifacts for Cloud deployment of Full orchestrator
 func (t Tarball) CloudFull() error {
-	return t.setupCollectors("cloudFull", []string{"example", "example-staging"})
+	return t.setupCollectors("cloudFull")
 }
 
 type CoUtils mg.Namespace
@@ -1995,4 +2095,15 @@ func (v Version) SetVersion() error {
 	return v.setVersion()
 }
 
+// Bumps the version in VERSION, on-prem-installers/VERSION and the Argo Charts: mage version:bump <major|minor|patch|rc|dev>
+func (v Version) Bump(kind string) error {
+	return v.bump(kind, false)
+}
+
+// Bumps the version like version:bump and commits it on the release-<major>.<minor> branch. An existing branch is checked
+// out and bumped from its own version, a missing one is created from the current HEAD
+func (v Version) BumpRelease(kind string) error {
+	return v.bump(kind, true)
+}
+
 type TenantUtils mg.Namespace
@@ -7,7 +7,9 @@ package mage
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -18,8 +20,15 @@ import (
 	"time"
 
 	"github.com/bitfield/script"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
 )
 
+// argoHealAuditLog is where the remediations of the ArgoCD applications are recorded, unless ARGO_HEAL_AUDIT_LOG is
+// set.
+const argoHealAuditLog = ".argo-heal-audit.log"
+
 var dockerHubChartOrgs = []string{"bitnamicharts"}
 
 func initialSecretInternal() (string, error) {
@@ -206,3 +215,40 @@ func (Argo) appSeq() error {
 	}
 	return nil
 }
+
+// newArgoHealer returns a healer of the ArgoCD applications that leaves failures alone for grace, so that ArgoCD can
+// recover from them by itself.
+func newArgoHealer(grace time.Duration) *argoheal.Healer {
+	auditLog := os.Getenv("ARGO_HEAL_AUDIT_LOG")
+	if auditLog == "" {
+		auditLog = argoHealAuditLog
+	}
+	return &argoheal.Healer{
+		X:        executor.Local{},
+		Limits:   argoheal.Limits{Grace: grace},
+		AuditLog: auditLog,
+		Log:      slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+}
+
+func (Argo) heal() error {
+	healer := newArgoHealer(0)
+	actions, err := healer.Heal()
+	if err != nil {
+		return fmt.Errorf("heal argocd applications: %w", err)
+	}
+	if len(actions) == 0 {
+		fmt.Println("No known failures to remediate 🟢")
+		return nil
+	}
+
+	var errs []error
+	for _, action := range actions {
+		fmt.Printf("Remediated %s/%s (wave %d): %s\n", action.Namespace, action.App, action.Wave, action.Signature)
+		if action.Error != "" {
+			errs = append(errs, fmt.Errorf("remediate %s: %s", action.App, action.Error))
+		}
+	}
+	fmt.Printf("Remediations recorded in %s\n", healer.AuditLog)
+	return errors.Join(errs...)
+}
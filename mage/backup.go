@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchbackup"
+)
+
+// defaultBackupDir is where backup archives are written, unless BACKUP_DIR is set.
+const defaultBackupDir = "backups"
+
+func newArchiver() *orchbackup.Archiver {
+	dir := os.Getenv("BACKUP_DIR")
+	if dir == "" {
+		dir = defaultBackupDir
+	}
+	return &orchbackup.Archiver{
+		Dir: dir,
+		X:   executor.Local{},
+		Log: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+}
+
+func (Backup) create() error {
+	archiver := newArchiver()
+	if err := os.MkdirAll(archiver.Dir, 0o700); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	archive, err := archiver.Create()
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	fmt.Printf("Backup written to %s 💾\n", archive)
+	return nil
+}
+
+func (Backup) restore(archive string) error {
+	archiver := newArchiver()
+	if err := os.MkdirAll(archiver.Dir, 0o700); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	opts := orchbackup.RestoreOptions{Force: os.Getenv("BACKUP_FORCE") == "true"}
+	if err := archiver.Restore(archive, opts); err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
+	fmt.Printf("Backup %s restored 🟢\n", archive)
+	return nil
+}
+
+func (Backup) list() error {
+	dir := os.Getenv("BACKUP_DIR")
+	if dir == "" {
+		dir = defaultBackupDir
+	}
+	status, err := orchbackup.ReadStatus(dir)
+	if err != nil {
+		return err
+	}
+	archives, err := orchbackup.ListArchives(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("No backups in %s\n", dir)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if last, ok := status.Last(); ok {
+		switch {
+		case last.Result == orchbackup.ResultSucceeded:
+			fmt.Printf("Last scheduled backup succeeded at %s 🟢\n", last.FinishedAt.Local().Format(time.DateTime))
+		default:
+			fmt.Printf("Last scheduled backup failed at %s: %s 🔴\n", last.FinishedAt.Local().Format(time.DateTime),
+				last.Error)
+			if success, ok := status.LastSuccess(); ok {
+				fmt.Printf("Last successful scheduled backup at %s\n", success.FinishedAt.Local().Format(time.DateTime))
+			}
+		}
+		fmt.Printf("Free space before the last backup: %.1f GiB\n\n", float64(last.FreeBytes)/(1<<30))
+	}
+
+	if len(archives) == 0 {
+		fmt.Printf("No backups in %s\n", dir)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ARCHIVE\tCREATED\tVERSION\tSIZE")
+	for _, archive := range archives {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.1f MiB\n", archive.Name(), archive.CreatedAt.Local().Format(time.DateTime),
+			archive.OrchestratorVersion, float64(archive.Size)/(1<<20))
+	}
+	return w.Flush()
+}
@@ -15,6 +15,8 @@ import (
 	"text/template"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/clusterconfig"
 )
 
 // Add default values if not specified in the parsed presetData.
@@ -58,82 +60,9 @@ func writeMapAsYAML(data map[string]interface{}) (string, error) {
 	return sb.String(), nil
 }
 
-// deepMerge performs a deep merge of newValuesMap into baseMap.
-func deepMerge(baseMap, newValuesMap map[string]interface{}) {
-	for key, newValue := range newValuesMap {
-		if baseValue, exists := baseMap[key]; exists {
-			// If both values are maps, perform a recursive merge.
-			baseMapAsMap, baseIsMap := baseValue.(map[string]interface{})
-			newValueAsMap, newIsMap := newValue.(map[string]interface{})
-			if baseIsMap && newIsMap {
-				deepMerge(baseMapAsMap, newValueAsMap)
-			} else {
-				// Overwrite the value in baseMap if it's not a map or types differ.
-				baseMap[key] = newValue
-			}
-		} else {
-			// Add the new value to baseMap if it doesn't exist.
-			baseMap[key] = newValue
-		}
-	}
-}
-
 // parseClusterValues loads and merges values from a cluster configuration file and its referenced files.
 func parseClusterValues(clusterConfigPath string) (map[string]interface{}, error) {
-	data, err := os.ReadFile(clusterConfigPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read cluster configuration file: %w", err)
-	}
-
-	var rootConfig map[string]interface{}
-	if err := yaml.Unmarshal(data, &rootConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cluster configuration: %w", err)
-	}
-
-	clusterValues := make(map[string]interface{})
-	if root, ok := rootConfig["root"].(map[string]interface{}); ok {
-		if clusterValuesPaths, ok := root["clusterValues"].([]interface{}); ok {
-			for _, path := range clusterValuesPaths {
-				filePath, ok := path.(string)
-				if !ok {
-					return nil, fmt.Errorf("invalid clusterValues entry, expected string but got %T", path)
-				}
-				fileData, err := os.ReadFile(filePath)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read cluster values file '%s': %w", filePath, err)
-				}
-
-				var fileValues map[string]interface{}
-				if err := yaml.Unmarshal(fileData, &fileValues); err != nil {
-					return nil, fmt.Errorf("failed to unmarshal cluster values from file '%s': %w", filePath, err)
-				}
-				if filePath != clusterConfigPath {
-					deepMerge(clusterValues, fileValues)
-				} else {
-					if root, ok := fileValues["root"].(map[string]interface{}); ok {
-						delete(root, "clusterValues")
-					}
-					deepMerge(clusterValues, fileValues)
-				}
-			}
-		} else {
-			return nil, fmt.Errorf("invalid cluster definition: 'clusterValues' list is missing in the configuration")
-		}
-	} else {
-		return nil, fmt.Errorf("invalid cluster definition: 'root' key is missing in the configuration")
-	}
-
-	// merge the cluster template into itself
-	var fileValues map[string]interface{}
-	if err := yaml.Unmarshal(data, &fileValues); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cluster template: %w", err)
-	}
-	if root, ok := fileValues["root"].(map[string]interface{}); ok {
-		delete(root, "clusterValues")
-	}
-	deepMerge(clusterValues, fileValues)
-
-	return clusterValues, nil
+	return clusterconfig.Merge(os.DirFS("."), clusterConfigPath)
 }
 
 func (Config) overrideFromEnvironment(presetData map[string]interface{}) error {
@@ -324,7 +253,7 @@ func renderClusterTemplate(presetData map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("failed to unmarshal proxy values: %w", err)
 	}
 
-	deepMerge(clusterValues, proxyValues)
+	clusterconfig.DeepMerge(clusterValues, proxyValues)
 
 	mergedYaml, err := writeMapAsYAML(clusterValues)
 	if err != nil {
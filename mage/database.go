@@ -5,11 +5,29 @@
 package mage
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
+	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/cnpg"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/dbreport"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/retry"
+)
+
+const (
+	databaseNamespace = "orch-database"
+	// postgresCluster is the CloudNativePG cluster of the Orchestrator.
+	postgresCluster = "postgresql-cluster"
+	// postgresPortForwardPort is the local port of the port-forward to the read-write service of the cluster.
+	postgresPortForwardPort = "5433"
 )
 
 // getPassword retrieves the admin password for the local postgres database.
@@ -26,20 +44,22 @@ func (Database) getPassword() (string, error) {
 	return string(pass), nil
 }
 
-// psql runs an interactive shell, or the given SQL statements, against the local postgres database.
+// psql runs an interactive shell, or the given SQL statements, against the local postgres database. The client of
+// the primary behind the read-write service is used, so that it always matches the version of the server, also after
+// a major-version upgrade switched the service to a new cluster.
 func (d Database) psql(commands ...string) error {
 	pgPass, err := d.getPassword()
 	if err != nil {
 		return err
 	}
-	envMap := make(map[string]string)
-	envMap["POSTGRES_PASSWORD"] = pgPass
+	pod, err := cnpg.ServicePrimary(executor.Local{}, databaseNamespace, postgresCluster+"-rw")
+	if err != nil {
+		return err
+	}
 
-	var args []string
-	args = append(args, "run", "postgresql-db-client", "--rm", "--tty", "-i",
-		"--restart=Never", "--namespace", "orch-database", "--image",
-		"docker.io/library/postgres:14.19-alpine3.22", "--env=PGPASSWORD=$POSTGRES_PASSWORD",
-		"--command", "--", "psql", "--host", "postgresql-cluster-rw", "-U", "orch-database-postgresql_user", "-d", "postgres", "-p", "5432")
+	args := []string{"exec", "--tty", "-i", "--namespace", databaseNamespace, pod, "-c", "postgres", "--",
+		"env", "PGPASSWORD=" + pgPass, "psql", "--host", postgresCluster + "-rw", "-U",
+		"orch-database-postgresql_user", "-d", "postgres", "-p", "5432"}
 
 	if len(commands) > 0 {
 		commandStr := strings.Join(commands, " ")
@@ -47,6 +67,136 @@ func (d Database) psql(commands ...string) error {
 		args = append(args, "-c", commandStr)
 	}
 
-	err = sh.RunWithV(envMap, "kubectl", args...)
-	return err
+	return sh.RunV("kubectl", args...)
+}
+
+func (Database) report() error {
+	thresholds, err := dbreport.ThresholdsFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	format := os.Getenv("DB_REPORT_FORMAT")
+	if format == "" {
+		format = "table"
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported DB_REPORT_FORMAT %q, expected table or json", format)
+	}
+
+	connect, stop, err := postgresPortForward()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	collector := dbreport.Collector{Connect: connect, Tables: 10, Queries: 10}
+	report, err := collector.Collect(ctx)
+	if err != nil {
+		return err
+	}
+	report.Time = time.Now()
+	if report.Cluster, err = postgresClusterState(); err != nil {
+		return err
+	}
+	report.Violations = dbreport.Evaluate(report, thresholds, report.Time)
+
+	if format == "json" {
+		err = dbreport.WriteJSON(os.Stdout, report)
+	} else {
+		err = dbreport.WriteTable(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+	// CI keeps the JSON as an artifact next to the table in its log.
+	if path := os.Getenv("DB_REPORT_JSON"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", path, err)
+		}
+		defer file.Close()
+		if err := dbreport.WriteJSON(file, report); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	if len(report.Violations) > 0 {
+		return fmt.Errorf("%d database health thresholds exceeded", len(report.Violations))
+	}
+	return nil
+}
+
+// postgresPortForward forwards a local port to the read-write service of the cluster and returns how to connect to
+// its databases as the superuser, so that the statistics of every role are visible.
+func postgresPortForward() (dbreport.Connector, func(), error) {
+	credentials := map[string]string{}
+	for _, key := range []string{"username", "password"} {
+		encoded, err := sh.Output("kubectl", "get", "secret", postgresCluster+"-superuser", "-n", databaseNamespace,
+			"-o", "jsonpath={.data."+key+"}")
+		if err != nil {
+			return nil, nil, fmt.Errorf("read the postgres superuser: %w", err)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode the postgres superuser %s: %w", key, err)
+		}
+		credentials[key] = string(value)
+	}
+
+	cmd := exec.Command("kubectl", "port-forward", "-n", databaseNamespace, "svc/"+postgresCluster+"-rw",
+		postgresPortForwardPort+":5432")
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start postgres port-forward: %w", err)
+	}
+	stop := func() {
+		if err := cmd.Process.Kill(); err != nil {
+			fmt.Printf("Warning: failed to stop postgres port-forward: %v\n", err)
+		}
+		_ = cmd.Wait()
+	}
+
+	// The server certificate is issued for the service name, so TLS is used without verifying it.
+	connect := func(ctx context.Context, database string) (*pgx.Conn, error) {
+		config, err := pgx.ParseConfig(fmt.Sprintf("host=127.0.0.1 port=%s dbname=%s sslmode=require",
+			postgresPortForwardPort, database))
+		if err != nil {
+			return nil, err
+		}
+		config.User = credentials["username"]
+		config.Password = credentials["password"]
+		return pgx.ConnectConfig(ctx, config)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := retry.UntilItSucceeds(ctx, func() error {
+		conn, err := connect(ctx, "postgres")
+		if err != nil {
+			return err
+		}
+		return conn.Close(ctx)
+	}, time.Second); err != nil {
+		stop()
+		return nil, nil, fmt.Errorf("postgres port-forward not ready: %w", err)
+	}
+	return connect, stop, nil
+}
+
+// postgresClusterState returns the state of the CloudNativePG cluster behind the read-write service and its last
+// successful backup. After a major-version upgrade that is the new cluster, not the one named postgresCluster.
+func postgresClusterState() (dbreport.Cluster, error) {
+	name, err := cnpg.ServiceCluster(executor.Local{}, databaseNamespace, postgresCluster+"-rw")
+	if err != nil {
+		return dbreport.Cluster{}, fmt.Errorf("find the postgres cluster: %w", err)
+	}
+	cluster, err := sh.Output("kubectl", "get", "clusters.postgresql.cnpg.io", name, "-n",
+		databaseNamespace, "-o", "json")
+	if err != nil {
+		return dbreport.Cluster{}, fmt.Errorf("get the postgres cluster: %w", err)
+	}
+	backups, err := sh.Output("kubectl", "get", "backups.postgresql.cnpg.io", "-n", databaseNamespace, "-o", "json")
+	if err != nil {
+		return dbreport.Cluster{}, fmt.Errorf("get the postgres backups: %w", err)
+	}
+	return dbreport.ParseCluster([]byte(cluster), []byte(backups))
 }
@@ -27,6 +27,8 @@ import (
 	"github.com/bitfield/script"
 	"github.com/magefile/mage/mg"
 	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
 )
 
 var pwChars = []rune(`abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789`)
@@ -1241,7 +1243,11 @@ func getDeployTag() (string, error) {
 	} else {
 		deployTag = strings.TrimSpace(string(versionBuf))
 	}
-	if strings.Contains(deployTag, "-dev") {
+	deploySemver, err := semver.Parse(deployTag)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse version file: %w", err)
+	}
+	if deploySemver.IsDev() {
 		deployRevision := getDeployRevision()
 		if len(deployRevision) == 0 {
 			return "", fmt.Errorf("failed to get edge-manageability-framework revision")
@@ -1254,7 +1260,11 @@ func getDeployTag() (string, error) {
 }
 
 func getVersionRevision(version string) (string, error) {
-	if strings.Contains(version, "-dev") {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", err
+	}
+	if v.IsDev() {
 		c, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
 		if err != nil {
 			return "", err
@@ -21,6 +21,8 @@ import (
 
 	"github.com/bitfield/script"
 	"gopkg.in/yaml.v3"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
 )
 
 func (Gen) dockerImageManifest() error {
@@ -254,19 +256,24 @@ func parseAppConfig(filename string) ([]*ComponentDetails, error) {
 }
 
 func getManifest() (*Manifest, error) {
-	var manifest Manifest
-	var err error
-
-	repoDir := getDeployDir()
 	configsDir := getConfigsDir()
 	if _, err := os.Stat(configsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("invalid config directory: %s", configsDir)
 	}
 
+	return getManifestFromDir(getDeployDir(), getDeployRevision())
+}
+
+// getManifestFromDir builds the release manifest from the deploy repo checked out at repoDir, gitHash being the
+// revision it is checked out at.
+func getManifestFromDir(repoDir string, gitHash string) (*Manifest, error) {
+	var manifest Manifest
+	var err error
+
 	manifest.Components = make(map[string]ComponentDetails)
 	manifest.Type = "argocd"
 
-	manifest.GitHash = getDeployRevision()
+	manifest.GitHash = gitHash
 	manifest.GitOrigin, err = getGitOriginPath(repoDir)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get deploy repo origins: %w", err)
@@ -779,35 +786,22 @@ func getImageManifest() ([]string, []string, error) {
 		return nil, nil, fmt.Errorf("error parsing templated charts: %w", err)
 	}
 
-	binaryList := []string{}
-	// Add OCI Tarball deployment artifacts
-	tarballRepos := []string{"orchestrator"}
-	tarballVariants := []string{"cloudFull", "onpremFull"}
-	installVariants := []string{"cloudFull"}
-
 	deployTag, err := getDeployTag()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get tag for deployment artifacts: %w", err)
 	}
 
-	for _, variant := range tarballVariants {
-		for _, repo := range tarballRepos {
-			// buildImageBasePath = ${REGISTRY}"/"${REGISTRY_PROJECT}"/"${SUB_COMPONENT_NAME}"/"${ARTIFACT_TYPE}"
-			// oras push $buildImageBasePath"/"${repo}"/"${VARIANT_LC}":"${TAG}"
-			binaryList = append(binaryList, fmt.Sprintf("%s/%s/%s:%s", binaryBasePath, repo,
-				strings.ToLower(variant), deployTag))
-		}
+	// Add OCI Tarball deployment artifacts and installer images declared by the tarball variants
+	tarballBinaries, installerImages, err := variantReleaseArtifacts(deployTag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tarball variant artifacts: %w", err)
 	}
 
+	binaryList := []string{}
+	binaryList = append(binaryList, tarballBinaries...)
 	binaryList = append(binaryList, fmt.Sprintf("%s/cloud-orchestrator-installer:%s", binaryBasePath, deployTag))
 
-	// Add OCI installer deployment artifacts
-	for _, installVariant := range installVariants {
-		// buildImageBasePath = ${REGISTRY}"/"${REGISTRY_PROJECT}"/
-		// oras push $buildImageBasePath"/"${repo}"/"${VARIANT_LC}":"${TAG}"
-		imageList = append(imageList, fmt.Sprintf("%s/orchestrator-installer-%s:%s", installBasePath,
-			strings.ToLower(installVariant), deployTag))
-	}
+	imageList = append(imageList, installerImages...)
 
 	return imageList, binaryList, nil
 }
@@ -875,35 +869,22 @@ func getLocalImageManifest() ([]string, []string, error) {
 		return nil, nil, fmt.Errorf("error parsing templated charts: %w", err)
 	}
 
-	binaryList := []string{}
-	// Add OCI Tarball deployment artifacts
-	tarballRepos := []string{"orchestrator"}
-	tarballVariants := []string{"cloudFull", "onpremFull"}
-	installVariants := []string{"cloudFull"}
-
 	deployTag, err := getDeployTag()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get tag for deployment artifacts: %w", err)
 	}
 
-	for _, variant := range tarballVariants {
-		for _, repo := range tarballRepos {
-			// buildImageBasePath = ${REGISTRY}"/"${REGISTRY_PROJECT}"/"${SUB_COMPONENT_NAME}"/"${ARTIFACT_TYPE}"
-			// oras push $buildImageBasePath"/"${repo}"/"${VARIANT_LC}":"${TAG}"
-			binaryList = append(binaryList, fmt.Sprintf("%s/%s/%s:%s", binaryBasePath, repo,
-				strings.ToLower(variant), deployTag))
-		}
+	// Add OCI Tarball deployment artifacts and installer images declared by the tarball variants
+	tarballBinaries, installerImages, err := variantReleaseArtifacts(deployTag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tarball variant artifacts: %w", err)
 	}
 
+	binaryList := []string{}
+	binaryList = append(binaryList, tarballBinaries...)
 	binaryList = append(binaryList, fmt.Sprintf("%s/cloud-orchestrator-installer:%s", binaryBasePath, deployTag))
 
-	// Add OCI installer deployment artifacts
-	for _, installVariant := range installVariants {
-		// buildImageBasePath = ${REGISTRY}"/"${REGISTRY_PROJECT}"/
-		// oras push $buildImageBasePath"/"${repo}"/"${VARIANT_LC}":"${TAG}"
-		imageList = append(imageList, fmt.Sprintf("%s/orchestrator-installer-%s:%s", installBasePath,
-			strings.ToLower(installVariant), deployTag))
-	}
+	imageList = append(imageList, installerImages...)
 
 	return imageList, binaryList, nil
 }
@@ -938,6 +919,11 @@ func GetDebVersion() (string, error) {
 		return "", fmt.Errorf("read version from 'VERSION' file: %w", err)
 	}
 
+	repoSemver, err := semver.Parse(repoVersion)
+	if err != nil {
+		return "", fmt.Errorf("parse version from 'VERSION' file: %w", err)
+	}
+
 	// get branch name
 	branchName, err := GetBranchName()
 	if err != nil {
@@ -950,7 +936,7 @@ func GetDebVersion() (string, error) {
 		strings.HasPrefix(branchName, "release")
 
 	// If release version on release branches, return the version as is
-	if isReleaseBranch && !strings.Contains(repoVersion, "-dev") {
+	if isReleaseBranch && !repoSemver.IsDev() {
 		return repoVersion, nil // e.g., 3.0.0, 3.0.0-rc1, 3.0.0-n20250306
 	}
 
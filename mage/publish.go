@@ -69,19 +69,22 @@ func (Publish) SourceTarballs(ctx context.Context) error {
 		)
 	}
 
-	for _, variant := range []string{"cloudFull", "onpremFull"} {
-		if err := buildVariant(ctx, variant); err != nil {
-			return fmt.Errorf("failed to build variant %s: %w", variant, err)
+	variants, err := publishedTarballVariants()
+	if err != nil {
+		return fmt.Errorf("failed to load tarball variants: %w", err)
+	}
+
+	for _, variant := range variants {
+		if err := buildVariant(ctx, variant.Name); err != nil {
+			return fmt.Errorf("failed to build variant %s: %w", variant.Name, err)
 		}
 
-		fileName := fmt.Sprintf("%s_edge-manageability-framework_%s.tgz", variant, defaultRepoVersionStr)
+		fileName := fmt.Sprintf("%s_edge-manageability-framework_%s.tgz", variant.Name, defaultRepoVersionStr)
 		if _, err := os.Stat(fileName); os.IsNotExist(err) {
 			return fmt.Errorf("file %s does not exist: %w", fileName, err)
 		}
 
-		variantLC := strings.ToLower(variant)
-
-		repoName := fmt.Sprintf("%s/common/files/orchestrator/%s", RepositoryName, variantLC)
+		repoName := fmt.Sprintf("%s/common/files/%s", RepositoryName, variant.Publish.Repository)
 		if err := TryToCreateECRRepository(ctx, repoName); err != nil {
 			fmt.Printf("failed to create ECR repository %s, ignoring: %v\n", repoName, err)
 		}
@@ -194,16 +197,7 @@ func gitShortHash() string {
 }
 
 func buildVariant(ctx context.Context, variant string) error {
-	switch variant {
-	case "cloudFull":
-		return Tarball{}.CloudFull()
-
-	case "onpremFull":
-		return Tarball{}.OnpremFull()
-
-	default:
-		return fmt.Errorf("unknown variant: %s", variant)
-	}
+	return Tarball{}.Variant(variant)
 }
 
 func pushArtifact(ctx context.Context, registry, repoName, tag, fileName, artifactType string) error {
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// commitTypeTitles lists the conventional commit types in the order they appear in the release notes.
+var commitTypeTitles = []struct {
+	commitType string
+	title      string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+	{"revert", "Reverts"},
+}
+
+const otherChangesTitle = "Other Changes"
+
+var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+type releaseNoteCommit struct {
+	Hash       string
+	Type       string
+	Subject    string
+	Breaking   bool
+	Subsystems []string
+}
+
+func (c releaseNoteCommit) markdown() string {
+	line := fmt.Sprintf("- %s (%s)", c.Subject, c.Hash)
+	if c.Breaking {
+		line = "- **BREAKING** " + strings.TrimPrefix(line, "- ")
+	}
+	return line
+}
+
+type componentVersionChange struct {
+	Component string
+	From      string
+	To        string
+}
+
+// generate Markdown release notes for the commits between fromRef and toRef.
+func (Gen) releaseNotes(fromRef string, toRef string) error {
+	commits, err := getReleaseNoteCommits(fromRef, toRef)
+	if err != nil {
+		return err
+	}
+
+	fromManifest, err := getManifestAtRef(fromRef)
+	if err != nil {
+		return fmt.Errorf("error creating manifest at %s: %w", fromRef, err)
+	}
+	toManifest, err := getManifestAtRef(toRef)
+	if err != nil {
+		return fmt.Errorf("error creating manifest at %s: %w", toRef, err)
+	}
+
+	fmt.Print(renderReleaseNotes(fromRef, toRef, commits, diffManifestComponents(fromManifest, toManifest)))
+	return nil
+}
+
+func getReleaseNoteCommits(fromRef string, toRef string) ([]releaseNoteCommit, error) {
+	// Records are separated by RS and fields by US so that subjects, bodies and file names can be split reliably.
+	out, err := exec.Command("git", "log", "--no-merges", "--name-only",
+		"--format=%x1e%h%x1f%s%x1f%b%x1f", fmt.Sprintf("%s..%s", fromRef, toRef)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get git log for %s..%s: %w", fromRef, toRef, err)
+	}
+
+	var commits []releaseNoteCommit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+
+		commit := releaseNoteCommit{
+			Hash:    fields[0],
+			Type:    otherChangesTitle,
+			Subject: fields[1],
+		}
+		if match := conventionalCommitRegex.FindStringSubmatch(fields[1]); match != nil {
+			commit.Type = strings.ToLower(match[1])
+			commit.Breaking = match[3] == "!"
+			commit.Subject = match[4]
+			if match[2] != "" {
+				commit.Subject = fmt.Sprintf("**%s:** %s", match[2], match[4])
+			}
+		}
+		if strings.Contains(fields[2], "BREAKING CHANGE:") || strings.Contains(fields[2], "BREAKING-CHANGE:") {
+			commit.Breaking = true
+		}
+
+		for _, file := range strings.Split(strings.TrimSpace(fields[3]), "\n") {
+			if subsystem := releaseNoteSubsystem(file); subsystem != "" && !slices.Contains(commit.Subsystems, subsystem) {
+				commit.Subsystems = append(commit.Subsystems, subsystem)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// releaseNoteSubsystem maps a changed file to the subsystem it belongs to, or "" if it is not part of one.
+func releaseNoteSubsystem(file string) string {
+	parts := strings.Split(file, "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "argocd" && parts[1] == "applications" &&
+		(parts[2] == "configs" || parts[2] == "templates" || parts[2] == "custom"):
+		return "ArgoCD app " + strings.TrimSuffix(strings.TrimSuffix(parts[3], ".yaml"), ".tpl")
+	case len(parts) >= 1 && parts[0] == "argocd":
+		return "ArgoCD"
+	case len(parts) == 3 && parts[0] == "orch-configs" && parts[1] == "profiles":
+		return "orch-configs profile " + strings.TrimSuffix(parts[2], ".yaml")
+	case len(parts) >= 1 && parts[0] == "orch-configs":
+		return "orch-configs"
+	case len(parts) >= 4 && parts[0] == "on-prem-installers" && parts[1] == "cmd":
+		return "on-prem installer " + parts[2]
+	case len(parts) >= 1 && parts[0] == "on-prem-installers":
+		return "on-prem installers"
+	case len(parts) >= 1 && (parts[0] == "mage" || parts[0] == "Magefile.go"):
+		return "mage"
+	default:
+		return ""
+	}
+}
+
+// getManifestAtRef builds the release manifest of the repo as it was at ref using a temporary worktree.
+func getManifestAtRef(ref string) (*Manifest, error) {
+	gitHash, err := exec.Command("git", "rev-parse", "--short", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %s: %w", ref, err)
+	}
+
+	worktreeDir, err := os.MkdirTemp("", "release-notes-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create worktree directory: %w", err)
+	}
+	defer os.RemoveAll(worktreeDir)
+
+	if out, err := exec.Command("git", "worktree", "add", "--detach", worktreeDir, ref).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("unable to check out %s: %w: %s", ref, err, out)
+	}
+	defer func() {
+		if out, err := exec.Command("git", "worktree", "remove", "--force", worktreeDir).CombinedOutput(); err != nil {
+			fmt.Printf("Warning: failed to remove worktree %s: %v: %s\n", worktreeDir, err, out)
+		}
+	}()
+
+	return getManifestFromDir(worktreeDir, strings.TrimSpace(string(gitHash)))
+}
+
+func diffManifestComponents(from *Manifest, to *Manifest) []componentVersionChange {
+	var names []string
+	for name := range from.Components {
+		names = append(names, name)
+	}
+	for name := range to.Components {
+		if _, ok := from.Components[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []componentVersionChange
+	for _, name := range names {
+		fromComponent, inFrom := from.Components[name]
+		toComponent, inTo := to.Components[name]
+		change := componentVersionChange{Component: name, From: "-", To: "-"}
+		if inFrom {
+			change.From = fromComponent.Version
+		}
+		if inTo {
+			change.To = toComponent.Version
+		}
+		if inFrom && inTo && change.From == change.To {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func renderReleaseNotes(fromRef string, toRef string, commits []releaseNoteCommit,
+	components []componentVersionChange,
+) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Release Notes (%s..%s)\n", fromRef, toRef)
+
+	var breaking []string
+	for _, commit := range commits {
+		if commit.Breaking {
+			breaking = append(breaking, commit.markdown())
+		}
+	}
+	if len(breaking) > 0 {
+		sb.WriteString("\n## Breaking Changes\n\n")
+		sb.WriteString(strings.Join(breaking, "\n") + "\n")
+	}
+
+	sb.WriteString("\n## Component Version Changes\n\n")
+	if len(components) == 0 {
+		sb.WriteString("No component versions changed.\n")
+	} else {
+		sb.WriteString("| Component | From | To |\n")
+		sb.WriteString("| --- | --- | --- |\n")
+		for _, change := range components {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", change.Component, change.From, change.To)
+		}
+	}
+
+	sb.WriteString("\n## Changes by Type\n")
+	byType := make(map[string][]string)
+	for _, commit := range commits {
+		byType[commit.Type] = append(byType[commit.Type], commit.markdown())
+	}
+	for _, t := range commitTypeTitles {
+		if lines, ok := byType[t.commitType]; ok {
+			fmt.Fprintf(&sb, "\n### %s\n\n%s\n", t.title, strings.Join(lines, "\n"))
+			delete(byType, t.commitType)
+		}
+	}
+	// Unknown conventional commit types are reported together with non-conventional commits.
+	var other []string
+	for _, commit := range commits {
+		if _, ok := byType[commit.Type]; ok {
+			other = append(other, commit.markdown())
+		}
+	}
+	if len(other) > 0 {
+		fmt.Fprintf(&sb, "\n### %s\n\n%s\n", otherChangesTitle, strings.Join(other, "\n"))
+	}
+
+	sb.WriteString("\n## Changes by Subsystem\n")
+	bySubsystem := make(map[string][]string)
+	for _, commit := range commits {
+		for _, subsystem := range commit.Subsystems {
+			bySubsystem[subsystem] = append(bySubsystem[subsystem], commit.markdown())
+		}
+	}
+	var subsystems []string
+	for subsystem := range bySubsystem {
+		subsystems = append(subsystems, subsystem)
+	}
+	slices.Sort(subsystems)
+	for _, subsystem := range subsystems {
+		fmt.Fprintf(&sb, "\n### %s\n\n%s\n", subsystem, strings.Join(bySubsystem[subsystem], "\n"))
+	}
+
+	return sb.String()
+}
@@ -6,24 +6,43 @@ package mage
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/bitfield/script"
 	"github.com/magefile/mage/mg"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	edgeManageabilityFramework = "edge-manageability-framework"
+	tarballVariantsFile        = "mage/tarball-variants.yaml"
 )
 
+// TarballVariant declares the contents of a source tarball and where it is published.
+type TarballVariant struct {
+	Name     string          `yaml:"name"`
+	Include  []string        `yaml:"include"`
+	Exclude  []string        `yaml:"exclude"`
+	Clusters []string        `yaml:"clusters"`
+	Publish  *TarballPublish `yaml:"publish"`
+}
+
+// TarballPublish declares the registry destination of a published tarball variant.
+type TarballPublish struct {
+	Repository     string `yaml:"repository"`
+	InstallerImage string `yaml:"installerImage"`
+}
+
 type TarballManifest struct {
-	variant      string
-	repoName     string
-	actualDir    string
-	clusterNames []string
-	manifest     []string
+	variant   TarballVariant
+	repoName  string
+	actualDir string
+	manifest  []string
 }
 
 // type clusterYaml struct {
@@ -32,30 +51,164 @@ type TarballManifest struct {
 // 	} `yaml:"root"`
 // }
 
-func (t Tarball) setupCollectors(variant string, clusterNames []string) error {
-	tmDeploy := NewTarballManifest(variant, edgeManageabilityFramework, ".", clusterNames)
-	err := tmDeploy.gatherFiles(variant)
+// loadTarballVariants reads and validates the tarball variants declared in tarballVariantsFile.
+func loadTarballVariants() ([]TarballVariant, error) {
+	data, err := os.ReadFile(tarballVariantsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball variants: %w", err)
+	}
+
+	var variantsFile struct {
+		Variants []TarballVariant `yaml:"variants"`
+	}
+	if err := yaml.Unmarshal(data, &variantsFile); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", tarballVariantsFile, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range variantsFile.Variants {
+		if err := v.validate(); err != nil {
+			return nil, fmt.Errorf("invalid variant in %s: %w", tarballVariantsFile, err)
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("duplicate variant %s in %s", v.Name, tarballVariantsFile)
+		}
+		seen[v.Name] = true
+	}
+
+	return variantsFile.Variants, nil
+}
+
+// getTarballVariant returns the variant with the given name.
+func getTarballVariant(name string) (TarballVariant, error) {
+	variants, err := loadTarballVariants()
+	if err != nil {
+		return TarballVariant{}, err
+	}
+	for _, v := range variants {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	return TarballVariant{}, fmt.Errorf("unknown variant: %s", name)
+}
+
+// publishedTarballVariants returns the variants that have a publish destination.
+func publishedTarballVariants() ([]TarballVariant, error) {
+	variants, err := loadTarballVariants()
+	if err != nil {
+		return nil, err
+	}
+	var published []TarballVariant
+	for _, v := range variants {
+		if v.Publish != nil {
+			published = append(published, v)
+		}
+	}
+	return published, nil
+}
+
+// variantReleaseArtifacts returns the tarball artifacts and installer images published for every variant with the
+// given tag.
+func variantReleaseArtifacts(tag string) ([]string, []string, error) {
+	variants, err := publishedTarballVariants()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var binaries, images []string
+	for _, v := range variants {
+		binaries = append(binaries, fmt.Sprintf("%s/%s:%s", binaryBasePath, v.Publish.Repository, tag))
+		if v.Publish.InstallerImage != "" {
+			images = append(images, fmt.Sprintf("%s/%s:%s", installBasePath, v.Publish.InstallerImage, tag))
+		}
+	}
+	return binaries, images, nil
+}
+
+func (v TarballVariant) validate() error {
+	if v.Name == "" {
+		return fmt.Errorf("variant name is required")
+	}
+	if len(v.Include) == 0 {
+		return fmt.Errorf("variant %s does not include any paths", v.Name)
+	}
+	for _, pattern := range append(append([]string{}, v.Exclude...), v.Clusters...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("variant %s has invalid pattern %q: %w", v.Name, pattern, err)
+		}
+	}
+	if v.Publish != nil && v.Publish.Repository == "" {
+		return fmt.Errorf("variant %s has a publish section without a repository", v.Name)
+	}
+	return nil
+}
+
+// excludes reports whether the slash separated path, relative to the repository root, is excluded from the variant.
+func (v TarballVariant) excludes(relPath string) bool {
+	for _, pattern := range v.Exclude {
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsCluster reports whether the cluster config with the given name is shipped with the variant.
+func (v TarballVariant) keepsCluster(cluster string) bool {
+	if len(v.Clusters) == 0 {
+		return true
+	}
+	for _, pattern := range v.Clusters {
+		if ok, _ := path.Match(pattern, cluster); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Tarball) setupCollectors(variantName string) error {
+	variant, err := getTarballVariant(variantName)
 	if err != nil {
 		return err
 	}
-	if err := tmDeploy.writeOutTar(tmDeploy.repoName, variant); err != nil {
+
+	tmDeploy := NewTarballManifest(variant, edgeManageabilityFramework, ".")
+	if err := tmDeploy.gatherFiles(); err != nil {
+		return err
+	}
+	if err := tmDeploy.writeOutTar(tmDeploy.repoName, variant.Name); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func NewTarballManifest(variant, repoName, actualName string, clusterNames []string) *TarballManifest {
+func (t Tarball) list() error {
+	variants, err := loadTarballVariants()
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		destination := "not published"
+		if v.Publish != nil {
+			destination = fmt.Sprintf("%s/%s", binaryBasePath, v.Publish.Repository)
+		}
+		fmt.Printf("%-20s %s\n", v.Name, destination)
+	}
+	return nil
+}
+
+func NewTarballManifest(variant TarballVariant, repoName, actualName string) *TarballManifest {
 	return &TarballManifest{
-		variant:      variant,
-		repoName:     repoName,
-		actualDir:    actualName,
-		clusterNames: clusterNames,
-		manifest:     make([]string, 0),
+		variant:   variant,
+		repoName:  repoName,
+		actualDir: actualName,
+		manifest:  make([]string, 0),
 	}
 }
 
-func (tm *TarballManifest) gatherFiles(variant string) error {
+func (tm *TarballManifest) gatherFiles() error {
 	switch tm.repoName {
 	case edgeManageabilityFramework:
 		if err := tm.addedgeManageabilityFrameworkFiles(); err != nil {
@@ -69,13 +222,74 @@ func (tm *TarballManifest) gatherFiles(variant string) error {
 }
 
 func (tm *TarballManifest) addedgeManageabilityFrameworkFiles() error {
-	tm.manifest = append(tm.manifest, tm.actualDir+"/VERSION")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/argocd")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/bootstrap")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/orch-configs/clusters")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/orch-configs/profiles")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/orch-configs/templates")
-	tm.manifest = append(tm.manifest, tm.actualDir+"/tools")
+	for _, include := range tm.variant.Include {
+		if err := tm.addPath(include); err != nil {
+			return err
+		}
+	}
+	return tm.addClusterConfigs()
+}
+
+// addPath adds every file below the include path to the manifest, skipping excluded paths.
+func (tm *TarballManifest) addPath(include string) error {
+	return filepath.WalkDir(filepath.Join(tm.actualDir, include), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to add %s to variant %s: %w", include, tm.variant.Name, err)
+		}
+
+		relPath, err := filepath.Rel(tm.actualDir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if tm.variant.excludes(relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			tm.manifest = append(tm.manifest, tm.actualDir+"/"+relPath)
+		}
+		return nil
+	})
+}
+
+// addClusterConfigs adds the cluster configs kept by the variant to the manifest. A cluster pattern that matches no
+// shipped cluster config is an error, since the variant would silently miss configs.
+func (tm *TarballManifest) addClusterConfigs() error {
+	clustersDir := path.Join("orch-configs", "clusters")
+	clusterFiles, err := filepath.Glob(filepath.Join(tm.actualDir, clustersDir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to list cluster configs: %w", err)
+	}
+
+	matched := make(map[string]bool)
+	for _, clusterFile := range clusterFiles {
+		cluster := strings.TrimSuffix(filepath.Base(clusterFile), ".yaml")
+		relPath := path.Join(clustersDir, filepath.Base(clusterFile))
+		if !tm.variant.keepsCluster(cluster) || tm.variant.excludes(relPath) {
+			continue
+		}
+		for _, pattern := range tm.variant.Clusters {
+			if ok, _ := path.Match(pattern, cluster); ok {
+				matched[pattern] = true
+			}
+		}
+		tm.manifest = append(tm.manifest, tm.actualDir+"/"+relPath)
+	}
+
+	var unmatched []string
+	for _, pattern := range tm.variant.Clusters {
+		if !matched[pattern] {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf("no cluster config in %s matches %s for variant %s", clustersDir,
+			strings.Join(unmatched, ", "), tm.variant.Name)
+	}
 	return nil
 }
 
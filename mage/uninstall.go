@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
+)
+
+// resourceInventory records the libvirt resources created by deploy:edgeNetwork and deploy:edgeStoragePool, so that
+// undeploy:onPrem removes what terraform destroy leaves behind.
+const resourceInventory = ".orch-resources.json"
+
+// recordTerraformResource records the resource named by the "name" output of a terraform module.
+func recordTerraformResource(kind uninstall.Kind, module string) error {
+	name, err := sh.Output("terraform", "-chdir="+filepath.Join("terraform", module), "output", "-raw", "name")
+	if err != nil {
+		return fmt.Errorf("failed to get the name of the %s: %w", kind, err)
+	}
+	resource := uninstall.Resource{Kind: kind, Name: strings.TrimSpace(name)}
+	if err := uninstall.Record(executor.Local{}, resourceInventory, resource); err != nil {
+		return fmt.Errorf("failed to record %s: %w", resource, err)
+	}
+	return nil
+}
+
+// removeLibvirtLeftovers removes the recorded libvirt resources that still exist and reports the ones it can't remove.
+func (Undeploy) removeLibvirtLeftovers() error {
+	inv, ok, err := uninstall.Load(resourceInventory)
+	if err != nil || !ok {
+		return err
+	}
+	uninstaller := uninstall.Uninstaller{
+		Root:  "/",
+		Exec:  executor.Local{},
+		Out:   os.Stdout,
+		Kinds: []uninstall.Kind{uninstall.KindLibvirtNetwork, uninstall.KindLibvirtPool},
+	}
+	report, err := uninstaller.Run(inv, resourceInventory)
+	if err != nil {
+		return err
+	}
+	if len(report.Leftovers) > 0 {
+		report.Print(os.Stdout)
+		return fmt.Errorf("libvirt resources left behind, they are kept in %s: %w", resourceInventory, report.Err())
+	}
+	return nil
+}
@@ -5,37 +5,109 @@
 package mage
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
-	"github.com/bitfield/script"
+	"github.com/hashicorp/vault/api"
 	"github.com/magefile/mage/sh"
+	"golang.org/x/term"
 
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
 	"github.com/open-edge-platform/edge-manageability-framework/internal/retry"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultcustody"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultops"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultunseal"
 )
 
-const vaultKeysFile = "vault-keys.json"
+const (
+	// vaultKeysFile is where vault:keys wrote the keys in plaintext before the shares were kept in custody.
+	vaultKeysFile = "vault-keys.json"
+	// defaultVaultSharesDir is where vault:keys writes the share files, unless VAULT_SHARES_DIR is set.
+	defaultVaultSharesDir = "vault-shares"
+	// vaultPortForwardPort is the local port of the port-forward to vault-0.
+	vaultPortForwardPort = "8201"
+	// passphraseAttempts is the number of times a passphrase is asked for before giving up on a share.
+	passphraseAttempts = 3
+)
 
 func (Vault) keys() error {
-	if err := waitForVaultKeysSecret(); err != nil {
+	keys, err := vaultKeys()
+	if err != nil {
+		return err
+	}
+	status, err := vaultStatus()
+	if err != nil {
 		return err
 	}
-	cmd := fmt.Sprintf(
-		"kubectl --v=%d -n orch-platform get secret vault-keys -o jsonpath='{.data.vault-keys}'",
-		verboseLevel,
-	)
-	if _, err := script.
-		NewPipe().
-		Exec(cmd).
-		Exec("base64 -d").
-		Exec("jq ."). // Use actual jq to pretty print
-		WriteFile(vaultKeysFile); err != nil {
-		return fmt.Errorf("vault init: %w ", err)
+
+	var names []string
+	if custodians := os.Getenv("VAULT_CUSTODIANS"); custodians != "" {
+		for _, name := range strings.Split(custodians, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	} else {
+		for i := range keys {
+			names = append(names, "custodian-"+strconv.Itoa(i+1))
+		}
 	}
-	fmt.Printf("Wrote Vault keys to file 🔒: %s\n", vaultKeysFile)
+	fmt.Printf("Splitting %d Vault key shares between %d custodians, %d of them unseal Vault 🔑\n", len(keys),
+		len(names), status.Threshold)
 
+	var custodians []vaultcustody.Custodian
+	for _, name := range names {
+		custodians = append(custodians, vaultcustody.Custodian{Name: name})
+	}
+	if uncovered := vaultcustody.Uncovered(keys, custodians); len(uncovered) > 0 {
+		var shares []string
+		for _, index := range uncovered {
+			shares = append(shares, strconv.Itoa(index))
+		}
+		if os.Getenv("VAULT_CUSTODY_PARTIAL") != "true" {
+			return fmt.Errorf("key shares %s would not be kept in custody, list one custodian per key share in "+
+				"VAULT_CUSTODIANS or set VAULT_CUSTODY_PARTIAL=true to keep only %d shares",
+				strings.Join(shares, ", "), len(custodians))
+		}
+		fmt.Printf("⚠️  Key shares %s are not kept in custody, only %d of the %d shares can unseal Vault\n",
+			strings.Join(shares, ", "), len(custodians), len(keys))
+	}
+	for i := range custodians {
+		passphrase, err := readNewPassphrase(custodians[i].Name)
+		if err != nil {
+			return err
+		}
+		custodians[i].Passphrase = passphrase
+	}
+	files, err := vaultcustody.Split(keys, status.Threshold, custodians)
+	if err != nil {
+		return fmt.Errorf("split vault keys: %w", err)
+	}
+	dir := os.Getenv("VAULT_SHARES_DIR")
+	if dir == "" {
+		dir = defaultVaultSharesDir
+	}
+	paths, err := vaultcustody.Write(dir, files)
+	if err != nil {
+		return fmt.Errorf("write vault shares: %w", err)
+	}
+
+	fmt.Printf("Wrote Vault key shares encrypted with the passphrases of their custodians 🔒:\n")
+	for _, path := range paths {
+		fmt.Printf("  %s\n", path)
+	}
+	fmt.Printf("Hand each file to its custodian and keep them apart, no single file unseals Vault.\n")
+	if _, err := os.Stat(vaultKeysFile); err == nil {
+		fmt.Printf("⚠️  %s holds the keys in plaintext, delete it once the shares are handed out.\n", vaultKeysFile)
+	}
 	return nil
 }
 
@@ -53,28 +125,94 @@ func waitForVaultKeysSecret() error {
 	return nil
 }
 
-func (Vault) unseal() error {
+// vaultKeysSecret returns the sys/init response in the vault-keys secret.
+func vaultKeysSecret() ([]byte, error) {
 	if err := waitForVaultKeysSecret(); err != nil {
+		return nil, err
+	}
+	encoded, err := sh.Output("kubectl", fmt.Sprintf("--v=%d", verboseLevel), "-n", "orch-platform", "get", "secret",
+		"vault-keys", "-o", "jsonpath={.data.vault-keys}")
+	if err != nil {
+		return nil, fmt.Errorf("get vault keys secret: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode vault keys secret: %w", err)
+	}
+	return data, nil
+}
+
+// vaultKeys returns the key shares of the vault-keys secret.
+func vaultKeys() ([]string, error) {
+	data, err := vaultKeysSecret()
+	if err != nil {
+		return nil, err
+	}
+	return vaultunseal.ParseKeys(data)
+}
+
+// vaultStatus returns the seal status of vault-0.
+func vaultStatus() (vaultunseal.SealStatus, error) {
+	var status vaultunseal.SealStatus
+	out, err := exec.Command("kubectl", "exec", "-n", "orch-platform", "vault-0", "-c", "vault", "--",
+		"vault", "status", "-format=json").Output()
+	// vault status exits with 2 when Vault is sealed.
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 2) {
+		return status, fmt.Errorf("get vault status: %w", err)
+	}
+	if err := json.Unmarshal(out, &status); err != nil {
+		return status, fmt.Errorf("parse vault status: %w", err)
+	}
+	return status, nil
+}
+
+func (Vault) unseal() error {
+	// The shares typed in are read while unsealing, until Vault is unsealed.
+	prompt := os.Getenv("VAULT_UNSEAL_PROMPT") == "true"
+	var keys []string
+	var err error
+	switch {
+	case os.Getenv("VAULT_SHARES") != "":
+		keys, err = collectShareFiles(strings.Split(os.Getenv("VAULT_SHARES"), ","))
+	case !prompt:
+		keys, err = vaultKeys()
+	}
+	if err != nil {
 		return err
 	}
-	cmd := "kubectl -n orch-platform get secret vault-keys -o jsonpath='{.data.vault-keys}'"
 
-	keys, err := script.Exec(cmd).Exec("base64 -d").JQ(".keys_base64 | .[]").Slice()
+	address, stop, err := vaultPortForward()
 	if err != nil {
-		return fmt.Errorf("executing kubectl pipeline: %w", err)
+		return err
 	}
+	defer stop()
+	client := vaultunseal.Client{Address: address}
 
-	for _, key := range keys {
-		if err := sh.RunV(
-			"kubectl",
-			"-n", "orch-platform",
-			"exec",
-			"-it",
-			"vault-0",
-			"--",
-			"vault", "operator", "unseal",
-			strings.Trim(key, "\""), // Strip double-quotes from the string
-		); err != nil {
+	ctx := context.Background()
+	status, err := client.SealStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("get vault seal status: %w", err)
+	}
+	if !status.Sealed {
+		fmt.Printf("Vault is already unsealed 🔓\n")
+		return nil
+	}
+	for i := 0; status.Sealed; i++ {
+		var key string
+		if prompt && keys == nil {
+			secret, err := readSecret(fmt.Sprintf("Key share (%d of %d submitted): ", status.Progress, status.Threshold))
+			if err != nil {
+				return err
+			}
+			key = string(secret)
+		} else if i < len(keys) {
+			key = keys[i]
+		} else {
+			return fmt.Errorf("vault still sealed after %d key shares, progress %d of %d", len(keys), status.Progress,
+				status.Threshold)
+		}
+		if status, err = client.Unseal(ctx, strings.TrimSpace(key)); err != nil {
 			return fmt.Errorf("apply unseal key share: %w", err)
 		}
 	}
@@ -82,3 +220,351 @@ func (Vault) unseal() error {
 
 	return nil
 }
+
+// collectShareFiles asks for the passphrases of the custodians of the share files until there are enough shares to
+// unseal Vault.
+func collectShareFiles(paths []string) ([]string, error) {
+	paths, err := vaultcustody.SharePaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	var collector vaultcustody.Collector
+	for _, path := range paths {
+		if needed, known := collector.Needed(); known && needed == 0 {
+			break
+		}
+		share, err := vaultcustody.ReadShare(path)
+		if err != nil {
+			return nil, err
+		}
+		for attempt := 1; ; attempt++ {
+			passphrase, err := readSecret(fmt.Sprintf("Passphrase of %s (%s): ", share.Custodian, path))
+			if err != nil {
+				return nil, err
+			}
+			err = collector.Add(share, passphrase)
+			if err == nil {
+				break
+			}
+			fmt.Printf("%v\n", err)
+			if !errors.Is(err, vaultcustody.ErrWrongPassphrase) || attempt == passphraseAttempts {
+				fmt.Printf("Skipping the share of %s\n", share.Custodian)
+				break
+			}
+		}
+	}
+	if err := collector.Complete(); err != nil {
+		return nil, err
+	}
+	return collector.Keys(), nil
+}
+
+// vaultPortForward forwards vaultPortForwardPort to vault-0 and returns its address, so that the key shares and
+// tokens are sent to the Vault API rather than passed as arguments of commands.
+func vaultPortForward() (string, func(), error) {
+	cmd := exec.Command("kubectl", "port-forward", "-n", "orch-platform", "pod/vault-0",
+		vaultPortForwardPort+":8200")
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("start vault port-forward: %w", err)
+	}
+	stop := func() {
+		if err := cmd.Process.Kill(); err != nil {
+			fmt.Printf("Warning: failed to stop vault port-forward: %v\n", err)
+		}
+		_ = cmd.Wait()
+	}
+
+	address := "http://127.0.0.1:" + vaultPortForwardPort
+	client := vaultunseal.Client{Address: address}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := retry.UntilItSucceeds(ctx, func() error {
+		_, err := client.SealStatus(ctx)
+		return err
+	}, time.Second); err != nil {
+		stop()
+		return "", nil, fmt.Errorf("vault port-forward not ready: %w", err)
+	}
+	return address, stop, nil
+}
+
+// readNewPassphrase asks for the passphrase of a custodian twice.
+func readNewPassphrase(custodian string) ([]byte, error) {
+	for {
+		passphrase, err := readSecret(fmt.Sprintf("Passphrase of %s (at least %d characters): ", custodian,
+			vaultcustody.MinPassphraseLength))
+		if err != nil {
+			return nil, err
+		}
+		if len(passphrase) < vaultcustody.MinPassphraseLength {
+			fmt.Printf("The passphrase is too short\n")
+			continue
+		}
+		again, err := readSecret(fmt.Sprintf("Passphrase of %s again: ", custodian))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			fmt.Printf("The passphrases don't match\n")
+			continue
+		}
+		return passphrase, nil
+	}
+}
+
+// readSecret reads a line from the terminal without echoing it.
+func readSecret(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd()) //nolint: gosec
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("reading key shares and passphrases needs a terminal")
+	}
+	fmt.Print(prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+	}
+	return secret, nil
+}
+
+// vaultAPI returns a client of the Vault API of vault-0 through a port-forward, with the token in VAULT_TOKEN or else
+// the root token of the vault-keys secret, and the function stopping the port-forward.
+func vaultAPI() (*api.Client, func(), error) {
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		var err error
+		if token, err = vaultRootToken(); err != nil {
+			return nil, nil, err
+		}
+	}
+	address, stop, err := vaultPortForward()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := api.NewClient(&api.Config{Address: address})
+	if err != nil {
+		stop()
+		return nil, nil, fmt.Errorf("create vault client: %w", err)
+	}
+	client.SetToken(token)
+	return client, stop, nil
+}
+
+// vaultRootToken returns the root token of the vault-keys secret.
+func vaultRootToken() (string, error) {
+	data, err := vaultKeysSecret()
+	if err != nil {
+		return "", err
+	}
+	var keys struct {
+		RootToken string `json:"root_token"`
+	}
+	if err := json.Unmarshal(data, &keys); err != nil || keys.RootToken == "" {
+		return "", errors.New("secret vault-keys has no root token")
+	}
+	return keys.RootToken, nil
+}
+
+func (Vault) status() error {
+	client, stop, err := vaultAPI()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	status, err := vaultops.GetStatus(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	seal := status.Seal
+	if seal.Sealed {
+		fmt.Printf("Vault %s is sealed 🔒, unseal progress %d of %d\n", seal.Version, seal.Progress, seal.T)
+		return nil
+	}
+	fmt.Printf("Vault %s is unsealed 🔓\n", seal.Version)
+	fmt.Printf("Seal: %s, %d of %d key shares unseal\n", seal.Type, seal.T, seal.N)
+	fmt.Printf("Storage: %s\n", seal.StorageType)
+	if status.Leader.HAEnabled {
+		fmt.Printf("HA: enabled, leader %s (self: %t)\n", status.Leader.LeaderAddress, status.Leader.IsSelf)
+	} else {
+		fmt.Printf("HA: disabled\n")
+	}
+	if len(status.Peers) == 0 {
+		return nil
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tSTATE\tVOTER")
+	for _, peer := range status.Peers {
+		state := "follower"
+		if peer.Leader {
+			state = "leader"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", peer.NodeID, peer.Address, state, peer.Voter)
+	}
+	return w.Flush()
+}
+
+func (Vault) snapshot() error {
+	client, stop, err := vaultAPI()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	path := fmt.Sprintf("vault-%s.snap", time.Now().UTC().Format("20060102-150405"))
+	// The snapshot holds all secrets of Vault, encrypted with its keys.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create vault snapshot: %w", err)
+	}
+	err = vaultops.Snapshot(context.Background(), client, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("vault snapshot: %w", err)
+	}
+	fmt.Printf("Vault snapshot written to %s 💾\n", path)
+	return nil
+}
+
+func (Vault) restore(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open vault snapshot: %w", err)
+	}
+	defer file.Close()
+
+	client, stop, err := vaultAPI()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	force := os.Getenv("VAULT_RESTORE_FORCE") == "true"
+	if err := vaultops.Restore(context.Background(), client, file, force); err != nil {
+		return fmt.Errorf("vault restore: %w", err)
+	}
+	fmt.Printf("Vault snapshot %s restored 🟢\n", path)
+	if force {
+		fmt.Printf("Vault now has the key shares and root token of the snapshot's cluster.\n")
+	}
+	return nil
+}
+
+func (Vault) rotateRoot() error {
+	var keys []string
+	var err error
+	if shares := os.Getenv("VAULT_SHARES"); shares != "" {
+		keys, err = collectShareFiles(strings.Split(shares, ","))
+	} else {
+		keys, err = vaultKeys()
+	}
+	if err != nil {
+		return err
+	}
+	oldToken, err := vaultRootToken()
+	if err != nil {
+		return err
+	}
+
+	client, stop, err := vaultAPI()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx := context.Background()
+	token, err := vaultops.GenerateRoot(ctx, client, keys)
+	if err != nil {
+		return fmt.Errorf("generate root token: %w", err)
+	}
+	client.SetToken(token)
+	if _, err := client.Auth().Token().LookupSelfWithContext(ctx); err != nil {
+		return fmt.Errorf("check new root token: %w", err)
+	}
+	// The new token is stored before the old one is revoked, so that a failure never leaves Vault without a known
+	// root token.
+	if err := storeVaultRootToken(token); err != nil {
+		return err
+	}
+	// The tokens created with the old root token keep working, only the old root token is revoked.
+	if err := vaultops.RevokeToken(ctx, client, oldToken); err != nil {
+		return fmt.Errorf("revoke previous root token: %w", err)
+	}
+	fmt.Printf("Vault root token rotated 🔁, the new token is in the vault-keys secret and the previous one revoked\n")
+	return nil
+}
+
+// storeVaultRootToken replaces the root token of the vault-keys secret.
+func storeVaultRootToken(token string) error {
+	out, err := sh.Output("kubectl", "get", "secret", "vault-keys", "-n", "orch-platform", "-o", "json")
+	if err != nil {
+		return fmt.Errorf("get vault keys secret: %w", err)
+	}
+	var secret map[string]any
+	if err := json.Unmarshal([]byte(out), &secret); err != nil {
+		return fmt.Errorf("parse vault keys secret: %w", err)
+	}
+	data, _ := secret["data"].(map[string]any)
+	encoded, _ := data["vault-keys"].(string)
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decode vault keys secret: %w", err)
+	}
+	var keys map[string]any
+	if err := json.Unmarshal(decoded, &keys); err != nil {
+		return fmt.Errorf("parse vault keys: %w", err)
+	}
+	keys["root_token"] = token
+	updated, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	data["vault-keys"] = base64.StdEncoding.EncodeToString(updated)
+	manifest, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	// The secret is passed on stdin to keep the token out of the arguments of kubectl.
+	if err := (executor.Local{}).RunInput(string(manifest), "kubectl", "replace", "-f", "-"); err != nil {
+		return fmt.Errorf("store new root token: %w", err)
+	}
+	return nil
+}
+
+func (Vault) auditSecrets() error {
+	client, stop, err := vaultAPI()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	secrets, err := vaultops.AuditSecrets(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		fmt.Printf("No KV secrets in Vault\n")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tVERSION\tLAST MODIFIED\tAGE\tPOLICIES")
+	for _, secret := range secrets {
+		version, modified, age := "-", "unknown (KV v1)", "-"
+		if secret.KVVersion == 2 {
+			version = strconv.Itoa(secret.CurrentVersion)
+			modified = secret.UpdatedTime.Local().Format(time.DateTime)
+			age = fmt.Sprintf("%dd", int(time.Since(secret.UpdatedTime).Hours()/24))
+		}
+		policies := strings.Join(secret.Policies, ",")
+		if policies == "" {
+			// Only the root token reads the secret, it may be unused.
+			policies = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", secret.Path, version, modified, age, policies)
+	}
+	return w.Flush()
+}
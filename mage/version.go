@@ -11,15 +11,20 @@ import (
 	"strings"
 
 	"github.com/bitfield/script"
+	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
 )
 
 const (
 	argoRootAppPath      = "argocd/root-app/Chart.yaml"
 	argoApplicationsPath = "argocd/applications/Chart.yaml"
+	versionPath          = "VERSION"
+	onPremVersionPath    = "on-prem-installers/VERSION"
 )
 
 func getVersionFromFile() (string, error) {
-	v, err := os.ReadFile("VERSION")
+	v, err := os.ReadFile(versionPath)
 	if err != nil {
 		return "", err
 	}
@@ -84,11 +89,22 @@ func (v Version) checkVersion() error {
 	if err != nil {
 		return err
 	}
-	if appsVersion != version {
-		return fmt.Errorf("VERSION (%s) and %s (%s) don't match. %s", version, argoApplicationsPath, appsVersion, fixMsg)
+
+	repoSemver, err := semver.Parse(version)
+	if err != nil {
+		return fmt.Errorf("VERSION is not a valid semantic version: %w", err)
 	}
-	if rootVersion != version {
-		return fmt.Errorf("VERSION (%s) and %s (%s) don't match. %s", version, argoRootAppPath, rootVersion, fixMsg)
+	for _, chart := range []struct{ path, version string }{
+		{argoApplicationsPath, appsVersion},
+		{argoRootAppPath, rootVersion},
+	} {
+		chartSemver, err := semver.Parse(chart.version)
+		if err != nil {
+			return fmt.Errorf("%s version is not a valid semantic version: %w. %s", chart.path, err, fixMsg)
+		}
+		if !chartSemver.Equal(repoSemver) {
+			return fmt.Errorf("VERSION (%s) and %s (%s) don't match. %s", version, chart.path, chart.version, fixMsg)
+		}
 	}
 	fmt.Println("All versions match")
 	return nil
@@ -108,3 +124,108 @@ func (v Version) setVersion() error {
 	}
 	return nil
 }
+
+// nextVersion returns the version in VERSION and the version it bumps to, after checking that the versions are in
+// sync.
+func (v Version) nextVersion(kind string) (semver.Version, semver.Version, error) {
+	if err := v.checkVersion(); err != nil {
+		return semver.Version{}, semver.Version{}, fmt.Errorf("refusing to bump out of sync versions: %w", err)
+	}
+
+	version, err := getVersionFromFile()
+	if err != nil {
+		return semver.Version{}, semver.Version{}, err
+	}
+	onPremVersion, err := os.ReadFile(onPremVersionPath)
+	if err != nil {
+		return semver.Version{}, semver.Version{}, err
+	}
+	if strings.TrimSpace(string(onPremVersion)) != version {
+		return semver.Version{}, semver.Version{}, fmt.Errorf(
+			"refusing to bump out of sync versions: VERSION (%s) and %s (%s) don't match",
+			version, onPremVersionPath, strings.TrimSpace(string(onPremVersion)))
+	}
+
+	current, err := semver.Parse(version)
+	if err != nil {
+		return semver.Version{}, semver.Version{}, fmt.Errorf("VERSION is not a valid semantic version: %w", err)
+	}
+	next, err := current.Bump(kind)
+	if err != nil {
+		return semver.Version{}, semver.Version{}, err
+	}
+	return current, next, nil
+}
+
+func (v Version) bump(kind string, releaseBranch bool) error {
+	current, next, err := v.nextVersion(kind)
+	if err != nil {
+		return err
+	}
+
+	if releaseBranch {
+		branch := releaseBranchName(next)
+		switched, err := checkoutReleaseBranch(branch)
+		if err != nil {
+			return err
+		}
+		// An existing release branch has its own versions, the bump continues from them.
+		if switched {
+			if current, next, err = v.nextVersion(kind); err != nil {
+				return err
+			}
+			if releaseBranchName(next) != branch {
+				return fmt.Errorf("bumping %s on %s gives %s, which doesn't belong to the branch", current, branch, next)
+			}
+		}
+	}
+
+	for _, path := range []string{versionPath, onPremVersionPath} {
+		if err := os.WriteFile(path, []byte(next.String()+"\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := v.setVersion(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Version bumped from %s to %s\n", current, next)
+
+	if releaseBranch {
+		args := []string{"commit", "-m", fmt.Sprintf("Bump version to %s", next), "--"}
+		args = append(args, versionPath, onPremVersionPath, argoApplicationsPath, argoRootAppPath)
+		if err := sh.RunV("git", args...); err != nil {
+			return fmt.Errorf("failed to commit version bump: %w", err)
+		}
+	}
+	return nil
+}
+
+// releaseBranchName returns the release-<major>.<minor> branch of the version.
+func releaseBranchName(version semver.Version) string {
+	return fmt.Sprintf("release-%d.%d", version.Major, version.Minor)
+}
+
+// checkoutReleaseBranch switches to the release branch, checking it out if it exists or creating it from the current
+// HEAD otherwise. It reports whether an existing branch was checked out.
+func checkoutReleaseBranch(branch string) (bool, error) {
+	current, err := sh.Output("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return false, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if current == branch {
+		return false, nil
+	}
+
+	if err := sh.Run("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		if err := sh.RunV("git", "checkout", branch); err != nil {
+			return false, fmt.Errorf("failed to check out release branch %s: %w", branch, err)
+		}
+		return true, nil
+	}
+
+	if err := sh.RunV("git", "checkout", "-b", branch); err != nil {
+		return false, fmt.Errorf("failed to create release branch %s: %w", branch, err)
+	}
+	return false, nil
+}
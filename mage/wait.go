@@ -10,11 +10,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/argoheal"
 )
 
+// appHealGrace is how long an application is left to recover from a failure by itself before it is remediated while
+// waiting for the deployment.
+var appHealGrace = 5 * time.Minute
+
 // appDeployDefaultMaxDuration is the default max duration to wait for an application to finish progressing to the
 // synced and healthy state before considering the application deployment a failure.
 var appDeployDefaultMaxDuration = 60 * time.Minute
@@ -40,8 +47,15 @@ var appDeployMaxDurationOverrides = map[string]time.Duration{
 
 // Blocks until the applications in the Orchestrator deployment are synced and healthy. If a particular application
 // deployment does not enter the synced and healthy state after the appDeployDefaultMaxDuration, the overall
-// deployment would be considered a failure and this method will return an error.
+// deployment would be considered a failure and this method will return an error. Known failures of the applications
+// are remediated while waiting if ORCH_ARGO_HEAL is set to true, which deletes failed jobs, removes finalizers and
+// restarts syncs within the rate limits of argo:heal.
 func (Deploy) WaitUntilComplete(ctx context.Context) error {
+	var healer *argoheal.Healer
+	if os.Getenv("ORCH_ARGO_HEAL") == "true" {
+		healer = newArgoHealer(appHealGrace)
+	}
+
 	for {
 		err := checkApps(ctx)
 
@@ -49,6 +63,11 @@ func (Deploy) WaitUntilComplete(ctx context.Context) error {
 		case errors.Is(err, errAppDeployExceededMaxDuration):
 			return err
 
+		case errors.Is(err, errOrchNotReady) && healer != nil:
+			if _, err := healer.Heal(); err != nil {
+				fmt.Printf("Error: failed to remediate applications: %s\n", err)
+			}
+
 		case errors.Is(err, errOrchNotReady):
 			// expected so no-op
 
@@ -88,3 +88,11 @@ applicationSet:
       path: /usr/local/share/ca-certificates/gitea_cert.crt
 " > /tmp/argo-cd/mounts.yaml
 helm install argocd /tmp/argo-cd/argo-cd --values /tmp/argo-cd/values.yaml -f /tmp/argo-cd/mounts.yaml -n argocd --create-namespace
+
+# Record the resources of the package for onprem-uninstall(1)
+if command -v onprem-uninstall >/dev/null; then
+  onprem-uninstall --record package onprem-argocd-installer
+  onprem-uninstall --record namespace argocd
+  # shellcheck disable=SC2046
+  onprem-uninstall --record crd $(kubectl get crd -o jsonpath='{.items[*].metadata.name}' | tr ' ' '\n' | grep 'argoproj.io$')
+fi
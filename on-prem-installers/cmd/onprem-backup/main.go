@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchbackup"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
+)
+
+const (
+	unitDir = "/etc/systemd/system"
+	// servicePath is the PATH of the backup service, which needs kubectl of RKE2 and helm.
+	servicePath = "/var/lib/rancher/rke2/bin:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+var (
+	dir        = flag.String("dir", "/var/backups/orch", "directory of the archives and of the backup status")
+	nfs        = flag.String("nfs", "", "NFS export to mount on --dir before the backup, e.g. nas:/exports/orch")
+	keepDaily  = flag.Int("keep-daily", 7, "number of days whose newest archive is kept")
+	keepWeekly = flag.Int("keep-weekly", 4, "number of weeks whose newest archive is kept")
+	minFreeGiB = flag.Int("min-free-gib", 10, "free space in GiB required in --dir before a backup")
+
+	installTimer = flag.Bool("install-timer", false, "install a systemd timer that runs the backup with these "+
+		"flags on --schedule instead of running it")
+	schedule   = flag.String("schedule", "*-*-* 02:00:00", "systemd calendar event of the timer")
+	kubeconfig = flag.String("kubeconfig", "", "kubeconfig of the timer, defaults to KUBECONFIG")
+	plan       = flag.Bool("plan", false, "print the changes installing the timer would make without applying them")
+)
+
+func main() {
+	flag.Parse()
+
+	backupDir, err := filepath.Abs(*dir)
+	if err != nil {
+		log.Fatalf("invalid directory %s - %v", *dir, err)
+	}
+
+	if *installTimer {
+		var x executor.Executor = executor.Local{}
+		planner := executor.NewPlan(os.Stdout)
+		if *plan {
+			x = planner
+		}
+		if err := installBackupTimer(x, backupDir); err != nil {
+			log.Fatalf("failed to install the backup timer - %v", err)
+		}
+		if *plan {
+			fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+			return
+		}
+		fmt.Printf("Backups of the Orchestrator are scheduled on %s, see systemctl list-timers %s.timer\n", *schedule,
+			orchbackup.TimerUnit)
+		return
+	}
+
+	x := executor.Local{}
+	if err := prepareDir(x, backupDir, *nfs); err != nil {
+		log.Fatalf("%v", err)
+	}
+	job := &orchbackup.Job{
+		Archiver: &orchbackup.Archiver{
+			Dir: backupDir,
+			X:   x,
+			Log: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		},
+		Retention:    orchbackup.Retention{Daily: *keepDaily, Weekly: *keepWeekly},
+		MinFreeBytes: uint64(*minFreeGiB) << 30, //nolint: gosec
+	}
+	run, err := job.Run()
+	if err != nil {
+		log.Fatalf("backup of the Orchestrator failed - %v", err)
+	}
+	fmt.Printf("Backup written to %s, %d expired archives removed\n", run.Archive, len(run.Pruned))
+}
+
+// prepareDir creates the backup directory and mounts the NFS export on it if it isn't mounted yet. The directory is
+// readable so that the backups can be listed, the archives themselves are only readable by root.
+func prepareDir(x executor.Executor, backupDir, export string) error {
+	if err := x.MkdirAll(backupDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory %s - %w", backupDir, err)
+	}
+	if export == "" {
+		return nil
+	}
+	if _, err := x.Query("mountpoint", "-q", backupDir); err == nil {
+		return nil
+	}
+	if err := x.Run("mount", "-t", "nfs", export, backupDir); err != nil {
+		return fmt.Errorf("failed to mount %s on %s - %w", export, backupDir, err)
+	}
+	return nil
+}
+
+// installBackupTimer renders the service and timer units that run this command with the same flags and enables the
+// timer.
+func installBackupTimer(x executor.Executor, backupDir string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the onprem-backup binary - %w", err)
+	}
+	command := []string{exe, "--dir", backupDir,
+		"--keep-daily", strconv.Itoa(*keepDaily),
+		"--keep-weekly", strconv.Itoa(*keepWeekly),
+		"--min-free-gib", strconv.Itoa(*minFreeGiB)}
+	if *nfs != "" {
+		command = append(command, "--nfs", *nfs)
+	}
+	env := map[string]string{"PATH": servicePath}
+	if config := firstNonEmpty(*kubeconfig, os.Getenv("KUBECONFIG")); config != "" {
+		if env["KUBECONFIG"], err = filepath.Abs(config); err != nil {
+			return fmt.Errorf("invalid kubeconfig %s - %w", config, err)
+		}
+	}
+
+	units, err := orchbackup.Timer{
+		Schedule:   *schedule,
+		Command:    command,
+		Env:        env,
+		MountPoint: backupDir,
+	}.Units()
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(units)) {
+		if err := x.WriteFile(filepath.Join(unitDir, name), []byte(units[name]), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s - %w", name, err)
+		}
+	}
+	if err := x.Run("systemctl", "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd - %w", err)
+	}
+	if err := x.Run("systemctl", "enable", "--now", orchbackup.TimerUnit+".timer"); err != nil {
+		return fmt.Errorf("failed to enable %s.timer - %w", orchbackup.TimerUnit, err)
+	}
+
+	var resources []uninstall.Resource
+	// The archives on an NFS export are never removed with the Orchestrator.
+	if *nfs == "" {
+		resources = append(resources, uninstall.Resource{Kind: uninstall.KindDirectory, Name: backupDir, Data: true})
+	}
+	for _, name := range slices.Sorted(maps.Keys(units)) {
+		resources = append(resources, uninstall.Resource{Kind: uninstall.KindSystemdUnit, Name: name})
+	}
+	return uninstall.Record(x, "/"+uninstall.InventoryPath, resources...)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -11,8 +11,9 @@ if [ "${1}" = "upgrade" ]; then
     exit 0
 fi
 
-# disable loading of kernel modules at boot
-rm -fr /etc/modules-load.d/lv-snapshots.conf
+# disable loading of kernel modules at boot, including the file written by earlier versions
+rm -f /etc/modules-load.d/99-orchestrator.conf /etc/modules-load.d/lv-snapshots.conf
+rm -f /etc/sysctl.d/99-orchestrator.conf
 
 # uninstall yq
 rm -rf /usr/local/bin/yq
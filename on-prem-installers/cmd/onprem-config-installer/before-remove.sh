@@ -0,0 +1,15 @@
+#!/usr/bin/env bash
+
+# SPDX-FileCopyrightText: 2026 Intel Corporation
+#
+# SPDX-License-Identifier: Apache-2.0
+
+set -o errexit
+
+# Keep the host configuration on upgrade, the new version applies it again.
+if [ "${1}" = "upgrade" ]; then
+    exit 0
+fi
+
+# Restore the kernel parameters and modules the host had before installation
+/usr/bin/onprem-config-installer --revert
@@ -5,18 +5,18 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 
-	"github.com/bitfield/script"
-	"github.com/magefile/mage/sh"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/hostconfig"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
 )
 
 const header = `
@@ -30,238 +30,144 @@ const header = `
 
 type CallbackFunc func(int64, string) (string, error)
 
+var (
+	plan   = flag.Bool("plan", false, "print the changes the installer would make without applying them")
+	revert = flag.Bool("revert", false, "restore the kernel parameters and modules the host had before the installer ran")
+)
+
 func main() {
+	flag.Parse()
+
 	fmt.Print(header)
 
-	if err := updateFanotifyFD("/etc/sysctl.conf"); err != nil {
-		log.Fatal(err)
+	var x executor.Executor = executor.Local{}
+	planner := executor.NewPlan(os.Stdout)
+	if *plan {
+		x = planner
+		fmt.Println("Planned changes:")
 	}
 
-	if err := preInstallPkg(); err != nil {
-		log.Fatal(err)
+	host := hostconfig.Manager{Root: "/", Exec: x, Out: os.Stdout}
+
+	if *revert {
+		if err := host.Revert(); err != nil {
+			log.Fatal(err)
+		}
+		if *plan {
+			fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+			return
+		}
+		fmt.Println("OnPrem OS configuration reverted!")
+		return
 	}
 
-	hostpathDirs := []string{"/var/openebs/local"}
-	if err := ensureHostpathDirectories(hostpathDirs); err != nil {
+	fmt.Println("config kernel parameters and modules...")
+	if err := host.Apply(); err != nil {
 		log.Fatal(err)
 	}
 
-	if err := configModules(); err != nil {
+	if err := preInstallPkg(x); err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("OnPrem OS configure completed!")
-}
-
-type BlockInfo struct {
-	Name        string      `json:"name"`
-	Size        int64       `json:"size"`
-	Type        string      `json:"type"`
-	MountPoints []string    `json:"mountpoints"`
-	Children    []BlockInfo `json:"children,omitempty"`
-}
-
-func privateCmdExcute(cmdline string) error {
-	cmd := exec.Command("/bin/sh", "-c", cmdline)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error executing %s commands: %w", cmdline, err)
+	hostpathDirs := []string{"/var/openebs/local"}
+	if err := ensureHostpathDirectories(x, hostpathDirs); err != nil {
+		log.Fatal(err)
 	}
-	return nil
-}
 
-// Enable kernel modules required for LV snapshots
-func configModules() error {
-	fmt.Println("config kernel modules...")
-
-	mods, err := os.OpenFile("/etc/modules-load.d/lv-snapshots.conf", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
-		return err
+	resources := []uninstall.Resource{
+		{Kind: uninstall.KindPackage, Name: "onprem-config-installer"},
+		{Kind: uninstall.KindSysctl, Name: "/" + hostconfig.SysctlDropIn},
+		{Kind: uninstall.KindSysctl, Name: "/" + hostconfig.ModulesDropIn},
 	}
-	defer func() {
-		if err := mods.Close(); err != nil {
-			fmt.Printf("Warning: failed to close mods file: %v\n", err)
-		}
-	}()
-
-	if _, err = mods.WriteString("dm-snapshot\ndm-mirror\n"); err != nil {
-		return err
+	for _, dir := range hostpathDirs {
+		resources = append(resources, uninstall.Resource{Kind: uninstall.KindDirectory, Name: dir, Data: true})
 	}
-
-	if err = sh.RunV("modprobe", "dm-snapshot"); err != nil {
-		return err
+	if err := uninstall.Record(x, "/"+uninstall.InventoryPath, resources...); err != nil {
+		log.Fatal(err)
 	}
-	if err = sh.RunV("modprobe", "dm-mirror"); err != nil {
-		return err
+
+	if *plan {
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		return
 	}
 
-	return nil
+	fmt.Println("OnPrem OS configure completed!")
 }
 
-func installYqTool(fileName string) error {
-	cmdline := "curl https://github.com/mikefarah/yq/releases/latest -s -L -I -o /dev/null -w "
-	pipe := script.NewPipe().Exec(cmdline + "'%{url_effective}'")
-	out, err := pipe.ReplaceRegexp(regexp.MustCompile(".*/"), "").String()
+func installYqTool(x executor.Executor, fileName string) error {
+	out, err := x.Query("curl", "https://github.com/mikefarah/yq/releases/latest", "-s", "-L", "-I",
+		"-o", "/dev/null", "-w", "%{url_effective}")
 	if err != nil {
 		return err
 	}
-	version := strings.ReplaceAll(out, "\n", "")
+	version := strings.ReplaceAll(regexp.MustCompile(".*/").ReplaceAllString(out, ""), "\n", "")
 
 	yqURL := fmt.Sprintf("https://github.com/mikefarah/yq/releases/download/%s/%s", version, fileName)
 
-	headers := make(http.Header)
-	headers.Set("User-Agent", "My-App/1.0")
-
 	if _, err = url.Parse(yqURL); err != nil {
 		return err
 	}
 
-	// Check file is exist
-	if err = os.Chdir("/tmp"); err != nil {
+	// Delete a leftover download
+	if err := x.RemoveAll(filepath.Join("/tmp", fileName)); err != nil {
 		return err
 	}
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		fmt.Println("File does not exist")
-	} else if err != nil {
-		return err
-	} else {
-		// Delete file
-		err := os.Remove(fileName)
-		if err != nil {
-			return err
-		}
-	}
-
-	var cmdlines []string
-	cmdline = fmt.Sprintf("curl -fsSL -o /tmp/%s %s", fileName, yqURL)
-	fmt.Println(cmdline)
-	cmdlines = append(cmdlines, cmdline)
-	cmdline = fmt.Sprintf("tar xvf /tmp/%s -C /usr/local/bin", fileName)
-	cmdlines = append(cmdlines, cmdline)
-	cmdline = "mv /usr/local/bin/yq_linux_amd64 /usr/local/bin/yq"
-	cmdlines = append(cmdlines, cmdline)
-	cmdline = "chmod +x /usr/local/bin/yq"
-	cmdlines = append(cmdlines, cmdline)
 
-	for _, cmdline := range cmdlines {
-		if err := privateCmdExcute(cmdline); err != nil {
-			return err
+	fmt.Printf("Installing yq %s\n", version)
+	cmds := [][]string{
+		{"curl", "-fsSL", "-o", filepath.Join("/tmp", fileName), yqURL},
+		{"tar", "xvf", filepath.Join("/tmp", fileName), "-C", "/usr/local/bin"},
+		{"mv", "/usr/local/bin/yq_linux_amd64", "/usr/local/bin/yq"},
+		{"chmod", "+x", "/usr/local/bin/yq"},
+	}
+	for _, cmd := range cmds {
+		if err := x.Run(cmd[0], cmd[1:]...); err != nil {
+			return fmt.Errorf("error executing %s commands: %w", strings.Join(cmd, " "), err)
 		}
 	}
 
 	return nil
 }
 
-func installHelmTool(fileName string, version string) error {
-	var cmdlines []string
+func installHelmTool(x executor.Executor, fileName string, version string) error {
 	helmURL := "https://raw.githubusercontent.com/helm/helm/main/scripts/get-helm-3"
 
-	// Check file is exist
-	if err := os.Chdir("/tmp"); err != nil {
-		return err
-	}
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		fmt.Println("File does not exist")
-	} else if err != nil {
+	// Delete a leftover download
+	if err := x.RemoveAll(filepath.Join("/tmp", fileName)); err != nil {
 		return err
-	} else {
-		// Delete file
-		err := os.Remove(fileName)
-		if err != nil {
-			return err
-		}
 	}
-	cmdline := fmt.Sprintf("curl -fsSL -o /tmp/%s %s", fileName, helmURL)
-	fmt.Println(cmdline)
-	cmdlines = append(cmdlines, cmdline)
-	cmdline = fmt.Sprintf("chmod 700 /tmp/%s", fileName)
-	cmdlines = append(cmdlines, cmdline)
-	cmdline = fmt.Sprintf("/tmp/%s --version %s", fileName, version)
-	cmdlines = append(cmdlines, cmdline)
 
-	for _, cmdline := range cmdlines {
-		if err := privateCmdExcute(cmdline); err != nil {
-			return err
+	fmt.Printf("Installing helm %s\n", version)
+	cmds := [][]string{
+		{"curl", "-fsSL", "-o", filepath.Join("/tmp", fileName), helmURL},
+		{"chmod", "700", filepath.Join("/tmp", fileName)},
+		{filepath.Join("/tmp", fileName), "--version", version},
+	}
+	for _, cmd := range cmds {
+		if err := x.Run(cmd[0], cmd[1:]...); err != nil {
+			return fmt.Errorf("error executing %s commands: %w", strings.Join(cmd, " "), err)
 		}
 	}
 
 	return nil
 }
 
-func preInstallPkg() error {
+func preInstallPkg(x executor.Executor) error {
 	fmt.Println("Install dependency packages...")
 
-	if err := installYqTool("yq_linux_amd64.tar.gz"); err != nil {
+	if err := installYqTool(x, "yq_linux_amd64.tar.gz"); err != nil {
 		return err
 	}
 
-	return installHelmTool("get_helm.sh", "v3.12.3")
-}
-
-func writeSysctlConfig(file *os.File, found1, found2, found3 bool) error {
-	if !found1 {
-		_, err := file.WriteString("fs.inotify.max_queued_events = 1048576\n")
-		if err != nil {
-			return fmt.Errorf("writing file: %w", err)
-		}
-	}
-	if !found2 {
-		_, err := file.WriteString("fs.inotify.max_user_instances = 1048576\n")
-		if err != nil {
-			return fmt.Errorf("writing file: %w", err)
-		}
-	}
-	if !found3 {
-		_, err := file.WriteString("fs.inotify.max_user_watches = 1048576\n")
-		if err != nil {
-			return fmt.Errorf("writing file: %w", err)
-		}
-	}
-	cmdline := "sysctl -p"
-
-	return privateCmdExcute(cmdline)
-}
-
-func updateFanotifyFD(path string) error {
-	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
-	if err != nil {
-		return fmt.Errorf("opening file: %w", err)
-	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			fmt.Printf("Warning: failed to close file: %v\n", err)
-		}
-	}()
-
-	scanner := bufio.NewScanner(file)
-	found1 := false
-	found2 := false
-	found3 := false
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "#") {
-			if strings.Contains(line, "fs.inotify.max_queued_events = 1048576") {
-				found1 = true
-			}
-			if strings.Contains(line, "fs.inotify.max_user_instances = 1048576") {
-				found2 = true
-			}
-			if strings.Contains(line, "fs.inotify.max_user_watches = 1048576") {
-				found3 = true
-			}
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanning file:%w", err)
-	}
-
-	return writeSysctlConfig(file, found1, found2, found3)
+	return installHelmTool(x, "get_helm.sh", "v3.12.3")
 }
 
 // Ensure necessary directories for Hostpath
-func ensureHostpathDirectories(directories []string) error {
+func ensureHostpathDirectories(x executor.Executor, directories []string) error {
 	for _, dir := range directories {
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			if err := os.MkdirAll(dir, 0o755); err != nil {
+			if err := x.MkdirAll(dir, 0o755); err != nil {
 				return fmt.Errorf("failed to create directory %s: %w", dir, err)
 			}
 		}
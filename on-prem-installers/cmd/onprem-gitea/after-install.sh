@@ -129,3 +129,10 @@ createGiteaSecret "cluster-gitea-credential" "clusterorch" "$clusterGiteaPasswor
   createGiteaAccount "argocd-gitea-credential" "argocd" "$argocdGiteaPassword" "argocd@orch-installer.com"
   createGiteaAccount "app-gitea-credential" "apporch" "$appGiteaPassword" "apporch@orch-installer.com"
   createGiteaAccount "cluster-gitea-credential" "clusterorch" "$clusterGiteaPassword" "clusterorch@orch-installer.com"
+
+# Record the resources of the package for onprem-uninstall(1)
+if command -v onprem-uninstall >/dev/null; then
+  onprem-uninstall --record package onprem-gitea-installer
+  onprem-uninstall --record namespace gitea
+  onprem-uninstall --record file /usr/local/share/ca-certificates/gitea_cert.crt
+fi
@@ -8,8 +8,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/inventory"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
 	"github.com/open-edge-platform/edge-manageability-framework/on-prem-installers/mage"
 )
 
@@ -27,7 +32,17 @@ const (
 	deploymentDefaultTimeout = "3600s" // must be a valid duration string
 )
 
-var upgrade = flag.Bool("upgrade", false, "determine if KE should be upgraded or installed")
+var (
+	upgrade  = flag.Bool("upgrade", false, "determine if KE should be upgraded or installed")
+	rollback = flag.Bool("rollback", false, "roll back the most recent RKE2 upgrade hop to its etcd snapshot")
+	plan     = flag.Bool("plan", false, "print the changes the installer would make without applying them")
+	target   = flag.String("target", "", "RKE2 version to upgrade to, defaults to the upgrade channel's default version")
+
+	inventoryPath = flag.String("inventory", os.Getenv("RKE2_INVENTORY"),
+		"node inventory of a multi-node cluster, defaults to $RKE2_INVENTORY")
+	concurrency = flag.Int("concurrency", envInt("RKE2_UPGRADE_CONCURRENCY", 1),
+		"number of agent nodes upgraded at the same time, defaults to $RKE2_UPGRADE_CONCURRENCY or 1")
+)
 
 func main() {
 	if err := os.Setenv("KUBECONFIG", fmt.Sprintf("/home/%s/.kube/config", os.Getenv("USER"))); err != nil {
@@ -57,8 +72,70 @@ func main() {
 	// --end
 
 	flag.Parse()
+	if *target != "" && !*upgrade {
+		fmt.Println("Error: -target can only be used together with -upgrade")
+		os.Exit(1)
+	}
+	if *rollback && *upgrade {
+		fmt.Println("Error: -rollback cannot be used together with -upgrade")
+		os.Exit(1)
+	}
+	if *concurrency < 1 {
+		fmt.Println("Error: -concurrency must be at least 1")
+		os.Exit(1)
+	}
+
+	var inv *inventory.Inventory
+	if *inventoryPath != "" {
+		var err error
+		if inv, err = inventory.Load(*inventoryPath); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	upgrader := mage.RKE2Upgrader{Target: *target, Inventory: inv, Concurrency: *concurrency}
+
+	if *upgrade && *plan {
+		if err := printUpgradePath(upgrader); err != nil {
+			fmt.Printf("Error planning cluster upgrade: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *rollback && *plan {
+		planner := executor.NewPlan(os.Stdout)
+		fmt.Println("Planned changes:")
+		if err := (mage.RKE2Rollback{Exec: planner, Inventory: inv}).Rollback(); err != nil {
+			fmt.Printf("Error planning cluster rollback: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		os.Exit(0)
+	}
+
+	if *rollback {
+		if err := (mage.RKE2Rollback{Exec: executor.Local{}, Inventory: inv}).Rollback(); err != nil {
+			fmt.Printf("Error rolling back cluster: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("RKE2 cluster rolled back: 😊")
+		os.Exit(0)
+	}
+
+	if *plan {
+		planner := executor.NewPlan(os.Stdout)
+		fmt.Println("Planned changes:")
+		if err := (mage.RKE2Installer{Exec: planner, Inventory: inv}).Deploy(); err != nil {
+			fmt.Printf("Error planning local cluster deployment: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		os.Exit(0)
+	}
+
 	if *upgrade {
-		if err := (mage.Upgrade{}).Rke2Cluster(); err != nil {
+		if err := upgrader.Upgrade(); err != nil {
 			fmt.Printf("Error upgrading cluster: %s\n", err)
 			os.Exit(1)
 		}
@@ -67,8 +144,65 @@ func main() {
 
 	// Deploy Online OnPrem RKE2 cluster
 	fmt.Print(header)
-	if err := (mage.Deploy{}).Rke2Cluster(); err != nil {
-		fmt.Printf("Error deploying local cluster: %s\n", err)
+	// Recorded first so that a partly deployed cluster is uninstalled too.
+	if err := uninstall.Record(executor.Local{}, "/"+uninstall.InventoryPath,
+		uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-ke-installer"},
+		uninstall.Resource{Kind: uninstall.KindRKE2, Name: "rke2"},
+	); err != nil {
+		fmt.Printf("Error recording cluster resources: %s\n", err)
 		os.Exit(1)
 	}
+	if inv == nil {
+		if err := (mage.Deploy{}).Rke2Cluster(); err != nil {
+			fmt.Printf("Error deploying local cluster: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := (mage.RKE2Installer{Exec: executor.Local{}, Inventory: inv}).Deploy(); err != nil {
+		fmt.Printf("Error deploying cluster: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("RKE2 cluster ready: 😊")
+}
+
+// envInt returns the integer value of the environment variable key, or def if it is unset or invalid.
+func envInt(key string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return def
+}
+
+// printUpgradePath prints the RKE2 versions the cluster would be upgraded through, without upgrading it.
+func printUpgradePath(upgrader mage.RKE2Upgrader) error {
+	path, err := upgrader.Path()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Current RKE2 version: %s\n", path.Current)
+	fmt.Printf("Target RKE2 version: %s\n", path.Target)
+	if len(path.Hops) == 0 {
+		fmt.Println("RKE2 is already at the target version. No upgrade needed.")
+		return nil
+	}
+	if path.Bundled {
+		fmt.Println("Artifacts: bundled, no network access needed")
+	} else {
+		fmt.Println("Artifacts: partly or not bundled, missing ones are pulled from the network")
+	}
+
+	fmt.Println("Upgrade path:")
+	from := path.Current
+	for i, hop := range path.Hops {
+		fmt.Printf("  %d. %s -> %s\n", i+1, from, hop)
+		from = hop
+	}
+	fmt.Println("Node order at each upgrade:")
+	for i, batch := range path.Batches {
+		fmt.Printf("  %d. %s\n", i+1, strings.Join(batch, ", "))
+	}
+	fmt.Printf("%d upgrades planned, nothing was changed.\n", len(path.Hops))
+	return nil
 }
@@ -31,3 +31,6 @@ fi
 
 # Secrets for postgresql are generated on each installation, so we have to clean them up to avoid issues during reinstallation
 kubectl delete secret -l managed-by=edge-manageability-framework -A || true
+
+# Remove the installation journal and extracted artifact kept for resuming the installer
+rm -rf /var/lib/orch-installer
@@ -7,16 +7,24 @@ package main
 import (
 	"bytes"
 	"encoding/base64"
+	"flag"
 	"fmt"
-	"io"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
-	"github.com/bitfield/script"
 	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/clusterconfig"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/gitea"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
 )
 
 const edgeManageabilityFrameworkRepo = "edge-manageability-framework"
@@ -33,9 +41,32 @@ const gitUserEnv = "GIT_USER"
 
 const deployRepoURLEnv = "DEPLOY_REPO_URL"
 
+const stateDir = "/var/lib/orch-installer"
+
 var giteaInstalled = os.Getenv("INSTALL_GITEA")
 
+var (
+	resume   = flag.Bool("resume", false, "resume the previous installation from the first step that did not complete")
+	fromStep = flag.String("from-step", "", "run the installation starting from the given step")
+	onlyStep = flag.String("only-step", "", "run only the given installation step")
+	plan     = flag.Bool("plan", false, "print the changes the selected steps would make without applying them")
+
+	forcePush = flag.Bool("force-push", false, "replace the history of the Gitea repo instead of adding a commit")
+)
+
+// installer holds the inputs shared by the installation steps.
+type installer struct {
+	x               executor.Executor
+	artifactPath    string
+	profile         string
+	giteaServiceURL string
+	// workDir holds the extracted artifact. It is kept under stateDir so that later steps can be resumed.
+	workDir string
+}
+
 func main() {
+	flag.Parse()
+
 	tarFilesLocation := os.Getenv(gitReposEnv)
 	if tarFilesLocation == "" {
 		log.Fatalf("%v env var is empty", gitReposEnv)
@@ -48,177 +79,285 @@ func main() {
 
 	log.Printf("Starting installation of orch-installer using %s profile...", orchInstallerProfile)
 
-	homeDir, err := os.UserHomeDir()
+	artifactPath, err := getArtifactPath(tarFilesLocation, edgeManageabilityFrameworkRepo)
 	if err != nil {
-		log.Fatalf("failed to get $HOME dir - %v", err)
+		log.Fatalf("%v", err)
 	}
 
-	edgeManageabilityFrameworkFolder, err := os.MkdirTemp(homeDir, edgeManageabilityFrameworkRepo)
-	if err != nil {
-		log.Panicf("failed to create temp directory - %v", err)
+	if err := checkProfile(artifactPath, orchInstallerProfile); err != nil {
+		log.Fatalf("%v", err)
 	}
-	defer func() {
-		if err := os.RemoveAll(edgeManageabilityFrameworkFolder); err != nil {
-			log.Printf("Warning: failed to remove temp directory %s: %v\n", edgeManageabilityFrameworkFolder, err)
-		}
-	}()
 
 	giteaServiceURL, err := getGiteaServiceURL()
 	if err != nil {
 		log.Fatalf("failed to get Gitea service URL - %v", err)
 	}
-	if strings.EqualFold(giteaInstalled, "true") {
-		err = pushArtifactRepoToGitea(edgeManageabilityFrameworkFolder, getArtifactPath(tarFilesLocation, edgeManageabilityFrameworkRepo),
-			edgeManageabilityFrameworkRepo, giteaServiceURL)
-		if err != nil {
-			log.Panicf("%v", err)
-		}
-	} else {
-		_, err := sh.Output("tar", "-xf", getArtifactPath(tarFilesLocation, edgeManageabilityFrameworkRepo), "-C", edgeManageabilityFrameworkFolder)
-		if err != nil {
-			log.Fatalf("failed to untar artifact - %v", err)
-		}
+
+	var x executor.Executor = executor.Local{}
+	planner := executor.NewPlan(os.Stdout)
+	if *plan {
+		x = planner
 	}
 
-	err = installRootApp(edgeManageabilityFrameworkFolder, orchInstallerProfile, giteaServiceURL)
-	if err != nil {
-		log.Panicf("failed to install root-app - %v", err)
+	inst := &installer{
+		x:               x,
+		artifactPath:    artifactPath,
+		profile:         orchInstallerProfile,
+		giteaServiceURL: giteaServiceURL,
+		workDir:         filepath.Join(stateDir, "work"),
 	}
-	configFiles, err := filepath.Glob(filepath.Join(edgeManageabilityFrameworkFolder, edgeManageabilityFrameworkRepo, "orch-configs/clusters/onprem*.yaml"))
+
+	journal, err := steps.LoadJournal(filepath.Join(stateDir, "journal.json"))
 	if err != nil {
-		log.Printf("failed to find onprem config files - %v", err)
-	} else {
-		for _, configFile := range configFiles {
-			fmt.Printf("Found config file: %s\n", configFile)
-			fileName := filepath.Base(configFile)
-			if fileName != "onprem.yaml" && fileName != "onprem-oxm.yaml" {
-				continue
-			}
-			content, err := os.ReadFile(configFile)
-			if err != nil {
-				log.Printf("failed to read config file %s - %v", configFile, err)
-				continue
-			}
-			fmt.Printf("Contents of %s:\n%s\n\n", configFile, string(content))
+		log.Fatalf("%v", err)
+	}
+
+	runner := &steps.Runner{
+		Steps:   inst.steps(),
+		Journal: journal,
+		Inputs: map[string]string{
+			"artifact":     artifactPath,
+			"profile":      orchInstallerProfile,
+			"giteaEnabled": strconv.FormatBool(strings.EqualFold(giteaInstalled, "true")),
+		},
+		Out: os.Stdout,
+	}
+
+	opts := steps.Options{Resume: *resume, FromStep: *fromStep, OnlyStep: *onlyStep}
+
+	if *plan {
+		if err := inst.printPlanInputs(); err != nil {
+			log.Fatalf("%v", err)
+		}
+		fmt.Println("Planned changes:")
+		if err := runner.Plan(opts); err != nil {
+			log.Fatalf("failed to plan installation of orch-installer - %v", err)
 		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		return
+	}
+
+	if err := uninstall.Record(x, "/"+uninstall.InventoryPath,
+		uninstall.Resource{Kind: uninstall.KindPackage, Name: "onprem-orch-installer"}); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if err := runner.Run(opts); err != nil {
+		log.Fatalf("installation of orch-installer failed - %v\nFix the issue and rerun orch-installer with --resume, "+
+			"or with --from-step/--only-step to rerun specific steps. Valid steps: %v", err, runner.Names())
 	}
 
 	fmt.Printf("Installation of orch-installer is completed.")
 }
 
-// Pushes repo from packaged tar file to Gitea repo on the cluster
-// Takes 2 arguments:
-// 1) Path to local tar file that contains repo
-// 2) Name of the Gitea repo that will be created.
-func pushArtifactRepoToGitea(untaredPath, artifactPath, repoName, giteaServiceURL string) error {
-	_, err := sh.Output("tar", "-xf", artifactPath, "-C", untaredPath)
-	if err != nil {
-		return fmt.Errorf("failed to untar artifact - %w", err)
+func (i *installer) steps() []steps.Step {
+	installSteps := []steps.Step{
+		{
+			Name:        "untar-artifact",
+			Description: "Extract the " + edgeManageabilityFrameworkRepo + " artifact",
+			Run:         i.untarArtifact,
+		},
 	}
 
-	buf := &bytes.Buffer{}
-	err = template.Must(template.New("job").Parse(`
-apiVersion: batch/v1
-kind: Job
-metadata:
-  name: gitea-init-{{ .repoName }}
-  namespace: gitea
-  labels:
-    managed-by: edge-manageability-framework
-spec:
-  template:
-    spec:
-      volumes:
-      - name: tea
-        hostPath:
-          path: /usr/bin/tea
-      - name: repo
-        hostPath:
-          path: {{ .repoDir }}
-      - name: tls
-        secret:
-          secretName: gitea-tls-certs
-      containers:
-      - name: alpine
-        image: alpine/git:2.49.1
-        env:
-        - name: GITEA_USERNAME
-          valueFrom:
-            secretKeyRef:
-              name: argocd-gitea-credential
-              key: username
-        - name: GITEA_PASSWORD
-          valueFrom:
-            secretKeyRef:
-              name: argocd-gitea-credential
-              key: password
-        command:
-        - /bin/sh
-        - -c
-        args:
-        - git config --global credential.helper store;
-          git config --global user.email $GITEA_USERNAME@orch-installer.com;
-          git config --global user.name $GITEA_USERNAME;
-          git config --global http.sslCAInfo /usr/local/share/ca-certificates/tls.crt;
-          git config --global --add safe.directory /repo;
-          echo "https://$GITEA_USERNAME:$GITEA_PASSWORD@{{ .giteaURL }}" > /root/.git-credentials;
-          chdir /repo;
-          git init;
-          git remote add gitea https://{{ .giteaURL }}/$GITEA_USERNAME/{{ .repoName }}.git;
-          git checkout -B main;
-          git add .;
-          git commit --allow-empty -m 'Recreate repo from artifact';
-          git push --force gitea main;
-        volumeMounts:
-        - name: tea
-          mountPath: /usr/bin/tea
-        - name: repo
-          mountPath: /repo
-        - name: tls
-          mountPath: /usr/local/share/ca-certificates/
-      restartPolicy: Never
-  backoffLimit: 5`)).
-		Execute(buf, map[string]string{
-			"repoName": repoName,
-			"repoDir":  filepath.Join(untaredPath, repoName),
-			"giteaURL": giteaServiceURL,
+	if strings.EqualFold(giteaInstalled, "true") {
+		installSteps = append(installSteps, steps.Step{
+			Name:        "push-to-gitea",
+			Description: "Push the " + edgeManageabilityFrameworkRepo + " repo to Gitea",
+			Run: func() error {
+				return pushArtifactRepoToGitea(i.x, i.workDir, edgeManageabilityFrameworkRepo, i.giteaServiceURL)
+			},
 		})
+	}
+
+	return append(installSteps,
+		steps.Step{
+			Name:        "install-root-app",
+			Description: "Install the ArgoCD root-app",
+			Run: func() error {
+				if err := installRootApp(i.x, i.workDir, i.profile, i.giteaServiceURL); err != nil {
+					return fmt.Errorf("failed to install root-app - %w", err)
+				}
+				return nil
+			},
+		},
+		steps.Step{
+			Name:        "print-cluster-configs",
+			Description: "Print the on-prem cluster configs",
+			Run:         i.printClusterConfigs,
+		},
+	)
+}
+
+// printPlanInputs prints what the installation is based on: the Gitea repos to push and the root-app values, which are
+// read straight from the artifact since it is not extracted when planning.
+func (i *installer) printPlanInputs() error {
+	fmt.Printf("Artifact: %s\n", i.artifactPath)
+	if strings.EqualFold(giteaInstalled, "true") {
+		fmt.Printf("Gitea repos: https://%s/<argocd user>/%s\n", i.giteaServiceURL, edgeManageabilityFrameworkRepo)
+	} else {
+		fmt.Println("Gitea repos: none, Gitea is not installed")
+	}
+
+	valuesFile := filepath.Join(edgeManageabilityFrameworkRepo, "orch-configs/clusters", i.profile+".yaml")
+	values, err := i.x.Query("tar", "-xzOf", i.artifactPath, valuesFile)
 	if err != nil {
-		return fmt.Errorf("failed to template job - %w", err)
+		return fmt.Errorf("failed to read root-app values %s from artifact - %w", valuesFile, err)
 	}
+	fmt.Printf("Root-app values (%s):\n%s\n\n", valuesFile, values)
+	return nil
+}
 
-	out, err := script.Echo(buf.String()).Exec("kubectl apply -f -").String()
+// checkProfile validates the profile against the cluster configurations shipped in the artifact, so that a bad profile
+// fails before anything is installed. Only orch-configs and the root-app defaults are extracted, to a temporary
+// directory.
+func checkProfile(artifactPath, profile string) error {
+	tmpDir, err := os.MkdirTemp("", "orch-installer-configs")
 	if err != nil {
-		return fmt.Errorf("failed to create job - %w %s", err, out)
+		return fmt.Errorf("failed to create temporary directory - %w", err)
 	}
+	defer os.RemoveAll(tmpDir)
 
-	log.Printf("Waiting for %v job to finish...", "gitea-init-"+repoName)
+	if err := sh.Run("tar", "-xzf", artifactPath, "-C", tmpDir,
+		filepath.Join(edgeManageabilityFrameworkRepo, "orch-configs"),
+		filepath.Join(edgeManageabilityFrameworkRepo, clusterconfig.RootAppValues)); err != nil {
+		return fmt.Errorf("failed to extract cluster configurations from artifact - %w", err)
+	}
+
+	if _, err := clusterconfig.Check(os.DirFS(filepath.Join(tmpDir, edgeManageabilityFrameworkRepo)), profile); err != nil {
+		return fmt.Errorf("invalid %s %s - %w", orchInstallerProfileEnv, profile, err)
+	}
+
+	log.Printf("Cluster values of %s profile are valid", profile)
+	return nil
+}
 
-	out, err = script.Exec("kubectl wait --for=condition=complete " +
-		"--timeout=300s -n gitea job/gitea-init-" + repoName).String()
+func (i *installer) untarArtifact() error {
+	// Start from an empty directory so that files removed from the artifact don't linger from an earlier attempt.
+	if err := i.x.RemoveAll(i.workDir); err != nil {
+		return fmt.Errorf("failed to clean up %s - %w", i.workDir, err)
+	}
+	if err := i.x.MkdirAll(i.workDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s - %w", i.workDir, err)
+	}
+
+	if err := i.x.Run("tar", "-xf", i.artifactPath, "-C", i.workDir); err != nil {
+		return fmt.Errorf("failed to untar artifact - %w", err)
+	}
+	return nil
+}
+
+// printClusterConfigs prints the on-prem cluster configs of the artifact. They are read straight from the artifact,
+// like in printPlanInputs, so that a plan shows the configs the installation would use rather than those of the work
+// directory of an earlier run.
+func (i *installer) printClusterConfigs() error {
+	files, err := i.x.Query("tar", "-tzf", i.artifactPath)
 	if err != nil {
-		return fmt.Errorf("failed to wait for job - %w %s", err, out)
+		return fmt.Errorf("failed to list artifact %s - %w", i.artifactPath, err)
 	}
 
+	pattern := filepath.Join(edgeManageabilityFrameworkRepo, "orch-configs/clusters/onprem*.yaml")
+	for _, configFile := range strings.Split(files, "\n") {
+		configFile = strings.TrimPrefix(strings.TrimSpace(configFile), "./")
+		if ok, _ := filepath.Match(pattern, configFile); !ok {
+			continue
+		}
+		fmt.Printf("Found config file: %s\n", configFile)
+		fileName := filepath.Base(configFile)
+		if fileName != "onprem.yaml" && fileName != "onprem-oxm.yaml" {
+			continue
+		}
+		content, err := i.x.Query("tar", "-xzOf", i.artifactPath, configFile)
+		if err != nil {
+			log.Printf("failed to read config file %s - %v", configFile, err)
+			continue
+		}
+		fmt.Printf("Contents of %s:\n%s\n\n", configFile, content)
+	}
 	return nil
 }
 
-func getArtifactPath(tarFilesLocation, repoName string) string {
+// Pushes repo from the extracted artifact to Gitea repo on the cluster over a port-forward to the Gitea service,
+// authenticating with the credentials of the argocd-gitea-credential secret.
+// Takes 3 arguments:
+// 1) Path to the directory the artifact was extracted to
+// 2) Name of the Gitea repo that will be created.
+// 3) Gitea service host and port.
+func pushArtifactRepoToGitea(x executor.Executor, untaredPath, repoName, giteaServiceURL string) error {
+	// Earlier versions pushed from an in-cluster Job, remove the one they left behind.
+	if err := x.Run("kubectl", "delete", "job", "--ignore-not-found", "-n", "gitea", "gitea-init-"+repoName); err != nil {
+		return fmt.Errorf("failed to delete previous job - %w", err)
+	}
+
+	username, password, err := giteaCredentials(x)
+	if err != nil {
+		return err
+	}
+	x.Redact(password)
+
+	caBase64, err := x.Query("kubectl", "get", "secret", "gitea-tls-certs", "-n", "gitea",
+		"-o", `jsonpath={.data.tls\.crt}`)
+	if err != nil {
+		return fmt.Errorf("failed to fetch gitea CA - %w", err)
+	}
+	ca, err := base64.StdEncoding.DecodeString(caBase64)
+	if err != nil {
+		return fmt.Errorf("failed to decode gitea CA - %w", err)
+	}
+
+	port := "443"
+	if _, p, err := net.SplitHostPort(giteaServiceURL); err == nil {
+		port = p
+	}
+	repoURL := fmt.Sprintf("https://%s/%s/%s.git", giteaServiceURL, username, repoName)
+	repoDir := filepath.Join(untaredPath, repoName)
+
+	return x.Apply(fmt.Sprintf("push %s to %s", repoDir, repoURL), func() error {
+		forward, err := gitea.StartPortForward("gitea", "gitea-http", port, time.Minute)
+		if err != nil {
+			return err
+		}
+		defer forward.Close()
+
+		httpClient, err := gitea.HTTPClient(forward.Addr, ca)
+		if err != nil {
+			return fmt.Errorf("failed to configure gitea client - %w", err)
+		}
+
+		message := "Update repo from artifact"
+		if *forcePush {
+			message = "Recreate repo from artifact"
+		}
+		result, err := gitea.Push(repoDir, gitea.PushOptions{
+			URL:        repoURL,
+			Username:   username,
+			Password:   password,
+			HTTPClient: httpClient,
+			Force:      *forcePush,
+			Message:    message,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to push %s to gitea - %w", repoName, err)
+		}
+		log.Printf("Pushed %s", result)
+		return nil
+	})
+}
+
+func getArtifactPath(tarFilesLocation, repoName string) (string, error) {
 	tarSuffix := ".tgz"
 
 	files, err := os.ReadDir(tarFilesLocation)
 	if err != nil {
-		log.Panicf("failed to read artifacts directory - %v", err)
+		return "", fmt.Errorf("failed to read artifacts directory - %w", err)
 	}
 
 	for _, file := range files {
 		if strings.Contains(file.Name(), repoName) && strings.HasSuffix(file.Name(), tarSuffix) {
-			return filepath.Join(tarFilesLocation, file.Name())
+			return filepath.Join(tarFilesLocation, file.Name()), nil
 		}
 	}
 
-	log.Panicf("failed to get *%v*.tgz artifact path", repoName)
-	return ""
+	return "", fmt.Errorf("failed to get *%v*.tgz artifact path", repoName)
 }
 
 // Deploys code from local Gitea repo using ArgoCd
@@ -226,13 +365,13 @@ func getArtifactPath(tarFilesLocation, repoName string) string {
 // 1) Name of the repo on Gitea
 // 2) Path to the helm chart inside the repo
 // 3) Namespace in which chart will be deployed.
-func installRootApp(edgeManageabilityFrameworkFolder, orchInstallerProfile, giteaServiceURL string) error {
+func installRootApp(x executor.Executor, edgeManageabilityFrameworkFolder, orchInstallerProfile, giteaServiceURL string) error {
 	namespace := "onprem"
-	
+
 	// Create repository credentials based on installation type
 	if strings.EqualFold(giteaInstalled, "true") {
 		// Use local Gitea
-		if err := createGiteaCredsSecret(edgeManageabilityFrameworkRepo, namespace, giteaServiceURL); err != nil {
+		if err := createGiteaCredsSecret(x, edgeManageabilityFrameworkRepo, namespace, giteaServiceURL); err != nil {
 			return fmt.Errorf("failed to create gitea secret with creds - %w", err)
 		}
 	} else {
@@ -246,49 +385,26 @@ func installRootApp(edgeManageabilityFrameworkFolder, orchInstallerProfile, gite
 		} else {
 			log.Println("No GitHub credentials provided, creating repository configuration for public access")
 		}
-		if err := createGitHubCredsSecret(edgeManageabilityFrameworkRepo, namespace); err != nil {
+		if err := createGitHubCredsSecret(x, edgeManageabilityFrameworkRepo, namespace); err != nil {
 			return fmt.Errorf("failed to create GitHub repository secret - %w", err)
 		}
 	}
 
-	return sh.RunV("helm", "upgrade", "--install", "root-app",
+	return x.Run("helm", "upgrade", "--install", "root-app",
 		filepath.Join(edgeManageabilityFrameworkFolder, edgeManageabilityFrameworkRepo, "argocd/root-app"),
 		"-f", filepath.Join(edgeManageabilityFrameworkFolder, edgeManageabilityFrameworkRepo, "orch-configs/clusters", orchInstallerProfile+".yaml"),
 		"-n", namespace, "--create-namespace")
 }
 
-func createGiteaCredsSecret(repoName string, namespace string, giteaServiceURL string) error {
-	argoCDCredsSecret := "argocd-gitea-credential"
-
-	giteaUsernameBase64, err := sh.Output("kubectl", "get", "secret",
-		argoCDCredsSecret, "-n", "gitea", "-o", "jsonpath={.data.username}")
-	if err != nil {
-		return fmt.Errorf("failed to fetch gitea username - %w", err)
-	}
-	giteaPasswordBase64, err := sh.Output("kubectl", "get", "secret",
-		argoCDCredsSecret, "-n", "gitea", "-o", "jsonpath={.data.password}")
-	if err != nil {
-		return fmt.Errorf("failed to fetch gitea password - %w", err)
-	}
-
-	usernameReader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(giteaUsernameBase64))
-	passwordReader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(giteaPasswordBase64))
-
-	giteaPasswordBytes, err := io.ReadAll(passwordReader)
-	if err != nil {
-		return fmt.Errorf("failed to decode gitea password - %w", err)
-	}
-	giteaPassword := string(giteaPasswordBytes)
-
-	giteaUsernameBytes, err := io.ReadAll(usernameReader)
+func createGiteaCredsSecret(x executor.Executor, repoName string, namespace string, giteaServiceURL string) error {
+	giteaUsername, giteaPassword, err := giteaCredentials(x)
 	if err != nil {
-		return fmt.Errorf("failed to decode gitea password - %w", err)
+		return err
 	}
-	giteaUsername := string(giteaUsernameBytes)
+	x.Redact(giteaPassword)
 
-	commandTemplate := template.Must(template.New("template").
-		Parse(`kubectl create -f - <<EOF
-apiVersion: v1
+	secretTemplate := template.Must(template.New("template").
+		Parse(`apiVersion: v1
 kind: Secret
 metadata:
   name: {{ .repoName }}
@@ -300,10 +416,9 @@ stringData:
   url:  https://{{ .giteaURL }}/{{ .username }}/{{ .repoName }}
   password: {{ .password }}
   username: {{ .username }}
-EOF
 `))
 
-	_, _ = sh.Output("kubectl", "delete", "secret", repoName, "-n", "argocd")
+	_ = x.Run("kubectl", "delete", "secret", repoName, "-n", "argocd", "--ignore-not-found")
 
 	buf := &bytes.Buffer{}
 
@@ -314,17 +429,44 @@ EOF
 		"password":  giteaPassword,
 		"giteaURL":  giteaServiceURL,
 	}
-	if err := commandTemplate.Execute(buf, templateParams); err != nil {
+	if err := secretTemplate.Execute(buf, templateParams); err != nil {
 		return fmt.Errorf("failed to run command - %w", err)
 	}
 
-	if err := sh.RunV("sh", "-c", buf.String()); err != nil {
+	if err := x.RunInput(buf.String(), "kubectl", "create", "-f", "-"); err != nil {
 		return fmt.Errorf("failed to create secret with credentials - %w", err)
 	}
 	return nil
 }
 
-func createGitHubCredsSecret(repoName string, namespace string) error {
+// giteaCredentials returns the username and password of the Gitea user Argo CD pulls with.
+func giteaCredentials(x executor.Executor) (string, string, error) {
+	argoCDCredsSecret := "argocd-gitea-credential"
+
+	giteaUsernameBase64, err := x.Query("kubectl", "get", "secret",
+		argoCDCredsSecret, "-n", "gitea", "-o", "jsonpath={.data.username}")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch gitea username - %w", err)
+	}
+	giteaPasswordBase64, err := x.Query("kubectl", "get", "secret",
+		argoCDCredsSecret, "-n", "gitea", "-o", "jsonpath={.data.password}")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch gitea password - %w", err)
+	}
+
+	giteaUsername, err := base64.StdEncoding.DecodeString(giteaUsernameBase64)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode gitea username - %w", err)
+	}
+	giteaPassword, err := base64.StdEncoding.DecodeString(giteaPasswordBase64)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode gitea password - %w", err)
+	}
+
+	return string(giteaUsername), string(giteaPassword), nil
+}
+
+func createGitHubCredsSecret(x executor.Executor, repoName string, namespace string) error {
 	gitToken := os.Getenv(gitTokenEnv)
 	gitUser := os.Getenv(gitUserEnv)
 	deployRepoURL := os.Getenv(deployRepoURLEnv)
@@ -334,14 +476,15 @@ func createGitHubCredsSecret(repoName string, namespace string) error {
 		log.Printf("Using default GitHub repository URL: %s", deployRepoURL)
 	}
 
-	var commandTemplate *template.Template
-	
+	x.Redact(gitToken)
+
+	var secretTemplate *template.Template
+
 	// Create secret with or without credentials based on what's provided
 	if gitToken != "" && gitUser != "" {
 		// Authenticated access for private repos
-		commandTemplate = template.Must(template.New("template").
-			Parse(`kubectl create -f - <<EOF
-apiVersion: v1
+		secretTemplate = template.Must(template.New("template").
+			Parse(`apiVersion: v1
 kind: Secret
 metadata:
   name: {{ .repoName }}
@@ -353,13 +496,11 @@ stringData:
   url: {{ .repoURL }}
   password: {{ .password }}
   username: {{ .username }}
-EOF
 `))
 	} else {
 		// Public repo access without credentials
-		commandTemplate = template.Must(template.New("template").
-			Parse(`kubectl create -f - <<EOF
-apiVersion: v1
+		secretTemplate = template.Must(template.New("template").
+			Parse(`apiVersion: v1
 kind: Secret
 metadata:
   name: {{ .repoName }}
@@ -369,11 +510,10 @@ metadata:
 stringData:
   type: git
   url: {{ .repoURL }}
-EOF
 `))
 	}
 
-	_, _ = sh.Output("kubectl", "delete", "secret", repoName, "-n", "argocd")
+	_ = x.Run("kubectl", "delete", "secret", repoName, "-n", "argocd", "--ignore-not-found")
 
 	buf := &bytes.Buffer{}
 
@@ -384,11 +524,11 @@ EOF
 		"password":  gitToken,
 		"repoURL":   deployRepoURL,
 	}
-	if err := commandTemplate.Execute(buf, templateParams); err != nil {
+	if err := secretTemplate.Execute(buf, templateParams); err != nil {
 		return fmt.Errorf("failed to run command - %w", err)
 	}
 
-	if err := sh.RunV("sh", "-c", buf.String()); err != nil {
+	if err := x.RunInput(buf.String(), "kubectl", "create", "-f", "-"); err != nil {
 		return fmt.Errorf("failed to create repository secret - %w", err)
 	}
 
@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/pgupgrade"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+var (
+	dir       = flag.String("dir", ".", "working directory of the upgrade, holding its journal")
+	namespace = flag.String("namespace", "orch-database", "namespace of the CloudNativePG cluster")
+	source    = flag.String("cluster", "postgresql-cluster", "CloudNativePG cluster to upgrade")
+	major     = flag.Int("version", 0, "major version of PostgreSQL to upgrade to")
+	target    = flag.String("target", "", "name of the cluster of the new version, defaults to <cluster>-<version>")
+	image     = flag.String("image", "", "PostgreSQL image of the new version, defaults to the CloudNativePG image")
+	backupDir = flag.String("backup-dir", "", "directory of the dumps, defaults to postgres-upgrade in --dir")
+	timeout   = flag.Duration("timeout", 15*time.Minute, "how long to wait for the new cluster to be healthy")
+	logDir    = flag.String("log-dir", "/var/log/orch-upgrade", "directory of the JSON log of the upgrade")
+	yes       = flag.Bool("yes", false, "don't ask to confirm --retire and --rollback")
+	verbose   = flag.Bool("verbose", false, "also log progress while waiting")
+
+	retire   = flag.Bool("retire", false, "delete the pods and volumes of the old cluster after the switch-over")
+	rollback = flag.Bool("rollback", false, "point the services back to the old cluster")
+
+	resume   = flag.Bool("resume", false, "resume the previous upgrade from the first phase that did not complete")
+	fromStep = flag.String("from-step", "", "run the upgrade starting from the given phase")
+	onlyStep = flag.String("only-step", "", "run only the given upgrade phase")
+	plan     = flag.Bool("plan", false, "print the changes the selected phases would make without applying them")
+)
+
+func main() {
+	flag.Parse()
+
+	if *major <= 0 {
+		log.Fatalf("no version to upgrade to, set --version")
+	}
+	if *retire && *rollback {
+		log.Fatalf("--retire and --rollback can't be combined")
+	}
+	workDir, err := filepath.Abs(*dir)
+	if err != nil {
+		log.Fatalf("invalid directory %s - %v", *dir, err)
+	}
+	cfg := pgupgrade.Config{
+		Namespace: *namespace,
+		Source:    *source,
+		Target:    firstNonEmpty(*target, *source+"-"+strconv.Itoa(*major)),
+		Major:     *major,
+		Image:     firstNonEmpty(*image, pgupgrade.DefaultImage(*major)),
+		BackupDir: firstNonEmpty(*backupDir, filepath.Join(workDir, "postgres-upgrade")),
+	}
+
+	logger, logFile, err := steps.NewLogger(*logDir, "onprem-postgres-upgrade", *verbose)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	var x executor.Executor = executor.Local{}
+	planner := executor.NewPlan(os.Stdout)
+	if *plan {
+		x = planner
+	}
+	upgrader := &pgupgrade.Upgrader{Config: cfg, Timeout: *timeout, X: x, Log: logger}
+
+	switch {
+	case *retire:
+		if !*yes && !*plan && !confirm(fmt.Sprintf("Delete the pods and volumes of cluster %s? Its data can't be "+
+			"recovered except from the dumps in %s.", cfg.Source, cfg.BackupDir)) {
+			log.Fatalf("retirement of cluster %s cancelled", cfg.Source)
+		}
+		if err := upgrader.Retire(); err != nil {
+			log.Fatalf("failed to retire cluster %s - %v", cfg.Source, err)
+		}
+		return
+	case *rollback:
+		if !*yes && !*plan && !confirm(fmt.Sprintf("Point the services back to cluster %s? Writes made to cluster %s "+
+			"since the switch-over are not moved back.", cfg.Source, cfg.Target)) {
+			log.Fatalf("rollback to cluster %s cancelled", cfg.Source)
+		}
+		if err := upgrader.Rollback(); err != nil {
+			log.Fatalf("failed to roll back to cluster %s - %v", cfg.Source, err)
+		}
+		return
+	}
+
+	journal, err := steps.LoadJournal(filepath.Join(workDir, ".onprem-postgres-upgrade", "journal.json"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	runner := &steps.Runner{
+		Steps:   upgrader.Phases(),
+		Journal: journal,
+		Inputs: map[string]string{
+			"namespace": cfg.Namespace,
+			"cluster":   cfg.Source,
+			"target":    cfg.Target,
+			"version":   strconv.Itoa(cfg.Major),
+			"image":     cfg.Image,
+		},
+		Out: os.Stdout,
+	}
+
+	opts := steps.Options{Resume: *resume, FromStep: *fromStep, OnlyStep: *onlyStep}
+
+	if *plan {
+		fmt.Println("Planned changes:")
+		if err := runner.Plan(opts); err != nil {
+			log.Fatalf("failed to plan the upgrade - %v", err)
+		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		return
+	}
+
+	logger.Info("upgrade started", "cluster", cfg.Source, "target", cfg.Target, "version", cfg.Major, "log", logFile)
+	if err := runner.Run(opts); err != nil {
+		log.Fatalf("upgrade of PostgreSQL failed - %v\nSee %s for details. Fix the issue and rerun "+
+			"onprem-postgres-upgrade with --resume, or with --from-step/--only-step to rerun specific phases. Valid "+
+			"phases: %v. Until the switch-over, --rollback points the services back to cluster %s.",
+			err, logFile, runner.Names(), cfg.Source)
+	}
+	logger.Info("upgrade completed", "cluster", cfg.Target, "version", cfg.Major)
+	fmt.Printf("The services now point to cluster %s. Cluster %s is kept: once the Orchestrator works, rerun with "+
+		"--retire to delete its pods and volumes, or with --rollback to switch back.\n", cfg.Target, cfg.Source)
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
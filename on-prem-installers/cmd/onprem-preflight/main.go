@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/preflight"
+)
+
+var (
+	profile = flag.String("profile", "", "installation profile to check the host against, "+
+		"defaults to ORCH_INSTALLER_PROFILE or onprem")
+	output  = flag.String("output", "text", "report format, text or json")
+	dataDir = flag.String("data-dir", "/var", "filesystem RKE2 and the persistent volumes are stored on")
+)
+
+func main() {
+	flag.Parse()
+
+	if *output != "text" && *output != "json" {
+		log.Fatalf("invalid output format %q, must be text or json", *output)
+	}
+
+	cfg := preflight.Config{
+		Profile:           firstNonEmpty(*profile, os.Getenv("ORCH_INSTALLER_PROFILE"), "onprem"),
+		ClusterDomain:     firstNonEmpty(os.Getenv("CLUSTER_DOMAIN"), "cluster.onprem"),
+		ReleaseServiceURL: firstNonEmpty(os.Getenv("RELEASE_SERVICE_URL"), "registry-rs.edgeorchestration.intel.com"),
+		DataDir:           *dataDir,
+	}
+
+	report := preflight.Run(preflight.LocalHost{}, cfg)
+
+	write := report.WriteText
+	if *output == "json" {
+		write = report.WriteJSON
+	}
+	if err := write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+
+	if report.Status == preflight.StatusFail {
+		os.Exit(1)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
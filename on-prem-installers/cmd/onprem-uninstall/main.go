@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
+)
+
+var (
+	keepData = flag.Bool("keep-data", false, "keep the hostpath volumes, the backups and the namespaces with "+
+		"persistent volume claims")
+	only = flag.String("only", "", "remove only the resources of these comma-separated kinds")
+	yes  = flag.Bool("yes", false, "don't ask to confirm the uninstallation")
+	plan = flag.Bool("plan", false, "print the resources that would be removed without removing them")
+
+	record = flag.String("record", "", "record the resources of the given kind named by the arguments in the "+
+		"inventory instead of uninstalling")
+	data = flag.Bool("data", false, "mark the recorded resources as data, kept by --keep-data")
+
+	inventoryPath = flag.String("inventory", "/"+uninstall.InventoryPath, "inventory of the installed resources")
+)
+
+func main() {
+	flag.Parse()
+
+	if *record != "" {
+		kind, err := uninstall.ParseKind(*record)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		var resources []uninstall.Resource
+		for _, name := range flag.Args() {
+			resources = append(resources, uninstall.Resource{Kind: kind, Name: name, Data: *data})
+		}
+		if err := uninstall.Record(executor.Local{}, *inventoryPath, resources...); err != nil {
+			log.Fatalf("failed to record %s resources - %v", kind, err)
+		}
+		return
+	}
+
+	inv, ok, err := uninstall.Load(*inventoryPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if !ok {
+		fmt.Printf("No inventory at %s, uninstalling the resources of installers that didn't record one\n",
+			*inventoryPath)
+		inv = uninstall.Legacy()
+	}
+
+	var kinds []uninstall.Kind
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			kind, err := uninstall.ParseKind(strings.TrimSpace(name))
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	var x executor.Executor = executor.Local{}
+	planner := executor.NewPlan(os.Stdout)
+	if *plan {
+		x = planner
+		fmt.Println("Planned changes:")
+	} else if !*yes && !confirm(inv) {
+		fmt.Println("Uninstallation cancelled, nothing was changed.")
+		return
+	}
+
+	uninstaller := uninstall.Uninstaller{Root: "/", Exec: x, Out: os.Stdout, KeepData: *keepData, Kinds: kinds}
+	report, err := uninstaller.Run(inv, *inventoryPath)
+	if *plan {
+		if err != nil {
+			log.Fatalf("failed to plan the uninstallation - %v", err)
+		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		return
+	}
+	report.Print(os.Stdout)
+	if err != nil {
+		log.Fatalf("uninstallation failed - %v", err)
+	}
+	if err := report.Err(); err != nil {
+		log.Fatalf("uninstallation incomplete - %v\nThe remaining resources are kept in %s, fix the issue and "+
+			"rerun onprem-uninstall.", err, *inventoryPath)
+	}
+	if len(report.Kept) > 0 {
+		fmt.Printf("Uninstallation completed, the kept resources are listed in %s.\n",
+			filepath.Clean(*inventoryPath))
+		return
+	}
+	fmt.Println("Uninstallation completed.")
+}
+
+func confirm(inv *uninstall.Inventory) bool {
+	fmt.Printf("This removes %d resources of the Orchestrator", len(inv.Resources))
+	if *keepData {
+		fmt.Print(", keeping the data")
+	}
+	fmt.Print(". Continue? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/orchupgrade"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/steps"
+)
+
+// passedEnv are the variables of onprem.env the installer packages read during the upgrade.
+var passedEnv = []string{"RKE2_INVENTORY", "RKE2_UPGRADE_CONCURRENCY", "GIT_TOKEN", "GIT_USER", "DEPLOY_REPO_URL"}
+
+var (
+	dir           = flag.String("dir", ".", "directory of the on-prem scripts and onprem.env")
+	version       = flag.String("version", "", "Orchestrator version to upgrade to, defaults to DEPLOY_VERSION")
+	profile       = flag.String("profile", "", "installation profile, defaults to ORCH_INSTALLER_PROFILE or onprem")
+	localPackages = flag.Bool("local-packages", false, "upgrade from the packages and repo archive already in the "+
+		"installers and repo_archives directories instead of pulling them")
+	backupVolumes = flag.Bool("backup-volumes", false, "back up the persistent volumes and take an etcd snapshot")
+	backupDir     = flag.String("backup-dir", "", "directory of the backup, defaults to backup in --dir")
+	logDir        = flag.String("log-dir", "/var/log/orch-upgrade", "directory of the JSON log of the upgrade")
+	yes           = flag.Bool("yes", false, "don't ask to confirm the generated cluster values")
+	verbose       = flag.Bool("verbose", false, "also log progress while waiting")
+
+	resume   = flag.Bool("resume", false, "resume the previous upgrade from the first phase that did not complete")
+	fromStep = flag.String("from-step", "", "run the upgrade starting from the given phase")
+	onlyStep = flag.String("only-step", "", "run only the given upgrade phase")
+	plan     = flag.Bool("plan", false, "print the changes the selected phases would make without applying them")
+)
+
+func main() {
+	flag.Parse()
+
+	workDir, err := filepath.Abs(*dir)
+	if err != nil {
+		log.Fatalf("invalid directory %s - %v", *dir, err)
+	}
+	cfg := orchupgrade.Config{
+		Dir:                workDir,
+		Version:            firstNonEmpty(*version, os.Getenv("DEPLOY_VERSION")),
+		Profile:            firstNonEmpty(*profile, os.Getenv("ORCH_INSTALLER_PROFILE"), "onprem"),
+		ReleaseServiceURL:  firstNonEmpty(os.Getenv("RELEASE_SERVICE_URL"), "registry-rs.edgeorchestration.intel.com"),
+		LocalPackages:      *localPackages,
+		PackagesDir:        filepath.Join(workDir, "installers"),
+		ReposDir:           filepath.Join(workDir, "repo_archives"),
+		BackupDir:          firstNonEmpty(*backupDir, filepath.Join(workDir, "backup")),
+		BackupVolumes:      *backupVolumes,
+		ClusterDomain:      os.Getenv("CLUSTER_DOMAIN"),
+		GiteaImageRegistry: firstNonEmpty(os.Getenv("GITEA_IMAGE_REGISTRY"), "docker.io"),
+		Env:                map[string]string{},
+	}
+	if cfg.Version == "" {
+		log.Fatalf("no version to upgrade to, set --version or source onprem.env")
+	}
+	for _, name := range passedEnv {
+		if value := os.Getenv(name); value != "" {
+			cfg.Env[name] = value
+		}
+	}
+
+	logger, logFile, err := steps.NewLogger(*logDir, "onprem-upgrade", *verbose)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	var x executor.Executor = executor.Local{}
+	planner := executor.NewPlan(os.Stdout)
+	if *plan {
+		x = planner
+	}
+
+	upgrader := &orchupgrade.Upgrader{Config: cfg, X: x, Log: logger}
+	if !*yes && !*plan {
+		upgrader.Confirm = confirm
+	}
+
+	journal, err := steps.LoadJournal(filepath.Join(workDir, ".onprem-upgrade", "journal.json"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	runner := &steps.Runner{
+		Steps:   upgrader.Phases(),
+		Journal: journal,
+		Inputs: map[string]string{
+			"version":       cfg.Version,
+			"profile":       cfg.Profile,
+			"localPackages": strconv.FormatBool(cfg.LocalPackages),
+		},
+		Out: os.Stdout,
+	}
+
+	opts := steps.Options{Resume: *resume, FromStep: *fromStep, OnlyStep: *onlyStep}
+
+	if *plan {
+		fmt.Println("Planned changes:")
+		if err := runner.Plan(opts); err != nil {
+			log.Fatalf("failed to plan the upgrade - %v", err)
+		}
+		fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+		return
+	}
+
+	logger.Info("upgrade started", "version", cfg.Version, "profile", cfg.Profile, "log", logFile)
+	if err := runner.Run(opts); err != nil {
+		log.Fatalf("upgrade of the Orchestrator failed - %v\nSee %s for details. Fix the issue and rerun onprem-upgrade "+
+			"with --resume, or with --from-step/--only-step to rerun specific phases. Valid phases: %v",
+			err, logFile, runner.Names())
+	}
+	logger.Info("upgrade completed", "version", cfg.Version)
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/uninstall"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/vaultunseal"
+)
+
+const unitDir = "/etc/systemd/system"
+
+var (
+	address   = flag.String("address", "", "address of a single Vault server to watch instead of the Vault pods")
+	namespace = flag.String("namespace", "orch-platform", "namespace of the Vault pods and of the keys secret")
+	selector  = flag.String("selector", "app.kubernetes.io/name=vault,component=server", "label selector of the "+
+		"Vault pods")
+	port   = flag.Int("port", 8200, "API port of the Vault pods")
+	scheme = flag.String("scheme", "http", "scheme of the Vault API of the pods")
+
+	keysFile = flag.String("keys-file", "", "file with the sys/init response holding the key shares, e.g. the "+
+		"mounted keys secret, instead of reading the secret")
+	keysSecret = flag.String("keys-secret", "vault-keys", "secret with the sys/init response holding the key shares")
+	keysKey    = flag.String("keys-key", "vault-keys", "key of the sys/init response in the keys secret")
+
+	interval    = flag.Duration("interval", vaultunseal.DefaultInterval, "time between two seal status checks")
+	metricsAddr = flag.String("metrics-address", "127.0.0.1:9102", "address of the Prometheus metrics endpoint, "+
+		"none if empty")
+	kubeconfig = flag.String("kubeconfig", "", "kubeconfig, defaults to KUBECONFIG and to the in-cluster config")
+	once       = flag.Bool("once", false, "check the seal status once and exit")
+
+	installService = flag.Bool("install-service", false, "install a systemd service that runs the watcher with "+
+		"these flags instead of running it")
+	plan = flag.Bool("plan", false, "print the changes installing the service would make without applying them")
+)
+
+func main() {
+	flag.Parse()
+
+	if *installService {
+		var x executor.Executor = executor.Local{}
+		planner := executor.NewPlan(os.Stdout)
+		if *plan {
+			x = planner
+		}
+		if err := installUnsealService(x); err != nil {
+			log.Fatalf("failed to install the Vault unseal service - %v", err)
+		}
+		if *plan {
+			fmt.Printf("%d changes planned, nothing was changed.\n", planner.Changes)
+			return
+		}
+		fmt.Printf("Vault is unsealed automatically, see systemctl status %s\n", vaultunseal.ServiceUnit)
+		return
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	watcher, err := newWatcher(logger)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		if err := watcher.Check(ctx); err != nil {
+			log.Fatalf("failed to unseal Vault - %v", err)
+		}
+		return
+	}
+
+	if *metricsAddr != "" {
+		registry := prometheus.NewRegistry()
+		watcher.Metrics = vaultunseal.NewMetrics(registry)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		server := &http.Server{Addr: *metricsAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("failed to serve metrics on %s - %v", *metricsAddr, err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	logger.Info("watching Vault seal status", "interval", *interval)
+	if err := watcher.Run(ctx); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// newWatcher returns a watcher of the Vault pods, or of the server at --address. The Kubernetes client is needed for
+// the pods, the keys secret and the events, a watcher of --address with --keys-file works without a cluster.
+func newWatcher(logger *slog.Logger) (*vaultunseal.Watcher, error) {
+	watcher := &vaultunseal.Watcher{Interval: *interval, Log: logger}
+
+	var client kubernetes.Interface
+	if *address == "" || *keysFile == "" {
+		config, err := clientcmd.BuildConfigFromFlags("", firstNonEmpty(*kubeconfig, os.Getenv("KUBECONFIG")))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the kubeconfig - %w", err)
+		}
+		if client, err = kubernetes.NewForConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to create the Kubernetes client - %w", err)
+		}
+		hostname, _ := os.Hostname()
+		watcher.Events = vaultunseal.KubeRecorder{Client: client, Component: "onprem-vault-unseal", Host: hostname}
+	}
+
+	if *address != "" {
+		watcher.Targets = vaultunseal.StaticTarget(*address)
+	} else {
+		watcher.Targets = vaultunseal.PodTargets{
+			Client:    client,
+			Namespace: *namespace,
+			Selector:  *selector,
+			Port:      *port,
+			Scheme:    *scheme,
+		}
+	}
+
+	if *keysFile != "" {
+		watcher.Keys = vaultunseal.FileKeys{Path: *keysFile}
+	} else {
+		watcher.Keys = vaultunseal.SecretKeys{Client: client, Namespace: *namespace, Name: *keysSecret, Key: *keysKey}
+	}
+	return watcher, nil
+}
+
+// installUnsealService renders the service unit that runs this command with the same flags and enables it.
+func installUnsealService(x executor.Executor) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the onprem-vault-unseal binary - %w", err)
+	}
+	command := []string{exe,
+		"--namespace", *namespace,
+		"--selector", *selector,
+		"--port", strconv.Itoa(*port),
+		"--scheme", *scheme,
+		"--keys-secret", *keysSecret,
+		"--keys-key", *keysKey,
+		"--interval", interval.String(),
+		"--metrics-address", *metricsAddr}
+	if *address != "" {
+		command = append(command, "--address", *address)
+	}
+	if *keysFile != "" {
+		command = append(command, "--keys-file", *keysFile)
+	}
+	env := map[string]string{}
+	if config := firstNonEmpty(*kubeconfig, os.Getenv("KUBECONFIG")); config != "" {
+		if env["KUBECONFIG"], err = filepath.Abs(config); err != nil {
+			return fmt.Errorf("invalid kubeconfig %s - %w", config, err)
+		}
+	}
+
+	unit, err := vaultunseal.Service{Command: command, Env: env}.Unit()
+	if err != nil {
+		return err
+	}
+	if err := x.WriteFile(filepath.Join(unitDir, vaultunseal.ServiceUnit), []byte(unit), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s - %w", vaultunseal.ServiceUnit, err)
+	}
+	if err := x.Run("systemctl", "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd - %w", err)
+	}
+	if err := x.Run("systemctl", "enable", "--now", vaultunseal.ServiceUnit); err != nil {
+		return fmt.Errorf("failed to enable %s - %w", vaultunseal.ServiceUnit, err)
+	}
+	return uninstall.Record(x, "/"+uninstall.InventoryPath,
+		uninstall.Resource{Kind: uninstall.KindSystemdUnit, Name: vaultunseal.ServiceUnit})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/mage"
+)
+
+const (
+	aptRepoDir          = "dist/apt-repo"
+	aptRepoSuite        = "stable"
+	aptRepoComponent    = "main"
+	aptRepoArchitecture = "amd64"
+	aptRepoKeyringName  = "orchestrator-archive-keyring.gpg"
+	aptRepoKeyName      = "Edge Orchestrator Local Repository"
+
+	// aptRepoGPGKeyEnv selects the key used to sign the repository from the caller's keyring. When unset, an
+	// ephemeral key is generated and its public part shipped with the repository.
+	aptRepoGPGKeyEnv = "APT_REPO_GPG_KEY_ID"
+)
+
+// Builds a signed apt repository from the DEB packages in dist, including their dependencies. Must run on Ubuntu 22.04.
+func (Build) AptRepo() error {
+	for _, tool := range []string{"apt-cache", "apt-get", "apt-ftparchive", "dpkg-deb", "gpg"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s is required to build the apt repository: %w", tool, err)
+		}
+	}
+
+	debs, err := filepath.Glob(filepath.Join("dist", "*.deb"))
+	if err != nil {
+		return fmt.Errorf("failed to list .deb files: %w", err)
+	}
+	if len(debs) == 0 {
+		return fmt.Errorf("no .deb files found in dist directory, build the installers first")
+	}
+
+	if err := os.RemoveAll(aptRepoDir); err != nil {
+		return fmt.Errorf("failed to clean up existing apt repository: %w", err)
+	}
+	poolDir := filepath.Join(aptRepoDir, "pool", aptRepoComponent)
+	if err := os.MkdirAll(poolDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create pool directory: %w", err)
+	}
+
+	var ownPackages, dependencies []string
+	for _, deb := range debs {
+		name, depends, err := debControlFields(deb)
+		if err != nil {
+			return err
+		}
+		ownPackages = append(ownPackages, name)
+		dependencies = append(dependencies, depends...)
+
+		if err := sh.Copy(filepath.Join(poolDir, filepath.Base(deb)), deb); err != nil {
+			return fmt.Errorf("failed to copy %s to the pool: %w", deb, err)
+		}
+	}
+
+	if err := downloadDEBDependencies(poolDir, dependencies, ownPackages); err != nil {
+		return err
+	}
+
+	if err := writeAptRepoIndexes(); err != nil {
+		return err
+	}
+
+	if err := signAptRepo(); err != nil {
+		return err
+	}
+
+	absRepoDir, err := filepath.Abs(aptRepoDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Apt repository created in %s ✅\n", aptRepoDir)
+	fmt.Println("Copy it to the offline host, install the keyring and add the repository, e.g.:")
+	fmt.Printf("  sudo cp %s/%s /usr/share/keyrings/\n", absRepoDir, aptRepoKeyringName)
+	fmt.Printf("  echo \"deb [signed-by=/usr/share/keyrings/%s] file:%s %s %s\" | "+
+		"sudo tee /etc/apt/sources.list.d/orchestrator.list\n",
+		aptRepoKeyringName, absRepoDir, aptRepoSuite, aptRepoComponent)
+	fmt.Println("Use an http:// URL instead of file: when serving the directory with a local HTTP server.")
+
+	return nil
+}
+
+// debControlFields returns the package name and the names of the packages it depends on. For alternative
+// dependencies only the first alternative is returned and version constraints are dropped.
+func debControlFields(deb string) (string, []string, error) {
+	out, err := exec.Command("dpkg-deb", "--showformat=${Package}\n${Depends}", "--show", deb).Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read control fields of %s: %w", deb, err)
+	}
+
+	name, depends, _ := strings.Cut(string(out), "\n")
+	if name == "" {
+		return "", nil, fmt.Errorf("failed to get name for %s: empty name", deb)
+	}
+
+	var deps []string
+	for _, dep := range strings.Split(depends, ",") {
+		dep, _, _ = strings.Cut(dep, "|")
+		dep, _, _ = strings.Cut(dep, "(")
+		if dep = strings.TrimSpace(dep); dep != "" {
+			deps = append(deps, dep)
+		}
+	}
+
+	return name, deps, nil
+}
+
+// downloadDEBDependencies downloads the recursive dependencies of the given packages into poolDir using the apt
+// sources configured on the build host.
+func downloadDEBDependencies(poolDir string, dependencies []string, exclude []string) error {
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	fmt.Println("Resolving DEB dependencies")
+	args := append([]string{
+		"depends", "--recurse",
+		"--no-recommends", "--no-suggests", "--no-conflicts", "--no-breaks", "--no-replaces", "--no-enhances",
+	}, dependencies...)
+	out, err := exec.Command("apt-cache", args...).Output()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	// Package names are the only lines that aren't indented, virtual packages are wrapped in angle brackets.
+	var packages []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "<") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, ":") // drop the architecture qualifier
+		if !slices.Contains(exclude, name) && !slices.Contains(packages, name) {
+			packages = append(packages, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to parse dependencies: %w", err)
+	}
+
+	fmt.Printf("Downloading %d dependency packages\n", len(packages))
+	cmd := exec.Command("apt-get", append([]string{"download"}, packages...)...)
+	cmd.Dir = poolDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to download dependencies: %w", err)
+	}
+
+	return nil
+}
+
+func writeAptRepoIndexes() error {
+	binaryDir := filepath.Join("dists", aptRepoSuite, aptRepoComponent, "binary-"+aptRepoArchitecture)
+	if err := os.MkdirAll(filepath.Join(aptRepoDir, binaryDir), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create %s: %w", binaryDir, err)
+	}
+
+	cmd := exec.Command("apt-ftparchive", "packages", "pool")
+	cmd.Dir = aptRepoDir
+	packages, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to generate Packages index: %w", err)
+	}
+	packagesPath := filepath.Join(aptRepoDir, binaryDir, "Packages")
+	if err := os.WriteFile(packagesPath, packages, 0o644); err != nil {
+		return fmt.Errorf("failed to write Packages index: %w", err)
+	}
+	if err := sh.Run("gzip", "--keep", "--force", "-9", packagesPath); err != nil {
+		return fmt.Errorf("failed to compress Packages index: %w", err)
+	}
+
+	version, err := mage.GetDebVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get DEB version: %w", err)
+	}
+
+	cmd = exec.Command("apt-ftparchive",
+		"-o", "APT::FTPArchive::Release::Origin=Intel Corporation",
+		"-o", "APT::FTPArchive::Release::Label="+aptRepoKeyName,
+		"-o", "APT::FTPArchive::Release::Suite="+aptRepoSuite,
+		"-o", "APT::FTPArchive::Release::Codename="+aptRepoSuite,
+		"-o", "APT::FTPArchive::Release::Version="+version,
+		"-o", "APT::FTPArchive::Release::Architectures="+aptRepoArchitecture,
+		"-o", "APT::FTPArchive::Release::Components="+aptRepoComponent,
+		"release", filepath.Join("dists", aptRepoSuite),
+	)
+	cmd.Dir = aptRepoDir
+	release, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to generate Release file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(aptRepoDir, "dists", aptRepoSuite, "Release"), release, 0o644); err != nil {
+		return fmt.Errorf("failed to write Release file: %w", err)
+	}
+
+	return nil
+}
+
+// signAptRepo writes InRelease and Release.gpg and exports the public key next to the repository.
+func signAptRepo() error {
+	gpgArgs := []string{"--batch", "--yes"}
+
+	keyID := os.Getenv(aptRepoGPGKeyEnv)
+	if keyID == "" {
+		gnupgHome, err := os.MkdirTemp("", "apt-repo-gnupg-")
+		if err != nil {
+			return fmt.Errorf("failed to create GnuPG home: %w", err)
+		}
+		defer os.RemoveAll(gnupgHome)
+
+		gpgArgs = append(gpgArgs, "--homedir", gnupgHome)
+		keyID = aptRepoKeyName
+
+		fmt.Printf("%s not set, generating an ephemeral signing key\n", aptRepoGPGKeyEnv)
+		if err := sh.Run("gpg", append(gpgArgs,
+			"--passphrase", "", "--quick-gen-key", keyID, "rsa4096", "sign", "never")...); err != nil {
+			return fmt.Errorf("failed to generate signing key: %w", err)
+		}
+	}
+
+	release := filepath.Join(aptRepoDir, "dists", aptRepoSuite, "Release")
+	if err := sh.Run("gpg", append(gpgArgs, "--local-user", keyID,
+		"--clearsign", "--output", filepath.Join(filepath.Dir(release), "InRelease"), release)...); err != nil {
+		return fmt.Errorf("failed to create InRelease: %w", err)
+	}
+	if err := sh.Run("gpg", append(gpgArgs, "--local-user", keyID,
+		"--armor", "--detach-sign", "--output", release+".gpg", release)...); err != nil {
+		return fmt.Errorf("failed to create Release.gpg: %w", err)
+	}
+
+	if err := sh.Run("gpg", append(gpgArgs,
+		"--output", filepath.Join(aptRepoDir, aptRepoKeyringName), "--export", keyID)...); err != nil {
+		return fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	return nil
+}
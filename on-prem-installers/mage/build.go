@@ -85,6 +85,7 @@ func (Build) onpremKeInstaller() error {
 			filepath.Join(".", "cmd", deployFilePath, "main.go"),
 			filepath.Join(".", "dist", "bin", deployFilePath),
 		),
+		Build{}.rke2UpgradeBundle,
 	)
 
 	debVersion, err := mage.GetDebVersion()
@@ -121,6 +122,36 @@ func (Build) osConfigInstaller() error {
 			filepath.Join(".", "cmd", "onprem-config-installer", "main.go"),
 			filepath.Join(".", "dist", "bin", "onprem-config-installer"),
 		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-preflight", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-preflight"),
+		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-upgrade", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-upgrade"),
+		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-postgres-upgrade", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-postgres-upgrade"),
+		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-backup", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-backup"),
+		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-uninstall", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-uninstall"),
+		),
+		mg.F(
+			compile,
+			filepath.Join(".", "cmd", "onprem-vault-unseal", "main.go"),
+			filepath.Join(".", "dist", "bin", "onprem-vault-unseal"),
+		),
 	)
 
 	debVersion, err := mage.GetDebVersion()
@@ -136,16 +167,29 @@ func (Build) osConfigInstaller() error {
 		"--name", "onprem-config-installer",
 		"-p", "./dist",
 		"-d", "jq,libpq5,apparmor,lvm2,net-tools,ntp,openssh-server",
-		"-d", "software-properties-common,tpm2-abrmd,tpm2-tools,unzip",
+		"-d", "software-properties-common,tpm2-abrmd,tpm2-tools,unzip,nfs-common",
 		"--version", debVersion,
 		"--architecture", "amd64",
 		"--description", "OS Configuration Powered By Intel",
 		"--url", "https://github.com/open-edge-platform/edge-manageability-framework/on-prem-installers",
 		"--maintainer", "Intel Corporation",
 		"--after-install", "./cmd/onprem-config-installer/after-install.sh",
+		"--before-remove", "./cmd/onprem-config-installer/before-remove.sh",
 		"--after-remove", "./cmd/onprem-config-installer/after-remove.sh",
 		"./dist/bin/onprem-config-installer=/usr/bin/onprem-config-installer",
 		"./cmd/onprem-config-installer/onprem-config-installer.1=/usr/share/man/man1/onprem-config-installer.1",
+		"./dist/bin/onprem-preflight=/usr/bin/onprem-preflight",
+		"./cmd/onprem-preflight/onprem-preflight.1=/usr/share/man/man1/onprem-preflight.1",
+		"./dist/bin/onprem-upgrade=/usr/bin/onprem-upgrade",
+		"./cmd/onprem-upgrade/onprem-upgrade.1=/usr/share/man/man1/onprem-upgrade.1",
+		"./dist/bin/onprem-postgres-upgrade=/usr/bin/onprem-postgres-upgrade",
+		"./cmd/onprem-postgres-upgrade/onprem-postgres-upgrade.1=/usr/share/man/man1/onprem-postgres-upgrade.1",
+		"./dist/bin/onprem-backup=/usr/bin/onprem-backup",
+		"./cmd/onprem-backup/onprem-backup.1=/usr/share/man/man1/onprem-backup.1",
+		"./dist/bin/onprem-uninstall=/usr/bin/onprem-uninstall",
+		"./cmd/onprem-uninstall/onprem-uninstall.1=/usr/share/man/man1/onprem-uninstall.1",
+		"./dist/bin/onprem-vault-unseal=/usr/bin/onprem-vault-unseal",
+		"./cmd/onprem-vault-unseal/onprem-vault-unseal.1=/usr/share/man/man1/onprem-vault-unseal.1",
 	)
 }
 
@@ -7,48 +7,100 @@ package mage
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
-	"github.com/magefile/mage/sh"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/inventory"
 )
 
-func (Deploy) rke2Cluster() error { //nolint: cyclop
+func (Deploy) rke2Cluster() error {
+	if err := (RKE2Installer{Exec: executor.Local{}}).Deploy(); err != nil {
+		return err
+	}
+
+	fmt.Println("RKE2 cluster ready: 😊")
+	return nil
+}
+
+const (
+	// rke2ClusterConfig is the RKE2 configuration drop-in that names the node and, on nodes joining the cluster,
+	// points them at the bootstrap server.
+	rke2ClusterConfig = "/etc/rancher/rke2/config.yaml.d/50-orchestrator-cluster.yaml"
+	rke2NodeToken     = "/var/lib/rancher/rke2/server/node-token"
+	// rke2SupervisorPort is where servers accept nodes joining the cluster.
+	rke2SupervisorPort = 9345
+	// remoteInstallerDir is where the install scripts are copied to on the other nodes.
+	remoteInstallerDir = "/tmp/onprem-ke-installer"
+)
+
+// RKE2Installer deploys the RKE2 cluster. Every change goes through Exec so that a deployment can be planned
+// without applying it.
+type RKE2Installer struct {
+	Exec executor.Executor
+	// Inventory lists the nodes of a multi-node cluster. The installer runs on its bootstrap server and joins the
+	// others over SSH. A single-node cluster is installed on the local host if nil.
+	Inventory *inventory.Inventory
+}
+
+// Deploy installs RKE2 and OpenEBS LocalPV on the local host and joins the other nodes of the inventory.
+func (i RKE2Installer) Deploy() error { //nolint: cyclop
+	x := i.Exec
+
+	if i.Inventory != nil {
+		bootstrap := i.Inventory.Bootstrap()
+		fmt.Printf("Installing %d node cluster, bootstrapping on %s\n", len(i.Inventory.Nodes), bootstrap.Name)
+		if err := x.MkdirAll(filepath.Dir(rke2ClusterConfig), 0o755); err != nil {
+			return err
+		}
+		if err := x.WriteFile(rke2ClusterConfig, []byte(i.clusterConfig(bootstrap, "")), 0o600); err != nil {
+			return fmt.Errorf("error writing %s: %w", rke2ClusterConfig, err)
+		}
+	}
+
 	dockerUser, dockerUserPresent := os.LookupEnv("DOCKER_USERNAME")
 	dockerPass, dockerPassPresent := os.LookupEnv("DOCKER_PASSWORD")
 
 	var args []string
 	if dockerUserPresent && dockerPassPresent {
 		fmt.Println("Using Docker credentials for customizing RKE2 installation")
+		x.Redact(dockerPass)
 		args = append(args, "-u", dockerUser, "-p", dockerPass)
 	}
 
-	if err := sh.RunV(filepath.Join("rke2", "rke2installerlocal.sh")); err != nil {
+	if err := x.Run(filepath.Join("rke2", "rke2installerlocal.sh")); err != nil {
 		return fmt.Errorf("error running rke2installerlocal.sh: %w", err)
 	}
 
-	if err := sh.RunV("/bin/bash", append([]string{filepath.Join("rke2", "customize-rke2.sh")}, args...)...); err != nil {
+	if err := x.Run("/bin/bash", append([]string{filepath.Join("rke2", "customize-rke2.sh")}, args...)...); err != nil {
 		return fmt.Errorf("error running customize-rke2.sh: %w", err)
 	}
 
 	// We need to wait for all deployments and pods to be Ready also before deploying OpenEBS
-	if err := testDeploymentAndPods(); err != nil {
+	if err := x.Wait("all deployments and pods are ready", testDeploymentAndPods); err != nil {
 		return fmt.Errorf("error testing deployments and pods: %w", err)
 	}
 
+	if err := i.joinNodes(); err != nil {
+		return err
+	}
+
 	// Add OpenEBS LocalPV helm repository
-	if err := sh.RunV("helm", "repo", "add", "openebs-localpv", "https://openebs.github.io/dynamic-localpv-provisioner"); err != nil {
+	if err := x.Run("helm", "repo", "add", "openebs-localpv", "https://openebs.github.io/dynamic-localpv-provisioner"); err != nil {
 		return fmt.Errorf("error adding openebs-localpv helm repo: %w", err)
 	}
 
 	// Update helm repositories
-	if err := sh.RunV("helm", "repo", "update"); err != nil {
+	if err := x.Run("helm", "repo", "update"); err != nil {
 		return fmt.Errorf("error updating helm repos: %w", err)
 	}
 
 	// Install/upgrade OpenEBS LocalPV provisioner
-	if err := sh.RunV("helm", "upgrade", "--install", "openebs-localpv", "openebs-localpv/localpv-provisioner",
+	if err := x.Run("helm", "upgrade", "--install", "openebs-localpv", "openebs-localpv/localpv-provisioner",
 		"--version", "4.3.0",
 		"--namespace", "openebs-system",
 		"--create-namespace",
@@ -60,7 +112,7 @@ func (Deploy) rke2Cluster() error { //nolint: cyclop
 	}
 
 	// create etcd-cert secret
-	if err := sh.RunV("kubectl", "create", "secret", "generic", "etcd-certs",
+	if err := x.Run("kubectl", "create", "secret", "generic", "etcd-certs",
 		"--from-file=/var/lib/rancher/rke2/server/tls/etcd/server-client.crt",
 		"--from-file=/var/lib/rancher/rke2/server/tls/etcd/server-client.key",
 		"--from-file=/var/lib/rancher/rke2/server/tls/etcd/server-ca.crt"); err != nil {
@@ -70,15 +122,14 @@ func (Deploy) rke2Cluster() error { //nolint: cyclop
 	// Do a final verification (after installing OpenEBS) of all deployments and pods
 	// before declaring cluster is ready
 	// We need to wait for all deployments and pods to be Ready also before deploying OpenEBS
-	if err := testDeploymentAndPods(); err != nil {
+	if err := x.Wait("all deployments and pods are ready", testDeploymentAndPods); err != nil {
 		return fmt.Errorf("error testing deployments and pods after OpenEBS installation: %w", err)
 	}
 
-	if err := sh.RunV(filepath.Join("rke2", "customize-rke2.sh")); err != nil {
+	if err := x.Run(filepath.Join("rke2", "customize-rke2.sh")); err != nil {
 		return fmt.Errorf("error running customize-rke2.sh after OpenEBS installation: %w", err)
 	}
 
-	fmt.Println("RKE2 cluster ready: 😊")
 	return nil
 }
 
@@ -128,3 +179,92 @@ func testDeploymentAndPods() error {
 	}
 	return nil
 }
+
+// clusterConfig returns the content of the rke2ClusterConfig drop-in of node. Servers accept the addresses of every
+// server so that nodes can join and clients connect through any of them. Nodes other than the bootstrap server join
+// it with token.
+func (i RKE2Installer) clusterConfig(node inventory.Node, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "node-name: %s\n", node.Name)
+	if node.Role == inventory.Server {
+		b.WriteString("tls-san:\n")
+		for _, server := range i.Inventory.Servers() {
+			fmt.Fprintf(&b, "  - %s\n", server.Address)
+		}
+	}
+	if bootstrap := i.Inventory.Bootstrap(); node.Name != bootstrap.Name {
+		fmt.Fprintf(&b, "server: https://%s\n", net.JoinHostPort(bootstrap.Address, strconv.Itoa(rke2SupervisorPort)))
+		fmt.Fprintf(&b, "token: %s\n", token)
+	}
+	return b.String()
+}
+
+// joinNodes joins the other nodes of the inventory to the cluster: the servers one at a time, since etcd adds
+// members one by one, and then the agents. Every node must be Ready before the next one joins.
+func (i RKE2Installer) joinNodes() error {
+	if i.Inventory == nil || len(i.Inventory.Nodes) == 1 {
+		return nil
+	}
+	x := i.Exec
+
+	token := "<node-token>"
+	if err := x.Apply("read the cluster join token", func() error {
+		data, err := os.ReadFile(rke2NodeToken)
+		if err != nil {
+			return err
+		}
+		token = strings.TrimSpace(string(data))
+		x.Redact(token)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error reading the cluster join token: %w", err)
+	}
+
+	bootstrap := i.Inventory.Bootstrap()
+	for _, node := range append(i.Inventory.Servers(), i.Inventory.Agents()...) {
+		if node.Name == bootstrap.Name {
+			continue
+		}
+		fmt.Printf("Joining %s %s (%s)\n", node.Role, node.Name, node.Address)
+
+		if err := runCommand(x, node.Command("mkdir", "-p", remoteInstallerDir+"/rke2")); err != nil {
+			return fmt.Errorf("error reaching %s: %w", node.Name, err)
+		}
+		for _, file := range []string{"rke2installerlocal.sh", "audit-policy.yaml"} {
+			if err := runCommand(x, node.Copy(filepath.Join("rke2", file), remoteInstallerDir+"/rke2/"+file)); err != nil {
+				return fmt.Errorf("error copying %s to %s: %w", file, node.Name, err)
+			}
+		}
+
+		// The token is passed on stdin so that it never shows up in a process listing.
+		install := fmt.Sprintf("mkdir -p %s && install -m 0600 /dev/stdin %s", filepath.Dir(rke2ClusterConfig),
+			rke2ClusterConfig)
+		if err := runCommandInput(x, i.clusterConfig(node, token), node.Command("sudo", "sh", "-c", install)); err != nil {
+			return fmt.Errorf("error configuring %s: %w", node.Name, err)
+		}
+
+		script := fmt.Sprintf("cd %s && bash rke2/rke2installerlocal.sh -r %s", remoteInstallerDir, node.Role)
+		if err := runCommand(x, node.Command("bash", "-c", script)); err != nil {
+			return fmt.Errorf("error installing RKE2 on %s: %w", node.Name, err)
+		}
+
+		if err := x.Wait(fmt.Sprintf("node %s is ready", node.Name), func() error {
+			return waitForNodeStatus("node/"+node.Name, "Ready")
+		}); err != nil {
+			return fmt.Errorf("error waiting for %s to join: %w", node.Name, err)
+		}
+
+		if err := runCommand(x, node.Command("rm", "-rf", remoteInstallerDir)); err != nil {
+			return fmt.Errorf("error cleaning up %s: %w", node.Name, err)
+		}
+	}
+	return nil
+}
+
+func runCommand(x executor.Executor, command []string) error {
+	return x.Run(command[0], command[1:]...)
+}
+
+func runCommandInput(x executor.Executor, input string, command []string) error {
+	return x.RunInput(input, command[0], command[1:]...)
+}
@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2channel"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+const (
+	systemUpgradeControllerVersion = "v0.13.2"
+	systemUpgradeControllerURL     = "https://github.com/rancher/system-upgrade-controller/releases/download/" +
+		systemUpgradeControllerVersion + "/system-upgrade-controller.yaml"
+
+	rke2UpgradeImage = "docker.io/rancher/rke2-upgrade"
+	// rke2InstallScriptURL is the RKE2 install script, which installs the bundled release artifacts on rollback.
+	rke2InstallScriptURL = "https://get.rke2.io"
+
+	// rke2AgentImagesDir is where RKE2 imports image tarballs from when it starts.
+	rke2AgentImagesDir = "/var/lib/rancher/rke2/agent/images"
+	rke2Ctr            = "/var/lib/rancher/rke2/bin/ctr"
+	rke2ContainerdSock = "/run/k3s/containerd/containerd.sock"
+)
+
+// rke2UpgradeBundleDir holds the artifacts to upgrade RKE2 without network access:
+//
+//	system-upgrade-controller.yaml
+//	install.sh                            RKE2 install script
+//	system-upgrade-controller-images.tar  controller and kubectl images
+//	<version>/rke2-upgrade.tar            upgrade image of each channel version
+//	<version>/rke2-images*.tar.zst        images RKE2 <version> runs, imported by RKE2 when it restarts
+//	<version>/rke2.linux-amd64.tar.gz     binaries, usable as INSTALL_RKE2_ARTIFACT_PATH for a manual upgrade
+//	<version>/sha256sum-amd64.txt         checksums of the release artifacts
+var rke2UpgradeBundleDir = filepath.Join("rke2", "upgrade-bundle")
+
+// rke2ReleaseArtifacts are the files bundled from each RKE2 release.
+var rke2ReleaseArtifacts = []string{
+	"rke2-images.linux-amd64.tar.zst",
+	"rke2-images-calico.linux-amd64.tar.zst",
+	"rke2.linux-amd64.tar.gz",
+	"sha256sum-amd64.txt",
+}
+
+// controllerImageRegex matches the images the system-upgrade-controller manifest runs, including the kubectl image
+// its upgrade jobs cordon and drain with.
+var controllerImageRegex = regexp.MustCompile(`(?m)^\s*(?:image|SYSTEM_UPGRADE_JOB_KUBECTL_IMAGE):\s*"?([^"\s]+)"?`)
+
+// pullPolicyRegex matches the pull policies of the controller and of the upgrade jobs it creates.
+var pullPolicyRegex = regexp.MustCompile(`(?m)((?:imagePullPolicy|SYSTEM_UPGRADE_JOB_IMAGE_PULL_POLICY):\s*)"?Always"?`)
+
+// rke2UpgradeImageTag returns the tag of the upgrade image of version, which replaces the '+' Docker does not allow.
+func rke2UpgradeImageTag(version semver.RKE2Version) string {
+	return strings.ReplaceAll(version.String(), "+", "-")
+}
+
+func (Build) rke2UpgradeBundle() error {
+	channel, err := rke2channel.Load(rke2ChannelPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(rke2UpgradeBundleDir, 0o755); err != nil {
+		return err
+	}
+
+	manifest := filepath.Join(rke2UpgradeBundleDir, "system-upgrade-controller.yaml")
+	fmt.Printf("Bundling system-upgrade-controller %s\n", systemUpgradeControllerVersion)
+	if err := downloadFile(manifest, systemUpgradeControllerURL); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return err
+	}
+	var images []string
+	for _, match := range controllerImageRegex.FindAllStringSubmatch(string(data), -1) {
+		images = append(images, match[1])
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no images found in %s", systemUpgradeControllerURL)
+	}
+	if err := saveImages(filepath.Join(rke2UpgradeBundleDir, "system-upgrade-controller-images.tar"), images...); err != nil {
+		return err
+	}
+
+	if err := downloadFile(filepath.Join(rke2UpgradeBundleDir, "install.sh"), rke2InstallScriptURL); err != nil {
+		return err
+	}
+
+	for _, release := range channel.Releases {
+		version := release.Version.String()
+		dir := filepath.Join(rke2UpgradeBundleDir, version)
+		fmt.Printf("Bundling RKE2 %s\n", version)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+
+		for _, file := range rke2ReleaseArtifacts {
+			// The image tarballs are large, so artifacts bundled by an earlier build are kept.
+			if _, err := os.Stat(filepath.Join(dir, file)); err == nil && file != "sha256sum-amd64.txt" {
+				continue
+			}
+			url := fmt.Sprintf("https://github.com/rancher/rke2/releases/download/%s/%s",
+				strings.ReplaceAll(version, "+", "%2B"), file)
+			if err := downloadFile(filepath.Join(dir, file), url); err != nil {
+				return err
+			}
+		}
+		if err := verifyRKE2Artifacts(dir); err != nil {
+			return err
+		}
+
+		image := rke2UpgradeImage + ":" + rke2UpgradeImageTag(release.Version)
+		if err := saveImages(filepath.Join(dir, "rke2-upgrade.tar"), image); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveImages pulls the amd64 variant of images and saves them to a single archive.
+func saveImages(archive string, images ...string) error {
+	for _, image := range images {
+		if err := sh.RunV("docker", "pull", "--platform", "linux/amd64", image); err != nil {
+			return fmt.Errorf("failed to pull %s: %w", image, err)
+		}
+	}
+	return sh.RunV("docker", append([]string{"save", "--output", archive}, images...)...)
+}
+
+// verifyRKE2Artifacts checks the bundled release artifacts in dir against the release checksums.
+func verifyRKE2Artifacts(dir string) error {
+	sums, err := os.Open(filepath.Join(dir, "sha256sum-amd64.txt"))
+	if err != nil {
+		return err
+	}
+	defer sums.Close()
+
+	expected := map[string]string{}
+	scanner := bufio.NewScanner(sums)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 {
+			expected[fields[1]] = fields[0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, file := range rke2ReleaseArtifacts {
+		if file == "sha256sum-amd64.txt" {
+			continue
+		}
+		sum, ok := expected[file]
+		if !ok {
+			return fmt.Errorf("no checksum for %s in %s", file, dir)
+		}
+		actual, err := sha256File(filepath.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		if actual != sum {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Join(dir, file), sum, actual)
+		}
+	}
+	return nil
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// rke2UpgradeBundle is the upgrade bundle shipped with the installer, if any.
+type rke2UpgradeBundle struct {
+	dir string
+	// verified records the versions whose artifacts passed the checksum verification.
+	verified map[string]bool
+}
+
+// loadRKE2UpgradeBundle returns the shipped upgrade bundle, or nil if the installer was built without one.
+func loadRKE2UpgradeBundle() (*rke2UpgradeBundle, error) {
+	_, err := os.Stat(filepath.Join(rke2UpgradeBundleDir, "system-upgrade-controller.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rke2UpgradeBundle{dir: rke2UpgradeBundleDir, verified: map[string]bool{}}, nil
+}
+
+// hasVersion reports whether the bundle holds the artifacts of version.
+func (b *rke2UpgradeBundle) hasVersion(version semver.RKE2Version) bool {
+	if b == nil {
+		return false
+	}
+	for _, file := range append([]string{"rke2-upgrade.tar"}, rke2ReleaseArtifacts...) {
+		if _, err := os.Stat(filepath.Join(b.dir, version.String(), file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// preloadController imports the controller images, and the kubectl image its jobs run, on host.
+func (b *rke2UpgradeBundle) preloadController(host rke2Host) error {
+	archive, err := host.stage(filepath.Join(b.dir, "system-upgrade-controller-images.tar"))
+	if err != nil {
+		return err
+	}
+	if err := importImages(host, archive); err != nil {
+		return err
+	}
+	return host.unstage(archive)
+}
+
+// controllerManifest renders the bundled manifest so that neither the controller nor its jobs pull images, and
+// returns the path of the rendered manifest.
+func (b *rke2UpgradeBundle) controllerManifest() (string, error) {
+	data, err := os.ReadFile(filepath.Join(b.dir, "system-upgrade-controller.yaml"))
+	if err != nil {
+		return "", err
+	}
+	rendered := filepath.Join("rke2", "system-upgrade-controller.yaml")
+	if err := os.WriteFile(rendered, pullPolicyRegex.ReplaceAll(data, []byte("${1}IfNotPresent")), 0o644); err != nil {
+		return "", err
+	}
+	return rendered, nil
+}
+
+// preload imports the upgrade image of version on host and stages the images RKE2 version runs, which RKE2 imports
+// when the upgrade restarts it.
+func (b *rke2UpgradeBundle) preload(host rke2Host, version semver.RKE2Version) error {
+	dir := filepath.Join(b.dir, version.String())
+	if !b.verified[version.String()] {
+		if err := verifyRKE2Artifacts(dir); err != nil {
+			return err
+		}
+		b.verified[version.String()] = true
+	}
+
+	staged, err := host.stage(dir)
+	if err != nil {
+		return err
+	}
+	if err := importImages(host, filepath.Join(staged, "rke2-upgrade.tar")); err != nil {
+		return err
+	}
+
+	if err := host.run("mkdir", "-p", rke2AgentImagesDir); err != nil {
+		return err
+	}
+	for _, file := range []string{"rke2-images.linux-amd64.tar.zst", "rke2-images-calico.linux-amd64.tar.zst"} {
+		// The tarballs are named the same for every version, so the ones of the previous hop are replaced.
+		if err := host.run("cp", filepath.Join(staged, file), rke2AgentImagesDir); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", file, err)
+		}
+	}
+	return host.unstage(staged)
+}
+
+// importImages imports an image archive on host into the containerd image store of RKE2.
+func importImages(host rke2Host, archive string) error {
+	fmt.Printf("Importing %s\n", archive)
+	return host.run(rke2Ctr, "--address", rke2ContainerdSock, "--namespace", "k8s.io", "images", "import", archive)
+}
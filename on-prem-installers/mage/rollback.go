@@ -0,0 +1,267 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/magefile/mage/sh"
+
+	"github.com/open-edge-platform/edge-manageability-framework/internal/executor"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/inventory"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2history"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
+)
+
+const (
+	// rke2HistoryPath records the version and etcd snapshot before each upgrade hop.
+	rke2HistoryPath = "/var/lib/orch-installer/rke2-upgrade-history.json"
+	rke2DBDir       = "/var/lib/rancher/rke2/server/db"
+	// remoteRollbackDir is where the bundled release artifacts are copied to on the other nodes.
+	remoteRollbackDir = "/tmp/rke2-rollback"
+)
+
+// snapshotBeforeHop takes an etcd snapshot of the cluster running from and records it in the upgrade history before
+// the hop to version to.
+func snapshotBeforeHop(history *rke2history.History, from, to semver.RKE2Version) error {
+	name := rke2history.SnapshotName(to.String())
+	fmt.Printf("Taking etcd snapshot %s before upgrading to %s\n", name, to)
+	if err := sh.RunV("rke2", "etcd-snapshot", "save", "--name", name); err != nil {
+		return fmt.Errorf("failed to take etcd snapshot: %w", err)
+	}
+	list, err := sh.Output("rke2", "etcd-snapshot", "list")
+	if err != nil {
+		return fmt.Errorf("failed to list etcd snapshots: %w", err)
+	}
+	snapshot, err := rke2history.FindSnapshot(list, name)
+	if err != nil {
+		return err
+	}
+
+	history.Entries = append(history.Entries, rke2history.Entry{
+		From:     from.String(),
+		To:       to.String(),
+		Snapshot: snapshot,
+		Time:     time.Now().UTC(),
+	})
+	return history.Save(rke2HistoryPath)
+}
+
+// RKE2Rollback rolls the cluster back to the version and etcd snapshot recorded before the most recent upgrade hop.
+// Every change goes through Exec so that a rollback can be planned without applying it.
+type RKE2Rollback struct {
+	Exec executor.Executor
+	// Inventory lists how to reach the other nodes of a multi-node cluster, which are reinstalled too. Required if
+	// the cluster has more than one node.
+	Inventory *inventory.Inventory
+}
+
+// rollbackNode is a node to roll back, reached over SSH unless remote is nil.
+type rollbackNode struct {
+	name   string
+	role   inventory.Role
+	remote *inventory.Node
+}
+
+// Rollback stops RKE2 on every node, restores the snapshot on this server, reinstalls the previous version on every
+// node and restarts them one at a time. Running it again rolls back the hop before.
+func (r RKE2Rollback) Rollback() error { //nolint: cyclop
+	x := r.Exec
+
+	history, err := rke2history.Load(rke2HistoryPath)
+	if err != nil {
+		return err
+	}
+	entry := history.Last()
+	if entry == nil {
+		return errors.New("no RKE2 upgrade recorded, nothing to roll back")
+	}
+	from, err := semver.ParseRKE2(entry.From)
+	if err != nil {
+		return err
+	}
+	state := "failed or interrupted"
+	if entry.Completed {
+		state = "completed"
+	}
+	fmt.Printf("Rolling back the %s upgrade from %s to %s started at %s\n", state, entry.From, entry.To,
+		entry.Time.Format(time.RFC3339))
+
+	// Validation gates: nothing is changed unless the rollback can be carried out on every node.
+	if _, err := os.Stat(entry.Snapshot); err != nil {
+		return fmt.Errorf("etcd snapshot taken before the upgrade to %s is missing: %w", entry.To, err)
+	}
+	nodes, err := r.nodes()
+	if err != nil {
+		return err
+	}
+	bundle, err := loadRKE2UpgradeBundle()
+	if err != nil {
+		return err
+	}
+	if !bundle.hasVersion(from) {
+		fmt.Printf("RKE2 %s is not bundled, it is installed from the network\n", from)
+	}
+
+	fmt.Println("Stopping RKE2 on every node")
+	for _, node := range nodes {
+		if err := r.run(node, "systemctl", "stop", "rke2-"+string(node.role)); err != nil {
+			return fmt.Errorf("error stopping RKE2 on %s: %w", node.name, err)
+		}
+	}
+
+	// The first node is this server, which holds the snapshot and restores it. The other servers rejoin with an
+	// empty database and sync it from this one.
+	for i, node := range nodes {
+		fmt.Printf("Reinstalling RKE2 %s on %s\n", from, node.name)
+		if err := r.reinstall(node, bundle, from); err != nil {
+			return fmt.Errorf("error reinstalling RKE2 on %s: %w", node.name, err)
+		}
+
+		switch {
+		case i == 0:
+			if err := x.Run("rke2", "server", "--cluster-reset", "--cluster-reset-restore-path="+entry.Snapshot); err != nil {
+				return fmt.Errorf("error restoring etcd snapshot %s: %w", entry.Snapshot, err)
+			}
+		case node.role == inventory.Server:
+			if err := r.run(node, "rm", "-rf", rke2DBDir); err != nil {
+				return fmt.Errorf("error removing the etcd database of %s: %w", node.name, err)
+			}
+		}
+
+		if err := r.run(node, "systemctl", "start", "rke2-"+string(node.role)); err != nil {
+			return fmt.Errorf("error starting RKE2 on %s: %w", node.name, err)
+		}
+		if err := x.Wait(fmt.Sprintf("node %s is ready", node.name), func() error {
+			return waitForNodeStatus("node/"+node.name, "Ready")
+		}); err != nil {
+			return fmt.Errorf("error waiting for %s: %w", node.name, err)
+		}
+	}
+
+	// Plans recorded in the snapshot must not upgrade the cluster again.
+	if err := x.Run("kubectl", "delete", "-n", "system-upgrade", "plans.upgrade.cattle.io", "--all",
+		"--ignore-not-found"); err != nil {
+		return err
+	}
+
+	// Validation gates: the rollback is complete once every node runs the previous version and the workloads are up.
+	for _, node := range nodes {
+		if err := x.Wait(fmt.Sprintf("node %s runs %s", node.name, from), func() error {
+			return waitForNewVersion("node/"+node.name, from.String())
+		}); err != nil {
+			return err
+		}
+	}
+	if err := x.Wait("all deployments and pods are ready", testDeploymentAndPods); err != nil {
+		return fmt.Errorf("error testing deployments and pods after the rollback: %w", err)
+	}
+
+	return x.Apply("remove the rolled back upgrade from "+rke2HistoryPath, func() error {
+		history.Pop()
+		return history.Save(rke2HistoryPath)
+	})
+}
+
+// nodes returns the nodes to roll back: this server first, then the other servers and the agents.
+func (r RKE2Rollback) nodes() ([]rollbackNode, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	// The API server may be down after a failed upgrade, so the inventory is relied on if there is one.
+	clusterNodes, listErr := getClusterNodes()
+
+	if r.Inventory == nil {
+		switch {
+		case listErr != nil:
+			fmt.Printf("Warning: failed to list cluster nodes, rolling back this host only: %v\n", listErr)
+		case len(clusterNodes) > 1:
+			return nil, fmt.Errorf("the cluster has %d nodes, an inventory is required to roll them all back",
+				len(clusterNodes))
+		}
+		return []rollbackNode{{name: strings.ToLower(hostname), role: inventory.Server}}, nil
+	}
+
+	local, ok := r.Inventory.Node(strings.ToLower(hostname))
+	if !ok || local.Role != inventory.Server {
+		return nil, fmt.Errorf("this host %s is not a server of the inventory", hostname)
+	}
+	for _, node := range clusterNodes {
+		if _, ok := r.Inventory.Node(node.name); !ok {
+			return nil, fmt.Errorf("cluster node %s is not listed in the inventory", node.name)
+		}
+	}
+
+	nodes := []rollbackNode{{name: local.Name, role: inventory.Server}}
+	for _, node := range append(r.Inventory.Servers(), r.Inventory.Agents()...) {
+		if node.Name != local.Name {
+			nodes = append(nodes, rollbackNode{name: node.Name, role: node.Role, remote: &node})
+		}
+	}
+	return nodes, nil
+}
+
+// run runs a command as root on node.
+func (r RKE2Rollback) run(node rollbackNode, name string, args ...string) error {
+	if node.remote == nil {
+		return r.Exec.Run(name, args...)
+	}
+	return runCommand(r.Exec, node.remote.Command(append([]string{"sudo", name}, args...)...))
+}
+
+// reinstall installs RKE2 version on node, from the upgrade bundle if it ships version and from the network
+// otherwise.
+func (r RKE2Rollback) reinstall(node rollbackNode, bundle *rke2UpgradeBundle, version semver.RKE2Version) error {
+	if !bundle.hasVersion(version) {
+		return r.run(node, "sh", "-c", fmt.Sprintf("curl -sfL %s | INSTALL_RKE2_VERSION=%s INSTALL_RKE2_TYPE=%s sh -",
+			rke2InstallScriptURL, version, node.role))
+	}
+
+	artifacts, err := filepath.Abs(filepath.Join(bundle.dir, version.String()))
+	if err != nil {
+		return err
+	}
+	script, err := filepath.Abs(filepath.Join(bundle.dir, "install.sh"))
+	if err != nil {
+		return err
+	}
+	if node.remote != nil {
+		if err := r.run(node, "rm", "-rf", remoteRollbackDir); err != nil {
+			return err
+		}
+		if err := runCommand(r.Exec, node.remote.Copy(artifacts, remoteRollbackDir)); err != nil {
+			return err
+		}
+		if err := runCommand(r.Exec, node.remote.Copy(script, remoteRollbackDir+"/install.sh")); err != nil {
+			return err
+		}
+		artifacts, script = remoteRollbackDir, remoteRollbackDir+"/install.sh"
+	}
+
+	if err := r.run(node, "env", "INSTALL_RKE2_ARTIFACT_PATH="+artifacts, "INSTALL_RKE2_TYPE="+string(node.role),
+		"sh", script); err != nil {
+		return err
+	}
+	// RKE2 imports the images of the version it runs from the staged tarballs, so that no network access is needed.
+	if err := r.run(node, "mkdir", "-p", rke2AgentImagesDir); err != nil {
+		return err
+	}
+	for _, file := range []string{"rke2-images.linux-amd64.tar.zst", "rke2-images-calico.linux-amd64.tar.zst"} {
+		if err := r.run(node, "cp", filepath.Join(artifacts, file), rke2AgentImagesDir); err != nil {
+			return err
+		}
+	}
+	if node.remote != nil {
+		return r.run(node, "rm", "-rf", remoteRollbackDir)
+	}
+	return nil
+}
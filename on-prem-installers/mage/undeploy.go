@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"os/exec"
 
 	"github.com/magefile/mage/sh"
 )
@@ -24,6 +25,11 @@ func (Undeploy) rke2server() error {
 		return nil
 	}
 
+	// onprem-uninstall reports what is left behind and keeps it in its inventory.
+	if _, err := exec.LookPath("onprem-uninstall"); err == nil {
+		return sh.RunV("sudo", "onprem-uninstall", "--yes", "--only", "rke2")
+	}
+
 	// TODO: Return nil if no cluster exists.
 	if err := sh.RunV("sudo", Rke2KillAllScript); err != nil {
 		return err
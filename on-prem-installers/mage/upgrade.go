@@ -7,6 +7,7 @@ package mage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,47 +18,142 @@ import (
 	"github.com/bitfield/script"
 	"github.com/magefile/mage/sh"
 
+	"github.com/open-edge-platform/edge-manageability-framework/internal/inventory"
 	"github.com/open-edge-platform/edge-manageability-framework/internal/retry"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2channel"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/rke2history"
+	"github.com/open-edge-platform/edge-manageability-framework/internal/semver"
 )
 
+// rke2ChannelPath is the RKE2 upgrade channel, listing the versions the cluster can be upgraded to.
+var rke2ChannelPath = filepath.Join("rke2", "upgrade-channel.yaml")
+
 func (Upgrade) rke2Cluster() error {
-	var stdout, stderr bytes.Buffer
+	return RKE2Upgrader{}.Upgrade()
+}
 
-	// Get Orchestrator node name
-	_, err := sh.Exec(nil, &stdout, &stderr, "kubectl", "get", "nodes", "-oname")
+// RKE2Upgrader upgrades the RKE2 cluster along the upgrade channel.
+type RKE2Upgrader struct {
+	// Target is the RKE2 version to upgrade to, the channel's default version if empty.
+	Target string
+	// Inventory lists how to reach the other nodes of a multi-node cluster, to preload the bundled artifacts on
+	// them. Optional.
+	Inventory *inventory.Inventory
+	// Concurrency is the number of agent nodes upgraded at the same time. Servers are always upgraded one at a time.
+	Concurrency int
+}
+
+// RKE2UpgradePath is the sequence of upgrades from the installed RKE2 version to the target.
+type RKE2UpgradePath struct {
+	// Current is the oldest version the cluster nodes run.
+	Current semver.RKE2Version
+	Target  semver.RKE2Version
+	// Hops are the versions upgraded to in order, ending with Target. Empty if Current is Target.
+	Hops []semver.RKE2Version
+	// Servers and Agents are the names of the cluster nodes by role.
+	Servers []string
+	Agents  []string
+	// Batches are the groups of nodes upgraded together at each hop, in order.
+	Batches [][]string
+	// Bundled is set when the installer ships the artifacts of every hop, so the upgrade needs no network access.
+	Bundled bool
+}
+
+// Path validates the target against the upgrade channel and computes the upgrades needed to reach it from the
+// installed version.
+func (u RKE2Upgrader) Path() (*RKE2UpgradePath, error) {
+	channel, err := rke2channel.Load(rke2ChannelPath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	target := channel.Default
+	if u.Target != "" {
+		if target, err = semver.ParseRKE2(u.Target); err != nil {
+			return nil, fmt.Errorf("invalid target version: %w", err)
+		}
+	}
+
+	nodes, err := getClusterNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	// A cluster whose earlier upgrade was interrupted resumes from the oldest version its nodes run.
+	path := &RKE2UpgradePath{Current: nodes[0].version, Target: target}
+	for _, node := range nodes {
+		if node.version.LessThan(path.Current) {
+			path.Current = node.version
+		}
+		if node.controlPlane {
+			path.Servers = append(path.Servers, node.name)
+		} else {
+			path.Agents = append(path.Agents, node.name)
+		}
+	}
+	path.Batches = inventory.UpgradeBatches(path.Servers, path.Agents, u.Concurrency)
+
+	if path.Hops, err = channel.Path(path.Current, target); err != nil {
+		return nil, err
 	}
-	nodeName := sanitizeString(stdout.String())
 
-	// Get current RKE2 version
-	currentVersion, err := getCurrentRKE2Version(nodeName)
+	bundle, err := loadRKE2UpgradeBundle()
 	if err != nil {
-		return fmt.Errorf("failed to get current RKE2 version: %w", err)
+		return nil, err
+	}
+	path.Bundled = bundle != nil
+	for _, hop := range path.Hops {
+		path.Bundled = path.Bundled && bundle.hasVersion(hop)
 	}
-	fmt.Printf("Current RKE2 version: %s\n", currentVersion)
 
-	// Target version
-	targetVersion := "v1.34.4+rke2r1"
+	return path, nil
+}
+
+// Upgrade upgrades the cluster through every hop of Path. At each hop the servers are drained and upgraded one at a
+// time, then the agents in batches of Concurrency nodes, and every node must run the new version and be Ready before
+// the next batch starts. Artifacts shipped in the upgrade bundle are preloaded into the containerd image store of
+// every node so that air-gapped clusters can be upgraded; anything not bundled is pulled from the network.
+func (u RKE2Upgrader) Upgrade() error { //nolint: cyclop
+	path, err := u.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Current RKE2 version: %s\n", path.Current)
 
 	// Check if already at target version
-	if currentVersion == targetVersion {
+	if len(path.Hops) == 0 {
 		fmt.Println("RKE2 is already at the target version. No upgrade needed.")
 		return nil
 	}
 
-	// Determine upgrade path from current version to target
-	upgradePath := determineUpgradePath(currentVersion, targetVersion)
-	if len(upgradePath) == 0 {
-		return fmt.Errorf("unable to determine upgrade path from %s to %s", currentVersion, targetVersion)
+	fmt.Printf("Upgrade path: %v\n", path.Hops)
+
+	hosts, err := u.hosts(path)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Upgrade path: %v\n", upgradePath)
+	bundle, err := loadRKE2UpgradeBundle()
+	if err != nil {
+		return err
+	}
 
 	// Install the system-upgrade-controller to perform automated upgrade
-	if err := sh.RunV("kubectl", "apply", "-f",
-		"https://github.com/rancher/system-upgrade-controller/releases/download/v0.13.2/system-upgrade-controller.yaml",
-	); err != nil {
+	controllerManifest := systemUpgradeControllerURL
+	if bundle != nil {
+		fmt.Println("Installing the bundled system-upgrade-controller")
+		for name, host := range hosts {
+			if err := bundle.preloadController(host); err != nil {
+				return fmt.Errorf("failed to preload the system-upgrade-controller on %s: %w", name, err)
+			}
+		}
+		if controllerManifest, err = bundle.controllerManifest(); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("No upgrade bundle found, installing the system-upgrade-controller from the network")
+	}
+	if err := sh.RunV("kubectl", "apply", "-f", controllerManifest); err != nil {
 		return err
 	}
 
@@ -78,70 +174,98 @@ func (Upgrade) rke2Cluster() error {
 		fmt.Printf("ignoring this error as it might be caused by the CRD not being created yet\n")
 	}
 
-	// Label Orchestrator node to mark it as ready for the upgrade
-	if err := sh.RunV("kubectl", "label", nodeName, "rke2-upgrade=true", "--overwrite"); err != nil {
+	// No node is selected by the upgrade Plans until it is labelled for upgrade below.
+	if err := labelNodesForUpgrade(append(path.Servers, path.Agents...), false); err != nil {
+		return err
+	}
+
+	// Each node gets the full deployment timeout to upgrade, however many nodes were upgraded before.
+	nodeTimeout, err := parseDeploymentTimeout()
+	if err != nil {
+		return err
+	}
+
+	history, err := rke2history.Load(rke2HistoryPath)
+	if err != nil {
 		return err
 	}
 
 	// Perform upgrades along the determined path
-	for i, rke2UpgradeVersion := range upgradePath {
-		// Set version in upgrade Plan and render template.
-		tmpl, err := template.ParseFiles(filepath.Join("rke2", "upgrade-plan.tmpl"))
-		if err != nil {
-			return err
-		}
+	from := path.Current
+	for i, hop := range path.Hops {
+		rke2UpgradeVersion := hop.String()
 
-		upgradePlan, err := os.Create(filepath.Join("rke2", "upgrade-plan.yaml"))
-		if err != nil {
+		// The snapshot lets --rollback return the cluster to from if the hop fails or misbehaves.
+		if err := snapshotBeforeHop(history, from, hop); err != nil {
 			return err
 		}
-		defer func() {
-			if err := upgradePlan.Close(); err != nil {
-				fmt.Printf("Warning: failed to close upgrade plan file: %v\n", err)
-			}
-		}()
 
-		if err := tmpl.Execute(upgradePlan, struct{ Version string }{Version: rke2UpgradeVersion}); err != nil {
-			return err
+		if bundle.hasVersion(hop) {
+			for name, host := range hosts {
+				fmt.Printf("Preloading bundled RKE2 %s artifacts on %s\n", rke2UpgradeVersion, name)
+				if err := bundle.preload(host, hop); err != nil {
+					return fmt.Errorf("failed to preload RKE2 %s on %s: %w", rke2UpgradeVersion, name, err)
+				}
+			}
+		} else {
+			fmt.Printf("RKE2 %s is not bundled, pulling its images from the network\n", rke2UpgradeVersion)
 		}
 
-		// Apply the upgrade Plan CRD
-		if err := sh.RunV("kubectl", "apply", "-f", filepath.Join("rke2", "upgrade-plan.yaml")); err != nil {
+		// Draining the only node of a cluster would evict workloads with nowhere to go, so it is cordoned only.
+		drain := len(path.Servers)+len(path.Agents) > 1
+		if err := applyUpgradePlans(rke2UpgradeVersion, max(u.Concurrency, 1), drain); err != nil {
 			return err
 		}
 
-		fmt.Printf("RKE2 upgrade Plan applied, waiting for upgrade to version %s to complete...\n", rke2UpgradeVersion)
+		fmt.Printf("RKE2 upgrade Plans applied, upgrading to version %s...\n", rke2UpgradeVersion)
+
+		for _, batch := range path.Batches {
+			if err := labelNodesForUpgrade(batch, true); err != nil {
+				return err
+			}
 
-		// Wait for node to upgrade to new rke2 version
-		// The kubeletVersion field uses "+" instead of "-" in its version string, so we replace "-" with "+" here.
-		if err := waitForNewVersion(nodeName, strings.ReplaceAll(rke2UpgradeVersion, "-", "+")); err != nil {
+			// Every node of the batch must be healthy on the new version before the next batch is upgraded.
+			for _, name := range batch {
+				nodeName := "node/" + name
+				if err := setDeploymentTimeout(nodeTimeout.String()); err != nil {
+					return err
+				}
+
+				// Wait for node to upgrade to new rke2 version
+				if err := waitForNewVersion(nodeName, rke2UpgradeVersion); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+
+				// Then wait for Ready state which means upgrade has been completed
+				if err := waitForNodeStatus(nodeName, "Ready"); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+
+		// Unlabel the nodes so that the Plans of the next hop select them batch by batch again.
+		if err := labelNodesForUpgrade(append(path.Servers, path.Agents...), false); err != nil {
 			return err
 		}
 
-		// Then wait for Ready state which means upgrade has been completed
-		if err := waitForNodeStatus(nodeName, "Ready"); err != nil {
+		history.Last().Completed = true
+		if err := history.Save(rke2HistoryPath); err != nil {
 			return err
 		}
+		from = hop
 
-		if i < len(upgradePath)-1 {
+		if i < len(path.Hops)-1 {
 			fmt.Printf("RKE2 upgraded to intermediate version %s, starting next upgrade...\n", rke2UpgradeVersion)
 		}
 	}
 
-	// Clean up after upgrade
-	if err := sh.RunV("kubectl", "label", nodeName, "rke2-upgrade=false", "--overwrite"); err != nil {
-		return err
-	}
-
 	// Delete finalizers as they sometimes cause the delete operation to block indefinitely
 	if err := sh.RunV(
 		"kubectl", "patch", "clusterrolebinding", "system-upgrade", "-p", `{"metadata":{"finalizers":null}}`,
 	); err != nil {
 		return err
 	}
-	if err := sh.RunV("kubectl", "delete", "-f",
-		"https://github.com/rancher/system-upgrade-controller/releases/download/v0.13.2/system-upgrade-controller.yaml",
-	); err != nil {
+	if err := sh.RunV("kubectl", "delete", "-f", controllerManifest); err != nil {
 		return err
 	}
 
@@ -150,6 +274,146 @@ func (Upgrade) rke2Cluster() error {
 	return nil
 }
 
+// hosts returns how to reach each node to preload the upgrade bundle: the node the upgrade runs on directly, the
+// others over SSH as listed in the inventory. Nodes that cannot be reached pull the upgrade images from the network.
+func (u RKE2Upgrader) hosts(path *RKE2UpgradePath) (map[string]rke2Host, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	names := append(append([]string{}, path.Servers...), path.Agents...)
+	hosts := map[string]rke2Host{}
+	for _, name := range names {
+		switch {
+		case len(names) == 1, strings.EqualFold(name, hostname):
+			hosts[name] = rke2Host{}
+		case u.Inventory != nil:
+			node, ok := u.Inventory.Node(name)
+			if !ok {
+				fmt.Printf("Warning: node %s is not listed in the inventory, it pulls the upgrade images itself\n", name)
+				continue
+			}
+			hosts[name] = rke2Host{node: &node}
+		default:
+			fmt.Printf("Warning: no inventory given to reach node %s, it pulls the upgrade images itself\n", name)
+		}
+	}
+	return hosts, nil
+}
+
+// rke2Host runs commands on a cluster node: the local host if node is nil and over SSH otherwise.
+type rke2Host struct {
+	node *inventory.Node
+}
+
+func (h rke2Host) run(name string, args ...string) error {
+	if h.node == nil {
+		return sh.RunV(name, args...)
+	}
+	command := h.node.Command(append([]string{"sudo", name}, args...)...)
+	return sh.RunV(command[0], command[1:]...)
+}
+
+// stage makes the local file or directory src available on the host and returns its path there.
+func (h rke2Host) stage(src string) (string, error) {
+	if h.node == nil {
+		return src, nil
+	}
+	dst := "/tmp/rke2-upgrade-" + filepath.Base(src)
+	if err := h.run("rm", "-rf", dst); err != nil {
+		return "", err
+	}
+	command := h.node.Copy(src, dst)
+	if err := sh.RunV(command[0], command[1:]...); err != nil {
+		return "", fmt.Errorf("failed to copy %s to %s: %w", src, h.node.Name, err)
+	}
+	return dst, nil
+}
+
+// unstage removes what stage copied to the host.
+func (h rke2Host) unstage(path string) error {
+	if h.node == nil {
+		return nil
+	}
+	return h.run("rm", "-rf", path)
+}
+
+type clusterNode struct {
+	name         string
+	controlPlane bool
+	version      semver.RKE2Version
+}
+
+// getClusterNodes lists the nodes of the cluster with their role and RKE2 version.
+func getClusterNodes() ([]clusterNode, error) {
+	out, err := sh.Output("kubectl", "get", "nodes", "-o", `jsonpath={range .items[*]}{.metadata.name}{"\t"}`+
+		`{.metadata.labels.node-role\.kubernetes\.io/control-plane}{"\t"}{.status.nodeInfo.kubeletVersion}{"\n"}{end}`)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []clusterNode
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected node %q", line)
+		}
+		version, err := semver.ParseRKE2(sanitizeString(fields[2]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of node %s: %w", fields[0], err)
+		}
+		nodes = append(nodes, clusterNode{name: fields[0], controlPlane: fields[1] == "true", version: version})
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes found")
+	}
+	return nodes, nil
+}
+
+// labelNodesForUpgrade selects or deselects nodes for the upgrade Plans.
+func labelNodesForUpgrade(names []string, upgrade bool) error {
+	args := []string{"label", "--overwrite"}
+	for _, name := range names {
+		args = append(args, "node/"+name)
+	}
+	return sh.RunV("kubectl", append(args, fmt.Sprintf("rke2-upgrade=%t", upgrade))...)
+}
+
+// applyUpgradePlans renders and applies the upgrade Plans of the servers and agents for version.
+func applyUpgradePlans(version string, concurrency int, drain bool) error {
+	// Set version in upgrade Plan and render template.
+	tmpl, err := template.ParseFiles(filepath.Join("rke2", "upgrade-plan.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	type plan struct {
+		Name, Image, Version string
+		ControlPlane, Drain  bool
+		Concurrency          int
+	}
+	var rendered bytes.Buffer
+	for _, p := range []plan{
+		// The upgrade image is pulled if missing only, so a preloaded image is used as is.
+		{Name: "server-plan", Image: rke2UpgradeImage, Version: version, ControlPlane: true, Drain: drain, Concurrency: 1},
+		{Name: "agent-plan", Image: rke2UpgradeImage, Version: version, Drain: drain, Concurrency: concurrency},
+	} {
+		rendered.WriteString("---\n")
+		if err := tmpl.Execute(&rendered, p); err != nil {
+			return err
+		}
+	}
+
+	planPath := filepath.Join("rke2", "upgrade-plan.yaml")
+	if err := os.WriteFile(planPath, rendered.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	// Apply the upgrade Plan CRD
+	return sh.RunV("kubectl", "apply", "-f", planPath)
+}
+
 // nodeName should be passed in format 'node/<node-name>'
 // status argument must be either 'Ready' or 'NotReady'.
 // If arguments are not in correct format, function will not behave correctly.
@@ -225,6 +489,12 @@ func waitForNodeStatus(nodeName, status string) error {
 
 // nodeName should be passed in format 'node/<node-name>'
 func waitForNewVersion(nodeName, version string) error {
+	// The kubeletVersion field uses "+" instead of "-" in its version string, ParseRKE2 accepts both forms.
+	expectedVersion, err := semver.ParseRKE2(version)
+	if err != nil {
+		return err
+	}
+
 	timeout, err := parseDeploymentTimeout()
 	if err != nil {
 		return err
@@ -247,7 +517,7 @@ func waitForNewVersion(nodeName, version string) error {
 		}
 		foundVersion = sanitizeString(foundVersion)
 
-		if foundVersion != version {
+		if found, err := semver.ParseRKE2(foundVersion); err != nil || !found.Equal(expectedVersion) {
 			fmt.Printf("RKE2 version is not %s yet...\n", version)
 			fmt.Println("Time remaining ⏰: ", time.Until(expireTime))
 			return fmt.Errorf("RKE2 version is not %s", version)
@@ -272,86 +542,3 @@ func waitForNewVersion(nodeName, version string) error {
 func sanitizeString(str string) string {
 	return strings.Trim(str, "\"\n\r\t ")
 }
-
-// getCurrentRKE2Version retrieves the current RKE2 version from the node.
-// nodeName should be in format 'node/<node-name>'
-func getCurrentRKE2Version(nodeName string) (string, error) {
-	version, err := script.NewPipe().
-		Exec(fmt.Sprintf("kubectl get %s -o json", nodeName)).
-		JQ(`.status.nodeInfo.kubeletVersion`).
-		String()
-	if err != nil {
-		return "", err
-	}
-	return sanitizeString(version), nil
-}
-
-// determineUpgradePath determines the upgrade path from current to target version.
-// It skips versions already installed and only includes necessary intermediate versions.
-func determineUpgradePath(currentVersion, targetVersion string) []string {
-	// All available versions in order
-	allVersions := []string{
-		"v1.30.14+rke2r2", // Patch update within 1.30
-		"v1.31.13+rke2r1", // Upgrade to 1.31
-		"v1.32.9+rke2r1",  // Upgrade to 1.32
-		"v1.33.5+rke2r1",  // Upgrade to 1.33
-		"v1.34.1+rke2r1",  // Upgrade to 1.34.1
-		"v1.34.4+rke2r1",  // Final target version
-	}
-
-	// Extract minor version from full version string (e.g., "v1.30.14+rke2r2" -> "1.30")
-	extractMinorVersion := func(version string) string {
-		parts := strings.Split(version, ".")
-		if len(parts) >= 2 {
-			return parts[0] + "." + parts[1]
-		}
-		return version
-	}
-
-	currentMinor := extractMinorVersion(currentVersion)
-	targetMinor := extractMinorVersion(targetVersion)
-
-	// Find starting index
-	startIdx := -1
-	for i, v := range allVersions {
-		if v == currentVersion {
-			startIdx = i
-			break
-		}
-		if strings.Contains(v, currentMinor) && startIdx == -1 {
-			startIdx = i
-		}
-	}
-
-	// If current version not found in list, start from beginning
-	if startIdx == -1 {
-		startIdx = 0
-	} else {
-		startIdx++ // Start from next version after current
-	}
-
-	// Find ending index
-	endIdx := -1
-	for i, v := range allVersions {
-		if v == targetVersion {
-			endIdx = i
-			break
-		}
-		if strings.Contains(v, targetMinor) {
-			endIdx = i
-		}
-	}
-
-	// If target version not found, include everything to the end
-	if endIdx == -1 {
-		endIdx = len(allVersions) - 1
-	}
-
-	// Build upgrade path
-	var upgradePath []string
-	if startIdx <= endIdx {
-		upgradePath = allVersions[startIdx : endIdx+1]
-	}
-
-	return upgradePath
-}
@@ -820,7 +820,7 @@ echo "OS level configuration upgraded to $(dpkg-query -W -f='${Version}' onprem-
 
 # Run RKE2 upgrade
 echo "Upgrading RKE2..."
-eval "sudo DEBIAN_FRONTEND=noninteractive NEEDRESTART_MODE=l apt-get install --only-upgrade --allow-downgrades -y $cwd/$deb_dir_name/onprem-ke-installer_*_amd64.deb"
+eval "sudo RKE2_INVENTORY='${RKE2_INVENTORY:-}' RKE2_UPGRADE_CONCURRENCY='${RKE2_UPGRADE_CONCURRENCY:-1}' DEBIAN_FRONTEND=noninteractive NEEDRESTART_MODE=l apt-get install --only-upgrade --allow-downgrades -y $cwd/$deb_dir_name/onprem-ke-installer_*_amd64.deb"
 echo "RKE2 upgraded to $(dpkg-query -W -f='${Version}' onprem-ke-installer)"
 
 # Run Gitea upgrade
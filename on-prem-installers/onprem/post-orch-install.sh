@@ -258,6 +258,10 @@ create_namespaces() {
   for ns in "${orch_namespace_list[@]}"; do
     kubectl create ns "$ns" --dry-run=client -o yaml | kubectl apply -f -
   done
+  # Record the namespaces for onprem-uninstall(1), which older config installers don't have
+  if command -v onprem-uninstall >/dev/null; then
+    sudo onprem-uninstall --record namespace "${orch_namespace_list[@]}"
+  fi
 }
 
 create_smtp_secrets() {
@@ -628,6 +632,33 @@ install_root_app() {
     -n "$namespace" --create-namespace
 }
 
+install_backup_timer() {
+  if [[ -z "${ORCH_BACKUP_SCHEDULE:-}" ]]; then
+    echo "Scheduled backups skipped (ORCH_BACKUP_SCHEDULE is not set)"
+    return
+  fi
+
+  echo "Scheduling Orchestrator backups on ${ORCH_BACKUP_SCHEDULE}..."
+  sudo onprem-backup --install-timer \
+    --schedule "$ORCH_BACKUP_SCHEDULE" \
+    --dir "${ORCH_BACKUP_DIR:-/var/backups/orch}" \
+    --nfs "${ORCH_BACKUP_NFS:-}" \
+    --keep-daily "${ORCH_BACKUP_KEEP_DAILY:-7}" \
+    --keep-weekly "${ORCH_BACKUP_KEEP_WEEKLY:-4}" \
+    --min-free-gib "${ORCH_BACKUP_MIN_FREE_GIB:-10}" \
+    --kubeconfig "$KUBECONFIG"
+}
+
+install_vault_unseal_service() {
+  if [[ "${VAULT_AUTO_UNSEAL:-true}" != "true" ]]; then
+    echo "Vault auto-unseal skipped (VAULT_AUTO_UNSEAL is not true)"
+    return
+  fi
+
+  echo "Installing the Vault auto-unseal service..."
+  sudo onprem-vault-unseal --install-service --kubeconfig "$KUBECONFIG"
+}
+
 
 ################################
 ##### INSTALL SCRIPT START #####
@@ -778,6 +809,9 @@ create_postgres_password orch-database "$postgres_password"
 
 install_root_app
 
+install_backup_timer
+install_vault_unseal_service
+
 printf "\nEdge Orchestrator SW is being deployed, please wait for all applications to deploy...\n\
 To check the status of the deployment run 'kubectl get applications -A'.\n\
 Installation is completed when 'root-app' Application is in 'Healthy' and 'Synced' state.\n\
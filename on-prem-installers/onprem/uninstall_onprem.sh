@@ -24,6 +24,14 @@ remove_package() {
     sudo dpkg --purge --force-remove-reinstreq "$package_name"
 }
 
+# onprem-uninstall removes what the installers recorded in reverse dependency order and reports the leftovers. The
+# arguments are passed to it, e.g. --keep-data to keep the volumes.
+if command -v onprem-uninstall >/dev/null; then
+    sudo onprem-uninstall --yes "$@"
+    sudo rm -rf repo_archives/ installers/
+    exit 0
+fi
+
 for package in "${packages[@]}"; do
     echo "Removing $package"
     remove_package "$package"
@@ -4,6 +4,10 @@
 #
 # SPDX-License-Identifier: Apache-2.0
 
+# Dump and restore of the databases around the root-app upgrade of onprem_upgrade.sh, which sources this file.
+# onprem-upgrade runs the same steps in Go. Moving a running cluster to a new major version of PostgreSQL is done
+# with onprem-postgres-upgrade instead.
+
 postgres_namespace=orch-database
 POSTGRES_LOCAL_BACKUP_PATH="./"
 local_backup_file="${postgres_namespace}_backup.sql"
@@ -7,22 +7,28 @@
 # Script Name: rke2installerlocal.sh
 # Description: This script installs RKE2 on the machine where the script is executed.
 #              This script takes arguments as described below.
-# Usage: ./rke2/rke2installerlocal.sh [ -i <host ip>] [ -v <rke2_version> ] [ -a ]
+# Usage: ./rke2/rke2installerlocal.sh [ -i <host ip>] [ -v <rke2_version> ] [ -r <role> ] [ -a ]
 #    -i:             Host IP (optional)
 #    -v:             rke2 version (optional)
+#    -r:             RKE2 role, server or agent (optional, defaults to server)
 #    -h:             help (optional)
+#
+# Nodes joining an existing cluster are configured with a drop-in in /etc/rancher/rke2/config.yaml.d written before
+# this script runs.
 
 set +xe
 
 HELP=""
 RKE2VERSION="v1.34.4+rke2r1"
 HOSTIP=""
+ROLE="server"
 ROOT_DIR=$(pwd)
 
-while getopts 'i:v:h' flag; do
+while getopts 'i:v:r:h' flag; do
   case "${flag}" in
    # i) HOSTIP="${HOSTIP}" ;; is a noop shellcheck flags?
     v) RKE2VERSION="${OPTARG}" ;;
+    r) ROLE="${OPTARG}" ;;
     h) HELP='true' ;;
     *) HELP='true' ;;
   esac
@@ -34,7 +40,7 @@ Purpose:
 install rke2 server on localhost
 
 Usage:
-$(basename "$0") [ -i <host ip >  -v <rke2_version> -a ]
+$(basename "$0") [ -i <host ip >  -v <rke2_version> -r <role> -a ]
 
 ex:
 ./rke2/rkeinstallerlocal.sh -i 192.168.0.100 -v v1.25.4+rke2r1 -a
@@ -42,6 +48,7 @@ ex:
 Options:
     -i:             Host IP (optional)
     -v:             rke2 version (optional)
+    -r:             RKE2 role, server or agent (optional, defaults to server)
     -h:             help (optional)
 EOF
 }
@@ -52,6 +59,12 @@ if [[ $HELP ]]; then
     exit 1
 fi
 
+if [[ "$ROLE" != "server" && "$ROLE" != "agent" ]]; then
+    echo "Invalid role ${ROLE}, must be server or agent"
+    usage
+    exit 1
+fi
+
 # Escape '+' character if found in the URL request
 # shellcheck disable=SC2001
 RKE2VERSION=$(echo "$RKE2VERSION" | sed 's/+/%2b/g')
@@ -105,9 +118,9 @@ fi
 
 # Install RKE2
 if [[ ${INSTALL_RKE2_MIRROR} && ${INSTALL_RKE2_MIRROR} == "cn" ]]; then
-  sudo INSTALL_RKE2_ARTIFACT_PATH="${ROOT_DIR}/assets/rke2/" INSTALL_RKE2_MIRROR=cn INSTALL_RKE2_CHANNEL="$RKE2VERSION"  sh "${ROOT_DIR}/assets/rke2/install.sh"
+  sudo INSTALL_RKE2_ARTIFACT_PATH="${ROOT_DIR}/assets/rke2/" INSTALL_RKE2_TYPE="$ROLE" INSTALL_RKE2_MIRROR=cn INSTALL_RKE2_CHANNEL="$RKE2VERSION"  sh "${ROOT_DIR}/assets/rke2/install.sh"
 else
-  sudo INSTALL_RKE2_ARTIFACT_PATH="${ROOT_DIR}/assets/rke2/" sh "${ROOT_DIR}/assets/rke2/install.sh"
+  sudo INSTALL_RKE2_ARTIFACT_PATH="${ROOT_DIR}/assets/rke2/" INSTALL_RKE2_TYPE="$ROLE" sh "${ROOT_DIR}/assets/rke2/install.sh"
 fi
 # Check return status of RKE2 install process
 # shellcheck disable=SC2181
@@ -123,6 +136,13 @@ fi
 sudo mkdir -p /etc/rancher/rke2
 sudo cp rke2/audit-policy.yaml /etc/rancher/rke2/audit-policy.yaml
 
+if [[ "$ROLE" == "agent" ]]; then
+# Agents take the cluster configuration from the servers, only the kubelet is configured locally.
+sudo bash -c 'cat << EOF >  /etc/rancher/rke2/config.yaml
+kubelet-arg:
+  - "max-pods=200"
+EOF'
+else
 # Enable Calico CNI. Disable Canal CNI and Nginx Ingress.
 sudo mkdir -p /etc/rancher/rke2
 sudo bash -c 'cat << EOF >  /etc/rancher/rke2/config.yaml
@@ -170,18 +190,19 @@ spec:
     service:
       name: "kube-dns"
 EOF'
+fi
 
 # Copy Calico Images tarball
 sudo mkdir -p /var/lib/rancher/rke2/agent/images/
 sudo cp "${ROOT_DIR}/assets/rke2/rke2-images-calico.linux-amd64.tar.zst" /var/lib/rancher/rke2/agent/images/
 
 # Enable RKE2
-sudo systemctl enable --now rke2-server.service
+sudo systemctl enable --now "rke2-${ROLE}.service"
 # Provide some buffer time before checking running status
 sleep 5
 
-running=$(systemctl status rke2-server.service | grep 'active (running)')
+running=$(systemctl status "rke2-${ROLE}.service" | grep 'active (running)')
 if [ "$running" == "" ]; then
-  echo "RKE2 server is not in active (running) state"
+  echo "RKE2 ${ROLE} is not in active (running) state"
   exit 1
 fi